@@ -0,0 +1,219 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth selects and applies credentials for fetching private
+// repositories from remote hosts.
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// A Config assigns credentials to remote hosts. It is usually read from a
+// JSON file of the form
+//
+//	{
+//	  "hosts": {
+//	    "github.com": {"token_env": "GITHUB_TOKEN"},
+//	    "github.com/example": {"github_app": {
+//	      "app_id": 12345,
+//	      "installation_id": 67890,
+//	      "private_key": "/path/to/app.pem"
+//	    }},
+//	    "gitlab.example.com": {"ssh_key": "/path/to/id_ed25519"}
+//	  }
+//	}
+//
+// Each key is a host name, optionally followed by a path prefix. The
+// credential for a repository is the one whose key is the longest prefix of
+// the canonical form of its URL (see deps.CanonicalURL), matching whole path
+// components.
+type Config struct {
+	Hosts map[string]*Credential `json:"hosts"`
+}
+
+// A Credential describes how to authenticate to a host. At most one of Token,
+// TokenEnv, and GitHubApp should be set.
+type Credential struct {
+	// The user name sent with a token over HTTPS. If empty, use
+	// "x-access-token", which GitHub accepts for every kind of token.
+	User string `json:"user,omitempty"`
+
+	// An access token to send over HTTPS.
+	Token string `json:"token,omitempty"`
+
+	// The name of an environment variable holding an access token.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// The path of an SSH private key for SSH remotes.
+	SSHKey string `json:"ssh_key,omitempty"`
+
+	// Credentials for a GitHub App installation, from which short-lived
+	// access tokens are obtained as needed.
+	GitHubApp *GitHubApp `json:"github_app,omitempty"`
+
+	mu      sync.Mutex
+	token   string    // the current installation token, if any
+	expires time.Time // when token expires
+}
+
+// Load reads a Config from the JSON file at path.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(Config)
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %q: %v", path, err)
+	}
+	for key, cred := range cfg.Hosts {
+		if cred == nil {
+			return nil, fmt.Errorf("parsing %q: no credential for %q", path, key)
+		}
+	}
+	return cfg, nil
+}
+
+// FromEnv returns the Config read from path, if path != "". Otherwise it
+// returns a Config that uses the tokens in the GITHUB_TOKEN and GITLAB_TOKEN
+// environment variables, if set, for github.com and gitlab.com respectively.
+func FromEnv(path string) (*Config, error) {
+	if path != "" {
+		return Load(path)
+	}
+	cfg := &Config{Hosts: make(map[string]*Credential)}
+	if os.Getenv("GITHUB_TOKEN") != "" {
+		cfg.Hosts["github.com"] = &Credential{TokenEnv: "GITHUB_TOKEN"}
+	}
+	if os.Getenv("GITLAB_TOKEN") != "" {
+		cfg.Hosts["gitlab.com"] = &Credential{User: "oauth2", TokenEnv: "GITLAB_TOKEN"}
+	}
+	return cfg, nil
+}
+
+// Lookup returns the credential for the repository at url, or nil if there is
+// none. A nil *Config has no credentials.
+func (c *Config) Lookup(url string) *Credential {
+	if c == nil {
+		return nil
+	}
+	curl := deps.CanonicalURL(url)
+	var best string
+	var cred *Credential
+	for key, kc := range c.Hosts {
+		key = strings.TrimSuffix(key, "/")
+		if (curl == key || strings.HasPrefix(curl, key+"/")) && len(key) > len(best) {
+			best, cred = key, kc
+		}
+	}
+	return cred
+}
+
+// HTTPToken returns the access token to send over HTTPS, or "" if c has none.
+func (c *Credential) HTTPToken(ctx context.Context) (string, error) {
+	switch {
+	case c == nil:
+		return "", nil
+	case c.Token != "":
+		return c.Token, nil
+	case c.TokenEnv != "":
+		tok := os.Getenv(c.TokenEnv)
+		if tok == "" {
+			return "", fmt.Errorf("environment variable %s is not set", c.TokenEnv)
+		}
+		return tok, nil
+	case c.GitHubApp != nil:
+		return c.appToken(ctx)
+	}
+	return "", nil
+}
+
+// appToken returns a current installation token for c.GitHubApp, requesting a
+// new one if the last one has expired or is about to.
+func (c *Credential) appToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.expires) > 5*time.Minute {
+		return c.token, nil
+	}
+	tok, exp, err := c.GitHubApp.installationToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expires = tok, exp
+	return tok, nil
+}
+
+// GitEnv returns environment settings that make the git command authenticate
+// to the repository at url with c. Settings are passed in the environment
+// rather than as arguments, so that secrets do not appear in process
+// listings. A nil *Credential has no settings.
+func (c *Credential) GitEnv(ctx context.Context, url string) ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	var env []string
+	if isSSH(url) {
+		if c.SSHKey == "" {
+			return nil, nil
+		}
+		env = append(env, "GIT_SSH_COMMAND=ssh -o IdentitiesOnly=yes -i "+shellQuote(c.SSHKey))
+	} else {
+		tok, err := c.HTTPToken(ctx)
+		if err != nil {
+			return nil, err
+		} else if tok == "" {
+			return nil, nil
+		}
+		user := c.User
+		if user == "" {
+			user = "x-access-token"
+		}
+		basic := base64.StdEncoding.EncodeToString([]byte(user + ":" + tok))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+basic,
+		)
+	}
+	// Fail rather than wait for a password if the credential is refused.
+	return append(env, "GIT_TERMINAL_PROMPT=0"), nil
+}
+
+// isSSH reports whether url refers to a remote reached over SSH, either with
+// an explicit scheme or in the scp-like form user@host:path.
+func isSSH(url string) bool {
+	if i := strings.Index(url, "://"); i >= 0 {
+		scheme := url[:i]
+		return scheme == "ssh" || scheme == "git+ssh" || scheme == "ssh+git"
+	}
+	i := strings.Index(url, ":")
+	return i > 0 && !strings.ContainsAny(url[:i], "/\\")
+}
+
+// shellQuote quotes s as a single word for the POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
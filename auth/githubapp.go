@@ -0,0 +1,131 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A GitHubApp identifies an installation of a GitHub App, whose access tokens
+// are used to fetch the repositories the installation can see.
+type GitHubApp struct {
+	AppID          int64  `json:"app_id"`
+	InstallationID int64  `json:"installation_id"`
+	PrivateKey     string `json:"private_key"` // path of the app's PEM key
+
+	// The base URL of the GitHub API. If empty, use https://api.github.com.
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// installationToken requests a new access token for the installation, and
+// returns it with its expiration time.
+func (a *GitHubApp) installationToken(ctx context.Context) (string, time.Time, error) {
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	api := strings.TrimSuffix(a.APIURL, "/")
+	if api == "" {
+		api = "https://api.github.com"
+	}
+	url := api + "/app/installations/" + strconv.FormatInt(a.InstallationID, 10) + "/access_tokens"
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	rsp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusCreated {
+		return "", time.Time{}, fmt.Errorf("github app %d: requesting token: %s", a.AppID, rsp.Status)
+	}
+	var tok struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(rsp.Body).Decode(&tok); err != nil {
+		return "", time.Time{}, fmt.Errorf("github app %d: decoding token: %v", a.AppID, err)
+	}
+	return tok.Token, tok.ExpiresAt, nil
+}
+
+// jwt returns a JSON Web Token identifying the app as of now, signed with its
+// private key, as GitHub requires to request installation tokens.
+func (a *GitHubApp) jwt(now time.Time) (string, error) {
+	key, err := a.signer()
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(), // allow for clock skew
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(a.AppID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	msg := header + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(msg))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return msg + "." + enc.EncodeToString(sig), nil
+}
+
+// signer reads the app's RSA private key, in PKCS #1 or PKCS #8 form.
+func (a *GitHubApp) signer() (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(a.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %q", a.PrivateKey)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q: %v", a.PrivateKey, err)
+	}
+	rk, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github app key is not an RSA key")
+	}
+	return rk, nil
+}
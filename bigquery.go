@@ -0,0 +1,275 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// A bqField describes a column of a BigQuery table, in the JSON format used
+// by the bq command-line tool for schema files.
+type bqField struct {
+	Name   string     `json:"name"`
+	Type   string     `json:"type"`
+	Mode   string     `json:"mode,omitempty"`
+	Fields []*bqField `json:"fields,omitempty"`
+}
+
+// bqSchemas are the schemas of the tables written by bigQuerySink, keyed by
+// table name. Changes must be reflected in the row types below.
+var bqSchemas = map[string][]*bqField{
+	"packages": {
+		{Name: "import_path", Type: "STRING", Mode: "REQUIRED"},
+		{Name: "name", Type: "STRING"},
+		{Name: "repository", Type: "STRING"},
+		{Name: "module", Type: "STRING"},
+		{Name: "submodule", Type: "STRING"},
+		{Name: "num_imports", Type: "INTEGER"},
+		{Name: "uses_unsafe", Type: "BOOLEAN"},
+		{Name: "uses_reflect", Type: "BOOLEAN"},
+		{Name: "uses_syscall", Type: "BOOLEAN"},
+		{Name: "owners", Type: "STRING", Mode: "REPEATED"},
+	},
+	"edges": {
+		{Name: "importer", Type: "STRING", Mode: "REQUIRED"},
+		{Name: "imported", Type: "STRING", Mode: "REQUIRED"},
+		{Name: "class", Type: "STRING"},
+		{Name: "replaced_from", Type: "STRING"},
+	},
+	"repos": {
+		{Name: "repository", Type: "STRING"},
+		{Name: "source", Type: "STRING"},
+		{Name: "num_packages", Type: "INTEGER"},
+		{Name: "modules", Type: "STRING", Mode: "REPEATED"},
+		{Name: "labels", Type: "RECORD", Mode: "REPEATED", Fields: []*bqField{
+			{Name: "key", Type: "STRING"},
+			{Name: "value", Type: "STRING"},
+		}},
+		{Name: "last_commit", Type: "TIMESTAMP"},
+		{Name: "commits_last_year", Type: "INTEGER"},
+		{Name: "total_commits", Type: "INTEGER"},
+		{Name: "authors", Type: "INTEGER"},
+		{Name: "stars", Type: "INTEGER"},
+		{Name: "forks", Type: "INTEGER"},
+		{Name: "archived", Type: "BOOLEAN"},
+		{Name: "language", Type: "STRING"},
+		{Name: "topics", Type: "STRING", Mode: "REPEATED"},
+		{Name: "description", Type: "STRING"},
+	},
+}
+
+type bqPackage struct {
+	ImportPath  string   `json:"import_path"`
+	Name        string   `json:"name,omitempty"`
+	Repository  string   `json:"repository,omitempty"`
+	Module      string   `json:"module,omitempty"`
+	Submodule   string   `json:"submodule,omitempty"`
+	NumImports  int      `json:"num_imports"`
+	UsesUnsafe  bool     `json:"uses_unsafe"`
+	UsesReflect bool     `json:"uses_reflect"`
+	UsesSyscall bool     `json:"uses_syscall"`
+	Owners      []string `json:"owners,omitempty"`
+}
+
+type bqEdge struct {
+	Importer     string `json:"importer"`
+	Imported     string `json:"imported"`
+	Class        string `json:"class,omitempty"`
+	ReplacedFrom string `json:"replaced_from,omitempty"`
+}
+
+type bqLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type bqRepo struct {
+	Repository      string    `json:"repository,omitempty"`
+	Source          string    `json:"source,omitempty"`
+	NumPackages     int       `json:"num_packages"`
+	Modules         []string  `json:"modules,omitempty"`
+	Labels          []bqLabel `json:"labels,omitempty"`
+	LastCommit      string    `json:"last_commit,omitempty"`
+	CommitsLastYear int64     `json:"commits_last_year,omitempty"`
+	TotalCommits    int64     `json:"total_commits,omitempty"`
+	Authors         int64     `json:"authors,omitempty"`
+	Stars           int64     `json:"stars,omitempty"`
+	Forks           int64     `json:"forks,omitempty"`
+	Archived        bool      `json:"archived,omitempty"`
+	Language        string    `json:"language,omitempty"`
+	Topics          []string  `json:"topics,omitempty"`
+	Description     string    `json:"description,omitempty"`
+}
+
+// bigQuerySink writes packages, edges, and repositories as newline-delimited
+// JSON files suitable for loading into BigQuery tables, along with a schema
+// file for each table. For example, with dir "out":
+//
+//	bq load --source_format=NEWLINE_DELIMITED_JSON \
+//	   dataset.packages out/packages.json out/packages.schema.json
+type bigQuerySink struct {
+	mu     sync.Mutex
+	files  []*os.File
+	tables map[string]*json.Encoder
+	bufs   []*bufio.Writer
+
+	flushEach bool // flush the tables after each write
+}
+
+func newBigQuerySink(dir string) (*bigQuerySink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &bigQuerySink{tables: make(map[string]*json.Encoder)}
+	for name, schema := range bqSchemas {
+		bits, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if err := writeFile(filepath.Join(dir, name+".schema.json"), append(bits, '\n')); err != nil {
+			s.Close()
+			return nil, err
+		}
+		f, err := os.Create(filepath.Join(dir, name+".json"))
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		buf := bufio.NewWriter(f)
+		s.files = append(s.files, f)
+		s.bufs = append(s.bufs, buf)
+		s.tables[name] = json.NewEncoder(buf)
+	}
+	return s, nil
+}
+
+func writeFile(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (s *bigQuerySink) Write(_ context.Context, repos []*deps.Repo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, repo := range repos {
+		var url string
+		if len(repo.Remotes) != 0 {
+			url = repo.Remotes[0].Url
+		}
+		for _, pkg := range repo.Packages {
+			if err := s.tables["packages"].Encode(bqPackage{
+				ImportPath:  pkg.ImportPath,
+				Name:        pkg.Name,
+				Repository:  url,
+				Module:      pkg.Module,
+				Submodule:   pkg.Submodule,
+				NumImports:  len(pkg.Imports),
+				UsesUnsafe:  pkg.UsesUnsafe,
+				UsesReflect: pkg.UsesReflect,
+				UsesSyscall: pkg.UsesSyscall,
+				Owners:      pkg.Owners,
+			}); err != nil {
+				return err
+			}
+			for i, ip := range pkg.Imports {
+				edge := bqEdge{
+					Importer:     pkg.ImportPath,
+					Imported:     ip,
+					ReplacedFrom: pkg.Replaced[ip],
+				}
+				if i < len(pkg.ImportClasses) {
+					edge.Class = pkg.ImportClasses[i].String()
+				}
+				if err := s.tables["edges"].Encode(edge); err != nil {
+					return err
+				}
+			}
+		}
+		if err := s.tables["repos"].Encode(newBQRepo(url, repo)); err != nil {
+			return err
+		}
+	}
+	if s.flushEach {
+		for _, buf := range s.bufs {
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newBQRepo(url string, repo *deps.Repo) bqRepo {
+	row := bqRepo{
+		Repository:  url,
+		Source:      repo.From,
+		NumPackages: len(repo.Packages),
+		Description: repo.Description,
+	}
+	for _, mod := range repo.Modules {
+		row.Modules = append(row.Modules, mod.Path)
+	}
+	for key, val := range repo.Labels {
+		row.Labels = append(row.Labels, bqLabel{Key: key, Value: val})
+	}
+	if act := repo.Activity; act != nil {
+		if act.LastCommit != 0 {
+			row.LastCommit = time.Unix(act.LastCommit, 0).UTC().Format(time.RFC3339)
+		}
+		row.CommitsLastYear = act.CommitsLastYear
+		row.TotalCommits = act.TotalCommits
+		row.Authors = act.Authors
+	}
+	if gh := repo.GithubInfo; gh != nil {
+		row.Stars = gh.Stars
+		row.Forks = gh.Forks
+		row.Archived = gh.Archived
+		row.Language = gh.Language
+		row.Topics = gh.Topics
+		if row.Description == "" {
+			row.Description = gh.Description
+		}
+	}
+	return row
+}
+
+func (s *bigQuerySink) Close() error {
+	var err error
+	for i, f := range s.files {
+		if ferr := s.bufs[i].Flush(); ferr != nil && err == nil {
+			err = ferr
+		}
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
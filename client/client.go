@@ -0,0 +1,308 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client implements a client for the dependency graph service
+// provided by the service package, as served by the servedeps tool.
+//
+// Each method of a Client calls the service method of the same name. An error
+// reported by the service has concrete type *service.Error, and its code can
+// be checked with ErrorCode:
+//
+//	c := client.New("http://localhost:8080/rpc", nil)
+//	imps, err := c.Imports(ctx, "github.com/creachadair/repodeps/deps")
+//	if client.ErrorCode(err) == service.CodeNotFound {
+//	   // ...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/service"
+)
+
+// A Client calls the methods of a remote graph service. It is safe for
+// concurrent use by multiple goroutines.
+type Client struct {
+	url    string
+	hc     *http.Client
+	token  string
+	nextID int64 // accessed atomically
+}
+
+// Options control the behaviour of a Client. A nil *Options is ready for use
+// and provides default values as described.
+type Options struct {
+	// The HTTP client used to send requests. If nil, http.DefaultClient is
+	// used, unless TLS is set.
+	HTTPClient *http.Client
+
+	// If set and HTTPClient is nil, requests are sent with this TLS
+	// configuration, for example from TLSConfig.
+	TLS *tls.Config
+
+	// If set, the access token presented to the service with each request.
+	Token string
+}
+
+func (o *Options) httpClient() *http.Client {
+	if o == nil {
+		return http.DefaultClient
+	} else if o.HTTPClient != nil {
+		return o.HTTPClient
+	} else if o.TLS != nil {
+		return &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: o.TLS,
+		}}
+	}
+	return http.DefaultClient
+}
+
+// New constructs a Client that sends requests to the service at url, for
+// example "http://localhost:8080/rpc".
+func New(url string, opts *Options) *Client {
+	c := &Client{url: url, hc: opts.httpClient()}
+	if opts != nil {
+		c.token = opts.Token
+	}
+	return c
+}
+
+// Add adds the packages and modules of repo to the graph.
+func (c *Client) Add(ctx context.Context, repo *deps.Repo) (*service.AddResult, error) {
+	var res service.AddResult
+	if err := c.call(ctx, "Add", &service.AddRequest{Repo: repo}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Remove deletes the package with the given import path from the graph, and
+// reports whether it was present.
+func (c *Client) Remove(ctx context.Context, pkg string) (bool, error) {
+	var res service.RemoveResult
+	if err := c.call(ctx, "Remove", &service.PackageRequest{Package: pkg}, &res); err != nil {
+		return false, err
+	}
+	return res.Removed, nil
+}
+
+// Row returns the row of the package with the given import path.
+func (c *Client) Row(ctx context.Context, pkg string) (*graph.Row, error) {
+	var row graph.Row
+	if err := c.call(ctx, "Row", &service.PackageRequest{Package: pkg}, &row); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// Imports returns the direct dependencies of the package with the given
+// import path.
+func (c *Client) Imports(ctx context.Context, pkg string) ([]string, error) {
+	var imps []string
+	if err := c.call(ctx, "Imports", &service.PackageRequest{Package: pkg}, &imps); err != nil {
+		return nil, err
+	}
+	return imps, nil
+}
+
+// Importers returns the import paths of the packages that directly depend on
+// the package with the given import path, in order of import path. It gets
+// every page of the results; use ImportersPage to get them one at a time.
+func (c *Client) Importers(ctx context.Context, pkg string) ([]string, error) {
+	var pkgs []string
+	req := &service.ImportersRequest{Package: pkg}
+	for {
+		res, err := c.ImportersPage(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, res.Packages...)
+		if res.Next == "" {
+			return pkgs, nil
+		}
+		req.Cursor = res.Next
+	}
+}
+
+// ImportersPage returns one page of the importers of a package.
+func (c *Client) ImportersPage(ctx context.Context, req *service.ImportersRequest) (*service.ImportersResult, error) {
+	var res service.ImportersResult
+	if err := c.call(ctx, "Importers", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Closure returns the transitive dependencies of the packages with the given
+// import paths.
+func (c *Client) Closure(ctx context.Context, roots []string) (*service.ClosureResult, error) {
+	var res service.ClosureResult
+	if err := c.call(ctx, "Closure", &service.ClosureRequest{Roots: roots}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Scan returns the rows whose import paths have the given prefix, in order of
+// import path. It gets every page of the results; use ScanPage to get them
+// one at a time.
+func (c *Client) Scan(ctx context.Context, prefix string) ([]*graph.Row, error) {
+	req := &service.ScanRequest{Prefix: prefix}
+	return allRows(func(cursor string) (*service.RowsResult, error) {
+		req.Cursor = cursor
+		return c.ScanPage(ctx, req)
+	})
+}
+
+// ScanPage returns one page of the rows with an import path prefix.
+func (c *Client) ScanPage(ctx context.Context, req *service.ScanRequest) (*service.RowsResult, error) {
+	var res service.RowsResult
+	if err := c.call(ctx, "Scan", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Search returns the rows matching query, in order of import path. The kind
+// is "prefix", "substring", or "name", as for graph.ParseSearchKind. It gets
+// every page of the results; use SearchPage to get them one at a time.
+func (c *Client) Search(ctx context.Context, kind, query string) ([]*graph.Row, error) {
+	req := &service.SearchRequest{Kind: kind, Query: query}
+	return allRows(func(cursor string) (*service.RowsResult, error) {
+		req.Cursor = cursor
+		return c.SearchPage(ctx, req)
+	})
+}
+
+// SearchPage returns one page of the rows found by a search.
+func (c *Client) SearchPage(ctx context.Context, req *service.SearchRequest) (*service.RowsResult, error) {
+	var res service.RowsResult
+	if err := c.call(ctx, "Search", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Version returns the current version of the graph.
+func (c *Client) Version(ctx context.Context) (int64, error) {
+	var res service.VersionResult
+	if err := c.call(ctx, "Version", &service.VersionRequest{}, &res); err != nil {
+		return 0, err
+	}
+	return res.Version, nil
+}
+
+// ChangedSince reports whether the graph has changed since it had the given
+// version, and returns its current version.
+func (c *Client) ChangedSince(ctx context.Context, version int64) (bool, int64, error) {
+	var res service.VersionResult
+	if err := c.call(ctx, "Version", &service.VersionRequest{Since: version}, &res); err != nil {
+		return false, 0, err
+	}
+	return res.Changed, res.Version, nil
+}
+
+// allRows calls page with the cursor of each page of a result in turn,
+// starting from "", and returns the rows of all the pages.
+func allRows(page func(cursor string) (*service.RowsResult, error)) ([]*graph.Row, error) {
+	var rows []*graph.Row
+	var cursor string
+	for {
+		res, err := page(cursor)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, res.Rows...)
+		if res.Next == "" {
+			return rows, nil
+		}
+		cursor = res.Next
+	}
+}
+
+// ErrorCode returns the code of err if it is an error reported by the
+// service, or 0 otherwise.
+func ErrorCode(err error) service.Code {
+	if e, ok := err.(*service.Error); ok {
+		return e.Code
+	}
+	return 0
+}
+
+type request struct {
+	Version string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *service.Error  `json:"error"`
+}
+
+// maxResponseBytes bounds the size of a response body.
+const maxResponseBytes = 256 << 20
+
+// call calls the named method with the given parameters, and decodes its
+// result into result.
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	id := atomic.AddInt64(&c.nextID, 1)
+	body, err := json.Marshal(request{Version: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	rsp, err := c.hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(rsp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	} else if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("calling %s: %s: %s", method, rsp.Status, bytes.TrimSpace(data))
+	}
+
+	var out response
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("calling %s: invalid response: %v", method, err)
+	} else if out.Error != nil {
+		return out.Error
+	} else if out.ID != id {
+		return fmt.Errorf("calling %s: response ID %d does not match request ID %d", method, out.ID, id)
+	}
+	return json.Unmarshal(out.Result, result)
+}
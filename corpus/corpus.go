@@ -0,0 +1,208 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package corpus generates synthetic repositories of Go packages, for
+// measuring the performance of the scanner. The repositories are Git
+// repositories with a remote and a single commit, and may also be packed into
+// Siva archives. The same configuration always generates the same corpus.
+package corpus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Config describes the shape of a synthetic corpus.
+type Config struct {
+	Repos    int   // number of repositories
+	Packages int   // packages per repository
+	Files    int   // source files per package
+	Funcs    int   // functions per source file
+	Imports  int   // imports per file of other packages in the same repository
+	External int   // imports per file of packages in other repositories
+	Modules  bool  // give each repository a go.mod file
+	Seed     int64 // seed for the choice of imports
+}
+
+// Defaults returns a configuration for a modest corpus, with 20 repositories
+// of 20 packages each.
+func Defaults() Config {
+	return Config{
+		Repos:    20,
+		Packages: 20,
+		Files:    5,
+		Funcs:    10,
+		Imports:  3,
+		External: 1,
+		Modules:  true,
+		Seed:     1,
+	}
+}
+
+// A Repo describes a generated repository.
+type Repo struct {
+	Dir   string // the directory containing the repository
+	URL   string // the URL of its remote
+	Files int    // the number of Go source files
+	Bytes int64  // the total size of the Go source files
+}
+
+// stdImports are the standard library packages imported by generated files,
+// with a function of each that the files call.
+var stdImports = [][2]string{
+	{"fmt", "Sprint"},
+	{"strings", "ToUpper"},
+	{"strconv", "Quote"},
+	{"path", "Clean"},
+	{"html", "EscapeString"},
+}
+
+// Generate writes the repositories of the corpus described by cfg into
+// subdirectories of dir, which must exist, and returns a description of each.
+// Generate requires the git command.
+func Generate(ctx context.Context, dir string, cfg Config) ([]*Repo, error) {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	var repos []*Repo
+	for i := 0; i < cfg.Repos; i++ {
+		r := &Repo{
+			Dir: filepath.Join(dir, repoName(i)),
+			URL: repoURL(i),
+		}
+		if err := writeRepo(r, i, cfg, rng); err != nil {
+			return nil, err
+		} else if err := commit(ctx, r); err != nil {
+			return nil, fmt.Errorf("committing %s: %v", r.Dir, err)
+		}
+		repos = append(repos, r)
+	}
+	return repos, nil
+}
+
+func repoName(i int) string    { return fmt.Sprintf("repo%03d", i) }
+func repoURL(i int) string     { return "example.com/corpus/" + repoName(i) }
+func pkgName(j int) string     { return fmt.Sprintf("pkg%03d", j) }
+func funcName(k, f int) string { return fmt.Sprintf("F%02d%02d", k, f) }
+
+// writeRepo writes the files of the ith repository of the corpus into r.Dir.
+func writeRepo(r *Repo, i int, cfg Config, rng *rand.Rand) error {
+	if cfg.Modules {
+		mod := fmt.Sprintf("module %s\n\ngo 1.12\n", r.URL)
+		if err := writeFile(filepath.Join(r.Dir, "go.mod"), mod); err != nil {
+			return err
+		}
+	}
+	for j := 0; j < cfg.Packages; j++ {
+		for k := 0; k < cfg.Files; k++ {
+			src := sourceFile(i, j, k, cfg, rng)
+			path := filepath.Join(r.Dir, pkgName(j), fmt.Sprintf("file%02d.go", k))
+			if err := writeFile(path, src); err != nil {
+				return err
+			}
+			r.Files++
+			r.Bytes += int64(len(src))
+		}
+	}
+	return nil
+}
+
+// sourceFile returns the text of the kth file of the jth package of the ith
+// repository. Each file imports some standard library packages, up to
+// cfg.Imports packages with lower indexes in the same repository, and up to
+// cfg.External packages in repositories with lower indexes, so that the
+// import graph has no cycles.
+func sourceFile(i, j, k int, cfg Config, rng *rand.Rand) string {
+	type dep struct{ path, name, fn string }
+	var deps []dep
+	seen := make(map[string]bool)
+	add := func(d dep) {
+		if !seen[d.path] {
+			seen[d.path] = true
+			deps = append(deps, d)
+		}
+	}
+	std := stdImports[(j+k)%len(stdImports)]
+	add(dep{std[0], filepath.Base(std[0]), std[1]})
+	for n := 0; n < cfg.Imports && j > 0; n++ {
+		t := rng.Intn(j)
+		add(dep{repoURL(i) + "/" + pkgName(t), pkgName(t), funcName(0, 0)})
+	}
+	for n := 0; n < cfg.External && i > 0 && cfg.Packages > 0; n++ {
+		r, t := rng.Intn(i), rng.Intn(cfg.Packages)
+		add(dep{repoURL(r) + "/" + pkgName(t), fmt.Sprintf("r%03d%s", r, pkgName(t)), funcName(0, 0)})
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by the corpus package. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName(j))
+	for _, d := range deps {
+		if d.name == filepath.Base(d.path) {
+			fmt.Fprintf(&buf, "\t%q\n", d.path)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", d.name, d.path)
+		}
+	}
+	buf.WriteString(")\n")
+	nf := cfg.Funcs
+	if nf < 1 {
+		nf = 1 // the first function uses the imports
+	}
+	for f := 0; f < nf; f++ {
+		fmt.Fprintf(&buf, "\n// %s is a generated function.\nfunc %s(s string) string {\n", funcName(k, f), funcName(k, f))
+		for n, d := range deps {
+			if n > 0 && (f == 0 || n%2 == f%2) {
+				fmt.Fprintf(&buf, "\ts = %s.%s(s)\n", d.name, d.fn)
+			}
+		}
+		fmt.Fprintf(&buf, "\tfor i := 0; i < %d; i++ {\n\t\ts += %s.%s(s)\n\t}\n\treturn s\n}\n", f+1, deps[0].name, deps[0].fn)
+	}
+	return buf.String()
+}
+
+// writeFile writes text to path, creating its directory if necessary.
+func writeFile(path, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(text), 0644)
+}
+
+// commit makes r.Dir a Git repository whose remote is r.URL, with a single
+// commit of its files. The commit has a fixed author and date, so that its
+// hash depends only on the files.
+func commit(ctx context.Context, r *Repo) error {
+	const date = "2019-01-01T00:00:00Z"
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=corpus", "GIT_AUTHOR_EMAIL=corpus@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=corpus", "GIT_COMMITTER_EMAIL=corpus@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", "https://" + r.URL},
+		{"add", "--all"},
+		{"commit", "--quiet", "--no-gpg-sign", "--message", "Generated corpus"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = r.Dir
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %v\n%s", args[0], err, out)
+		}
+	}
+	return nil
+}
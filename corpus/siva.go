@@ -0,0 +1,83 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"path/filepath"
+
+	sivafs "gopkg.in/src-d/go-billy-siva.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+// PackSiva writes the history of r into a new rooted Siva archive at path, in
+// the layout used by Borges: the remote of the archive is named by a UUID and
+// has the URL of r, and the branches of r are stored as references of the
+// form refs/heads/NAME/UUID.
+func PackSiva(ctx context.Context, r *Repo, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	sfs, err := sivafs.NewFilesystem(osfs.New("/"), abs, memfs.New())
+	if err != nil {
+		return fmt.Errorf("creating siva filesystem: %v", err)
+	}
+	stg := filesystem.NewStorage(sfs, cache.NewObjectLRUDefault())
+	repo, err := git.Init(stg, nil)
+	if err != nil {
+		return err
+	}
+
+	// Fetch from the repository directory, then point the remote at the URL.
+	uuid := remoteUUID(r.URL)
+	if _, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: uuid,
+		URLs: []string{r.Dir},
+	}); err != nil {
+		return err
+	}
+	if err := repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: uuid,
+		RefSpecs:   []config.RefSpec{config.RefSpec("+refs/heads/*:refs/heads/*/" + uuid)},
+		Tags:       git.NoTags,
+	}); err != nil {
+		return fmt.Errorf("fetching %s: %v", r.Dir, err)
+	}
+	cfg, err := stg.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Remotes[uuid].URLs = []string{"https://" + r.URL}
+	if err := stg.SetConfig(cfg); err != nil {
+		return err
+	}
+	return sfs.Sync()
+}
+
+// remoteUUID returns a name-based UUID for the repository with the given URL.
+func remoteUUID(url string) string {
+	h := sha1.Sum([]byte(url))
+	h[6] = h[6]&0x0f | 0x50 // version 5
+	h[8] = h[8]&0x3f | 0x80 // variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
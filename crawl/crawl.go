@@ -0,0 +1,189 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crawl supports finding, fetching, and scanning the repositories
+// that define packages missing from a dependency graph.
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+)
+
+// A Target is a repository to be fetched to resolve missing packages.
+type Target struct {
+	Root      string   // the import path prefix of the repository
+	URL       string   // the URL from which the repository may be cloned
+	Packages  []string // the missing packages inside the repository
+	Importers int      // the total number of importers of the packages
+	Err       error    // non-nil if the repository could not be determined
+}
+
+// Frontier returns the crawl queue for g: the repositories that define
+// packages listed as dependencies in g but not themselves present, ordered by
+// decreasing number of importers. Standard library packages are not included.
+// A package whose repository cannot be determined is reported as a Target with
+// a non-nil Err.
+func Frontier(ctx context.Context, g *graph.Graph) ([]*Target, error) {
+	return frontier(ctx, g, nil)
+}
+
+// frontier implements Frontier. Packages inside one of the repository roots
+// in known are assigned to that root, with the given URL, without a lookup.
+func frontier(ctx context.Context, g *graph.Graph, known map[string]string) ([]*Target, error) {
+	// Read from a snapshot, so that packages added while the graph is scanned
+	// do not skew the counts.
+	snap, c, err := g.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	missing, err := snap.Unresolved(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []string
+	for pkg := range missing {
+		if !deps.IsStdlib(pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(pkgs)
+
+	// Packages sharing a repository are usually adjacent in sorted order, so
+	// check the most recent root before resolving another.
+	var targets []*Target
+	var last *Target
+	for _, pkg := range pkgs {
+		if last == nil || last.Err != nil || !within(pkg, last.Root) {
+			root, url, ok := knownRoot(pkg, known)
+			var err error
+			if !ok {
+				root, url, err = RepoRoot(ctx, pkg)
+				if err != nil {
+					root = pkg
+				}
+			}
+			last = &Target{Root: root, URL: url, Err: err}
+			targets = append(targets, last)
+		}
+		last.Packages = append(last.Packages, pkg)
+		last.Importers += missing[pkg]
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Importers > targets[j].Importers
+	})
+	return targets, nil
+}
+
+// HTTPClient is the client used to fetch go-import metadata.
+var HTTPClient = http.DefaultClient
+
+// knownHosts maps hosting sites to the number of path elements in the import
+// path of a repository root on that site.
+var knownHosts = map[string]int{
+	"github.com":    3,
+	"gitlab.com":    3,
+	"bitbucket.org": 3,
+}
+
+// RepoRoot returns the import path prefix of the repository that defines the
+// package with import path ipath, and a URL from which it can be cloned.
+// Well-known hosting sites are recognized by their paths; otherwise the
+// go-import metadata served for ipath is consulted, as "go get" does.
+func RepoRoot(ctx context.Context, ipath string) (root, url string, err error) {
+	parts := strings.Split(ipath, "/")
+	if n, ok := knownHosts[parts[0]]; ok {
+		if len(parts) < n {
+			return "", "", fmt.Errorf("invalid import path %q", ipath)
+		}
+		root = strings.Join(parts[:n], "/")
+		return root, "https://" + root, nil
+	} else if parts[0] == "golang.org" && len(parts) >= 3 && parts[1] == "x" {
+		return strings.Join(parts[:3], "/"), "https://go.googlesource.com/" + parts[2], nil
+	} else if parts[0] == "gopkg.in" {
+		// gopkg.in/pkg.vN or gopkg.in/user/pkg.vN
+		n := 2
+		if len(parts) > 2 && !strings.Contains(parts[1], ".v") {
+			n = 3
+		}
+		if len(parts) < n {
+			return "", "", fmt.Errorf("invalid import path %q", ipath)
+		}
+		root = strings.Join(parts[:n], "/")
+		return root, "https://" + root, nil
+	}
+	return metaImport(ctx, ipath)
+}
+
+var metaTag = regexp.MustCompile(`(?is)<meta\s+name=["']go-import["']\s+content=["']([^"']*)["']`)
+
+// metaImport fetches the go-import metadata for ipath and returns the root
+// and URL of the Git repository it describes.
+func metaImport(ctx context.Context, ipath string) (root, url string, err error) {
+	req, err := http.NewRequest("GET", "https://"+ipath+"?go-get=1", nil)
+	if err != nil {
+		return "", "", err
+	}
+	rsp, err := HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", "", err
+	}
+	defer rsp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(rsp.Body, 1<<20))
+	if err != nil {
+		return "", "", err
+	}
+	for _, m := range metaTag.FindAllStringSubmatch(string(body), -1) {
+		f := strings.Fields(m[1])
+		if len(f) == 3 && within(ipath, f[0]) {
+			if f[1] != "git" {
+				return "", "", fmt.Errorf("unsupported VCS %q for %q", f[1], ipath)
+			}
+			return f[0], f[2], nil
+		}
+	}
+	return "", "", errors.New("no go-import metadata found")
+}
+
+// knownRoot returns the root and URL from known of the repository containing
+// ipath, if there is one.
+func knownRoot(ipath string, known map[string]string) (root, url string, ok bool) {
+	for root := ipath; root != ""; {
+		if url, ok := known[root]; ok {
+			return root, url, true
+		}
+		i := strings.LastIndex(root, "/")
+		if i < 0 {
+			break
+		}
+		root = root[:i]
+	}
+	return "", "", false
+}
+
+// within reports whether ipath is root or a package inside it.
+func within(ipath, root string) bool {
+	return ipath == root || strings.HasPrefix(ipath, root+"/")
+}
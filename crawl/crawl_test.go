@@ -0,0 +1,214 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crawl
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/internal/memstore"
+)
+
+// roundTripFunc implements http.RoundTripper by calling the function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// serveMeta replaces HTTPClient with a client that serves the given bodies,
+// keyed by request URL, and returns a function that restores it.
+func serveMeta(pages map[string]string) func() {
+	old := HTTPClient
+	HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, ok := pages[req.URL.String()]
+		code := http.StatusOK
+		if !ok {
+			code = http.StatusNotFound
+		}
+		return &http.Response{
+			StatusCode: code,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+	return func() { HTTPClient = old }
+}
+
+func TestRepoRoot(t *testing.T) {
+	defer serveMeta(map[string]string{
+		"https://example.com/pkg/sub?go-get=1": `<html><head>
+<meta name="go-import" content="example.com/pkg git https://git.example.com/pkg">
+</head></html>`,
+		"https://example.com/hg/sub?go-get=1": `<meta name="go-import" content="example.com/hg hg https://hg.example.com">`,
+	})()
+
+	tests := []struct {
+		ipath, root, url string
+		ok               bool
+	}{
+		{"github.com/foo/bar", "github.com/foo/bar", "https://github.com/foo/bar", true},
+		{"github.com/foo/bar/baz/quux", "github.com/foo/bar", "https://github.com/foo/bar", true},
+		{"gitlab.com/a/b/c", "gitlab.com/a/b", "https://gitlab.com/a/b", true},
+		{"golang.org/x/tools/go/packages", "golang.org/x/tools", "https://go.googlesource.com/tools", true},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml.v2", "https://gopkg.in/yaml.v2", true},
+		{"gopkg.in/src-d/go-git.v4/plumbing", "gopkg.in/src-d/go-git.v4", "https://gopkg.in/src-d/go-git.v4", true},
+		{"example.com/pkg/sub", "example.com/pkg", "https://git.example.com/pkg", true},
+
+		{"github.com/foo", "", "", false},     // too short
+		{"example.com/hg/sub", "", "", false}, // not Git
+		{"example.com/none", "", "", false},   // no metadata
+	}
+	ctx := context.Background()
+	for _, test := range tests {
+		root, url, err := RepoRoot(ctx, test.ipath)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("RepoRoot(%q): got (%q, %q), want error", test.ipath, root, url)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("RepoRoot(%q): unexpected error: %v", test.ipath, err)
+			continue
+		}
+		if root != test.root || url != test.url {
+			t.Errorf("RepoRoot(%q): got (%q, %q), want (%q, %q)", test.ipath, root, url, test.root, test.url)
+		}
+	}
+}
+
+// addPackages adds a package to g for each import path in pkgs, importing the
+// packages it maps to.
+func addPackages(ctx context.Context, t *testing.T, g *graph.Graph, pkgs map[string][]string) {
+	t.Helper()
+	repo := &deps.Repo{Remotes: []*deps.Remote{{Url: "example.com/r"}}}
+	for ipath, imports := range pkgs {
+		if err := g.Add(ctx, repo, &deps.Package{Name: "p", ImportPath: ipath, Imports: imports}); err != nil {
+			t.Fatalf("Add %q: %v", ipath, err)
+		}
+	}
+}
+
+func TestFrontier(t *testing.T) {
+	ctx := context.Background()
+	g := graph.New(memstore.New(graph.ErrNotFound))
+	addPackages(ctx, t, g, map[string][]string{
+		"example.com/r/a": {"fmt", "github.com/foo/bar", "github.com/foo/bar/sub", "github.com/x/y"},
+		"example.com/r/b": {"github.com/foo/bar", "github.com/bad", "example.com/r/a"},
+	})
+
+	targets, err := Frontier(ctx, g)
+	if err != nil {
+		t.Fatalf("Frontier: unexpected error: %v", err)
+	}
+	type result struct {
+		Root      string
+		URL       string
+		Packages  []string
+		Importers int
+		Failed    bool
+	}
+	var got []result
+	for _, tgt := range targets {
+		got = append(got, result{tgt.Root, tgt.URL, tgt.Packages, tgt.Importers, tgt.Err != nil})
+	}
+	want := []result{
+		{"github.com/foo/bar", "https://github.com/foo/bar",
+			[]string{"github.com/foo/bar", "github.com/foo/bar/sub"}, 3, false},
+		{"github.com/bad", "", []string{"github.com/bad"}, 1, true},
+		{"github.com/x/y", "https://github.com/x/y", []string{"github.com/x/y"}, 1, false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Frontier:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	g := graph.New(memstore.New(graph.ErrNotFound))
+	addPackages(ctx, t, g, map[string][]string{
+		"example.com/r/a": {"github.com/foo/bar", "github.com/x/y", "github.com/bad"},
+	})
+
+	checkQueue := func(want ...string) []*graph.Pending {
+		t.Helper()
+		queue, err := Queue(ctx, g, time.Now())
+		if err != nil {
+			t.Fatalf("Queue: unexpected error: %v", err)
+		}
+		var got []string
+		for _, p := range queue {
+			got = append(got, p.Root)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Queue: got %q, want %q", got, want)
+		}
+		return queue
+	}
+
+	failed, err := Refresh(ctx, g)
+	if err != nil {
+		t.Fatalf("Refresh: unexpected error: %v", err)
+	}
+	if len(failed) != 1 || failed[0].Root != "github.com/bad" {
+		t.Errorf("Refresh: got failures %+v, want github.com/bad", failed)
+	}
+	checkQueue("github.com/foo/bar", "github.com/x/y")
+
+	// A failed attempt lowers the priority of the entry, and is retained when
+	// the queue is refreshed.
+	if err := Attempted(ctx, g, "github.com/foo/bar", context.Canceled); err != nil {
+		t.Fatalf("Attempted: unexpected error: %v", err)
+	}
+	if _, err := Refresh(ctx, g); err != nil {
+		t.Fatalf("Refresh: unexpected error: %v", err)
+	}
+	queue := checkQueue("github.com/x/y", "github.com/foo/bar")
+	if p := queue[1]; p.Attempts != 1 || p.Error != context.Canceled.Error() {
+		t.Errorf("Attempted: got attempts=%d error=%q, want 1, %q", p.Attempts, p.Error, context.Canceled)
+	}
+
+	// Once its packages are present, an entry is removed.
+	addPackages(ctx, t, g, map[string][]string{"github.com/x/y": nil})
+	if _, err := Refresh(ctx, g); err != nil {
+		t.Fatalf("Refresh: unexpected error: %v", err)
+	}
+	checkQueue("github.com/foo/bar")
+}
+
+func TestPriority(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	const day = 24 * 60 * 60
+	tests := []struct {
+		p    *graph.Pending
+		want float64
+	}{
+		{&graph.Pending{Importers: 5, Added: now.Unix()}, 5},
+		{&graph.Pending{Importers: 5, Added: now.Unix() - 2*day}, 15},
+		{&graph.Pending{Importers: 5, Added: now.Unix() + day}, 5}, // not in the future
+		{&graph.Pending{Importers: 6, Added: now.Unix(), Attempts: 2}, 2},
+		{&graph.Pending{Importers: 6, Added: now.Unix() - day, Attempts: 1}, 6},
+	}
+	for _, test := range tests {
+		if got := Priority(test.p, now); got != test.want {
+			t.Errorf("Priority(%+v): got %v, want %v", test.p, got, test.want)
+		}
+	}
+}
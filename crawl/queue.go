@@ -0,0 +1,118 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crawl
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/creachadair/repodeps/graph"
+)
+
+// Refresh brings the crawl queue persisted in g up to date with the packages
+// currently missing from g. Repositories that define missing packages are
+// added to the queue, or have their packages and importer counts updated if
+// they are already queued; repositories none of whose packages are still
+// missing are removed. Packages inside a queued repository are not looked up
+// again. Refresh returns the targets whose repositories could not be
+// determined, which are not queued.
+func Refresh(ctx context.Context, g *graph.Graph) ([]*Target, error) {
+	known := make(map[string]string)
+	if err := g.ScanPending(ctx, func(p *graph.Pending) error {
+		known[p.Root] = p.Url
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	targets, err := frontier(ctx, g, known)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	var failed []*Target
+	for _, t := range targets {
+		if t.Err != nil {
+			failed = append(failed, t)
+			continue
+		}
+		delete(known, t.Root)
+		if err := g.AddPending(ctx, &graph.Pending{
+			Root:      t.Root,
+			Url:       t.URL,
+			Packages:  t.Packages,
+			Importers: int64(t.Importers),
+			Added:     now,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Whatever remains in known has nothing left to resolve.
+	for root := range known {
+		if err := g.RemovePending(ctx, root); err != nil && err != graph.ErrNotFound {
+			return nil, err
+		}
+	}
+	return failed, nil
+}
+
+// Priority returns the crawl priority of p as of now; a larger value is more
+// urgent. The priority is proportional to the number of importers of the
+// missing packages, and grows by that amount for each day p has been waiting,
+// so that repositories with few importers are not starved. Each previous
+// attempt to fetch the repository divides its priority, so that repositories
+// that fail, or that do not define the packages expected of them, do not hold
+// the head of the queue.
+func Priority(p *graph.Pending, now time.Time) float64 {
+	days := now.Sub(time.Unix(p.Added, 0)).Hours() / 24
+	if days < 0 {
+		days = 0
+	}
+	return float64(p.Importers) * (1 + days) / float64(1+p.Attempts)
+}
+
+// Queue returns the crawl queue persisted in g, ordered by decreasing
+// priority as of now. Use Refresh to update the queue first.
+func Queue(ctx context.Context, g *graph.Graph, now time.Time) ([]*graph.Pending, error) {
+	var queue []*graph.Pending
+	if err := g.ScanPending(ctx, func(p *graph.Pending) error {
+		queue = append(queue, p)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		return Priority(queue[i], now) > Priority(queue[j], now)
+	})
+	return queue, nil
+}
+
+// Attempted records an attempt to fetch the queued repository with the given
+// root, and its error, if any. The entry remains in the queue until Refresh
+// finds that its packages are no longer missing.
+func Attempted(ctx context.Context, g *graph.Graph, root string, err error) error {
+	p, lerr := g.Pending(ctx, root)
+	if lerr != nil {
+		return lerr
+	}
+	p.Attempted = time.Now().Unix()
+	p.Attempts++
+	p.Error = ""
+	if err != nil {
+		p.Error = err.Error()
+	}
+	return g.UpdatePending(ctx, p)
+}
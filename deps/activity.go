@@ -0,0 +1,55 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"strings"
+	"time"
+)
+
+// An ActivityTally accumulates commits into an Activity summary.
+type ActivityTally struct {
+	since   time.Time
+	act     Activity
+	authors map[string]bool
+}
+
+// NewActivityTally returns a tally that counts commits made in the year
+// before now as recent.
+func NewActivityTally(now time.Time) *ActivityTally {
+	return &ActivityTally{
+		since:   now.AddDate(-1, 0, 0),
+		authors: make(map[string]bool),
+	}
+}
+
+// Add records a commit made at the given time by the given author.
+func (t *ActivityTally) Add(when time.Time, author string) {
+	t.act.TotalCommits++
+	if when.After(t.since) {
+		t.act.CommitsLastYear++
+	}
+	if sec := when.Unix(); sec > t.act.LastCommit {
+		t.act.LastCommit = sec
+	}
+	t.authors[strings.ToLower(author)] = true
+}
+
+// Activity returns the summary of the commits added so far.
+func (t *ActivityTally) Activity() *Activity {
+	act := t.act
+	act.Authors = int64(len(t.authors))
+	return &act
+}
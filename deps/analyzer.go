@@ -0,0 +1,223 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// An Analyzer extracts the packages of one language or package ecosystem from
+// a directory. Implementations detect whether the directory contains files
+// they understand, and if so report the packages those files define and the
+// dependencies they declare. Files are accessed through a build.Context, so
+// that the same analyzer works for working trees and archives alike.
+type Analyzer interface {
+	// Name returns the name of the ecosystem, for example "go" or "npm".
+	Name() string
+
+	// Analyze reports the packages defined by the files in dir, which belongs
+	// to the repository rooted at root. If there is nothing for the analyzer
+	// in dir, Analyze returns no packages and no error. Packages from any
+	// ecosystem other than Go must set the Language field to Name. A package
+	// that has no name of its own may leave ImportPath empty, and the loader
+	// will name it with NameLocal.
+	Analyze(bc *build.Context, root, dir string, opts *Options) ([]*Package, error)
+}
+
+// analyzers is the registry of known analyzers, by name.
+var analyzers = map[string]Analyzer{
+	"bazel":  new(bazelAnalyzer),
+	"cargo":  cargoAnalyzer{},
+	"go":     goAnalyzer{},
+	"jvm":    jvmAnalyzer{},
+	"npm":    npmAnalyzer{},
+	"python": pythonAnalyzer{},
+}
+
+// A dirSkipper is an Analyzer that excludes some directories from scanning,
+// such as directories of installed third-party packages.
+type dirSkipper interface {
+	SkipDir(name string) bool
+}
+
+// Skipped reports whether the repository-relative directory dir should not
+// be scanned, because one of its path elements is excluded by one of the
+// analyzers selected by o.
+func (o *Options) Skipped(dir string) bool {
+	var names []string
+	if o != nil {
+		names = o.Analyzers
+	}
+	for _, name := range names {
+		ds, ok := analyzers[name].(dirSkipper)
+		if !ok {
+			continue
+		}
+		for _, elt := range strings.Split(filepath.ToSlash(dir), "/") {
+			if ds.SkipDir(elt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RegisterAnalyzer adds a to the set of available analyzers. It panics if an
+// analyzer with the same name is already registered.
+func RegisterAnalyzer(a Analyzer) {
+	if _, ok := analyzers[a.Name()]; ok {
+		panic(fmt.Sprintf("duplicate analyzer %q", a.Name()))
+	}
+	analyzers[a.Name()] = a
+}
+
+// Analyzers returns the names of the registered analyzers, in sorted order.
+func Analyzers() []string {
+	var names []string
+	for name := range analyzers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseAnalyzers parses a comma-separated list of analyzer names, and reports
+// an error if any of them is not registered. The name "all" selects all the
+// registered analyzers.
+func ParseAnalyzers(s string) ([]string, error) {
+	if s == "all" {
+		return Analyzers(), nil
+	}
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		} else if _, ok := analyzers[name]; !ok {
+			return nil, fmt.Errorf("unknown analyzer %q", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// AnalyzeDir returns the packages found in dir by each of the analyzers
+// selected by opts, in order. If no analyzers are selected, only Go packages
+// are reported. An analyzer that fails on dir contributes no packages, as if
+// there were nothing for it there.
+//
+// If the Go source files in dir declare conflicting package names, so that no
+// Go package can be loaded from it, AnalyzeDir also returns a record of the
+// conflict. Otherwise the conflict is nil.
+func AnalyzeDir(bc *build.Context, root, dir string, opts *Options) ([]*Package, *PackageConflict) {
+	names := []string{"go"}
+	if opts != nil && len(opts.Analyzers) != 0 {
+		names = opts.Analyzers
+	}
+	var pkgs []*Package
+	var conflict *PackageConflict
+	for _, name := range names {
+		a, ok := analyzers[name]
+		if !ok {
+			continue
+		}
+		found, err := a.Analyze(bc, root, dir, opts)
+		if err != nil {
+			if name == "go" {
+				src := newSourceReader(bc)
+				conflict = packageConflict(src, root, dir, err)
+				src.release()
+			}
+			continue
+		}
+		pkgs = append(pkgs, found...)
+	}
+	return pkgs, conflict
+}
+
+// NameLocal assigns an import path to p, if it does not already have one,
+// based on the URL of the repository that contains it and the directory where
+// it was found, relative to the repository root. Unless p is a Go package, the
+// path is prefixed with its language so that it does not collide with a Go
+// import path.
+func NameLocal(p *Package, repoURL, dir string) {
+	if p.ImportPath != "" {
+		return
+	}
+	if i := strings.Index(repoURL, "://"); i >= 0 {
+		repoURL = repoURL[i+3:]
+	}
+	ipath := strings.TrimSuffix(repoURL, ".git")
+	if dir = path.Clean(filepath.ToSlash(dir)); dir != "." {
+		ipath += "/" + dir
+	}
+	if p.Language != "" {
+		ipath = p.Language + ":" + ipath
+	}
+	p.ImportPath = ipath
+}
+
+// pathLanguages maps the prefixes used by analyzers for the names of packages
+// to the names of the corresponding analyzers.
+var pathLanguages = map[string]string{
+	"crates": "cargo",
+	"maven":  "jvm",
+	"npm":    "npm",
+	"pypi":   "python",
+}
+
+// PathLanguage returns the language of the package with the given import
+// path, according to the naming conventions of the analyzers: "go" for a Go
+// import path, otherwise the name of the analyzer that uses its prefix. Paths
+// assigned by NameLocal are prefixed with the name of the analyzer itself.
+func PathLanguage(ipath string) string {
+	i := strings.Index(ipath, ":")
+	if i < 0 {
+		return "go" // Go import paths never contain a colon
+	}
+	if lang, ok := pathLanguages[ipath[:i]]; ok {
+		return lang
+	}
+	return ipath[:i]
+}
+
+// readFile reads the complete contents of the file at path using bc.
+func readFile(bc *build.Context, path string) ([]byte, error) {
+	rc, err := openFile(bc, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+// goAnalyzer implements the Analyzer interface for Go packages.
+type goAnalyzer struct{}
+
+func (goAnalyzer) Name() string { return "go" }
+
+func (goAnalyzer) Analyze(bc *build.Context, root, dir string, opts *Options) ([]*Package, error) {
+	rec, err := ImportDir(bc, root, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return []*Package{rec}, nil
+}
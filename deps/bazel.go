@@ -0,0 +1,251 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"go/build"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// bazelAnalyzer implements the Analyzer interface for Go packages built with
+// Bazel, using the go_library and go_binary rules of rules_go. This is meant
+// for repositories that do not follow the standard Go layout, and should be
+// used instead of the Go analyzer rather than alongside it.
+//
+// BUILD files are parsed directly rather than with "bazel query", so macros
+// and computed attributes are not understood. Labels of dependencies are
+// mapped to import paths using the importpath attributes of the rules they
+// name, and the go_repository rules of the WORKSPACE for external
+// repositories. Dependencies whose import paths cannot be found, and imports
+// of the standard library (which do not appear as dependencies), are not
+// recorded.
+type bazelAnalyzer struct {
+	mu   sync.Mutex
+	last *bazelWorkspace // the workspace most recently read
+}
+
+// A bazelWorkspace records the external repositories of a workspace, for the
+// build context of the load that read it.
+type bazelWorkspace struct {
+	bc   *build.Context
+	root string
+	repo map[string]string // :: repository name → import path
+}
+
+func (*bazelAnalyzer) Name() string { return "bazel" }
+
+// SkipDir reports whether the directory name should not be scanned.
+func (*bazelAnalyzer) SkipDir(name string) bool { return strings.HasPrefix(name, "bazel-") }
+
+func (b *bazelAnalyzer) Analyze(bc *build.Context, root, dir string, opts *Options) ([]*Package, error) {
+	rules := readBazelRules(bc, dir)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	rel, _ := filepath.Rel(root, dir)
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	externals := b.externals(bc, root)
+
+	var pkgs []*Package
+	for _, r := range rules {
+		if r.kind != "go_library" && r.kind != "go_binary" {
+			continue
+		}
+		rec := &Package{Name: r.name, ImportPath: r.importPath}
+		if r.kind == "go_binary" {
+			rec.Name = "main"
+		}
+		seen := make(map[string]bool)
+		for _, label := range r.deps {
+			ip := resolveLabel(bc, root, rel, label, rules, externals)
+			if ip != "" && !seen[ip] {
+				seen[ip] = true
+				rec.Imports = append(rec.Imports, ip)
+			}
+		}
+		sort.Strings(rec.Imports)
+		pkgs = append(pkgs, rec)
+	}
+	return pkgs, nil
+}
+
+// externals returns the import paths of the external Go repositories declared
+// by go_repository rules in the workspace rooted at root, keyed by repository
+// name.
+//
+// The result for the most recent workspace is cached, so that a load does not
+// read it again for each directory. Each load has its own build context, and
+// the cache is keyed by it as well as the root, since the same root may name
+// different trees in different loads, such as the refs of a siva archive.
+// Only one workspace is kept, so a finished load is not retained.
+func (b *bazelAnalyzer) externals(bc *build.Context, root string) map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if w := b.last; w != nil && w.bc == bc && w.root == root {
+		return w.repo
+	}
+	m := make(map[string]string)
+	for _, name := range []string{"WORKSPACE", "WORKSPACE.bazel", "deps.bzl"} {
+		data, err := readFile(bc, filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		for _, r := range parseBazelRules(string(data)) {
+			if r.kind == "go_repository" && r.name != "" && r.importPath != "" {
+				m[r.name] = r.importPath
+			}
+		}
+	}
+	b.last = &bazelWorkspace{bc: bc, root: root, repo: m}
+	return m
+}
+
+// resolveLabel returns the import path of the Go package named by label,
+// which appears in the BUILD file of the repository-relative directory rel,
+// or "" if it cannot be determined.
+func resolveLabel(bc *build.Context, root, rel, label string, local []*bazelRule, externals map[string]string) string {
+	if strings.HasPrefix(label, "@") {
+		// @repo//pkg/path:target — the import path of an external repository
+		// generated by Gazelle follows its directory structure.
+		i := strings.Index(label, "//")
+		if i < 0 {
+			return ""
+		}
+		base, ok := externals[strings.TrimPrefix(label[:i], "@")]
+		if !ok {
+			return ""
+		}
+		pkg, _ := splitLabel(label[i+2:])
+		if pkg == "" {
+			return base
+		}
+		return base + "/" + pkg
+	}
+
+	pkg, name := rel, strings.TrimPrefix(label, ":")
+	if strings.HasPrefix(label, "//") {
+		pkg, name = splitLabel(label[2:])
+	}
+	rules := local
+	if pkg != rel {
+		rules = readBazelRules(bc, filepath.Join(root, filepath.FromSlash(pkg)))
+	}
+	for _, r := range rules {
+		if r.name == name && r.importPath != "" {
+			return r.importPath
+		}
+	}
+	return ""
+}
+
+// splitLabel splits the package-relative part of a label, "pkg/path:target",
+// into its package and target. If the target is omitted, it is the last
+// element of the package path.
+func splitLabel(s string) (pkg, target string) {
+	if i := strings.Index(s, ":"); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, path.Base(s)
+}
+
+// A bazelRule records the attributes of a rule relevant to dependencies.
+type bazelRule struct {
+	kind       string // e.g., go_library
+	name       string
+	importPath string
+	deps       []string
+}
+
+// readBazelRules reads and parses the BUILD file in dir, if there is one.
+func readBazelRules(bc *build.Context, dir string) []*bazelRule {
+	for _, name := range []string{"BUILD.bazel", "BUILD"} {
+		if data, err := readFile(bc, filepath.Join(dir, name)); err == nil {
+			return parseBazelRules(string(data))
+		}
+	}
+	return nil
+}
+
+var (
+	bazelRuleStart = regexp.MustCompile(`(?m)^\s*(go_library|go_binary|go_repository)\s*\(`)
+	bazelStrAttr   = regexp.MustCompile(`(?m)^\s*(name|importpath)\s*=\s*"([^"]*)"`)
+	bazelDepsAttr  = regexp.MustCompile(`(?ms)^\s*deps\s*=\s*\[(.*?)\]`)
+	bazelString    = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// parseBazelRules extracts the Go rules from the text of a Starlark file.
+// Only literal attribute values are understood.
+func parseBazelRules(text string) []*bazelRule {
+	var rules []*bazelRule
+	for _, loc := range bazelRuleStart.FindAllStringSubmatchIndex(text, -1) {
+		body := text[loc[1]:]
+		body = body[:callEnd(body)]
+		r := &bazelRule{kind: text[loc[2]:loc[3]]}
+		for _, m := range bazelStrAttr.FindAllStringSubmatch(body, -1) {
+			switch m[1] {
+			case "name":
+				r.name = m[2]
+			case "importpath":
+				r.importPath = m[2]
+			}
+		}
+		if m := bazelDepsAttr.FindStringSubmatch(body); m != nil {
+			for _, s := range bazelString.FindAllStringSubmatch(m[1], -1) {
+				r.deps = append(r.deps, s[1])
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// callEnd returns the offset in s of the parenthesis closing a call whose
+// opening parenthesis immediately precedes s, or len(s) if it is not closed.
+func callEnd(s string) int {
+	depth := 1
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
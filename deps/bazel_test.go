@@ -0,0 +1,135 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// memContext returns a build context that reads the given files, keyed by
+// path, from memory.
+func memContext(files map[string]string) *build.Context {
+	bc := build.Default
+	bc.OpenFile = func(path string) (io.ReadCloser, error) {
+		text, ok := files[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(strings.NewReader(text)), nil
+	}
+	return &bc
+}
+
+func TestBazelAnalyze(t *testing.T) {
+	bc := memContext(map[string]string{
+		"/r/WORKSPACE": `
+go_repository(
+    name = "com_github_x_y",
+    importpath = "github.com/x/y",
+)
+`,
+		"/r/lib/BUILD.bazel": `
+load("@io_bazel_rules_go//go:def.bzl", "go_library")
+
+go_library(
+    name = "go_default_library",
+    srcs = ["lib.go"],  # (not used)
+    importpath = "example.com/r/lib",
+    deps = [
+        ":helper",
+        "//util",
+        "//util:go_default_library",
+        "@com_github_x_y//z:go_default_library",
+        "@com_github_x_y//:go_default_library",
+        "@unknown//q:go_default_library",
+        "//missing:go_default_library",
+    ],
+)
+
+go_library(
+    name = "helper",
+    importpath = "example.com/r/lib/helper",
+)
+`,
+		"/r/util/BUILD": `
+go_library(
+    name = "util",
+    importpath = "example.com/r/util",
+)
+go_library(
+    name = "go_default_library",
+    importpath = "example.com/r/util",
+)
+`,
+		"/r/cmd/BUILD": `
+go_binary(
+    name = "tool",
+    importpath = "example.com/r/cmd",
+    deps = ["//lib:go_default_library"],
+)
+`,
+	})
+
+	tests := []struct {
+		dir  string
+		want []*Package
+	}{
+		{"/r/lib", []*Package{
+			{Name: "go_default_library", ImportPath: "example.com/r/lib", Imports: []string{
+				"example.com/r/lib/helper", "example.com/r/util", "github.com/x/y", "github.com/x/y/z",
+			}},
+			{Name: "helper", ImportPath: "example.com/r/lib/helper"},
+		}},
+		{"/r/cmd", []*Package{
+			{Name: "main", ImportPath: "example.com/r/cmd", Imports: []string{"example.com/r/lib"}},
+		}},
+		{"/r/none", nil},
+	}
+	b := new(bazelAnalyzer)
+	for _, test := range tests {
+		got, err := b.Analyze(bc, "/r", test.dir, nil)
+		if err != nil {
+			t.Errorf("Analyze %q: unexpected error: %v", test.dir, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Analyze %q:\n got %+v\nwant %+v", test.dir, got, test.want)
+		}
+	}
+}
+
+func TestBazelWorkspaces(t *testing.T) {
+	// Two loads see different trees at the same root, as the refs of a siva
+	// archive do. Each must use its own workspace.
+	tree := func(ipath string) *build.Context {
+		return memContext(map[string]string{
+			"/src/r/WORKSPACE": "go_repository(\n name = \"dep\",\n importpath = \"" + ipath + "\",\n)\n",
+			"/src/r/BUILD":     "go_library(\n name = \"r\",\n deps = [\"@dep//:go_default_library\"],\n)\n",
+		})
+	}
+	b := new(bazelAnalyzer)
+	for _, ipath := range []string{"example.com/one", "example.com/two", "example.com/one"} {
+		pkgs, err := b.Analyze(tree(ipath), "/src/r", "/src/r", nil)
+		if err != nil {
+			t.Fatalf("Analyze: unexpected error: %v", err)
+		} else if len(pkgs) != 1 || !reflect.DeepEqual(pkgs[0].Imports, []string{ipath}) {
+			t.Errorf("Analyze with dependency %q: got %+v", ipath, pkgs)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bufio"
+	"go/build"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// scanBuildTags returns the names of the build tags that appear in the build
+// constraints of the named Go source files in dir, including the operating
+// system and architecture implied by file names like x_linux_amd64.go. The
+// result is sorted and free of duplicates.
+func scanBuildTags(bc *build.Context, dir string, names []string) ([]string, error) {
+	tags := make(map[string]bool)
+	for _, name := range names {
+		for _, tag := range fileNameTags(name) {
+			tags[tag] = true
+		}
+		rc, err := openFile(bc, filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		sc := bufio.NewScanner(rc)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if strings.HasPrefix(line, "package ") {
+				break // constraints must precede the package clause
+			}
+			for _, tag := range constraintTags(line) {
+				tags[tag] = true
+			}
+		}
+		err = sc.Err()
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out []string
+	for tag := range tags {
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// constraintTags returns the names of the tags mentioned by line, if it is a
+// //go:build or // +build constraint, or nil if it is not. The syntax of the
+// constraint is checked only so far as it uses the right characters; this
+// avoids go/build/constraint, which requires Go 1.16.
+func constraintTags(line string) []string {
+	var expr, ops string
+	if rest := strings.TrimPrefix(line, "//go:build"); rest != line && isConstraintArgs(rest) {
+		expr, ops = rest, "!&|()"
+	} else if rest := strings.TrimPrefix(line, "//"); rest != line {
+		rest = strings.TrimLeft(rest, " \t")
+		if args := strings.TrimPrefix(rest, "+build"); args != rest && isConstraintArgs(args) {
+			expr, ops = args, "!,"
+		}
+	}
+	if expr == "" {
+		return nil
+	}
+	var out []string
+	for _, word := range strings.FieldsFunc(expr, func(r rune) bool {
+		return r == ' ' || r == '\t' || strings.ContainsRune(ops, r)
+	}) {
+		for _, r := range word {
+			if !isTagRune(r) {
+				return nil // not a valid constraint
+			}
+		}
+		out = append(out, word)
+	}
+	return out
+}
+
+// isConstraintArgs reports whether s could be the arguments of a constraint
+// line, which are separated from the keyword by space.
+func isConstraintArgs(s string) bool { return s != "" && (s[0] == ' ' || s[0] == '\t') }
+
+// isTagRune reports whether r may appear in the name of a build tag.
+func isTagRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fileNameTags returns the operating system and architecture tags implied by
+// the name of a Go source file, following the rules of the go command.
+func fileNameTags(name string) []string {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".go"), "_test")
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return nil // the first element is never a constraint
+	}
+	n := len(parts)
+	if n >= 3 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2:]
+	} else if knownOS[parts[n-1]] || knownArch[parts[n-1]] {
+		return parts[n-1:]
+	}
+	return nil
+}
+
+var knownOS = stringSet(`aix android darwin dragonfly freebsd hurd illumos ios js
+linux nacl netbsd openbsd plan9 solaris wasip1 windows zos`)
+
+var knownArch = stringSet(`386 amd64 amd64p32 arm armbe arm64 arm64be loong64
+mips mipsle mips64 mips64le mips64p32 mips64p32le ppc ppc64 ppc64le riscv
+riscv64 s390 s390x sparc sparc64 wasm`)
+
+func stringSet(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, word := range strings.Fields(s) {
+		m[word] = true
+	}
+	return m
+}
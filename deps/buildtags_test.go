@@ -0,0 +1,94 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConstraintTags(t *testing.T) {
+	tests := []struct {
+		line string
+		want string // space-separated
+	}{
+		{"//go:build linux", "linux"},
+		{"//go:build linux && (amd64 || arm64)", "linux amd64 arm64"},
+		{"//go:build !windows && go1.18", "windows go1.18"},
+		{"//go:build\tcgo", "cgo"},
+		{"// +build linux,386 darwin,!cgo", "linux 386 darwin cgo"},
+		{"//+build ignore", "ignore"},
+		{"//   +build   a b", "a b"},
+
+		{"//go:builds linux", ""},
+		{"// +builder linux", ""},
+		{"// go:build linux", ""},
+		{"//go:build linux; rm -rf", ""},
+		{"// +build a&&b", ""},
+		{"// Package a does things.", ""},
+		{"x := 1", ""},
+	}
+	for _, test := range tests {
+		got := strings.Join(constraintTags(test.line), " ")
+		if got != test.want {
+			t.Errorf("constraintTags(%q): got %q, want %q", test.line, got, test.want)
+		}
+	}
+}
+
+func TestScanBuildTags(t *testing.T) {
+	bc := memContext(map[string]string{
+		"/d/a.go": `// Copyright notice.
+
+//go:build linux && !purego
+// +build linux,!purego
+
+package a
+
+//go:build notthis
+`,
+		"/d/b_windows_amd64.go": "package a\n",
+		"/d/c_test.go":          "// +build integration\n\npackage a\n",
+	})
+	got, err := scanBuildTags(bc, "/d", []string{"a.go", "b_windows_amd64.go", "c_test.go"})
+	if err != nil {
+		t.Fatalf("scanBuildTags: unexpected error: %v", err)
+	}
+	want := []string{"amd64", "integration", "linux", "purego", "windows"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanBuildTags: got %q, want %q", got, want)
+	}
+}
+
+func TestFileNameTags(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"a.go", ""},
+		{"linux.go", ""},
+		{"a_linux.go", "linux"},
+		{"a_amd64.go", "amd64"},
+		{"a_linux_amd64.go", "linux amd64"},
+		{"a_linux_amd64_test.go", "linux amd64"},
+		{"a_linux_test.go", "linux"},
+		{"a_nonesuch.go", ""},
+	}
+	for _, test := range tests {
+		if got := strings.Join(fileNameTags(test.name), " "); got != test.want {
+			t.Errorf("fileNameTags(%q): got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
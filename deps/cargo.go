@@ -0,0 +1,221 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"go/build"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// cargoAnalyzer implements the Analyzer interface for Rust crates, described
+// by a Cargo.toml manifest. Crate and dependency names are prefixed with
+// "crates:". Development dependencies are not recorded. If a Cargo.lock file
+// is present alongside the manifest, it is used to find the resolved versions
+// of the dependencies.
+type cargoAnalyzer struct{}
+
+func (cargoAnalyzer) Name() string { return "cargo" }
+
+// SkipDir reports whether the directory name should not be scanned.
+func (cargoAnalyzer) SkipDir(name string) bool { return name == "target" }
+
+func (cargoAnalyzer) Analyze(bc *build.Context, root, dir string, opts *Options) ([]*Package, error) {
+	data, err := readFile(bc, filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return nil, nil // no crate here
+	}
+	man := parseCargoManifest(data)
+	if man.name == "" {
+		return nil, nil // e.g., a virtual workspace manifest
+	}
+	rec := &Package{
+		Name:       man.name,
+		ImportPath: "crates:" + man.name,
+		Language:   "cargo",
+	}
+	if man.version != "" {
+		rec.Versions = map[string]string{rec.ImportPath: man.version}
+	}
+	for _, dep := range man.deps {
+		rec.Imports = append(rec.Imports, "crates:"+dep)
+	}
+	sort.Strings(rec.Imports)
+
+	if data, err := readFile(bc, filepath.Join(dir, "Cargo.lock")); err == nil {
+		locked := parseCargoLock(data)
+		for _, dep := range man.deps {
+			if vs := locked[dep]; len(vs) == 1 {
+				if rec.Versions == nil {
+					rec.Versions = make(map[string]string)
+				}
+				rec.Versions["crates:"+dep] = vs[0]
+			}
+		}
+	}
+	return []*Package{rec}, nil
+}
+
+type cargoManifest struct {
+	name, version string
+	deps          []string // sorted, without duplicates
+}
+
+// parseCargoManifest extracts the package name, version, and the names of
+// the normal and build dependencies from a Cargo.toml file, including those
+// specific to a target. A dependency renamed with a "package" key is recorded
+// under the name of the crate it refers to. This is not a general TOML parser.
+func parseCargoManifest(data []byte) *cargoManifest {
+	man := new(cargoManifest)
+	seen := make(map[string]bool)
+	addDep := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			man.deps = append(man.deps, name)
+		}
+	}
+	var table string
+	var depTable string // the dependency named by a [dependencies.x] table
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		} else if strings.HasPrefix(line, "[") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			depTable = ""
+			if kind, name := cargoDepTable(table); kind != "" && name != "" {
+				depTable = name
+				addDep(name)
+			}
+			continue
+		}
+		key, val, ok := tomlKeyValue(line)
+		if !ok {
+			continue
+		}
+		if table == "package" {
+			if s := tomlStrings(val); len(s) == 1 {
+				switch key {
+				case "name":
+					man.name = s[0]
+				case "version":
+					man.version = s[0]
+				}
+			}
+		} else if depTable != "" && key == "package" {
+			// [dependencies.alias] with package = "real-name"
+			if s := tomlStrings(val); len(s) == 1 {
+				delete(seen, depTable)
+				man.deps = removeString(man.deps, depTable)
+				addDep(s[0])
+			}
+		} else if kind, name := cargoDepTable(table); kind != "" && name == "" {
+			addDep(cargoRename(key, val))
+		}
+	}
+	sort.Strings(man.deps)
+	return man
+}
+
+// cargoDepTable reports whether table is a table of normal or build
+// dependencies, possibly for a specific target, returning the kind of the
+// table and the name of the dependency if the table describes only one.
+func cargoDepTable(table string) (kind, name string) {
+	if strings.HasPrefix(table, "target.") {
+		// target.'cfg(unix)'.dependencies — the cfg may contain dots.
+		for _, k := range []string{"dependencies", "build-dependencies"} {
+			if i := strings.Index(table, "."+k); i >= 0 {
+				table = table[i+1:]
+				break
+			}
+		}
+	}
+	for _, k := range []string{"dependencies", "build-dependencies"} {
+		if table == k {
+			return k, ""
+		} else if strings.HasPrefix(table, k+".") {
+			return k, strings.Trim(table[len(k)+1:], `"'`)
+		}
+	}
+	return "", ""
+}
+
+// cargoRename returns the crate name for a dependency declared as key = val,
+// which is key unless val is an inline table with a package key.
+func cargoRename(key, val string) string {
+	if i := strings.Index(val, "package"); i >= 0 && strings.HasPrefix(val, "{") {
+		if rest := strings.TrimSpace(val[i+len("package"):]); strings.HasPrefix(rest, "=") {
+			if s := tomlStrings(rest); len(s) != 0 {
+				return s[0]
+			}
+		}
+	}
+	return key
+}
+
+// parseCargoLock returns the versions of each package listed in a Cargo.lock
+// file, keyed by name.
+func parseCargoLock(data []byte) map[string][]string {
+	locked := make(map[string][]string)
+	var name string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "[[package]]" {
+			name = ""
+			continue
+		}
+		key, val, ok := tomlKeyValue(line)
+		if !ok {
+			continue
+		}
+		s := tomlStrings(val)
+		if len(s) != 1 {
+			continue
+		}
+		switch key {
+		case "name":
+			name = s[0]
+		case "version":
+			if name != "" {
+				locked[name] = append(locked[name], s[0])
+			}
+		}
+	}
+	return locked
+}
+
+// tomlKeyValue splits a TOML key/value line into its key, with any quotes
+// removed, and its unparsed value.
+func tomlKeyValue(line string) (key, val string, ok bool) {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.Trim(strings.TrimSpace(parts[0]), `"'`), strings.TrimSpace(parts[1]), true
+}
+
+func removeString(ss []string, s string) []string {
+	for i, elt := range ss {
+		if elt == s {
+			return append(ss[:i], ss[i+1:]...)
+		}
+	}
+	return ss
+}
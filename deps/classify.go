@@ -0,0 +1,81 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import "strings"
+
+// ClassifyImports sets the ImportClasses field of each package in repo to
+// describe the relationship between that package and each of its imports.
+// Import paths are classified as belonging to the same module if the imported
+// package is in the same module as the importer, and the same repository if it
+// is defined in the repository or falls within the path of one of its modules.
+// Imports listed among the StdImports of a package are in the standard library.
+// Packages other than Go packages are not classified.
+func ClassifyImports(repo *Repo) {
+	local := make(map[string]string) // :: import path → module path
+	for _, pkg := range repo.Packages {
+		if pkg.Language == "" {
+			local[pkg.ImportPath] = modulePath(pkg.Module)
+		}
+	}
+	for _, pkg := range repo.Packages {
+		if pkg.Language != "" {
+			continue
+		}
+		mpath := modulePath(pkg.Module)
+		std := make(map[string]bool)
+		for _, ip := range pkg.StdImports {
+			std[ip] = true
+		}
+		classes := make([]ImportClass, len(pkg.Imports))
+		for i, ip := range pkg.Imports {
+			classes[i] = ImportClass_EXTERNAL
+			if std[ip] {
+				classes[i] = ImportClass_STDLIB
+			} else if m, ok := local[ip]; ok {
+				classes[i] = ImportClass_SAME_REPOSITORY
+				if m == mpath {
+					classes[i] = ImportClass_SAME_MODULE
+				}
+			} else if mod := enclosingModule(repo.Modules, ip); mod != nil {
+				classes[i] = ImportClass_SAME_REPOSITORY
+				if mod.Path == mpath {
+					classes[i] = ImportClass_SAME_MODULE
+				}
+			}
+		}
+		pkg.ImportClasses = classes
+	}
+}
+
+// enclosingModule returns the module among mods whose path is the longest
+// prefix of ip, or nil if there is none.
+func enclosingModule(mods []*Module, ip string) *Module {
+	var best *Module
+	for _, mod := range mods {
+		if within(ip, mod.Path) && (best == nil || len(mod.Path) > len(best.Path)) {
+			best = mod
+		}
+	}
+	return best
+}
+
+// modulePath returns the module path from a path@version label.
+func modulePath(label string) string {
+	if i := strings.Index(label, "@"); i >= 0 {
+		return label[:i]
+	}
+	return label
+}
@@ -17,17 +17,717 @@
 package deps
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/scanner"
 	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/zeebo/blake3"
 )
 
 //go:generate protoc --go_out=. deps.proto
 
+// SchemaVersion is the version of the Repo schema written by this package.
+// It must be incremented when a change to the schema would cause older readers
+// to misinterpret new records.
+const SchemaVersion = 1
+
+// CheckSchema reports an error if repo was written with a newer version of
+// the schema than this package understands. Records that predate schema
+// versioning are accepted.
+func CheckSchema(repo *Repo) error {
+	if repo.Schema > SchemaVersion {
+		return fmt.Errorf("repository %q has schema version %d, newer than supported (%d)",
+			repo.From, repo.Schema, SchemaVersion)
+	}
+	return nil
+}
+
 // Options control the behaviour of the Load function. A nil *Options behaves
 // as a zero-valued Options struct.
 type Options struct {
 	HashSourceFiles bool // record source file digests
+	FileImports     bool // record the imports declared by each source file
+	ImportSites     bool // record the file and line of each import declaration
+	CountRefs       bool // record how often and how widely each import is used
+	Directives      bool // record go:embed and go:generate directives
+	BuildTags       bool // record the build tags used by constraints
+
+	// If set, scan the tree at this revision rather than the working tree or
+	// the default tip of the repository.
+	Ref string
+
+	// If non-empty, record only packages whose repository-relative directory
+	// matches at least one of these patterns. See Options.Included.
+	Include []string
+
+	// If set, initialize and scan the submodules of a repository, attributing
+	// their packages to the enclosing submodule.
+	Submodules bool
+
+	// How to handle symbolic links found while scanning a directory tree.
+	Symlinks LinkPolicy
+
+	// If set, repositories nested inside the worktree of another repository
+	// are scanned as separate repositories. Otherwise they are skipped. A
+	// nested repository that cannot be loaded is skipped, as are submodules
+	// checked out without setting Submodules.
+	ScanNested bool
+
+	// How to record imports of standard library packages.
+	Stdlib StdlibPolicy
+
+	// If set, standard library imports are those listed in this catalog for
+	// the Go version GoVersion, rather than those recognized by IsStdlib.
+	StdlibCatalog StdlibCatalog
+	GoVersion     string
+
+	// How to compute the digests of source files, if they are recorded.
+	Hash HashAlgorithm
+
+	// If set, summarize the commit history of each repository.
+	Activity bool
+
+	// If set, give each module defined by a repository scanned at a commit
+	// the version of that commit, a tag or a pseudo-version, rather than
+	// leaving it unversioned. See ModuleVersion. If the tags of the repository
+	// cannot be read, its modules are left unversioned.
+	PseudoVersions bool
+
+	// If set, only count the files and packages in each repository, without
+	// loading the packages themselves.
+	SummaryOnly bool
+
+	// The names of the analyzers to apply to each directory, in order. If
+	// empty, only Go packages are loaded. See ParseAnalyzers.
+	Analyzers []string
+
+	// If set, use the go command to resolve the imports of packages inside a
+	// module, which applies build constraints and cgo processing exactly as a
+	// build would. This is much slower than the default scan, requires a Go
+	// toolchain, and is only supported for local repositories. Packages the go
+	// command cannot load keep the imports found by the scan.
+	Precise bool
+
+	// If set, the import paths recorded for packages and source files are
+	// interned here, so that packages importing the same path share one copy
+	// of it. An Interner may be shared by concurrent scans.
+	Interner *Interner
+}
+
+// AddDir updates s with the names of the files in a single directory.
+func (s *Summary) AddDir(names []string) {
+	var goFiles int64
+	for _, name := range names {
+		s.Files++
+		switch {
+		case name == "go.mod":
+			s.Modules++
+		case strings.HasSuffix(name, "_test.go"):
+			s.TestFiles++
+		case strings.HasSuffix(name, ".go"):
+			goFiles++
+		}
+	}
+	s.GoFiles += goFiles
+	if goFiles != 0 {
+		s.Packages++
+	}
+}
+
+// A LinkPolicy determines how symbolic links are handled when scanning a
+// directory tree.
+type LinkPolicy int
+
+// Constants for the LinkPolicy type.
+const (
+	SkipLinks   LinkPolicy = iota // ignore symbolic links (default)
+	FollowLinks                   // follow symbolic links, visiting each target once
+	RejectLinks                   // report an error if a symbolic link is found
+)
+
+// ParseLinkPolicy parses the name of a link policy, one of "skip", "follow",
+// or "error".
+func ParseLinkPolicy(s string) (LinkPolicy, error) {
+	switch s {
+	case "skip", "":
+		return SkipLinks, nil
+	case "follow":
+		return FollowLinks, nil
+	case "error":
+		return RejectLinks, nil
+	}
+	return 0, fmt.Errorf("unknown link policy %q", s)
+}
+
+// A StdlibPolicy determines how imports of standard library packages are
+// recorded. Unless they are excluded, standard library imports are also listed
+// in the StdImports field of each package.
+type StdlibPolicy int
+
+// Constants for the StdlibPolicy type.
+const (
+	IncludeStdlib  StdlibPolicy = iota // record stdlib imports in Imports (default)
+	ExcludeStdlib                      // omit stdlib imports entirely
+	SeparateStdlib                     // record stdlib imports only in StdImports
+)
+
+// ParseStdlibPolicy parses the name of a standard library policy, one of
+// "include", "exclude", or "separate".
+func ParseStdlibPolicy(s string) (StdlibPolicy, error) {
+	switch s {
+	case "include", "":
+		return IncludeStdlib, nil
+	case "exclude":
+		return ExcludeStdlib, nil
+	case "separate":
+		return SeparateStdlib, nil
+	}
+	return 0, fmt.Errorf("unknown stdlib policy %q", s)
+}
+
+// A HashAlgorithm determines how the digests of source files are computed.
+type HashAlgorithm int
+
+// Constants for the HashAlgorithm type.
+const (
+	HashSHA256  HashAlgorithm = iota // SHA-256 of the contents (default)
+	HashBLAKE3                       // BLAKE3 of the contents, 256 bits
+	HashGitBlob                      // Git object ID of the contents as a blob
+)
+
+// ParseHashAlgorithm parses the name of a hash algorithm, one of "sha256",
+// "blake3", or "git".
+func ParseHashAlgorithm(s string) (HashAlgorithm, error) {
+	switch s {
+	case "sha256", "":
+		return HashSHA256, nil
+	case "blake3":
+		return HashBLAKE3, nil
+	case "git":
+		return HashGitBlob, nil
+	}
+	return 0, fmt.Errorf("unknown hash algorithm %q", s)
+}
+
+// String returns the name of h, as accepted by ParseHashAlgorithm.
+func (h HashAlgorithm) String() string {
+	switch h {
+	case HashBLAKE3:
+		return "blake3"
+	case HashGitBlob:
+		return "git"
+	}
+	return "sha256"
+}
+
+// Sum returns the digest of data computed by h. For HashGitBlob this is the
+// SHA-1 of the Git blob header and data, which is the ID Git would assign to
+// a file with that content.
+func (h HashAlgorithm) Sum(data []byte) []byte {
+	switch h {
+	case HashBLAKE3:
+		sum := blake3.Sum256(data)
+		return sum[:]
+	case HashGitBlob:
+		g := getSHA1()
+		defer putSHA1(g)
+		var hdr [32]byte
+		g.Write(append(strconv.AppendInt(append(hdr[:0], "blob "...), int64(len(data)), 10), 0))
+		g.Write(data)
+		return g.Sum(nil)
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// IsStdlib reports whether ipath is the import path of a standard library
+// package. Like the go command, it treats any import path whose first element
+// does not contain a dot as belonging to the standard library. The cgo
+// pseudo-package "C" is not considered part of the standard library, nor is
+// any package of another language, such as "npm:react" (see PathLanguage). To
+// check against the packages of a particular Go version, use a StdlibCatalog.
+func IsStdlib(ipath string) bool {
+	if ipath == "C" || PathLanguage(ipath) != "go" {
+		return false
+	}
+	if i := strings.Index(ipath, "/"); i >= 0 {
+		ipath = ipath[:i]
+	}
+	return !strings.Contains(ipath, ".")
+}
+
+// Included reports whether packages in the specified repository-relative
+// directory should be recorded according to the Include patterns. A pattern
+// ending in "/..." matches the named directory and all its subdirectories;
+// otherwise patterns are matched using path.Match.
+func (o *Options) Included(dir string) bool {
+	if o == nil || len(o.Include) == 0 {
+		return true
+	}
+	dir = path.Clean(dir)
+	for _, pat := range o.Include {
+		if base := strings.TrimSuffix(pat, "/..."); base != pat {
+			if base == "." || base == "" || dir == base || strings.HasPrefix(dir, base+"/") {
+				return true
+			}
+		} else if ok, _ := path.Match(pat, dir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportDir loads the Go package defined in dir using bc, and returns a record
+// describing it. The root is the path of the enclosing repository, and is used
+// to compute repository-relative file paths. If dir does not contain an
+// importable package, ImportDir reports an error.
+//
+// Source files whose imports cannot be parsed are left out of the package and
+// recorded in its ParseErrors, so long as at least one Go file remains. If a
+// source file cannot be read to compute its digest, the error is recorded in
+// ParseErrors, the file has no digest, and the package digest is omitted.
+func ImportDir(bc *build.Context, root, dir string, opts *Options) (*Package, error) {
+	pkg, err := bc.ImportDir(dir, 0)
+	src := newSourceReader(bc)
+	defer src.release()
+	var diags []*ParseError
+	if err != nil {
+		if _, ok := err.(*build.MultiplePackageError); ok || pkg == nil {
+			return nil, err
+		}
+		diags = parseErrors(src, root, dir)
+		if len(diags) == 0 {
+			return nil, err
+		} else if err := dropBadFiles(src, pkg, diags); err != nil {
+			return nil, err
+		}
+	}
+	rec := &Package{
+		Name:        pkg.Name,
+		ImportPath:  pkg.ImportPath,
+		ParseErrors: diags,
+	}
+	rec.SetImports(pkg.Imports, opts)
+	if opts != nil && opts.CountRefs {
+		uses, err := countSymbolUses(src, dir, pkg.GoFiles)
+		if err != nil {
+			return nil, err
+		}
+		rec.ImportRefs = make([]int64, len(rec.Imports))
+		rec.ImportSymbols = make([]int64, len(rec.Imports))
+		for i, ip := range rec.Imports {
+			rec.ImportRefs[i] = uses.refs(ip)
+			rec.ImportSymbols[i] = int64(len(uses[ip]))
+		}
+	}
+	if opts != nil && opts.Directives {
+		if err := scanDirectives(bc, dir, pkg.GoFiles, rec); err != nil {
+			return nil, err
+		}
+	}
+	if opts != nil && opts.BuildTags {
+		var names []string
+		for _, list := range [][]string{
+			pkg.GoFiles, pkg.CgoFiles, pkg.IgnoredGoFiles, pkg.TestGoFiles, pkg.XTestGoFiles,
+		} {
+			names = append(names, list...)
+		}
+		tags, err := scanBuildTags(bc, dir, names)
+		if err != nil {
+			return nil, err
+		}
+		rec.BuildTags = tags
+	}
+	kept := make(map[string]bool) // imports retained by the stdlib policy
+	for _, ip := range rec.Imports {
+		kept[ip] = true
+	}
+	for _, ip := range rec.StdImports {
+		kept[ip] = true
+	}
+	blank, dot, err := scanImportNames(src, dir, append(append([]string(nil), pkg.GoFiles...), pkg.CgoFiles...))
+	if err != nil {
+		return nil, err
+	}
+	rec.BlankImports = keepImports(blank, kept)
+	rec.DotImports = keepImports(dot, kept)
+	unhashed := false // whether some file could not be hashed
+	hashFile := func(fpath string) []byte {
+		digest, err := src.hash(fpath, opts.Hash)
+		if err != nil {
+			rel, _ := filepath.Rel(root, fpath)
+			rec.ParseErrors = append(rec.ParseErrors, &ParseError{
+				RepoPath: filepath.ToSlash(rel),
+				Message:  fmt.Sprintf("computing digest: %v", err),
+			})
+			unhashed = true
+		}
+		return digest
+	}
+	if opts != nil && opts.HashSourceFiles {
+		rec.HashAlgorithm = opts.Hash.String()
+	}
+	if opts != nil && (opts.HashSourceFiles || opts.FileImports) {
+		for _, name := range pkg.GoFiles {
+			fpath := filepath.Join(dir, name)
+			rel, _ := filepath.Rel(root, fpath)
+			file := &File{RepoPath: filepath.ToSlash(rel)}
+			if opts.HashSourceFiles {
+				file.Digest = hashFile(fpath)
+			}
+			if opts.FileImports {
+				imps, err := fileImports(src, fpath)
+				if err != nil {
+					return nil, err
+				}
+				for _, ip := range imps {
+					if kept[ip] {
+						file.Imports = append(file.Imports, opts.intern(ip))
+					}
+				}
+			}
+			rec.Sources = append(rec.Sources, file)
+		}
+	}
+	if opts != nil && opts.ImportSites {
+		for _, list := range [][]string{pkg.GoFiles, pkg.CgoFiles} {
+			for _, name := range list {
+				sites, err := importSites(src, root, filepath.Join(dir, name))
+				if err != nil {
+					return nil, err
+				}
+				for _, site := range sites {
+					if kept[site.ImportPath] {
+						site.ImportPath = opts.intern(site.ImportPath)
+						rec.ImportSites = append(rec.ImportSites, site)
+					}
+				}
+			}
+		}
+	}
+	if opts != nil && opts.HashSourceFiles {
+		for _, list := range [][]string{
+			pkg.SFiles, pkg.CFiles, pkg.CXXFiles, pkg.HFiles, pkg.MFiles,
+			pkg.FFiles, pkg.SwigFiles, pkg.SwigCXXFiles, pkg.SysoFiles,
+		} {
+			for _, name := range list {
+				fpath := filepath.Join(dir, name)
+				rel, _ := filepath.Rel(root, fpath)
+				rec.OtherSources = append(rec.OtherSources, &File{
+					RepoPath: filepath.ToSlash(rel),
+					Digest:   hashFile(fpath),
+				})
+			}
+		}
+		if !unhashed {
+			rec.Digest = packageDigest(opts.Hash, rec)
+		}
+	}
+	return rec, nil
+}
+
+// packageDigest returns a digest of the contents of the source files of p,
+// computed by h over their digests in sorted order. It does not depend on the
+// names or locations of the files, so copies of a package have equal digests
+// wherever they are found.
+func packageDigest(h HashAlgorithm, p *Package) []byte {
+	var sums [][]byte
+	for _, list := range [][]*File{p.Sources, p.OtherSources} {
+		for _, src := range list {
+			sums = append(sums, src.Digest)
+		}
+	}
+	sort.Slice(sums, func(i, j int) bool { return bytes.Compare(sums[i], sums[j]) < 0 })
+	return h.Sum(bytes.Join(sums, nil))
+}
+
+// isStdlib reports whether ipath is a standard library package, according to
+// the catalog selected by o if any, or else IsStdlib.
+func (o *Options) isStdlib(ipath string) bool {
+	if o == nil || o.StdlibCatalog == nil {
+		return IsStdlib(ipath)
+	}
+	return o.StdlibCatalog.Contains(ipath, o.GoVersion)
+}
+
+// SetImports sets the direct imports of p to imports, subject to the stdlib
+// policy of opts, and updates the flags for the special packages it uses. If p
+// has usage counts for its imports, they are carried over to the new imports
+// that match; other imports get counts of zero. Blank and dot imports that are
+// no longer imports of p are dropped.
+func (p *Package) SetImports(imports []string, opts *Options) {
+	refs := indexCounts(p.Imports, p.ImportRefs)
+	syms := indexCounts(p.Imports, p.ImportSymbols)
+	p.UsesUnsafe, p.UsesReflect, p.UsesSyscall = false, false, false
+	for _, ip := range imports {
+		switch ip {
+		case "unsafe":
+			p.UsesUnsafe = true
+		case "reflect":
+			p.UsesReflect = true
+		case "syscall":
+			p.UsesSyscall = true
+		}
+	}
+	p.Imports, p.StdImports = nil, nil
+	for _, ip := range imports {
+		ip = opts.intern(ip)
+		std := opts.isStdlib(ip)
+		if !std || opts == nil || opts.Stdlib == IncludeStdlib {
+			p.Imports = append(p.Imports, ip)
+		}
+		if std && (opts == nil || opts.Stdlib != ExcludeStdlib) {
+			p.StdImports = append(p.StdImports, ip)
+		}
+	}
+	if refs != nil {
+		p.ImportRefs = alignCounts(p.Imports, refs)
+	}
+	if p.BlankImports != nil || p.DotImports != nil {
+		kept := make(map[string]bool)
+		for _, ip := range p.Imports {
+			kept[ip] = true
+		}
+		for _, ip := range p.StdImports {
+			kept[ip] = true
+		}
+		p.BlankImports = keepImports(p.BlankImports, kept)
+		p.DotImports = keepImports(p.DotImports, kept)
+	}
+	if syms != nil {
+		p.ImportSymbols = alignCounts(p.Imports, syms)
+	}
+}
+
+// keepImports returns the elements of imports for which keep is true.
+func keepImports(imports []string, keep map[string]bool) []string {
+	var out []string
+	for _, ip := range imports {
+		if keep[ip] {
+			out = append(out, ip)
+		}
+	}
+	return out
+}
+
+// indexCounts maps each of imports to the corresponding entry of counts, or
+// returns nil if counts is nil.
+func indexCounts(imports []string, counts []int64) map[string]int64 {
+	if counts == nil {
+		return nil
+	}
+	m := make(map[string]int64)
+	for i, ip := range imports {
+		m[ip] = counts[i]
+	}
+	return m
+}
+
+// alignCounts returns a slice of the counts for each of imports from m.
+func alignCounts(imports []string, m map[string]int64) []int64 {
+	out := make([]int64, len(imports))
+	for i, ip := range imports {
+		out[i] = m[ip]
+	}
+	return out
+}
+
+func openFile(bc *build.Context, path string) (io.ReadCloser, error) {
+	if bc.OpenFile != nil {
+		return bc.OpenFile(path)
+	}
+	return os.Open(path)
+}
+
+// fileImports returns the import paths declared by the Go source file at
+// path, in order of appearance and without duplicates.
+func fileImports(src *sourceReader, path string) ([]string, error) {
+	f, err := src.parse(path, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	var imps []string
+	for _, spec := range f.Imports {
+		ip, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid import path %s: %v", spec.Path.Value, err)
+		}
+		imps = appendNew(imps, ip)
+	}
+	return imps, nil
+}
+
+// importSites returns the location of each import declaration in the Go
+// source file at path, in order of appearance. The root is the path of the
+// enclosing repository.
+func importSites(src *sourceReader, root, path string) ([]*ImportSite, error) {
+	f, err := src.parse(path, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	rel, _ := filepath.Rel(root, path)
+	var sites []*ImportSite
+	for _, spec := range f.Imports {
+		ip, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid import path %s: %v", spec.Path.Value, err)
+		}
+		pos := src.fset.Position(spec.Path.Pos())
+		sites = append(sites, &ImportSite{
+			ImportPath: ip,
+			RepoPath:   filepath.ToSlash(rel),
+			Line:       int32(pos.Line),
+			Column:     int32(pos.Column),
+		})
+	}
+	return sites, nil
+}
+
+// parseErrors parses the imports of each Go source file in dir that would be
+// built, and returns a record of the first error in each file that fails.
+// The root is the path of the enclosing repository.
+func parseErrors(src *sourceReader, root, dir string) []*ParseError {
+	names, err := buildFiles(src.bc, dir)
+	if err != nil {
+		return nil
+	}
+	var diags []*ParseError
+	for _, name := range names {
+		fpath := filepath.Join(dir, name)
+		_, err := src.parse(fpath, parser.ImportsOnly)
+		if err == nil {
+			continue
+		} else if _, ok := err.(scanner.ErrorList); !ok {
+			continue // not a parse error
+		}
+		rel, _ := filepath.Rel(root, fpath)
+		diag := &ParseError{RepoPath: filepath.ToSlash(rel), Message: err.Error()}
+		if list, ok := err.(scanner.ErrorList); ok && len(list) != 0 {
+			diag.Line = int32(list[0].Pos.Line)
+			diag.Column = int32(list[0].Pos.Column)
+			diag.Message = list[0].Msg
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+// dropBadFiles removes the files named by diags from the file lists of pkg,
+// and recomputes its imports from the files that remain. It reports an error
+// if no Go files remain.
+func dropBadFiles(src *sourceReader, pkg *build.Package, diags []*ParseError) error {
+	bad := make(map[string]bool)
+	for _, diag := range diags {
+		bad[path.Base(diag.RepoPath)] = true
+	}
+	for _, list := range []*[]string{
+		&pkg.GoFiles, &pkg.CgoFiles, &pkg.IgnoredGoFiles, &pkg.TestGoFiles, &pkg.XTestGoFiles,
+	} {
+		var keep []string
+		for _, name := range *list {
+			if !bad[name] {
+				keep = append(keep, name)
+			}
+		}
+		*list = keep
+	}
+	if len(pkg.GoFiles) == 0 && len(pkg.CgoFiles) == 0 {
+		return fmt.Errorf("no Go files in %s parse", pkg.Dir)
+	}
+	seen := make(map[string]bool)
+	pkg.Imports = nil
+	for _, list := range [][]string{pkg.GoFiles, pkg.CgoFiles} {
+		for _, name := range list {
+			imps, err := fileImports(src, filepath.Join(pkg.Dir, name))
+			if err != nil {
+				return err
+			}
+			for _, ip := range imps {
+				if !seen[ip] {
+					seen[ip] = true
+					pkg.Imports = append(pkg.Imports, ip)
+				}
+			}
+		}
+	}
+	sort.Strings(pkg.Imports)
+	return nil
+}
+
+// packageConflict returns a record of the package clauses of the Go source
+// files in dir that bc would build, if err reports that they disagree, or nil
+// if it does not. External test files (package x_test) do not conflict, nor do
+// files of package documentation, matching the rules of go/build. The root is
+// the path of the enclosing repository.
+func packageConflict(src *sourceReader, root, dir string, err error) *PackageConflict {
+	mp, ok := err.(*build.MultiplePackageError)
+	if !ok {
+		return nil
+	}
+	rel, _ := filepath.Rel(root, dir)
+	pc := &PackageConflict{Dir: filepath.ToSlash(rel)}
+	names, err := buildFiles(src.bc, dir)
+	if err != nil {
+		// Fall back to the pair of files reported by go/build.
+		for i, name := range mp.Files {
+			frel, _ := filepath.Rel(root, filepath.Join(dir, name))
+			pc.Files = append(pc.Files, filepath.ToSlash(frel))
+			pc.Packages = append(pc.Packages, mp.Packages[i])
+		}
+		return pc
+	}
+	for _, name := range names {
+		fpath := filepath.Join(dir, name)
+		f, err := src.parse(fpath, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		pkg := f.Name.Name
+		if pkg == "documentation" {
+			continue
+		} else if strings.HasSuffix(name, "_test.go") && strings.HasSuffix(pkg, "_test") {
+			continue
+		}
+		frel, _ := filepath.Rel(root, fpath)
+		pc.Files = append(pc.Files, filepath.ToSlash(frel))
+		pc.Packages = append(pc.Packages, pkg)
+	}
+	return pc
+}
+
+// buildFiles returns the names of the Go source files in dir that bc would
+// build, including tests.
+func buildFiles(bc *build.Context, dir string) ([]string, error) {
+	readDir := ioutil.ReadDir
+	if bc.ReadDir != nil {
+		readDir = bc.ReadDir
+	}
+	fis, err := readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		} else if ok, err := bc.MatchFile(dir, name); err != nil || !ok {
+			continue // excluded by build constraints
+		}
+		names = append(names, name)
+	}
+	return names, nil
 }
 
 // Hash produces a SHA-256 digest of the contents of r.
@@ -20,6 +20,42 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// An ImportClass describes the relationship between a package and one of its
+// direct dependencies.
+type ImportClass int32
+
+const (
+	ImportClass_UNCLASSIFIED    ImportClass = 0
+	ImportClass_STDLIB          ImportClass = 1
+	ImportClass_SAME_MODULE     ImportClass = 2
+	ImportClass_SAME_REPOSITORY ImportClass = 3
+	ImportClass_EXTERNAL        ImportClass = 4
+)
+
+var ImportClass_name = map[int32]string{
+	0: "UNCLASSIFIED",
+	1: "STDLIB",
+	2: "SAME_MODULE",
+	3: "SAME_REPOSITORY",
+	4: "EXTERNAL",
+}
+
+var ImportClass_value = map[string]int32{
+	"UNCLASSIFIED":    0,
+	"STDLIB":          1,
+	"SAME_MODULE":     2,
+	"SAME_REPOSITORY": 3,
+	"EXTERNAL":        4,
+}
+
+func (x ImportClass) String() string {
+	return proto.EnumName(ImportClass_name, int32(x))
+}
+
+func (ImportClass) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{0}
+}
+
 // Deps records dependency information for a collection of repositories.
 type Deps struct {
 	Repositories         []*Repo  `protobuf:"bytes,1,rep,name=repositories,proto3" json:"repositories,omitempty"`
@@ -69,10 +105,42 @@ type Repo struct {
 	// The remotes defined by this repository.
 	Remotes []*Remote `protobuf:"bytes,2,rep,name=remotes,proto3" json:"remotes,omitempty"`
 	// The source packages defined inside this repository.
-	Packages             []*Package `protobuf:"bytes,3,rep,name=packages,proto3" json:"packages,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	Packages []*Package `protobuf:"bytes,3,rep,name=packages,proto3" json:"packages,omitempty"`
+	// Arbitrary key-value labels attached to this repository by the caller.
+	Labels map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The Go modules defined inside this repository, if known.
+	Modules []*Module `protobuf:"bytes,5,rep,name=modules,proto3" json:"modules,omitempty"`
+	// The submodules of this repository that were scanned, if any.
+	Submodules []*Submodule `protobuf:"bytes,6,rep,name=submodules,proto3" json:"submodules,omitempty"`
+	// The paths of other repositories found nested inside the worktree of this
+	// one, relative to the repository root. Their packages are not included in
+	// this repository.
+	Nested []string `protobuf:"bytes,7,rep,name=nested,proto3" json:"nested,omitempty"`
+	// Metadata about the repository from GitHub, if requested and available.
+	GithubInfo *GitHubInfo `protobuf:"bytes,8,opt,name=github_info,json=githubInfo,proto3" json:"github_info,omitempty"`
+	// Commit activity in the history of the scanned revision, if requested.
+	Activity *Activity `protobuf:"bytes,9,opt,name=activity,proto3" json:"activity,omitempty"`
+	// The version of the schema this record was written with. Zero means the
+	// record predates schema versioning. See deps.SchemaVersion.
+	Schema int32 `protobuf:"varint,10,opt,name=schema,proto3" json:"schema,omitempty"`
+	// Counts of the contents of the repository, if a summary was requested.
+	Summary *Summary `protobuf:"bytes,11,opt,name=summary,proto3" json:"summary,omitempty"`
+	// The commit ID of the revision that was scanned, if known. This is empty
+	// for a working tree with no commits.
+	Commit string `protobuf:"bytes,12,opt,name=commit,proto3" json:"commit,omitempty"`
+	// Directories whose Go source files declare conflicting package names, so
+	// that they contribute no Go package.
+	Conflicts []*PackageConflict `protobuf:"bytes,13,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	// A short human-readable description of the repository, taken from the
+	// title of the README file at its root, if there is one.
+	Description string `protobuf:"bytes,14,opt,name=description,proto3" json:"description,omitempty"`
+	// The SPDX identifier of the license of the repository, detected from the
+	// license file at its root, or "unknown" if the license file is not
+	// recognized. It is empty if there is no license file.
+	License              string   `protobuf:"bytes,15,opt,name=license,proto3" json:"license,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Repo) Reset()         { *m = Repo{} }
@@ -121,193 +189,1299 @@ func (m *Repo) GetPackages() []*Package {
 	return nil
 }
 
-// A Remote records information about a Git remote.
-type Remote struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+func (m *Repo) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Repo) GetModules() []*Module {
+	if m != nil {
+		return m.Modules
+	}
+	return nil
+}
+
+func (m *Repo) GetSubmodules() []*Submodule {
+	if m != nil {
+		return m.Submodules
+	}
+	return nil
+}
+
+func (m *Repo) GetNested() []string {
+	if m != nil {
+		return m.Nested
+	}
+	return nil
+}
+
+func (m *Repo) GetGithubInfo() *GitHubInfo {
+	if m != nil {
+		return m.GithubInfo
+	}
+	return nil
+}
+
+func (m *Repo) GetActivity() *Activity {
+	if m != nil {
+		return m.Activity
+	}
+	return nil
+}
+
+func (m *Repo) GetSchema() int32 {
+	if m != nil {
+		return m.Schema
+	}
+	return 0
+}
+
+func (m *Repo) GetSummary() *Summary {
+	if m != nil {
+		return m.Summary
+	}
+	return nil
+}
+
+func (m *Repo) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+func (m *Repo) GetConflicts() []*PackageConflict {
+	if m != nil {
+		return m.Conflicts
+	}
+	return nil
+}
+
+func (m *Repo) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Repo) GetLicense() string {
+	if m != nil {
+		return m.License
+	}
+	return ""
+}
+
+// Summary records counts of the files in a repository, without regard to
+// their contents. Vendored files are not counted.
+type Summary struct {
+	Files                int64    `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"`
+	GoFiles              int64    `protobuf:"varint,2,opt,name=go_files,json=goFiles,proto3" json:"go_files,omitempty"`
+	TestFiles            int64    `protobuf:"varint,3,opt,name=test_files,json=testFiles,proto3" json:"test_files,omitempty"`
+	Packages             int64    `protobuf:"varint,4,opt,name=packages,proto3" json:"packages,omitempty"`
+	Modules              int64    `protobuf:"varint,5,opt,name=modules,proto3" json:"modules,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Remote) Reset()         { *m = Remote{} }
-func (m *Remote) String() string { return proto.CompactTextString(m) }
-func (*Remote) ProtoMessage()    {}
-func (*Remote) Descriptor() ([]byte, []int) {
+func (m *Summary) Reset()         { *m = Summary{} }
+func (m *Summary) String() string { return proto.CompactTextString(m) }
+func (*Summary) ProtoMessage()    {}
+func (*Summary) Descriptor() ([]byte, []int) {
 	return fileDescriptor_8a878629c37a3cae, []int{2}
 }
 
-func (m *Remote) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Remote.Unmarshal(m, b)
+func (m *Summary) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Summary.Unmarshal(m, b)
 }
-func (m *Remote) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Remote.Marshal(b, m, deterministic)
+func (m *Summary) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Summary.Marshal(b, m, deterministic)
 }
-func (m *Remote) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Remote.Merge(m, src)
+func (m *Summary) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Summary.Merge(m, src)
 }
-func (m *Remote) XXX_Size() int {
-	return xxx_messageInfo_Remote.Size(m)
+func (m *Summary) XXX_Size() int {
+	return xxx_messageInfo_Summary.Size(m)
 }
-func (m *Remote) XXX_DiscardUnknown() {
-	xxx_messageInfo_Remote.DiscardUnknown(m)
+func (m *Summary) XXX_DiscardUnknown() {
+	xxx_messageInfo_Summary.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Remote proto.InternalMessageInfo
+var xxx_messageInfo_Summary proto.InternalMessageInfo
 
-func (m *Remote) GetName() string {
+func (m *Summary) GetFiles() int64 {
 	if m != nil {
-		return m.Name
+		return m.Files
 	}
-	return ""
+	return 0
 }
 
-func (m *Remote) GetUrl() string {
+func (m *Summary) GetGoFiles() int64 {
 	if m != nil {
-		return m.Url
+		return m.GoFiles
 	}
-	return ""
+	return 0
 }
 
-type Package struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	ImportPath           string   `protobuf:"bytes,2,opt,name=import_path,json=importPath,proto3" json:"import_path,omitempty"`
-	Imports              []string `protobuf:"bytes,3,rep,name=imports,proto3" json:"imports,omitempty"`
-	Sources              []*File  `protobuf:"bytes,4,rep,name=sources,proto3" json:"sources,omitempty"`
+func (m *Summary) GetTestFiles() int64 {
+	if m != nil {
+		return m.TestFiles
+	}
+	return 0
+}
+
+func (m *Summary) GetPackages() int64 {
+	if m != nil {
+		return m.Packages
+	}
+	return 0
+}
+
+func (m *Summary) GetModules() int64 {
+	if m != nil {
+		return m.Modules
+	}
+	return 0
+}
+
+// Activity summarizes the commit history of a repository.
+type Activity struct {
+	LastCommit           int64    `protobuf:"varint,1,opt,name=last_commit,json=lastCommit,proto3" json:"last_commit,omitempty"`
+	CommitsLastYear      int64    `protobuf:"varint,2,opt,name=commits_last_year,json=commitsLastYear,proto3" json:"commits_last_year,omitempty"`
+	TotalCommits         int64    `protobuf:"varint,3,opt,name=total_commits,json=totalCommits,proto3" json:"total_commits,omitempty"`
+	Authors              int64    `protobuf:"varint,4,opt,name=authors,proto3" json:"authors,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Package) Reset()         { *m = Package{} }
-func (m *Package) String() string { return proto.CompactTextString(m) }
-func (*Package) ProtoMessage()    {}
-func (*Package) Descriptor() ([]byte, []int) {
+func (m *Activity) Reset()         { *m = Activity{} }
+func (m *Activity) String() string { return proto.CompactTextString(m) }
+func (*Activity) ProtoMessage()    {}
+func (*Activity) Descriptor() ([]byte, []int) {
 	return fileDescriptor_8a878629c37a3cae, []int{3}
 }
 
-func (m *Package) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Package.Unmarshal(m, b)
+func (m *Activity) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Activity.Unmarshal(m, b)
 }
-func (m *Package) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Package.Marshal(b, m, deterministic)
+func (m *Activity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Activity.Marshal(b, m, deterministic)
 }
-func (m *Package) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Package.Merge(m, src)
+func (m *Activity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Activity.Merge(m, src)
 }
-func (m *Package) XXX_Size() int {
-	return xxx_messageInfo_Package.Size(m)
+func (m *Activity) XXX_Size() int {
+	return xxx_messageInfo_Activity.Size(m)
 }
-func (m *Package) XXX_DiscardUnknown() {
-	xxx_messageInfo_Package.DiscardUnknown(m)
+func (m *Activity) XXX_DiscardUnknown() {
+	xxx_messageInfo_Activity.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Package proto.InternalMessageInfo
+var xxx_messageInfo_Activity proto.InternalMessageInfo
 
-func (m *Package) GetName() string {
+func (m *Activity) GetLastCommit() int64 {
+	if m != nil {
+		return m.LastCommit
+	}
+	return 0
+}
+
+func (m *Activity) GetCommitsLastYear() int64 {
+	if m != nil {
+		return m.CommitsLastYear
+	}
+	return 0
+}
+
+func (m *Activity) GetTotalCommits() int64 {
+	if m != nil {
+		return m.TotalCommits
+	}
+	return 0
+}
+
+func (m *Activity) GetAuthors() int64 {
+	if m != nil {
+		return m.Authors
+	}
+	return 0
+}
+
+// GitHubInfo records metadata about a repository hosted on GitHub.
+type GitHubInfo struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Stars                int64    `protobuf:"varint,2,opt,name=stars,proto3" json:"stars,omitempty"`
+	Forks                int64    `protobuf:"varint,3,opt,name=forks,proto3" json:"forks,omitempty"`
+	Archived             bool     `protobuf:"varint,4,opt,name=archived,proto3" json:"archived,omitempty"`
+	Language             string   `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"`
+	Topics               []string `protobuf:"bytes,6,rep,name=topics,proto3" json:"topics,omitempty"`
+	Description          string   `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GitHubInfo) Reset()         { *m = GitHubInfo{} }
+func (m *GitHubInfo) String() string { return proto.CompactTextString(m) }
+func (*GitHubInfo) ProtoMessage()    {}
+func (*GitHubInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{4}
+}
+
+func (m *GitHubInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GitHubInfo.Unmarshal(m, b)
+}
+func (m *GitHubInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GitHubInfo.Marshal(b, m, deterministic)
+}
+func (m *GitHubInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GitHubInfo.Merge(m, src)
+}
+func (m *GitHubInfo) XXX_Size() int {
+	return xxx_messageInfo_GitHubInfo.Size(m)
+}
+func (m *GitHubInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_GitHubInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GitHubInfo proto.InternalMessageInfo
+
+func (m *GitHubInfo) GetName() string {
 	if m != nil {
 		return m.Name
 	}
 	return ""
 }
 
-func (m *Package) GetImportPath() string {
+func (m *GitHubInfo) GetStars() int64 {
 	if m != nil {
-		return m.ImportPath
+		return m.Stars
+	}
+	return 0
+}
+
+func (m *GitHubInfo) GetForks() int64 {
+	if m != nil {
+		return m.Forks
+	}
+	return 0
+}
+
+func (m *GitHubInfo) GetArchived() bool {
+	if m != nil {
+		return m.Archived
+	}
+	return false
+}
+
+func (m *GitHubInfo) GetLanguage() string {
+	if m != nil {
+		return m.Language
 	}
 	return ""
 }
 
-func (m *Package) GetImports() []string {
+func (m *GitHubInfo) GetTopics() []string {
 	if m != nil {
-		return m.Imports
+		return m.Topics
 	}
 	return nil
 }
 
-func (m *Package) GetSources() []*File {
+func (m *GitHubInfo) GetDescription() string {
 	if m != nil {
-		return m.Sources
+		return m.Description
 	}
-	return nil
+	return ""
 }
 
-type File struct {
-	// The path of the file relative to the enclosing repository root.
-	RepoPath string `protobuf:"bytes,1,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
-	// A hash of the content of the file (sha256).
-	Digest               []byte   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+// A Submodule records information about a Git submodule.
+type Submodule struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Commit               string   `protobuf:"bytes,3,opt,name=commit,proto3" json:"commit,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *File) Reset()         { *m = File{} }
-func (m *File) String() string { return proto.CompactTextString(m) }
-func (*File) ProtoMessage()    {}
-func (*File) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8a878629c37a3cae, []int{4}
+func (m *Submodule) Reset()         { *m = Submodule{} }
+func (m *Submodule) String() string { return proto.CompactTextString(m) }
+func (*Submodule) ProtoMessage()    {}
+func (*Submodule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{5}
 }
 
-func (m *File) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_File.Unmarshal(m, b)
+func (m *Submodule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Submodule.Unmarshal(m, b)
 }
-func (m *File) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_File.Marshal(b, m, deterministic)
+func (m *Submodule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Submodule.Marshal(b, m, deterministic)
 }
-func (m *File) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_File.Merge(m, src)
+func (m *Submodule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Submodule.Merge(m, src)
 }
-func (m *File) XXX_Size() int {
-	return xxx_messageInfo_File.Size(m)
+func (m *Submodule) XXX_Size() int {
+	return xxx_messageInfo_Submodule.Size(m)
 }
-func (m *File) XXX_DiscardUnknown() {
-	xxx_messageInfo_File.DiscardUnknown(m)
+func (m *Submodule) XXX_DiscardUnknown() {
+	xxx_messageInfo_Submodule.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_File proto.InternalMessageInfo
+var xxx_messageInfo_Submodule proto.InternalMessageInfo
 
-func (m *File) GetRepoPath() string {
+func (m *Submodule) GetPath() string {
 	if m != nil {
-		return m.RepoPath
+		return m.Path
 	}
 	return ""
 }
 
-func (m *File) GetDigest() []byte {
+func (m *Submodule) GetUrl() string {
 	if m != nil {
-		return m.Digest
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Submodule) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+// A Module records information about a Go module.
+type Module struct {
+	Path                 string         `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version              string         `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Dir                  string         `protobuf:"bytes,3,opt,name=dir,proto3" json:"dir,omitempty"`
+	Requires             []*Requirement `protobuf:"bytes,4,rep,name=requires,proto3" json:"requires,omitempty"`
+	Excludes             []*Requirement `protobuf:"bytes,5,rep,name=excludes,proto3" json:"excludes,omitempty"`
+	Replaces             []*Replacement `protobuf:"bytes,6,rep,name=replaces,proto3" json:"replaces,omitempty"`
+	GoVersion            string         `protobuf:"bytes,7,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	Toolchain            string         `protobuf:"bytes,8,opt,name=toolchain,proto3" json:"toolchain,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Module) Reset()         { *m = Module{} }
+func (m *Module) String() string { return proto.CompactTextString(m) }
+func (*Module) ProtoMessage()    {}
+func (*Module) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{6}
+}
+
+func (m *Module) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Module.Unmarshal(m, b)
+}
+func (m *Module) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Module.Marshal(b, m, deterministic)
+}
+func (m *Module) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Module.Merge(m, src)
+}
+func (m *Module) XXX_Size() int {
+	return xxx_messageInfo_Module.Size(m)
+}
+func (m *Module) XXX_DiscardUnknown() {
+	xxx_messageInfo_Module.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Module proto.InternalMessageInfo
+
+func (m *Module) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Module) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Module) GetDir() string {
+	if m != nil {
+		return m.Dir
+	}
+	return ""
+}
+
+func (m *Module) GetRequires() []*Requirement {
+	if m != nil {
+		return m.Requires
+	}
+	return nil
+}
+
+func (m *Module) GetExcludes() []*Requirement {
+	if m != nil {
+		return m.Excludes
+	}
+	return nil
+}
+
+func (m *Module) GetReplaces() []*Replacement {
+	if m != nil {
+		return m.Replaces
 	}
 	return nil
 }
 
+func (m *Module) GetGoVersion() string {
+	if m != nil {
+		return m.GoVersion
+	}
+	return ""
+}
+
+func (m *Module) GetToolchain() string {
+	if m != nil {
+		return m.Toolchain
+	}
+	return ""
+}
+
+// A Requirement records a module path and version.
+type Requirement struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Indirect             bool     `protobuf:"varint,3,opt,name=indirect,proto3" json:"indirect,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Requirement) Reset()         { *m = Requirement{} }
+func (m *Requirement) String() string { return proto.CompactTextString(m) }
+func (*Requirement) ProtoMessage()    {}
+func (*Requirement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{7}
+}
+
+func (m *Requirement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Requirement.Unmarshal(m, b)
+}
+func (m *Requirement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Requirement.Marshal(b, m, deterministic)
+}
+func (m *Requirement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Requirement.Merge(m, src)
+}
+func (m *Requirement) XXX_Size() int {
+	return xxx_messageInfo_Requirement.Size(m)
+}
+func (m *Requirement) XXX_DiscardUnknown() {
+	xxx_messageInfo_Requirement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Requirement proto.InternalMessageInfo
+
+func (m *Requirement) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Requirement) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Requirement) GetIndirect() bool {
+	if m != nil {
+		return m.Indirect
+	}
+	return false
+}
+
+// A Replacement records a replace directive from a go.mod file.
+type Replacement struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	NewPath              string   `protobuf:"bytes,3,opt,name=new_path,json=newPath,proto3" json:"new_path,omitempty"`
+	NewVersion           string   `protobuf:"bytes,4,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Replacement) Reset()         { *m = Replacement{} }
+func (m *Replacement) String() string { return proto.CompactTextString(m) }
+func (*Replacement) ProtoMessage()    {}
+func (*Replacement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{8}
+}
+
+func (m *Replacement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Replacement.Unmarshal(m, b)
+}
+func (m *Replacement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Replacement.Marshal(b, m, deterministic)
+}
+func (m *Replacement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Replacement.Merge(m, src)
+}
+func (m *Replacement) XXX_Size() int {
+	return xxx_messageInfo_Replacement.Size(m)
+}
+func (m *Replacement) XXX_DiscardUnknown() {
+	xxx_messageInfo_Replacement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Replacement proto.InternalMessageInfo
+
+func (m *Replacement) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Replacement) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Replacement) GetNewPath() string {
+	if m != nil {
+		return m.NewPath
+	}
+	return ""
+}
+
+func (m *Replacement) GetNewVersion() string {
+	if m != nil {
+		return m.NewVersion
+	}
+	return ""
+}
+
+// A Remote records information about a Git remote.
+type Remote struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Remote) Reset()         { *m = Remote{} }
+func (m *Remote) String() string { return proto.CompactTextString(m) }
+func (*Remote) ProtoMessage()    {}
+func (*Remote) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{9}
+}
+
+func (m *Remote) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Remote.Unmarshal(m, b)
+}
+func (m *Remote) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Remote.Marshal(b, m, deterministic)
+}
+func (m *Remote) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Remote.Merge(m, src)
+}
+func (m *Remote) XXX_Size() int {
+	return xxx_messageInfo_Remote.Size(m)
+}
+func (m *Remote) XXX_DiscardUnknown() {
+	xxx_messageInfo_Remote.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Remote proto.InternalMessageInfo
+
+func (m *Remote) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Remote) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+type Package struct {
+	Name       string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ImportPath string   `protobuf:"bytes,2,opt,name=import_path,json=importPath,proto3" json:"import_path,omitempty"`
+	Imports    []string `protobuf:"bytes,3,rep,name=imports,proto3" json:"imports,omitempty"`
+	Sources    []*File  `protobuf:"bytes,4,rep,name=sources,proto3" json:"sources,omitempty"`
+	Module     string   `protobuf:"bytes,5,opt,name=module,proto3" json:"module,omitempty"`
+	Submodule  string   `protobuf:"bytes,6,opt,name=submodule,proto3" json:"submodule,omitempty"`
+	// Imports that were rewritten by a replace directive in the enclosing
+	// module, mapping the effective import path to the path as written.
+	Replaced map[string]string `protobuf:"bytes,7,rep,name=replaced,proto3" json:"replaced,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Whether the package directly imports these low-level packages.
+	UsesUnsafe  bool `protobuf:"varint,8,opt,name=uses_unsafe,json=usesUnsafe,proto3" json:"uses_unsafe,omitempty"`
+	UsesReflect bool `protobuf:"varint,9,opt,name=uses_reflect,json=usesReflect,proto3" json:"uses_reflect,omitempty"`
+	UsesSyscall bool `protobuf:"varint,10,opt,name=uses_syscall,json=usesSyscall,proto3" json:"uses_syscall,omitempty"`
+	// Direct dependencies that belong to the standard library. Depending on the
+	// options used for the scan, these may or may not also appear in imports.
+	StdImports []string `protobuf:"bytes,11,rep,name=std_imports,json=stdImports,proto3" json:"std_imports,omitempty"`
+	// The classification of each entry of imports, in the same order.
+	ImportClasses []ImportClass `protobuf:"varint,12,rep,packed,name=import_classes,json=importClasses,proto3,enum=deps.ImportClass" json:"import_classes,omitempty"`
+	// The owners of the package according to the CODEOWNERS or OWNERS files of
+	// the enclosing repository, if any.
+	Owners []string `protobuf:"bytes,13,rep,name=owners,proto3" json:"owners,omitempty"`
+	// Whether the imports of this package were resolved by the go command
+	// rather than by scanning its source files.
+	Precise bool `protobuf:"varint,14,opt,name=precise,proto3" json:"precise,omitempty"`
+	// The number of references to exported identifiers of each import, in the
+	// same order as imports, if usage counting was requested.
+	ImportRefs []int64 `protobuf:"varint,15,rep,packed,name=import_refs,json=importRefs,proto3" json:"import_refs,omitempty"`
+	// The number of distinct exported identifiers of each import that are
+	// referenced, in the same order as imports, if usage counting was requested.
+	ImportSymbols []int64 `protobuf:"varint,16,rep,packed,name=import_symbols,json=importSymbols,proto3" json:"import_symbols,omitempty"`
+	// The file patterns named by //go:embed directives in the package, and the
+	// commands given by its //go:generate directives, if requested.
+	Embeds    []string `protobuf:"bytes,17,rep,name=embeds,proto3" json:"embeds,omitempty"`
+	Generates []string `protobuf:"bytes,18,rep,name=generates,proto3" json:"generates,omitempty"`
+	// The build tags mentioned by the build constraints of any of the files of
+	// the package, including those implied by file names, if requested.
+	BuildTags []string `protobuf:"bytes,19,rep,name=build_tags,json=buildTags,proto3" json:"build_tags,omitempty"`
+	// The non-Go source files comprising the package, such as assembly (.s),
+	// C (.c, .h), and C++ files, and compiled objects (.syso), if source file
+	// hashing was requested.
+	OtherSources []*File `protobuf:"bytes,20,rep,name=other_sources,json=otherSources,proto3" json:"other_sources,omitempty"`
+	// The language or package ecosystem of the package, as named by the
+	// analyzer that found it, if it is not a Go package.
+	Language string `protobuf:"bytes,21,opt,name=language,proto3" json:"language,omitempty"`
+	// The declared version of the package and the resolved versions of its
+	// imports, keyed by import path, for ecosystems that record them outside
+	// of Go modules.
+	Versions map[string]string `protobuf:"bytes,22,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The location of each import declaration in the Go source files of the
+	// package, if requested.
+	ImportSites []*ImportSite `protobuf:"bytes,23,rep,name=import_sites,json=importSites,proto3" json:"import_sites,omitempty"`
+	// The imports that the package declares only as blank (_) imports, for their
+	// side effects, and those it declares as dot (.) imports in any file.
+	BlankImports []string `protobuf:"bytes,24,rep,name=blank_imports,json=blankImports,proto3" json:"blank_imports,omitempty"`
+	DotImports   []string `protobuf:"bytes,25,rep,name=dot_imports,json=dotImports,proto3" json:"dot_imports,omitempty"`
+	// The source files that were left out of the package because their imports
+	// could not be parsed, and those whose digests could not be computed.
+	ParseErrors []*ParseError `protobuf:"bytes,26,rep,name=parse_errors,json=parseErrors,proto3" json:"parse_errors,omitempty"`
+	// The name of the algorithm used to compute the digests of the source
+	// files of the package, if they were recorded: "sha256", "blake3", or
+	// "git" (the Git blob object ID).
+	HashAlgorithm string `protobuf:"bytes,27,opt,name=hash_algorithm,json=hashAlgorithm,proto3" json:"hash_algorithm,omitempty"`
+	// A digest of the contents of the package, if source digests were recorded,
+	// computed with hash_algorithm over the sorted digests of its files. Copies
+	// of a package have the same digest regardless of where they are found. It
+	// is omitted if the digest of some file could not be computed.
+	Digest               []byte   `protobuf:"bytes,28,opt,name=digest,proto3" json:"digest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Package) Reset()         { *m = Package{} }
+func (m *Package) String() string { return proto.CompactTextString(m) }
+func (*Package) ProtoMessage()    {}
+func (*Package) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{10}
+}
+
+func (m *Package) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Package.Unmarshal(m, b)
+}
+func (m *Package) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Package.Marshal(b, m, deterministic)
+}
+func (m *Package) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Package.Merge(m, src)
+}
+func (m *Package) XXX_Size() int {
+	return xxx_messageInfo_Package.Size(m)
+}
+func (m *Package) XXX_DiscardUnknown() {
+	xxx_messageInfo_Package.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Package proto.InternalMessageInfo
+
+func (m *Package) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Package) GetImportPath() string {
+	if m != nil {
+		return m.ImportPath
+	}
+	return ""
+}
+
+func (m *Package) GetImports() []string {
+	if m != nil {
+		return m.Imports
+	}
+	return nil
+}
+
+func (m *Package) GetSources() []*File {
+	if m != nil {
+		return m.Sources
+	}
+	return nil
+}
+
+func (m *Package) GetModule() string {
+	if m != nil {
+		return m.Module
+	}
+	return ""
+}
+
+func (m *Package) GetSubmodule() string {
+	if m != nil {
+		return m.Submodule
+	}
+	return ""
+}
+
+func (m *Package) GetReplaced() map[string]string {
+	if m != nil {
+		return m.Replaced
+	}
+	return nil
+}
+
+func (m *Package) GetUsesUnsafe() bool {
+	if m != nil {
+		return m.UsesUnsafe
+	}
+	return false
+}
+
+func (m *Package) GetUsesReflect() bool {
+	if m != nil {
+		return m.UsesReflect
+	}
+	return false
+}
+
+func (m *Package) GetUsesSyscall() bool {
+	if m != nil {
+		return m.UsesSyscall
+	}
+	return false
+}
+
+func (m *Package) GetStdImports() []string {
+	if m != nil {
+		return m.StdImports
+	}
+	return nil
+}
+
+func (m *Package) GetImportClasses() []ImportClass {
+	if m != nil {
+		return m.ImportClasses
+	}
+	return nil
+}
+
+func (m *Package) GetOwners() []string {
+	if m != nil {
+		return m.Owners
+	}
+	return nil
+}
+
+func (m *Package) GetPrecise() bool {
+	if m != nil {
+		return m.Precise
+	}
+	return false
+}
+
+func (m *Package) GetImportRefs() []int64 {
+	if m != nil {
+		return m.ImportRefs
+	}
+	return nil
+}
+
+func (m *Package) GetImportSymbols() []int64 {
+	if m != nil {
+		return m.ImportSymbols
+	}
+	return nil
+}
+
+func (m *Package) GetEmbeds() []string {
+	if m != nil {
+		return m.Embeds
+	}
+	return nil
+}
+
+func (m *Package) GetGenerates() []string {
+	if m != nil {
+		return m.Generates
+	}
+	return nil
+}
+
+func (m *Package) GetBuildTags() []string {
+	if m != nil {
+		return m.BuildTags
+	}
+	return nil
+}
+
+func (m *Package) GetOtherSources() []*File {
+	if m != nil {
+		return m.OtherSources
+	}
+	return nil
+}
+
+func (m *Package) GetLanguage() string {
+	if m != nil {
+		return m.Language
+	}
+	return ""
+}
+
+func (m *Package) GetVersions() map[string]string {
+	if m != nil {
+		return m.Versions
+	}
+	return nil
+}
+
+func (m *Package) GetImportSites() []*ImportSite {
+	if m != nil {
+		return m.ImportSites
+	}
+	return nil
+}
+
+func (m *Package) GetBlankImports() []string {
+	if m != nil {
+		return m.BlankImports
+	}
+	return nil
+}
+
+func (m *Package) GetDotImports() []string {
+	if m != nil {
+		return m.DotImports
+	}
+	return nil
+}
+
+func (m *Package) GetParseErrors() []*ParseError {
+	if m != nil {
+		return m.ParseErrors
+	}
+	return nil
+}
+
+func (m *Package) GetHashAlgorithm() string {
+	if m != nil {
+		return m.HashAlgorithm
+	}
+	return ""
+}
+
+func (m *Package) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+type File struct {
+	// The path of the file relative to the enclosing repository root.
+	RepoPath string `protobuf:"bytes,1,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	// A hash of the content of the file, computed with the hash_algorithm of
+	// the enclosing package.
+	Digest []byte `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The import paths declared by this file, subject to the same stdlib
+	// filtering as the imports of its package.
+	Imports              []string `protobuf:"bytes,3,rep,name=imports,proto3" json:"imports,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *File) Reset()         { *m = File{} }
+func (m *File) String() string { return proto.CompactTextString(m) }
+func (*File) ProtoMessage()    {}
+func (*File) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{11}
+}
+
+func (m *File) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_File.Unmarshal(m, b)
+}
+func (m *File) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_File.Marshal(b, m, deterministic)
+}
+func (m *File) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_File.Merge(m, src)
+}
+func (m *File) XXX_Size() int {
+	return xxx_messageInfo_File.Size(m)
+}
+func (m *File) XXX_DiscardUnknown() {
+	xxx_messageInfo_File.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_File proto.InternalMessageInfo
+
+func (m *File) GetRepoPath() string {
+	if m != nil {
+		return m.RepoPath
+	}
+	return ""
+}
+
+func (m *File) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+func (m *File) GetImports() []string {
+	if m != nil {
+		return m.Imports
+	}
+	return nil
+}
+
+// An ImportSite records where a source file imports a package.
+type ImportSite struct {
+	// The import path as written in the source file.
+	ImportPath string `protobuf:"bytes,1,opt,name=import_path,json=importPath,proto3" json:"import_path,omitempty"`
+	// The path of the file relative to the enclosing repository root.
+	RepoPath string `protobuf:"bytes,2,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	// The line and column (in bytes) where the import path begins, from 1.
+	Line                 int32    `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Column               int32    `protobuf:"varint,4,opt,name=column,proto3" json:"column,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportSite) Reset()         { *m = ImportSite{} }
+func (m *ImportSite) String() string { return proto.CompactTextString(m) }
+func (*ImportSite) ProtoMessage()    {}
+func (*ImportSite) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{12}
+}
+
+func (m *ImportSite) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportSite.Unmarshal(m, b)
+}
+func (m *ImportSite) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportSite.Marshal(b, m, deterministic)
+}
+func (m *ImportSite) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportSite.Merge(m, src)
+}
+func (m *ImportSite) XXX_Size() int {
+	return xxx_messageInfo_ImportSite.Size(m)
+}
+func (m *ImportSite) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportSite.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportSite proto.InternalMessageInfo
+
+func (m *ImportSite) GetImportPath() string {
+	if m != nil {
+		return m.ImportPath
+	}
+	return ""
+}
+
+func (m *ImportSite) GetRepoPath() string {
+	if m != nil {
+		return m.RepoPath
+	}
+	return ""
+}
+
+func (m *ImportSite) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *ImportSite) GetColumn() int32 {
+	if m != nil {
+		return m.Column
+	}
+	return 0
+}
+
+// A PackageConflict records a directory whose Go source files declare more
+// than one package name. External test files (package x_test) are not
+// considered.
+type PackageConflict struct {
+	// The path of the directory relative to the enclosing repository root.
+	Dir string `protobuf:"bytes,1,opt,name=dir,proto3" json:"dir,omitempty"`
+	// The paths of the Go source files in the directory, relative to the
+	// repository root, and the package name declared by each.
+	Files                []string `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	Packages             []string `protobuf:"bytes,3,rep,name=packages,proto3" json:"packages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PackageConflict) Reset()         { *m = PackageConflict{} }
+func (m *PackageConflict) String() string { return proto.CompactTextString(m) }
+func (*PackageConflict) ProtoMessage()    {}
+func (*PackageConflict) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{13}
+}
+
+func (m *PackageConflict) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PackageConflict.Unmarshal(m, b)
+}
+func (m *PackageConflict) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PackageConflict.Marshal(b, m, deterministic)
+}
+func (m *PackageConflict) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PackageConflict.Merge(m, src)
+}
+func (m *PackageConflict) XXX_Size() int {
+	return xxx_messageInfo_PackageConflict.Size(m)
+}
+func (m *PackageConflict) XXX_DiscardUnknown() {
+	xxx_messageInfo_PackageConflict.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PackageConflict proto.InternalMessageInfo
+
+func (m *PackageConflict) GetDir() string {
+	if m != nil {
+		return m.Dir
+	}
+	return ""
+}
+
+func (m *PackageConflict) GetFiles() []string {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+func (m *PackageConflict) GetPackages() []string {
+	if m != nil {
+		return m.Packages
+	}
+	return nil
+}
+
+// A ParseError records a source file that could not be parsed.
+type ParseError struct {
+	// The path of the file relative to the enclosing repository root.
+	RepoPath string `protobuf:"bytes,1,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	// The line and column (in bytes) of the first error, from 1, if known.
+	Line   int32 `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Column int32 `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	// A description of the error.
+	Message              string   `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ParseError) Reset()         { *m = ParseError{} }
+func (m *ParseError) String() string { return proto.CompactTextString(m) }
+func (*ParseError) ProtoMessage()    {}
+func (*ParseError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a878629c37a3cae, []int{14}
+}
+
+func (m *ParseError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ParseError.Unmarshal(m, b)
+}
+func (m *ParseError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ParseError.Marshal(b, m, deterministic)
+}
+func (m *ParseError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ParseError.Merge(m, src)
+}
+func (m *ParseError) XXX_Size() int {
+	return xxx_messageInfo_ParseError.Size(m)
+}
+func (m *ParseError) XXX_DiscardUnknown() {
+	xxx_messageInfo_ParseError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ParseError proto.InternalMessageInfo
+
+func (m *ParseError) GetRepoPath() string {
+	if m != nil {
+		return m.RepoPath
+	}
+	return ""
+}
+
+func (m *ParseError) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *ParseError) GetColumn() int32 {
+	if m != nil {
+		return m.Column
+	}
+	return 0
+}
+
+func (m *ParseError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 func init() {
+	proto.RegisterEnum("deps.ImportClass", ImportClass_name, ImportClass_value)
 	proto.RegisterType((*Deps)(nil), "deps.Deps")
 	proto.RegisterType((*Repo)(nil), "deps.Repo")
+	proto.RegisterMapType((map[string]string)(nil), "deps.Repo.LabelsEntry")
+	proto.RegisterType((*Summary)(nil), "deps.Summary")
+	proto.RegisterType((*Activity)(nil), "deps.Activity")
+	proto.RegisterType((*GitHubInfo)(nil), "deps.GitHubInfo")
+	proto.RegisterType((*Submodule)(nil), "deps.Submodule")
+	proto.RegisterType((*Module)(nil), "deps.Module")
+	proto.RegisterType((*Requirement)(nil), "deps.Requirement")
+	proto.RegisterType((*Replacement)(nil), "deps.Replacement")
 	proto.RegisterType((*Remote)(nil), "deps.Remote")
 	proto.RegisterType((*Package)(nil), "deps.Package")
+	proto.RegisterMapType((map[string]string)(nil), "deps.Package.ReplacedEntry")
+	proto.RegisterMapType((map[string]string)(nil), "deps.Package.VersionsEntry")
 	proto.RegisterType((*File)(nil), "deps.File")
+	proto.RegisterType((*ImportSite)(nil), "deps.ImportSite")
+	proto.RegisterType((*PackageConflict)(nil), "deps.PackageConflict")
+	proto.RegisterType((*ParseError)(nil), "deps.ParseError")
 }
 
 func init() { proto.RegisterFile("deps.proto", fileDescriptor_8a878629c37a3cae) }
 
 var fileDescriptor_8a878629c37a3cae = []byte{
-	// 268 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0x41, 0x4b, 0x3b, 0x31,
-	0x10, 0xc5, 0xd9, 0x6e, 0xd8, 0xed, 0x4e, 0xf7, 0x0f, 0x7f, 0xe6, 0x20, 0x01, 0x0f, 0x96, 0x20,
-	0x52, 0x2f, 0x7b, 0x50, 0xf0, 0xe2, 0x55, 0x3c, 0x97, 0x7c, 0x01, 0x89, 0xed, 0xd8, 0x06, 0x1b,
-	0x13, 0x92, 0xf4, 0xea, 0x67, 0x97, 0x24, 0x1b, 0x51, 0xf0, 0x36, 0xf3, 0x7b, 0x79, 0xf3, 0x86,
-	0x09, 0xc0, 0x9e, 0x5c, 0x98, 0x9c, 0xb7, 0xd1, 0x22, 0x4b, 0xb5, 0x78, 0x00, 0xf6, 0x44, 0x2e,
-	0xe0, 0x04, 0xa3, 0x27, 0x67, 0x83, 0x8e, 0xd6, 0x6b, 0x0a, 0xbc, 0x59, 0xb7, 0x9b, 0xd5, 0x1d,
-	0x4c, 0xd9, 0x20, 0xc9, 0x59, 0xf9, 0x4b, 0x17, 0x06, 0x58, 0xa2, 0x88, 0xc0, 0xde, 0xbc, 0x35,
-	0xbc, 0x59, 0x37, 0x9b, 0x41, 0xe6, 0x1a, 0x6f, 0xa0, 0xf7, 0x64, 0x6c, 0xa4, 0xc0, 0x17, 0x79,
-	0xcc, 0x58, 0xc7, 0x24, 0x28, 0xab, 0x88, 0xb7, 0xb0, 0x74, 0x6a, 0xf7, 0xae, 0x0e, 0x14, 0x78,
-	0x9b, 0x1f, 0xfe, 0x2b, 0x0f, 0xb7, 0x85, 0xca, 0x6f, 0x59, 0x4c, 0xd0, 0x15, 0x77, 0x0a, 0xfc,
-	0x50, 0x86, 0x6a, 0x60, 0xaa, 0xf1, 0x3f, 0xb4, 0x67, 0x7f, 0xe2, 0x8b, 0x8c, 0x52, 0x29, 0x3e,
-	0xa1, 0x9f, 0x87, 0xfc, 0x69, 0xb8, 0x82, 0x95, 0x36, 0xce, 0xfa, 0xf8, 0xe2, 0x54, 0x3c, 0xce,
-	0x46, 0x28, 0x68, 0xab, 0xe2, 0x11, 0x39, 0xf4, 0xa5, 0x2b, 0x9b, 0x0d, 0xb2, 0xb6, 0x78, 0x0d,
-	0x7d, 0xb0, 0x67, 0xbf, 0xa3, 0xc0, 0xd9, 0xcf, 0x1b, 0x3d, 0xeb, 0x13, 0xc9, 0x2a, 0x89, 0x47,
-	0x60, 0x09, 0xe0, 0x25, 0x0c, 0xe9, 0x6c, 0x25, 0xa6, 0x6c, 0xb0, 0x4c, 0x20, 0x87, 0x5c, 0x40,
-	0xb7, 0xd7, 0x07, 0x0a, 0x31, 0x2f, 0x30, 0xca, 0xb9, 0x7b, 0xed, 0xf2, 0x07, 0xdd, 0x7f, 0x05,
-	0x00, 0x00, 0xff, 0xff, 0x5b, 0xb5, 0x9b, 0x89, 0xae, 0x01, 0x00, 0x00,
+	// 1447 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x95, 0x57, 0xdb, 0x72, 0x1b, 0x45,
+	0x10, 0x45, 0x17, 0x5b, 0x52, 0x4b, 0xb2, 0x95, 0xc9, 0x85, 0x8d, 0x13, 0x8a, 0x20, 0x6e, 0x26,
+	0x55, 0x98, 0x22, 0xa9, 0x82, 0x00, 0x4f, 0xc6, 0x56, 0xc0, 0x55, 0x76, 0xe2, 0x1a, 0xd9, 0x40,
+	0x9e, 0x54, 0xab, 0xd5, 0x58, 0xda, 0xf2, 0xae, 0x56, 0xec, 0xec, 0x3a, 0xe8, 0x2b, 0xa8, 0xe2,
+	0x81, 0xcf, 0xe1, 0x8f, 0x78, 0xe1, 0x0b, 0xe8, 0xee, 0x99, 0x59, 0xad, 0x2f, 0x50, 0xe5, 0x27,
+	0x4d, 0x9f, 0xee, 0xed, 0xe9, 0xe9, 0x3e, 0xd3, 0x3d, 0x02, 0x98, 0xa8, 0x85, 0xde, 0x59, 0xa4,
+	0x49, 0x96, 0x88, 0x3a, 0xad, 0xfb, 0x5f, 0x41, 0x7d, 0x1f, 0x7f, 0xc5, 0x0e, 0x74, 0x52, 0xb5,
+	0x48, 0x74, 0x98, 0x25, 0x69, 0xa8, 0xb4, 0x57, 0x79, 0x52, 0xdb, 0x6e, 0x3f, 0x83, 0x1d, 0xfe,
+	0x40, 0xa2, 0x46, 0x5e, 0xd2, 0xf7, 0xff, 0xa9, 0x43, 0x9d, 0x60, 0x21, 0xa0, 0x7e, 0x96, 0x26,
+	0x31, 0x7e, 0x50, 0xd9, 0x6e, 0x49, 0x5e, 0x8b, 0x4f, 0xa0, 0x91, 0xaa, 0x38, 0xc9, 0xd0, 0x4f,
+	0x95, 0xfd, 0x74, 0x9c, 0x1f, 0x02, 0xa5, 0x53, 0x8a, 0xcf, 0xa0, 0xb9, 0xf0, 0x83, 0x73, 0x7f,
+	0x8a, 0x86, 0x35, 0x36, 0xec, 0x1a, 0xc3, 0x63, 0x83, 0xca, 0x42, 0x8d, 0xf1, 0xad, 0x47, 0xfe,
+	0x58, 0x45, 0xda, 0xab, 0xb3, 0xe1, 0x83, 0x55, 0x64, 0x3b, 0x87, 0xac, 0x18, 0xcc, 0xb3, 0x74,
+	0x29, 0xad, 0x15, 0x85, 0x10, 0x27, 0x93, 0x3c, 0x42, 0xcf, 0x6b, 0xe5, 0x10, 0x8e, 0x18, 0x94,
+	0x4e, 0x29, 0xbe, 0x00, 0xd0, 0xf9, 0xd8, 0x99, 0xae, 0xb3, 0xe9, 0xa6, 0x31, 0x1d, 0x3a, 0x5c,
+	0x96, 0x4c, 0xc4, 0x03, 0x58, 0x9f, 0x2b, 0x9d, 0xa9, 0x89, 0xd7, 0x40, 0xe3, 0x96, 0xb4, 0x92,
+	0xf8, 0x12, 0xda, 0xd3, 0x30, 0x9b, 0xe5, 0xe3, 0x51, 0x38, 0x3f, 0x4b, 0xbc, 0x26, 0xa6, 0xa3,
+	0xfd, 0xac, 0x67, 0x3c, 0xfd, 0x10, 0x66, 0x3f, 0xe6, 0xe3, 0x03, 0xc4, 0x25, 0x18, 0x23, 0x5a,
+	0x8b, 0xa7, 0xd0, 0xf4, 0x83, 0x2c, 0xbc, 0x08, 0xb3, 0xa5, 0xd7, 0x62, 0xfb, 0x0d, 0x63, 0xbf,
+	0x6b, 0x51, 0x59, 0xe8, 0x69, 0x5b, 0x1d, 0xcc, 0x54, 0xec, 0x7b, 0x80, 0x96, 0x6b, 0xd2, 0x4a,
+	0xe2, 0x53, 0x68, 0xe8, 0x3c, 0x8e, 0xfd, 0x74, 0xe9, 0xb5, 0xd9, 0x45, 0xd7, 0x05, 0xcf, 0xa0,
+	0x74, 0x5a, 0x72, 0x10, 0x24, 0x71, 0x1c, 0x66, 0x5e, 0x87, 0x2b, 0x65, 0x25, 0xf1, 0x1c, 0x5a,
+	0x41, 0x32, 0x3f, 0x8b, 0xc2, 0x20, 0xd3, 0x5e, 0x97, 0xcf, 0x7f, 0xff, 0x52, 0x11, 0xf6, 0xac,
+	0x56, 0xae, 0xec, 0xc4, 0x13, 0x68, 0x4f, 0x94, 0x0e, 0xd2, 0x70, 0x91, 0x85, 0xc9, 0xdc, 0xdb,
+	0x60, 0x8f, 0x65, 0x48, 0x78, 0xd0, 0x40, 0x53, 0x35, 0xd7, 0xca, 0xdb, 0x64, 0xad, 0x13, 0xb7,
+	0xbe, 0x81, 0x76, 0xa9, 0x60, 0xa2, 0x07, 0xb5, 0x73, 0xb5, 0xb4, 0xf4, 0xa1, 0xa5, 0xb8, 0x07,
+	0x6b, 0x17, 0x7e, 0x94, 0x2b, 0xe4, 0x0e, 0x61, 0x46, 0xf8, 0xb6, 0xfa, 0xa2, 0xd2, 0xff, 0xbd,
+	0x02, 0x0d, 0x7b, 0x30, 0xb2, 0x3a, 0x0b, 0x23, 0x66, 0x6a, 0x65, 0xbb, 0x26, 0x8d, 0x20, 0x1e,
+	0x42, 0x73, 0x9a, 0x8c, 0x8c, 0xa2, 0xca, 0x8a, 0xc6, 0x34, 0x79, 0xc9, 0xaa, 0xf7, 0x00, 0x90,
+	0x73, 0x99, 0x55, 0xd6, 0x58, 0xd9, 0x22, 0xc4, 0xa8, 0xb7, 0x4a, 0x5c, 0xac, 0xb3, 0x72, 0x45,
+	0x3e, 0xaf, 0x4c, 0x26, 0x76, 0x6a, 0xc5, 0xfe, 0x9f, 0x15, 0x68, 0xba, 0x6a, 0x89, 0xf7, 0xa1,
+	0x1d, 0xf9, 0xb8, 0x83, 0xcd, 0xb3, 0x09, 0x0c, 0x08, 0xda, 0x33, 0xb9, 0x7e, 0x0a, 0x77, 0x8c,
+	0x4e, 0x8f, 0xd8, 0x70, 0xa9, 0xfc, 0xd4, 0x86, 0xb9, 0x69, 0x15, 0x87, 0x88, 0xbf, 0x41, 0x58,
+	0x7c, 0x08, 0xdd, 0x2c, 0xc9, 0xfc, 0xc8, 0x7a, 0x73, 0x11, 0x77, 0x18, 0x34, 0xfe, 0x38, 0x30,
+	0x3f, 0xcf, 0x66, 0x49, 0xea, 0x62, 0x76, 0x62, 0xff, 0xaf, 0x0a, 0xc0, 0x8a, 0x76, 0x74, 0x4b,
+	0xe7, 0x7e, 0xac, 0xdc, 0x2d, 0xa5, 0x35, 0x65, 0x50, 0x67, 0x7e, 0xea, 0x12, 0x65, 0x04, 0xce,
+	0x6b, 0x92, 0x9e, 0xbb, 0xfd, 0x8c, 0x40, 0xd9, 0xf1, 0xd3, 0x60, 0x16, 0x5e, 0x20, 0xef, 0x69,
+	0xa7, 0xa6, 0x2c, 0x64, 0xd2, 0x45, 0xfe, 0x7c, 0x9a, 0x63, 0xaa, 0x38, 0x3d, 0x2d, 0x59, 0xc8,
+	0xc4, 0xba, 0x2c, 0x59, 0x84, 0x81, 0xb9, 0x5a, 0xc8, 0x3a, 0x23, 0x5d, 0x25, 0x50, 0xe3, 0x1a,
+	0x81, 0xfa, 0x07, 0xd0, 0x2a, 0x2e, 0x20, 0x85, 0xbf, 0xf0, 0xb3, 0x99, 0x0b, 0x9f, 0xd6, 0x44,
+	0x9c, 0x3c, 0x8d, 0x2c, 0x49, 0x68, 0x59, 0xa2, 0x78, 0xad, 0x4c, 0xf1, 0xfe, 0x1f, 0x55, 0x58,
+	0x3f, 0xfa, 0x6f, 0x47, 0x98, 0xc4, 0x0b, 0x95, 0x6a, 0x8a, 0xc3, 0x38, 0x73, 0x22, 0x6d, 0x31,
+	0x09, 0x53, 0xeb, 0x8d, 0x96, 0xe2, 0x73, 0x68, 0xa6, 0xea, 0xd7, 0x3c, 0x4c, 0x95, 0x6b, 0x44,
+	0x77, 0x5c, 0x23, 0x62, 0x34, 0x56, 0xf3, 0x4c, 0x16, 0x26, 0x64, 0xae, 0x7e, 0x0b, 0xa2, 0x7c,
+	0x52, 0xb4, 0xa1, 0x9b, 0xcc, 0x9d, 0x89, 0xf1, 0xbe, 0x88, 0xfc, 0xa0, 0x68, 0x45, 0x85, 0x39,
+	0xa3, 0xce, 0xbb, 0x31, 0x21, 0x46, 0x23, 0xd9, 0x5d, 0xec, 0x26, 0x87, 0xad, 0x69, 0xf2, 0x93,
+	0x8d, 0xfe, 0x31, 0xb4, 0xb2, 0x24, 0x89, 0x82, 0x99, 0x1f, 0xce, 0xb9, 0x1f, 0xa1, 0xb6, 0x00,
+	0xfa, 0x3f, 0x43, 0xbb, 0x14, 0xc4, 0x2d, 0x13, 0x83, 0x25, 0x0f, 0xe7, 0x98, 0x0f, 0x15, 0x98,
+	0x5c, 0x23, 0x1d, 0x9c, 0xdc, 0x7f, 0x4b, 0x8e, 0x8b, 0x70, 0x6f, 0xe9, 0x18, 0xef, 0xef, 0x5c,
+	0xbd, 0x1d, 0xf1, 0x17, 0x26, 0xed, 0x0d, 0x94, 0x8f, 0xe9, 0x23, 0xbc, 0x5d, 0xa4, 0x72, 0x1f,
+	0xd6, 0x59, 0x0b, 0x08, 0xd9, 0xf3, 0xf6, 0x71, 0x44, 0x98, 0x01, 0x73, 0x23, 0xdb, 0xaf, 0xd1,
+	0xa5, 0xff, 0x77, 0x13, 0x1a, 0xb6, 0xc7, 0xdd, 0xf8, 0x05, 0x6e, 0x18, 0xc6, 0x8b, 0x24, 0xcd,
+	0x4c, 0x38, 0xe6, 0x4b, 0x30, 0xd0, 0xb1, 0x3d, 0x86, 0x91, 0xcc, 0xf4, 0xc2, 0x58, 0xad, 0x28,
+	0x3e, 0xc2, 0xae, 0x9c, 0xe4, 0x69, 0x50, 0xb0, 0xc4, 0x0e, 0x52, 0x6a, 0x35, 0xd2, 0xa9, 0x88,
+	0xaf, 0x86, 0xdf, 0xf6, 0xda, 0x58, 0x89, 0x0a, 0x57, 0x0c, 0x1c, 0xe4, 0x01, 0x17, 0xae, 0x00,
+	0xc4, 0xd7, 0x05, 0x49, 0xcc, 0x08, 0x6a, 0x3f, 0x7b, 0x74, 0xa9, 0x5f, 0x3b, 0xb2, 0x4c, 0xcc,
+	0x40, 0x2c, 0x8c, 0xe9, 0x3c, 0xb9, 0x56, 0x7a, 0x94, 0xcf, 0xb5, 0x7f, 0xa6, 0x98, 0x11, 0x4d,
+	0x09, 0x04, 0x9d, 0x32, 0x22, 0x3e, 0x80, 0x0e, 0x1b, 0xa4, 0xea, 0x2c, 0xa2, 0xca, 0xb6, 0xd8,
+	0x82, 0x3f, 0x92, 0x06, 0x2a, 0x4c, 0xf4, 0x52, 0x07, 0x7e, 0x14, 0xf1, 0x30, 0xb2, 0x26, 0x43,
+	0x03, 0xd1, 0x36, 0x3a, 0x9b, 0x8c, 0x5c, 0x66, 0xda, 0x9c, 0x19, 0x40, 0xe8, 0xc0, 0x26, 0xe7,
+	0x05, 0x6c, 0xd8, 0xbc, 0x06, 0xd8, 0x05, 0xf1, 0x4b, 0x9c, 0x48, 0xb5, 0xed, 0x0d, 0xc7, 0x75,
+	0x63, 0xb6, 0x47, 0x2a, 0xd9, 0x0d, 0x57, 0x82, 0x49, 0x58, 0xf2, 0x76, 0x8e, 0xe5, 0xe6, 0x41,
+	0x85, 0x09, 0x33, 0x12, 0x15, 0x62, 0x81, 0xdc, 0x0b, 0x71, 0xd8, 0x6c, 0x70, 0x40, 0x4e, 0x2c,
+	0xd5, 0x10, 0x0f, 0xa5, 0x71, 0x14, 0xd5, 0xa8, 0x25, 0x1b, 0x08, 0xcf, 0xa4, 0xc5, 0xc7, 0x45,
+	0x30, 0x7a, 0x19, 0x8f, 0x13, 0x7c, 0x5f, 0xf4, 0xd8, 0xc6, 0xee, 0x3c, 0x34, 0x20, 0xed, 0xac,
+	0xe2, 0xb1, 0x9a, 0x68, 0xef, 0x8e, 0xd9, 0xd9, 0x48, 0x54, 0xaa, 0xa9, 0xc2, 0x18, 0x7c, 0x7a,
+	0xeb, 0x08, 0x56, 0xad, 0x00, 0xba, 0xa0, 0xe3, 0x3c, 0x8c, 0x26, 0xa3, 0xcc, 0x9f, 0x6a, 0xef,
+	0xae, 0x51, 0x33, 0x72, 0x82, 0x00, 0xbe, 0x3d, 0xba, 0x49, 0x36, 0x53, 0xe9, 0xc8, 0x71, 0xe5,
+	0xde, 0x35, 0xae, 0x74, 0xd8, 0x60, 0x68, 0x09, 0x53, 0xee, 0xb4, 0xf7, 0xaf, 0x74, 0x5a, 0xa4,
+	0x85, 0xbd, 0x1a, 0xda, 0x7b, 0x70, 0x13, 0x2d, 0xec, 0x35, 0xb1, 0xef, 0xa4, 0xc2, 0x18, 0x1f,
+	0x00, 0x1d, 0x97, 0x81, 0x90, 0x4e, 0xf1, 0x2e, 0x7f, 0xdc, 0x2b, 0x17, 0x63, 0x88, 0x0a, 0x69,
+	0x13, 0x49, 0x6b, 0x4d, 0xd3, 0x69, 0x8c, 0x5b, 0x9f, 0x17, 0x65, 0xf6, 0xf8, 0x70, 0x1d, 0x06,
+	0x5d, 0xa1, 0x31, 0xf9, 0x93, 0x24, 0x2b, 0x4c, 0x1e, 0x1a, 0x26, 0x20, 0xe4, 0x0c, 0x70, 0xeb,
+	0x05, 0xce, 0x1c, 0x35, 0x52, 0x69, 0x4a, 0x33, 0x6c, 0xab, 0xbc, 0xf5, 0x31, 0x69, 0x06, 0xa4,
+	0x90, 0xed, 0x45, 0xb1, 0xe6, 0x8a, 0xcd, 0x7c, 0x3d, 0x1b, 0xf9, 0xd1, 0x14, 0x9f, 0xa2, 0xd9,
+	0x2c, 0xf6, 0x1e, 0x71, 0x2a, 0xba, 0x84, 0xee, 0x3a, 0x90, 0x2a, 0x36, 0x09, 0x71, 0x78, 0x67,
+	0xde, 0x63, 0x54, 0x77, 0xa4, 0x95, 0xb6, 0xbe, 0x83, 0xee, 0xa5, 0x0b, 0x72, 0x9b, 0x07, 0x08,
+	0x7d, 0x7c, 0x29, 0x8d, 0xb7, 0x7a, 0xbd, 0x9c, 0x42, 0x9d, 0x6a, 0x2a, 0x1e, 0x41, 0x8b, 0x9e,
+	0xd2, 0xa3, 0x52, 0x5b, 0xa4, 0x4b, 0x9a, 0x70, 0x4f, 0x59, 0x85, 0x5d, 0x2d, 0x87, 0xfd, 0xdf,
+	0xbd, 0xa6, 0x7f, 0x01, 0xb0, 0xaa, 0xd2, 0xd5, 0xa6, 0x55, 0xb9, 0xd6, 0xb4, 0x2e, 0xed, 0x5e,
+	0xbd, 0xb2, 0x3b, 0xb6, 0xc1, 0x28, 0x9c, 0x2b, 0x6e, 0xbd, 0x6b, 0x92, 0xd7, 0x66, 0xaa, 0x46,
+	0x79, 0x6c, 0x5a, 0xee, 0x9a, 0xb4, 0x12, 0x1e, 0x67, 0xf3, 0xca, 0x0b, 0xd1, 0xcd, 0xcb, 0xca,
+	0x6a, 0x5e, 0x16, 0xaf, 0xb4, 0x2a, 0x07, 0x6d, 0x5f, 0x69, 0x5b, 0x57, 0xde, 0xfd, 0xad, 0xd5,
+	0x5b, 0xab, 0x9f, 0x00, 0xac, 0x2a, 0xff, 0xff, 0xb9, 0x72, 0xd1, 0x56, 0x6f, 0x8c, 0xb6, 0x56,
+	0x8e, 0x96, 0x9f, 0x70, 0x4a, 0x6b, 0xba, 0x39, 0x66, 0x72, 0x38, 0xf1, 0xe9, 0x08, 0xda, 0xa5,
+	0x96, 0x83, 0x67, 0xe8, 0x9c, 0xbe, 0xda, 0x3b, 0xdc, 0x1d, 0x0e, 0x0f, 0x5e, 0x1e, 0x0c, 0xf6,
+	0x7b, 0xef, 0x08, 0x80, 0xf5, 0xe1, 0xc9, 0xfe, 0xe1, 0xc1, 0xf7, 0xbd, 0x8a, 0xd8, 0x84, 0xf6,
+	0x70, 0xf7, 0x68, 0x30, 0x3a, 0x7a, 0xbd, 0x7f, 0x7a, 0x38, 0xe8, 0x55, 0xc5, 0x5d, 0xd8, 0x64,
+	0x40, 0x0e, 0x8e, 0x5f, 0x0f, 0x0f, 0x4e, 0x5e, 0xcb, 0x37, 0xbd, 0x9a, 0xe8, 0x40, 0x73, 0xf0,
+	0xcb, 0xc9, 0x40, 0xbe, 0xda, 0x3d, 0xec, 0xd5, 0xc7, 0xeb, 0xfc, 0x7f, 0xeb, 0xf9, 0xbf, 0x04,
+	0xaf, 0xd1, 0xae, 0x7d, 0x0d, 0x00, 0x00,
 }
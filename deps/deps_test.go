@@ -0,0 +1,121 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"errors"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsStdlib(t *testing.T) {
+	tests := []struct {
+		ipath string
+		want  bool
+	}{
+		{"fmt", true},
+		{"net/http", true},
+		{"internal/poll", true},
+		{"C", false},
+		{"github.com/foo/bar", false},
+		{"golang.org/x/text", false},
+		{"example.com", false},
+
+		// Packages of other languages have no dot in their first element,
+		// but are never part of the Go standard library.
+		{"npm:react", false},
+		{"npm:@scope/x", false},
+		{"pypi:requests", false},
+		{"crates:serde", false},
+		{"maven:org.example:lib", false},
+	}
+	for _, test := range tests {
+		if got := IsStdlib(test.ipath); got != test.want {
+			t.Errorf("IsStdlib(%q): got %v, want %v", test.ipath, got, test.want)
+		}
+	}
+}
+
+func TestPathLanguage(t *testing.T) {
+	tests := []struct {
+		ipath, want string
+	}{
+		{"fmt", "go"},
+		{"github.com/foo/bar", "go"},
+		{"npm:react", "npm"},
+		{"npm:@scope/x", "npm"},
+		{"pypi:requests", "python"},
+	}
+	for _, test := range tests {
+		if got := PathLanguage(test.ipath); got != test.want {
+			t.Errorf("PathLanguage(%q): got %q, want %q", test.ipath, got, test.want)
+		}
+	}
+}
+
+func TestImportDirHashError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "importdir")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for name, text := range map[string]string{
+		"a.go": "package a\n\nimport \"example.com/b\"\n",
+		"b.s":  "// assembly\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	// Let the build context read b.s when it imports the package, but fail
+	// when its contents are read again to compute the digest.
+	bc := build.Default
+	opens := 0
+	bc.OpenFile = func(path string) (io.ReadCloser, error) {
+		if filepath.Base(path) == "b.s" {
+			opens++
+			if opens > 1 {
+				return nil, errors.New("disk on fire")
+			}
+		}
+		return os.Open(path)
+	}
+	pkg, err := ImportDir(&bc, dir, dir, &Options{HashSourceFiles: true})
+	if err != nil {
+		t.Fatalf("ImportDir: unexpected error: %v", err)
+	}
+	if len(pkg.Imports) != 1 || pkg.Imports[0] != "example.com/b" {
+		t.Errorf("Imports: got %q, want [example.com/b]", pkg.Imports)
+	}
+	if len(pkg.Sources) != 1 || len(pkg.Sources[0].Digest) == 0 {
+		t.Errorf("Sources: got %+v, want a.go with a digest", pkg.Sources)
+	}
+	if len(pkg.OtherSources) != 1 || pkg.OtherSources[0].Digest != nil {
+		t.Errorf("OtherSources: got %+v, want b.s without a digest", pkg.OtherSources)
+	}
+	if len(pkg.ParseErrors) != 1 || pkg.ParseErrors[0].RepoPath != "b.s" ||
+		!strings.Contains(pkg.ParseErrors[0].Message, "disk on fire") {
+		t.Errorf("ParseErrors: got %+v, want an error for b.s", pkg.ParseErrors)
+	}
+	if pkg.Digest != nil {
+		t.Errorf("Digest: got %x, want none", pkg.Digest)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bufio"
+	"go/build"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// scanDirectives reads the named Go source files in dir and records the
+// //go:embed patterns and //go:generate commands they contain in rec.
+// Directives are recognized only at the start of a line, as the go command
+// requires.
+func scanDirectives(bc *build.Context, dir string, names []string, rec *Package) error {
+	for _, name := range names {
+		rc, err := openFile(bc, filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		sc := bufio.NewScanner(rc)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			line := sc.Text()
+			if cmd := directive(line, "//go:generate"); cmd != "" {
+				rec.Generates = append(rec.Generates, cmd)
+			} else if pats := directive(line, "//go:embed"); pats != "" {
+				for _, pat := range embedPatterns(pats) {
+					rec.Embeds = appendNew(rec.Embeds, pat)
+				}
+			}
+		}
+		err = sc.Err()
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// directive returns the trimmed arguments of line if it is a directive with
+// the given prefix, or "" if it is not.
+func directive(line, prefix string) string {
+	if !strings.HasPrefix(line, prefix) {
+		return ""
+	}
+	rest := line[len(prefix):]
+	if rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return "" // e.g., //go:embedded
+	}
+	return strings.TrimSpace(rest)
+}
+
+// embedPatterns splits the arguments of a //go:embed directive into patterns,
+// which are separated by spaces and may be quoted.
+func embedPatterns(args string) []string {
+	var pats []string
+	for args != "" {
+		var pat string
+		if args[0] == '"' || args[0] == '`' {
+			q := quotedPrefix(args)
+			p, err := strconv.Unquote(q)
+			if err != nil {
+				return pats // malformed; keep what we have
+			}
+			pat, args = p, args[len(q):]
+		} else if i := strings.IndexAny(args, " \t"); i >= 0 {
+			pat, args = args[:i], args[i:]
+		} else {
+			pat, args = args, ""
+		}
+		pats = append(pats, pat)
+		args = strings.TrimLeft(args, " \t")
+	}
+	return pats
+}
+
+// quotedPrefix returns the Go string literal at the beginning of s, which
+// starts with a quotation mark or backquote, through its closing quote, or
+// all of s if it is not closed. The literal is not otherwise checked.
+func quotedPrefix(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == s[0] {
+			return s[:i+1]
+		} else if s[i] == '\\' && s[0] == '"' {
+			i++ // skip the escaped character
+		}
+	}
+	return s
+}
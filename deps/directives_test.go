@@ -0,0 +1,52 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanDirectives(t *testing.T) {
+	bc := memContext(map[string]string{
+		"/d/a.go": `package a
+
+//go:generate stringer -type=Kind
+//go:embed static/*.html "with space.txt" ` + "`raw\\name`" + `
+//go:embed static/*.html
+//go:embedded not a directive
+	//go:generate indented, so not a directive
+var x string
+`,
+		"/d/b.go": `package a
+
+//go:generate   go run gen.go  
+//go:embed "unclosed
+//go:embed "a\"b" "c" bad"quote
+`,
+	})
+	var rec Package
+	if err := scanDirectives(bc, "/d", []string{"a.go", "b.go"}, &rec); err != nil {
+		t.Fatalf("scanDirectives: unexpected error: %v", err)
+	}
+	wantGen := []string{"stringer -type=Kind", "go run gen.go"}
+	wantEmbed := []string{"static/*.html", "with space.txt", `raw\name`, `a"b`, "c", `bad"quote`}
+	if !reflect.DeepEqual(rec.Generates, wantGen) {
+		t.Errorf("Generates: got %q, want %q", rec.Generates, wantGen)
+	}
+	if !reflect.DeepEqual(rec.Embeds, wantEmbed) {
+		t.Errorf("Embeds: got %q, want %q", rec.Embeds, wantEmbed)
+	}
+}
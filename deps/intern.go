@@ -0,0 +1,65 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import "sync"
+
+// An Interner maps equal strings to a single shared copy. Across a corpus the
+// same import paths occur many times over, and without interning each
+// occurrence holds its own copy of the path for as long as its package
+// record is retained. The zero value is ready for use, and an Interner is
+// safe for concurrent use. Strings once interned are retained for the life
+// of the Interner.
+type Interner struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+// Intern returns a string equal to s, which is the same string returned by
+// every call to Intern on in with an equal argument.
+func (in *Interner) Intern(s string) string {
+	in.mu.RLock()
+	t, ok := in.m[s]
+	in.mu.RUnlock()
+	if ok {
+		return t
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if t, ok := in.m[s]; ok {
+		return t
+	}
+	if in.m == nil {
+		in.m = make(map[string]string)
+	}
+	in.m[s] = s
+	return s
+}
+
+// Len returns the number of distinct strings held by in.
+func (in *Interner) Len() int {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return len(in.m)
+}
+
+// intern returns s interned by the Interner of o, or s itself if o does not
+// have one.
+func (o *Options) intern(s string) string {
+	if o == nil || o.Interner == nil {
+		return s
+	}
+	return o.Interner.Intern(s)
+}
@@ -0,0 +1,188 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"encoding/xml"
+	"go/build"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jvmAnalyzer implements the Analyzer interface for JVM projects built with
+// Maven (pom.xml) or Gradle (build.gradle or build.gradle.kts). Artifacts are
+// named "maven:<group>:<artifact>", and dependencies in test scopes or
+// configurations are not recorded. A Gradle project that does not declare
+// its group is unnamed, and the loader names it after its location.
+type jvmAnalyzer struct{}
+
+func (jvmAnalyzer) Name() string { return "jvm" }
+
+// SkipDir reports whether the directory name should not be scanned.
+func (jvmAnalyzer) SkipDir(name string) bool { return name == ".gradle" || name == ".mvn" }
+
+func (jvmAnalyzer) Analyze(bc *build.Context, root, dir string, opts *Options) ([]*Package, error) {
+	if data, err := readFile(bc, filepath.Join(dir, "pom.xml")); err == nil {
+		rec, err := parsePOM(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*Package{rec}, nil
+	}
+	for _, name := range []string{"build.gradle", "build.gradle.kts"} {
+		if data, err := readFile(bc, filepath.Join(dir, name)); err == nil {
+			return []*Package{parseGradle(data, filepath.Base(dir))}, nil
+		}
+	}
+	return nil, nil
+}
+
+type pomProject struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+		Scope      string `xml:"scope"`
+	} `xml:"dependencies>dependency"`
+}
+
+var pomProperty = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// parsePOM returns a package describing the artifact defined by a Maven POM.
+// Property references are expanded using the properties of the POM itself;
+// the group and version may be inherited from the parent.
+func parsePOM(data []byte) (*Package, error) {
+	var pom pomProject
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+	if pom.GroupID == "" {
+		pom.GroupID = pom.Parent.GroupID
+	}
+	if pom.Version == "" {
+		pom.Version = pom.Parent.Version
+	}
+	props := map[string]string{
+		"project.groupId":    pom.GroupID,
+		"project.artifactId": pom.ArtifactID,
+		"project.version":    pom.Version,
+	}
+	for _, e := range pom.Properties.Entries {
+		props[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+	expand := func(s string) string {
+		return pomProperty.ReplaceAllStringFunc(strings.TrimSpace(s), func(ref string) string {
+			if v, ok := props[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+
+	rec := &Package{
+		Name:       expand(pom.ArtifactID),
+		ImportPath: mavenName(expand(pom.GroupID), expand(pom.ArtifactID)),
+		Language:   "jvm",
+	}
+	if v := expand(pom.Version); v != "" {
+		rec.Versions = map[string]string{rec.ImportPath: v}
+	}
+	seen := make(map[string]bool)
+	for _, dep := range pom.Dependencies {
+		if dep.Scope == "test" {
+			continue
+		}
+		ip := mavenName(expand(dep.GroupID), expand(dep.ArtifactID))
+		if !seen[ip] {
+			seen[ip] = true
+			rec.Imports = append(rec.Imports, ip)
+		}
+		if v := expand(dep.Version); v != "" && !strings.Contains(v, "${") {
+			if rec.Versions == nil {
+				rec.Versions = make(map[string]string)
+			}
+			rec.Versions[ip] = v
+		}
+	}
+	sort.Strings(rec.Imports)
+	return rec, nil
+}
+
+var (
+	gradleGroup = regexp.MustCompile(`(?m)^\s*group\s*=?\s*["']([^"']+)["']`)
+	gradleDep   = regexp.MustCompile(`(?m)^\s*(\w+)\s*\(?\s*["']([^"':\s]+):([^"':\s]+)(?::([^"':\s@]+))?[^"']*["']`)
+)
+
+// parseGradle returns a package describing the project defined by a Gradle
+// build script, whose directory has the given name. As is the default for
+// Gradle, the artifact is assumed to be named after the directory. Only
+// dependencies written as "group:artifact:version" strings are recognized.
+func parseGradle(data []byte, dirName string) *Package {
+	rec := &Package{Name: dirName, Language: "jvm"}
+	if m := gradleGroup.FindSubmatch(data); m != nil {
+		rec.ImportPath = mavenName(string(m[1]), dirName)
+	}
+	seen := make(map[string]bool)
+	for _, m := range gradleDep.FindAllSubmatch(data, -1) {
+		conf := string(m[1])
+		if !isGradleConfig(conf) {
+			continue
+		}
+		ip := mavenName(string(m[2]), string(m[3]))
+		if !seen[ip] {
+			seen[ip] = true
+			rec.Imports = append(rec.Imports, ip)
+		}
+		if v := string(m[4]); v != "" && !strings.Contains(v, "$") {
+			if rec.Versions == nil {
+				rec.Versions = make(map[string]string)
+			}
+			rec.Versions[ip] = v
+		}
+	}
+	sort.Strings(rec.Imports)
+	return rec
+}
+
+// isGradleConfig reports whether conf names a Gradle dependency configuration
+// other than a test configuration.
+func isGradleConfig(conf string) bool {
+	if strings.HasPrefix(conf, "test") || strings.Contains(conf, "Test") {
+		return false
+	}
+	switch conf {
+	case "implementation", "api", "compile", "compileOnly", "runtime", "runtimeOnly",
+		"annotationProcessor", "kapt", "ksp", "classpath":
+		return true
+	}
+	return false
+}
+
+func mavenName(group, artifact string) string { return "maven:" + group + ":" + artifact }
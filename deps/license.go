@@ -0,0 +1,67 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import "strings"
+
+// LicensePaths are the repository-relative locations where a license file is
+// recognized, in order of precedence.
+var LicensePaths = []string{
+	"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "COPYING", "COPYING.md",
+}
+
+// licenseRules identify licenses by phrases of their text, in order. A rule
+// matches if the normalized text contains all of its phrases. Rules for
+// licenses whose text mentions another license come before the rule for the
+// other license.
+var licenseRules = []struct {
+	id      string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"gnu affero general public license"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software"}},
+	{"Unlicense", []string{"free and unencumbered software released into the public domain"}},
+	{"CC0-1.0", []string{"cc0"}},
+}
+
+// DetectLicense returns the SPDX identifier of the license whose text is
+// data, such as "MIT" or "Apache-2.0", or "unknown" if it is not one of the
+// common licenses it recognizes. The match is by characteristic phrases, so a
+// modified license text may be reported as the license it was derived from.
+func DetectLicense(data []byte) string {
+	text := strings.ToLower(strings.Join(strings.Fields(string(data)), " "))
+	for _, rule := range licenseRules {
+		ok := true
+		for _, p := range rule.phrases {
+			if !strings.Contains(text, p) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return rule.id
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+// +build ignore
+
+// Program mkstdlib generates the built-in catalog of standard library
+// packages from the installed Go toolchain. The packages are those listed by
+// "go list std", other than internal and vendored packages, and the version
+// that introduced each is the first whose API file in $GOROOT/api mentions it.
+//
+// Usage: go run mkstdlib.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var apiFile = regexp.MustCompile(`^go1(?:\.(\d+))?\.txt$`)
+
+// noAPI gives the minor versions that introduced packages that export no API,
+// and so do not appear in the API files.
+var noAPI = map[string]int{
+	"runtime/race": 1,
+	"time/tzdata":  15,
+	"unsafe":       0,
+}
+
+func main() {
+	out, err := exec.Command("go", "list", "std").Output()
+	if err != nil {
+		log.Fatalf("Listing std: %v", err)
+	}
+	var pkgs []string
+	for _, ip := range strings.Fields(string(out)) {
+		if strings.Contains("/"+ip+"/", "/internal/") || strings.HasPrefix(ip, "vendor/") {
+			continue
+		}
+		pkgs = append(pkgs, ip)
+	}
+
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		log.Fatalf("Finding GOROOT: %v", err)
+	}
+	apiDir := filepath.Join(strings.TrimSpace(string(goroot)), "api")
+	fis, err := ioutil.ReadDir(apiDir)
+	if err != nil {
+		log.Fatalf("Reading API files: %v", err)
+	}
+	added := make(map[string]int) // :: import path → minor version
+	for ip, v := range noAPI {
+		added[ip] = v
+	}
+	for _, fi := range fis {
+		m := apiFile.FindStringSubmatch(fi.Name())
+		if m == nil {
+			continue
+		}
+		minor, _ := strconv.Atoi(m[1]) // go1.txt is 1.0
+		f, err := os.Open(filepath.Join(apiDir, fi.Name()))
+		if err != nil {
+			log.Fatalf("Reading API file: %v", err)
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			line := strings.TrimPrefix(s.Text(), "pkg ")
+			if len(line) == len(s.Text()) {
+				continue
+			}
+			ip := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' })[0]
+			if v, ok := added[ip]; !ok || minor < v {
+				added[ip] = minor
+			}
+		}
+		f.Close()
+	}
+
+	sort.Strings(pkgs)
+	var buf bytes.Buffer
+	fmt.Fprint(&buf, `// Code generated by mkstdlib.go. DO NOT EDIT.
+
+package deps
+
+// stdlibPackages maps the import paths of the standard library packages to
+// the Go version that introduced each, or "" if it is not known.
+var stdlibPackages = map[string]string{
+`)
+	for _, ip := range pkgs {
+		version := ""
+		if v, ok := added[ip]; ok {
+			version = fmt.Sprintf("1.%d", v)
+		}
+		fmt.Fprintf(&buf, "\t%q: %q,\n", ip, version)
+	}
+	fmt.Fprintln(&buf, "}")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Formatting output: %v", err)
+	}
+	if err := ioutil.WriteFile("stdlib_table.go", src, 0644); err != nil {
+		log.Fatalf("Writing output: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/creachadair/repodeps/modfile"
+)
+
+// A ModuleSet records the Go modules defined in a repository, and resolves the
+// import paths of packages and their dependencies relative to them.
+// The zero value is ready for use.
+type ModuleSet struct {
+	mods  []*Module                // ordered by decreasing length of dir, root last
+	files map[string]*modfile.File // :: dir → parsed module file
+}
+
+// Add parses the contents of a go.mod file found in the repository-relative
+// directory dir, and adds the resulting module to the set.
+func (m *ModuleSet) Add(dir string, data []byte) (*Module, error) {
+	f, err := modfile.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	dir = path.Clean(dir)
+	mod := &Module{
+		Path:      f.Module,
+		Dir:       dir,
+		GoVersion: f.Go,
+		Toolchain: f.Toolchain,
+	}
+	for _, req := range f.Require {
+		mod.Requires = append(mod.Requires, &Requirement{
+			Path:     req.Path,
+			Version:  req.Version.Version,
+			Indirect: req.Indirect,
+		})
+	}
+	for _, ex := range f.Exclude {
+		mod.Excludes = append(mod.Excludes, &Requirement{Path: ex.Path, Version: ex.Version})
+	}
+	for _, r := range f.Replace {
+		mod.Replaces = append(mod.Replaces, &Replacement{
+			Path:       r.Old.Path,
+			Version:    r.Old.Version,
+			NewPath:    r.New.Path,
+			NewVersion: r.New.Version,
+		})
+	}
+	if m.files == nil {
+		m.files = make(map[string]*modfile.File)
+	}
+	m.files[dir] = f
+	m.mods = append(m.mods, mod)
+	sort.Slice(m.mods, func(i, j int) bool {
+		a, b := m.mods[i].Dir, m.mods[j].Dir
+		if (a == ".") != (b == ".") {
+			return b == "." // the root encloses everything, so it goes last
+		} else if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return mod, nil
+}
+
+// Modules returns the modules in the set, ordered by directory.
+func (m *ModuleSet) Modules() []*Module {
+	out := make([]*Module, len(m.mods))
+	copy(out, m.mods)
+	sort.Slice(out, func(i, j int) bool { return out[i].Dir < out[j].Dir })
+	return out
+}
+
+// Enclosing returns the innermost module containing the repository-relative
+// directory dir, or nil if there is none. A module at the root of the
+// repository encloses dir only if no other module does.
+func (m *ModuleSet) Enclosing(dir string) *Module {
+	dir = path.Clean(dir)
+	var root *Module
+	for _, mod := range m.mods {
+		if mod.Dir == "." {
+			root = mod
+		} else if dir == mod.Dir || strings.HasPrefix(dir, mod.Dir+"/") {
+			return mod
+		}
+	}
+	return root
+}
+
+// Resolve updates rec, a package found in the repository-relative directory
+// dir, with the import path implied by its enclosing module, and rewrites its
+// imports, including its blank and dot imports, to their effective paths
+// according to the replace directives of that module. If dir is not inside
+// any module, or rec is not a Go package, rec is not modified.
+func (m *ModuleSet) Resolve(rec *Package, dir string) {
+	mod := m.Enclosing(dir)
+	if mod == nil || rec.Language != "" {
+		return
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(path.Clean(dir), mod.Dir), "/")
+	if mod.Dir == "." {
+		rel = path.Clean(dir)
+	}
+	rec.ImportPath = path.Join(mod.Path, rel)
+	rec.Module = mod.Path
+	if mod.Version != "" {
+		rec.Module += "@" + mod.Version
+	}
+
+	f := m.files[mod.Dir]
+	if len(f.Replace) == 0 {
+		return
+	}
+	resolve := func(in []string) []string {
+		if in == nil {
+			return nil
+		}
+		out := make([]string, len(in))
+		for i, ip := range in {
+			out[i] = ip
+			if eff := m.replace(f, mod.Dir, ip); eff != ip {
+				if rec.Replaced == nil {
+					rec.Replaced = make(map[string]string)
+				}
+				rec.Replaced[eff] = ip
+				out[i] = eff
+			}
+		}
+		return out
+	}
+	rec.Imports = resolve(rec.Imports)
+	rec.BlankImports = resolve(rec.BlankImports)
+	rec.DotImports = resolve(rec.DotImports)
+}
+
+// replace returns the effective import path for ip according to the replace
+// directives of f, which is defined in the repository-relative directory dir.
+func (m *ModuleSet) replace(f *modfile.File, dir, ip string) string {
+	var best *modfile.Replace
+	for _, r := range f.Replace {
+		if !within(ip, r.Old.Path) || (best != nil && len(r.Old.Path) <= len(best.Old.Path)) {
+			continue
+		} else if r.Old.Version != "" && r.Old.Version != required(f, r.Old.Path) {
+			continue // this replacement does not apply to the required version
+		}
+		best = r
+	}
+	if best == nil {
+		return ip
+	}
+	target := best.New.Path
+	if best.IsLocal() {
+		// A directory replacement refers to another module in the same
+		// repository, whose path we use if we know it.
+		tdir := path.Join(dir, target)
+		var tmod *Module
+		for _, mod := range m.mods {
+			if mod.Dir == tdir {
+				tmod = mod
+				break
+			}
+		}
+		if tmod == nil {
+			return ip // not in this repository
+		}
+		target = tmod.Path
+	}
+	return target + strings.TrimPrefix(ip, best.Old.Path)
+}
+
+// required returns the version of path required by f, or "".
+func required(f *modfile.File, path string) string {
+	for _, req := range f.Require {
+		if req.Path == path {
+			return req.Version.Version
+		}
+	}
+	return ""
+}
+
+// within reports whether import path ip is equal to or inside root.
+func within(ip, root string) bool {
+	return ip == root || strings.HasPrefix(ip, root+"/")
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	var ms ModuleSet
+	for dir, data := range map[string]string{
+		".": `module example.com/root
+require (
+	example.com/old v1.0.0
+	example.com/pinned v1.2.0
+)
+replace (
+	example.com/old => example.com/new v1.1.0
+	example.com/pinned v1.0.0 => example.com/never v1.0.0
+	example.com/sub => ./sub
+	example.com/gone => ../outside
+)
+`,
+		"sub": `module example.com/sub/v2`,
+	} {
+		if _, err := ms.Add(dir, []byte(data)); err != nil {
+			t.Fatalf("Add %q: %v", dir, err)
+		}
+	}
+
+	tests := []struct {
+		dir  string
+		in   *Package
+		want *Package
+	}{
+		{"pkg/a", &Package{
+			Imports:      []string{"example.com/old/x", "example.com/pinned", "example.com/sub/y", "example.com/gone", "fmt"},
+			BlankImports: []string{"example.com/old/x"},
+			DotImports:   []string{"example.com/sub/y"},
+		}, &Package{
+			ImportPath:   "example.com/root/pkg/a",
+			Module:       "example.com/root",
+			Imports:      []string{"example.com/new/x", "example.com/pinned", "example.com/sub/v2/y", "example.com/gone", "fmt"},
+			BlankImports: []string{"example.com/new/x"},
+			DotImports:   []string{"example.com/sub/v2/y"},
+			Replaced: map[string]string{
+				"example.com/new/x":    "example.com/old/x",
+				"example.com/sub/v2/y": "example.com/sub/y",
+			},
+		}},
+
+		// A package at the root of a module takes the module path.
+		{".", &Package{}, &Package{ImportPath: "example.com/root", Module: "example.com/root"}},
+
+		// The nested module applies to its own directory, and has no
+		// replacements of its own.
+		{"sub/z", &Package{Imports: []string{"example.com/old"}}, &Package{
+			ImportPath: "example.com/sub/v2/z",
+			Module:     "example.com/sub/v2",
+			Imports:    []string{"example.com/old"},
+		}},
+
+		// Packages in other languages are not modified.
+		{"pkg/js", &Package{ImportPath: "js", Language: "npm", Imports: []string{"example.com/old"}},
+			&Package{ImportPath: "js", Language: "npm", Imports: []string{"example.com/old"}}},
+	}
+	for _, test := range tests {
+		ms.Resolve(test.in, test.dir)
+		if !reflect.DeepEqual(test.in, test.want) {
+			t.Errorf("Resolve(%q):\n got %+v\nwant %+v", test.dir, test.in, test.want)
+		}
+	}
+}
+
+func TestEnclosing(t *testing.T) {
+	// Add the modules in each order, since the root module must not win a
+	// tie with a module whose directory has a one-character name.
+	orders := [][]string{{".", "a", "a/b", "ab"}, {"ab", "a/b", "a", "."}, {"a", ".", "ab", "a/b"}}
+	for _, order := range orders {
+		var ms ModuleSet
+		for _, dir := range order {
+			if _, err := ms.Add(dir, []byte("module example.com/"+dir)); err != nil {
+				t.Fatalf("Add %q: %v", dir, err)
+			}
+		}
+		tests := []struct {
+			dir, want string
+		}{
+			{".", "."},
+			{"x", "."},
+			{"a", "a"},
+			{"a/x", "a"},
+			{"a/b", "a/b"},
+			{"a/b/c", "a/b"},
+			{"a/bc", "a"},
+			{"ab", "ab"},
+			{"ab/x", "ab"},
+			{"abc", "."},
+			{"./a/x", "a"},
+		}
+		for _, test := range tests {
+			got := ms.Enclosing(test.dir)
+			if got == nil || got.Dir != test.want {
+				t.Errorf("Add order %q: Enclosing(%q): got %+v, want dir %q", order, test.dir, got, test.want)
+			}
+		}
+	}
+
+	var empty ModuleSet
+	if got := empty.Enclosing("a"); got != nil {
+		t.Errorf("Enclosing in an empty set: got %+v, want nil", got)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"encoding/json"
+	"go/build"
+	"path/filepath"
+	"sort"
+)
+
+// npmAnalyzer implements the Analyzer interface for npm packages, described
+// by a package.json file. Package and dependency names are prefixed with
+// "npm:" so that they do not collide with Go import paths. If a
+// package-lock.json file is present, it is used to find the versions of the
+// direct dependencies that were resolved.
+type npmAnalyzer struct{}
+
+func (npmAnalyzer) Name() string { return "npm" }
+
+// SkipDir reports whether the directory name should not be scanned.
+func (npmAnalyzer) SkipDir(name string) bool { return name == "node_modules" }
+
+type npmManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	Dependencies         map[string]string `json:"dependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+type npmLock struct {
+	// Lockfile version 1 records dependencies by name.
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+
+	// Lockfile versions 2 and 3 record packages by installed path.
+	Packages map[string]struct {
+		Version string `json:"version"`
+	} `json:"packages"`
+}
+
+func (npmAnalyzer) Analyze(bc *build.Context, root, dir string, opts *Options) ([]*Package, error) {
+	data, err := readFile(bc, filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, nil // no package here
+	}
+	var man npmManifest
+	if err := json.Unmarshal(data, &man); err != nil {
+		return nil, err
+	} else if man.Name == "" {
+		return nil, nil // an unnamed package cannot be depended upon
+	}
+	rec := &Package{
+		Name:       man.Name,
+		ImportPath: "npm:" + man.Name,
+		Language:   "npm",
+	}
+	if man.Version != "" {
+		rec.Versions = map[string]string{rec.ImportPath: man.Version}
+	}
+	names := make(map[string]bool)
+	for _, m := range []map[string]string{man.Dependencies, man.PeerDependencies, man.OptionalDependencies} {
+		for name := range m {
+			names[name] = true
+		}
+	}
+	for name := range names {
+		rec.Imports = append(rec.Imports, "npm:"+name)
+	}
+	sort.Strings(rec.Imports)
+
+	if data, err := readFile(bc, filepath.Join(dir, "package-lock.json")); err == nil {
+		var lock npmLock
+		if err := json.Unmarshal(data, &lock); err == nil {
+			for name := range names {
+				v := lock.Packages["node_modules/"+name].Version
+				if v == "" {
+					v = lock.Dependencies[name].Version
+				}
+				if v != "" {
+					if rec.Versions == nil {
+						rec.Versions = make(map[string]string)
+					}
+					rec.Versions["npm:"+name] = v
+				}
+			}
+		}
+	}
+	return []*Package{rec}, nil
+}
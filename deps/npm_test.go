@@ -0,0 +1,94 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNPMAnalyze(t *testing.T) {
+	bc := memContext(map[string]string{
+		// Lockfile version 3 records versions by installed path.
+		"/r/app/package.json": `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {"left-pad": "^1.3.0", "@scope/util": "~2.0.0"},
+  "peerDependencies": {"react": ">=16"},
+  "optionalDependencies": {"left-pad": "^1.3.0"},
+  "devDependencies": {"mocha": "^10.0.0"}
+}`,
+		"/r/app/package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0"},
+    "node_modules/left-pad": {"version": "1.3.0"},
+    "node_modules/@scope/util": {"version": "2.0.4"},
+    "node_modules/mocha": {"version": "10.2.0"}
+  }
+}`,
+
+		// Lockfile version 1 records versions by name.
+		"/r/old/package.json":      `{"name": "old", "dependencies": {"lodash": "^4.0.0"}}`,
+		"/r/old/package-lock.json": `{"lockfileVersion": 1, "dependencies": {"lodash": {"version": "4.17.21"}}}`,
+
+		"/r/unnamed/package.json": `{"private": true, "dependencies": {"lodash": "*"}}`,
+		"/r/invalid/package.json": `{"name": `,
+	})
+
+	tests := []struct {
+		dir  string
+		want []*Package
+		ok   bool
+	}{
+		{"/r/app", []*Package{{
+			Name:       "app",
+			ImportPath: "npm:app",
+			Language:   "npm",
+			Imports:    []string{"npm:@scope/util", "npm:left-pad", "npm:react"},
+			Versions: map[string]string{
+				"npm:app":         "1.0.0",
+				"npm:@scope/util": "2.0.4",
+				"npm:left-pad":    "1.3.0",
+			},
+		}}, true},
+		{"/r/old", []*Package{{
+			Name:       "old",
+			ImportPath: "npm:old",
+			Language:   "npm",
+			Imports:    []string{"npm:lodash"},
+			Versions:   map[string]string{"npm:lodash": "4.17.21"},
+		}}, true},
+		{"/r/unnamed", nil, true},
+		{"/r/none", nil, true},
+		{"/r/invalid", nil, false},
+	}
+	var a npmAnalyzer
+	for _, test := range tests {
+		got, err := a.Analyze(bc, "/r", test.dir, nil)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("Analyze %q: got %+v, want error", test.dir, got)
+			}
+		} else if err != nil {
+			t.Errorf("Analyze %q: unexpected error: %v", test.dir, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Analyze %q:\n got %+v\nwant %+v", test.dir, got, test.want)
+		}
+	}
+	if !a.SkipDir("node_modules") || a.SkipDir("src") {
+		t.Error("SkipDir: node_modules should be skipped, and only it")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"strings"
+)
+
+// CodeownersPaths are the repository-relative locations where a CODEOWNERS
+// file is recognized, in order of precedence.
+var CodeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// An OwnerSet records the ownership rules defined in a repository by a
+// CODEOWNERS file and by per-directory OWNERS files, and assigns owners to
+// directories according to them. The zero value is ready for use.
+type OwnerSet struct {
+	rules []ownerRule         // CODEOWNERS rules, in file order
+	dirs  map[string][]string // :: dir → owners from an OWNERS file
+}
+
+type ownerRule struct {
+	pattern string
+	owners  []string
+}
+
+// AddCodeowners parses the contents of a CODEOWNERS file. Each non-comment
+// line gives a path pattern followed by zero or more owners. As on GitHub, the
+// last matching pattern takes precedence.
+func (o *OwnerSet) AddCodeowners(data []byte) {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		o.rules = append(o.rules, ownerRule{pattern: fields[0], owners: fields[1:]})
+	}
+}
+
+// AddOwners parses the contents of an OWNERS file found in the
+// repository-relative directory dir. Both the plain format (one owner per
+// line) and the YAML format with "approvers" and "reviewers" lists are
+// understood. Directives such as "per-file" and "set noparent" are ignored.
+func (o *OwnerSet) AddOwners(dir string, data []byte) {
+	var owners []string
+	var section string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		} else if strings.HasSuffix(line, ":") {
+			section = strings.TrimSuffix(line, ":")
+		} else if item := strings.TrimPrefix(line, "- "); item != line {
+			if section == "approvers" || section == "reviewers" {
+				owners = appendNew(owners, strings.TrimSpace(item))
+			}
+		} else if !strings.ContainsAny(line, " =:") {
+			owners = appendNew(owners, line)
+		}
+	}
+	if len(owners) == 0 {
+		return
+	}
+	if o.dirs == nil {
+		o.dirs = make(map[string][]string)
+	}
+	o.dirs[path.Clean(dir)] = owners
+}
+
+// Owners returns the owners of the repository-relative directory dir. If a
+// CODEOWNERS rule matches dir, its owners are returned. Otherwise, the owners
+// are taken from the OWNERS file in the nearest enclosing directory, if any.
+func (o *OwnerSet) Owners(dir string) []string {
+	dir = path.Clean(dir)
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if matchOwnerPattern(o.rules[i].pattern, dir) {
+			return o.rules[i].owners
+		}
+	}
+	for cur := dir; ; cur = path.Dir(cur) {
+		if owners, ok := o.dirs[cur]; ok {
+			return owners
+		} else if cur == "." || cur == "/" {
+			return nil
+		}
+	}
+}
+
+// matchOwnerPattern reports whether the CODEOWNERS pattern matches the
+// directory dir, or a Go source file within it. As in .gitignore, a pattern
+// matches a directory if it matches the directory or any of its ancestors; a
+// pattern containing a slash is anchored at the repository root, otherwise it
+// may match at any depth.
+func matchOwnerPattern(pat, dir string) bool {
+	if !strings.Contains(strings.TrimSuffix(pat, "/"), "/") {
+		pat = strings.TrimSuffix(pat, "/")
+		if ok, _ := path.Match(pat, "x.go"); ok {
+			return true // a file pattern matching Go sources
+		}
+		for _, elt := range strings.Split(dir, "/") {
+			if ok, _ := path.Match(pat, elt); ok {
+				return true
+			}
+		}
+		return false
+	}
+	pat = strings.TrimSuffix(strings.TrimPrefix(pat, "/"), "/")
+	pat = strings.TrimSuffix(pat, "/**")
+	for cur := dir; cur != "." && cur != "/"; cur = path.Dir(cur) {
+		if ok, _ := path.Match(pat, cur); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// appendNew appends s to ss if it is not already present.
+func appendNew(ss []string, s string) []string {
+	for _, elt := range ss {
+		if elt == s {
+			return ss
+		}
+	}
+	return append(ss, s)
+}
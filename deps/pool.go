@@ -0,0 +1,47 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"hash"
+	"sync"
+)
+
+// Scratch space used while analyzing a directory is drawn from these pools,
+// so that a worker reuses the buffers of the directories it analyzed before
+// rather than allocating new ones for each.
+var (
+	bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+	sha1Pool   = sync.Pool{New: func() interface{} { return sha1.New() }}
+)
+
+// maxPooledBuffer is the largest buffer returned to bufferPool. A rare huge
+// file should not pin its buffer for the life of the process.
+const maxPooledBuffer = 1 << 20
+
+func getBuffer() *bytes.Buffer { return bufferPool.Get().(*bytes.Buffer) }
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		buf.Reset()
+		bufferPool.Put(buf)
+	}
+}
+
+func getSHA1() hash.Hash { return sha1Pool.Get().(hash.Hash) }
+
+func putSHA1(h hash.Hash) { h.Reset(); sha1Pool.Put(h) }
@@ -0,0 +1,126 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/creachadair/repodeps/modfile"
+	"github.com/creachadair/repodeps/semver"
+)
+
+// A Revision describes a commit of a repository, for deriving the versions of
+// the modules defined at that commit.
+type Revision struct {
+	Commit string    // the full commit ID
+	Time   time.Time // the commit time
+
+	Tags  []string // the names of the tags on the commit or its ancestors
+	Exact []string // the names of the tags on the commit itself
+}
+
+// ModuleVersion returns the version of mod at rev, as the go command would
+// resolve it: the highest version tagged on the commit itself, if any, or
+// otherwise a pseudo-version based on the highest version tagged on its
+// ancestors. The tags of a module in a subdirectory of the repository are
+// prefixed with its directory, as in "sub/v1.2.0". Tags whose versions do not
+// agree with the major version suffix of the module path are ignored.
+func ModuleVersion(mod *Module, rev *Revision) string {
+	if v := highestTag(mod, rev.Exact); v != "" {
+		return v
+	}
+	major := "v0"
+	if _, suffix := modfile.SplitPathVersion(mod.Path); suffix != "" {
+		major = strings.TrimLeft(suffix, "./")
+	}
+	return PseudoVersion(major, highestTag(mod, rev.Tags), rev.Time, rev.Commit)
+}
+
+// highestTag returns the highest version tagged for mod among tags, or "" if
+// none of them is a valid version of mod.
+func highestTag(mod *Module, tags []string) string {
+	prefix := ""
+	if mod.Dir != "" && mod.Dir != "." {
+		prefix = mod.Dir + "/"
+	}
+	var best string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		v := strings.TrimPrefix(tag, prefix)
+		if !semver.IsValid(v) || strings.Contains(v, "+") {
+			continue // invalid, or carries build metadata
+		} else if modfile.CheckPathMajor(mod.Path, v) != nil {
+			continue
+		} else if best == "" || semver.Compare(v, best) > 0 {
+			best = v
+		}
+	}
+	return best
+}
+
+// PseudoVersion returns the Go pseudo-version for the commit with the given ID
+// and time. The base is the highest version tagged on an ancestor of the
+// commit, or "" if there is none, in which case the pseudo-version has the
+// given major version, such as "v0" or "v2". For example:
+//
+//	vX.0.0-yyyymmddhhmmss-abcdefabcdef        (no base)
+//	vX.Y.Z-pre.0.yyyymmddhhmmss-abcdefabcdef  (base vX.Y.Z-pre)
+//	vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef  (base vX.Y.Z)
+func PseudoVersion(major, base string, t time.Time, commit string) string {
+	stamp := t.UTC().Format("20060102150405")
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	switch {
+	case base == "":
+		return major + ".0.0-" + stamp + "-" + commit
+	case semver.Prerelease(base) != "":
+		return base + ".0." + stamp + "-" + commit
+	}
+	return incPatch(base) + "-0." + stamp + "-" + commit
+}
+
+// incPatch returns the release version v with its patch number incremented.
+func incPatch(v string) string {
+	i := strings.LastIndex(v, ".")
+	n, err := strconv.Atoi(v[i+1:])
+	if i < 0 || err != nil {
+		return v
+	}
+	return v[:i+1] + strconv.Itoa(n+1)
+}
+
+// SetModuleVersions sets the version of each unversioned module of repo to its
+// version at rev, as computed by ModuleVersion, and updates the module labels
+// of the packages of repo to match.
+func SetModuleVersions(repo *Repo, rev *Revision) {
+	label := make(map[string]string) // :: old label → new label
+	for _, mod := range repo.Modules {
+		if mod.Version != "" {
+			continue
+		}
+		mod.Version = ModuleVersion(mod, rev)
+		label[mod.Path] = mod.Path + "@" + mod.Version
+	}
+	for _, pkg := range repo.Packages {
+		if v, ok := label[pkg.Module]; ok {
+			pkg.Module = v
+		}
+	}
+}
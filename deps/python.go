@@ -0,0 +1,249 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"go/build"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pythonAnalyzer implements the Analyzer interface for Python projects,
+// described by any of pyproject.toml, setup.cfg, and requirements.txt.
+// Package and dependency names are normalized as in PEP 503 and prefixed
+// with "pypi:". A directory with only a requirements file defines an unnamed
+// package, which the loader names after its location in the repository.
+type pythonAnalyzer struct{}
+
+func (pythonAnalyzer) Name() string { return "python" }
+
+// SkipDir reports whether the directory name should not be scanned.
+func (pythonAnalyzer) SkipDir(name string) bool {
+	switch name {
+	case "__pycache__", ".tox", ".venv", "venv", "site-packages":
+		return true
+	}
+	return false
+}
+
+func (pythonAnalyzer) Analyze(bc *build.Context, root, dir string, opts *Options) ([]*Package, error) {
+	var name string
+	var reqs []string
+	found := false
+	if data, err := readFile(bc, filepath.Join(dir, "pyproject.toml")); err == nil {
+		found = true
+		n, r := parsePyproject(data)
+		name = n
+		reqs = append(reqs, r...)
+	}
+	if data, err := readFile(bc, filepath.Join(dir, "setup.cfg")); err == nil {
+		n, r := parseSetupCfg(data)
+		if n != "" || len(r) != 0 {
+			found = true
+		}
+		if name == "" {
+			name = n
+		}
+		reqs = append(reqs, r...)
+	}
+	if data, err := readFile(bc, filepath.Join(dir, "requirements.txt")); err == nil {
+		found = true
+		reqs = append(reqs, parseRequirements(data)...)
+	}
+	if !found {
+		return nil, nil // no project here
+	}
+
+	rec := &Package{Name: name, Language: "python"}
+	if name != "" {
+		rec.ImportPath = "pypi:" + normalizePyName(name)
+	}
+	seen := make(map[string]bool)
+	for _, req := range reqs {
+		dep, version := splitRequirement(req)
+		if dep == "" || strings.EqualFold(dep, "python") {
+			continue
+		}
+		ip := "pypi:" + normalizePyName(dep)
+		if !seen[ip] {
+			seen[ip] = true
+			rec.Imports = append(rec.Imports, ip)
+		}
+		if version != "" {
+			if rec.Versions == nil {
+				rec.Versions = make(map[string]string)
+			}
+			rec.Versions[ip] = version
+		}
+	}
+	sort.Strings(rec.Imports)
+	return []*Package{rec}, nil
+}
+
+var (
+	pyNameSep = regexp.MustCompile(`[-_.]+`)
+	pyReqName = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:===?\s*([^\s,;]+)\s*$)?`)
+)
+
+// normalizePyName normalizes a Python distribution name as in PEP 503.
+func normalizePyName(name string) string {
+	return strings.ToLower(pyNameSep.ReplaceAllString(name, "-"))
+}
+
+// splitRequirement returns the distribution name of a PEP 508 requirement
+// and, if the requirement pins an exact version, that version.
+func splitRequirement(req string) (name, version string) {
+	if i := strings.Index(req, ";"); i >= 0 {
+		req = req[:i] // discard environment markers
+	}
+	m := pyReqName.FindStringSubmatch(strings.TrimSpace(req))
+	if m == nil {
+		return "", ""
+	}
+	return m[1], m[2]
+}
+
+// parseRequirements returns the requirements listed in a pip requirements
+// file. Options, includes of other files, and editable or URL requirements
+// are ignored.
+func parseRequirements(data []byte) []string {
+	var reqs []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		reqs = append(reqs, line)
+	}
+	return reqs
+}
+
+// parseSetupCfg returns the name and install_requires entries of a setup.cfg
+// file.
+func parseSetupCfg(data []byte) (name string, reqs []string) {
+	var section, key string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		} else if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section, key = strings.Trim(trimmed, "[]"), ""
+			continue
+		} else if line[0] == ' ' || line[0] == '\t' {
+			// A continuation of the previous value.
+			if section == "options" && key == "install_requires" {
+				reqs = append(reqs, trimmed)
+			}
+			continue
+		}
+		parts := strings.SplitN(trimmed, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key = strings.TrimSpace(parts[0])
+		val := strings.TrimSpace(parts[1])
+		switch {
+		case section == "metadata" && key == "name":
+			name = val
+		case section == "options" && key == "install_requires" && val != "":
+			reqs = append(reqs, val)
+		}
+	}
+	return name, reqs
+}
+
+// parsePyproject returns the project name and dependencies of a
+// pyproject.toml file, from either the standard [project] table or the
+// [tool.poetry] tables. This is not a general TOML parser: it understands
+// only the string values and arrays of strings used by these tables.
+func parsePyproject(data []byte) (name string, reqs []string) {
+	var table string
+	var inDeps bool // inside a multi-line dependencies array
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if inDeps {
+			if strings.HasPrefix(line, "]") {
+				inDeps = false
+				continue
+			}
+			reqs = append(reqs, tomlStrings(line)...)
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+		key, val, ok := tomlKeyValue(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case (table == "project" || table == "tool.poetry") && key == "name":
+			if s := tomlStrings(val); len(s) == 1 {
+				name = s[0]
+			}
+		case table == "project" && key == "dependencies":
+			reqs = append(reqs, tomlStrings(val)...)
+			inDeps = strings.HasPrefix(val, "[") && !strings.Contains(val, "]")
+		case table == "tool.poetry.dependencies":
+			reqs = append(reqs, key)
+		}
+	}
+	return name, reqs
+}
+
+// tomlStrings returns the quoted strings appearing in s, in order.
+func tomlStrings(s string) []string {
+	var out []string
+	for {
+		i := strings.IndexAny(s, `"'`)
+		if i < 0 {
+			return out
+		}
+		q := s[i]
+		j := strings.IndexByte(s[i+1:], q)
+		if j < 0 {
+			return out
+		}
+		lit := s[i : i+j+2]
+		if q == '"' {
+			if u, err := strconv.Unquote(lit); err == nil {
+				lit = u
+			} else {
+				lit = lit[1 : len(lit)-1]
+			}
+		} else {
+			lit = lit[1 : len(lit)-1]
+		}
+		out = append(out, lit)
+		s = s[i+j+2:]
+	}
+}
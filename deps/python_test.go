@@ -0,0 +1,145 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPythonAnalyze(t *testing.T) {
+	bc := memContext(map[string]string{
+		"/r/proj/pyproject.toml": `
+[build-system]
+requires = ["setuptools"]
+
+[project]
+name = "My_Project"
+dependencies = [
+    "requests>=2.0",
+    "Django==3.2.1",  # pinned
+    'numpy[extra] == 1.24 ; python_version >= "3.8"',
+]
+`,
+		"/r/proj/requirements.txt": `
+# Development requirements.
+-r base.txt
+pytest==7.0 # test runner
+git+https://example.com/dep.git
+requests
+`,
+		"/r/poetry/pyproject.toml": `
+[tool.poetry]
+name = "poetic"
+
+[tool.poetry.dependencies]
+python = "^3.8"
+Flask = "^2.0"
+`,
+		"/r/cfg/setup.cfg": `
+[metadata]
+name = cfg.tool
+
+[options]
+install_requires =
+    attrs==21.4.0
+    six
+`,
+		"/r/reqs/requirements.txt": "PyYAML==6.0\n",
+		"/r/other/setup.cfg":       "[flake8]\nmax-line-length = 100\n",
+	})
+
+	tests := []struct {
+		dir  string
+		want []*Package
+	}{
+		{"/r/proj", []*Package{{
+			Name:       "My_Project",
+			ImportPath: "pypi:my-project",
+			Language:   "python",
+			Imports:    []string{"pypi:django", "pypi:numpy", "pypi:pytest", "pypi:requests"},
+			Versions: map[string]string{
+				"pypi:django": "3.2.1",
+				"pypi:numpy":  "1.24",
+				"pypi:pytest": "7.0",
+			},
+		}}},
+		{"/r/poetry", []*Package{{
+			Name:       "poetic",
+			ImportPath: "pypi:poetic",
+			Language:   "python",
+			Imports:    []string{"pypi:flask"},
+		}}},
+		{"/r/cfg", []*Package{{
+			Name:       "cfg.tool",
+			ImportPath: "pypi:cfg-tool",
+			Language:   "python",
+			Imports:    []string{"pypi:attrs", "pypi:six"},
+			Versions:   map[string]string{"pypi:attrs": "21.4.0"},
+		}}},
+		{"/r/reqs", []*Package{{
+			Language: "python",
+			Imports:  []string{"pypi:pyyaml"},
+			Versions: map[string]string{"pypi:pyyaml": "6.0"},
+		}}},
+		{"/r/other", nil},
+		{"/r/none", nil},
+	}
+	var a pythonAnalyzer
+	for _, test := range tests {
+		got, err := a.Analyze(bc, "/r", test.dir, nil)
+		if err != nil {
+			t.Errorf("Analyze %q: unexpected error: %v", test.dir, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Analyze %q:\n got %+v\nwant %+v", test.dir, got, test.want)
+		}
+	}
+}
+
+func TestSplitRequirement(t *testing.T) {
+	tests := []struct {
+		req, name, version string
+	}{
+		{"requests", "requests", ""},
+		{"requests>=2.0,<3", "requests", ""},
+		{"Django==3.2", "Django", "3.2"},
+		{"pkg===1.0", "pkg", "1.0"},
+		{"numpy[a,b] == 1.24 ; sys_platform == 'linux'", "numpy", "1.24"},
+		{"zope.interface~=5.0", "zope.interface", ""},
+		{"", "", ""},
+		{"-e .", "", ""},
+	}
+	for _, test := range tests {
+		name, version := splitRequirement(test.req)
+		if name != test.name || version != test.version {
+			t.Errorf("splitRequirement(%q): got (%q, %q), want (%q, %q)",
+				test.req, name, version, test.name, test.version)
+		}
+	}
+}
+
+func TestNormalizePyName(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"requests", "requests"},
+		{"Django", "django"},
+		{"zope.interface", "zope-interface"},
+		{"My__Odd-._Name", "my-odd-name"},
+	}
+	for _, test := range tests {
+		if got := normalizePyName(test.input); got != test.want {
+			t.Errorf("normalizePyName(%q): got %q, want %q", test.input, got, test.want)
+		}
+	}
+}
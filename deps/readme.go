@@ -0,0 +1,73 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// ReadmePaths are the repository-relative locations where a README file is
+// recognized, in order of precedence.
+var ReadmePaths = []string{
+	"README.md", "README", "README.markdown", "README.rst", "README.txt", "readme.md",
+}
+
+// ReadmeTitle returns the title of a README file with the given contents, or
+// "" if it has none. The title is the text of the first Markdown heading or
+// reStructuredText section title, if the file begins with one, or otherwise
+// its first line of text. Leading badges, images, and HTML are skipped.
+func ReadmeTitle(data []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	var prev string // the previous line of text, if not yet used
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "":
+			if prev != "" {
+				return prev
+			}
+		case isUnderline(line):
+			if prev != "" {
+				return prev // setext or reStructuredText title
+			}
+		case strings.HasPrefix(line, "#"):
+			if prev != "" {
+				return prev
+			}
+			if title := strings.Trim(strings.TrimLeft(line, "#"), "# \t"); title != "" {
+				return title
+			}
+		case strings.HasPrefix(line, "<"), strings.HasPrefix(line, "!["), strings.HasPrefix(line, "[!["):
+			// HTML, images, and badges are not titles.
+		default:
+			if prev != "" {
+				return prev
+			}
+			prev = line
+		}
+	}
+	return prev
+}
+
+// isUnderline reports whether line is a row of a single punctuation character
+// that marks a title, such as "=====" or "-----".
+func isUnderline(line string) bool {
+	if len(line) < 2 || !strings.ContainsRune("=-~*^+#", rune(line[0])) {
+		return false
+	}
+	return strings.Trim(line, line[:1]) == ""
+}
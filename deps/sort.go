@@ -0,0 +1,115 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import "sort"
+
+// SortRepo puts the contents of repo into a deterministic order: packages by
+// import path, modules by directory, conflicts by directory, and the imports,
+// sources, requirements, and other lists within them by name, or by position
+// for import sites and parse errors. Parallel lists, such as the imports of a
+// package and their classifications, are kept in correspondence.
+func SortRepo(repo *Repo) {
+	sort.Slice(repo.Remotes, func(i, j int) bool { return repo.Remotes[i].Name < repo.Remotes[j].Name })
+	sort.Slice(repo.Submodules, func(i, j int) bool { return repo.Submodules[i].Path < repo.Submodules[j].Path })
+	sort.Strings(repo.Nested)
+	sort.Slice(repo.Modules, func(i, j int) bool { return repo.Modules[i].Dir < repo.Modules[j].Dir })
+	for _, mod := range repo.Modules {
+		sortRequirements(mod.Requires)
+		sortRequirements(mod.Excludes)
+		sort.Slice(mod.Replaces, func(i, j int) bool {
+			a, b := mod.Replaces[i], mod.Replaces[j]
+			return a.Path < b.Path || (a.Path == b.Path && a.Version < b.Version)
+		})
+	}
+	sort.Slice(repo.Conflicts, func(i, j int) bool { return repo.Conflicts[i].Dir < repo.Conflicts[j].Dir })
+	for _, c := range repo.Conflicts {
+		sort.Sort(conflictFiles{c})
+	}
+	sort.Slice(repo.Packages, func(i, j int) bool { return repo.Packages[i].ImportPath < repo.Packages[j].ImportPath })
+	for _, pkg := range repo.Packages {
+		sortImports(pkg)
+		sort.Strings(pkg.StdImports)
+		sort.Strings(pkg.Owners)
+		sort.Strings(pkg.Embeds)
+		sort.Strings(pkg.Generates)
+		sort.Strings(pkg.BuildTags)
+		sort.Strings(pkg.BlankImports)
+		sort.Strings(pkg.DotImports)
+		sort.Slice(pkg.Sources, func(i, j int) bool { return pkg.Sources[i].RepoPath < pkg.Sources[j].RepoPath })
+		sort.Slice(pkg.OtherSources, func(i, j int) bool { return pkg.OtherSources[i].RepoPath < pkg.OtherSources[j].RepoPath })
+		sort.Slice(pkg.ImportSites, func(i, j int) bool {
+			a, b := pkg.ImportSites[i], pkg.ImportSites[j]
+			return positionLess(a.RepoPath, a.Line, a.Column, b.RepoPath, b.Line, b.Column)
+		})
+		sort.Slice(pkg.ParseErrors, func(i, j int) bool {
+			a, b := pkg.ParseErrors[i], pkg.ParseErrors[j]
+			return positionLess(a.RepoPath, a.Line, a.Column, b.RepoPath, b.Line, b.Column)
+		})
+	}
+}
+
+// positionLess reports whether the position at line1:col1 of file1 precedes
+// the position at line2:col2 of file2.
+func positionLess(file1 string, line1, col1 int32, file2 string, line2, col2 int32) bool {
+	if file1 != file2 {
+		return file1 < file2
+	} else if line1 != line2 {
+		return line1 < line2
+	}
+	return col1 < col2
+}
+
+func sortRequirements(reqs []*Requirement) {
+	sort.Slice(reqs, func(i, j int) bool {
+		a, b := reqs[i], reqs[j]
+		return a.Path < b.Path || (a.Path == b.Path && a.Version < b.Version)
+	})
+}
+
+// sortImports sorts the imports of pkg along with their classifications and
+// usage counts, if present.
+func sortImports(pkg *Package) {
+	sort.Sort(importsByPath{pkg})
+}
+
+type importsByPath struct{ *Package }
+
+func (p importsByPath) Len() int           { return len(p.Imports) }
+func (p importsByPath) Less(i, j int) bool { return p.Imports[i] < p.Imports[j] }
+func (p importsByPath) Swap(i, j int) {
+	p.Imports[i], p.Imports[j] = p.Imports[j], p.Imports[i]
+	if len(p.ImportClasses) == len(p.Imports) {
+		p.ImportClasses[i], p.ImportClasses[j] = p.ImportClasses[j], p.ImportClasses[i]
+	}
+	if len(p.ImportRefs) == len(p.Imports) {
+		p.ImportRefs[i], p.ImportRefs[j] = p.ImportRefs[j], p.ImportRefs[i]
+	}
+	if len(p.ImportSymbols) == len(p.Imports) {
+		p.ImportSymbols[i], p.ImportSymbols[j] = p.ImportSymbols[j], p.ImportSymbols[i]
+	}
+}
+
+// conflictFiles sorts the files of a conflict along with their package names.
+type conflictFiles struct{ *PackageConflict }
+
+func (c conflictFiles) Len() int           { return len(c.Files) }
+func (c conflictFiles) Less(i, j int) bool { return c.Files[i] < c.Files[j] }
+func (c conflictFiles) Swap(i, j int) {
+	c.Files[i], c.Files[j] = c.Files[j], c.Files[i]
+	if len(c.Packages) == len(c.Files) {
+		c.Packages[i], c.Packages[j] = c.Packages[j], c.Packages[i]
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func TestSortRepo(t *testing.T) {
+	repo := &Repo{
+		Nested: []string{"z", "a"},
+		Conflicts: []*PackageConflict{
+			{Dir: "q", Files: []string{"q/b.go", "q/a.go"}, Packages: []string{"b", "a"}},
+			{Dir: "p"},
+		},
+		Packages: []*Package{{
+			ImportPath:    "r/b",
+			Imports:       []string{"y", "x"},
+			ImportClasses: []ImportClass{ImportClass_EXTERNAL, ImportClass_STDLIB},
+			ImportRefs:    []int64{2, 1},
+			Generates:     []string{"stringer -type=T", "go run gen.go"},
+			BuildTags:     []string{"linux", "cgo"},
+			BlankImports:  []string{"y", "x"},
+			DotImports:    []string{"y", "x"},
+			ImportSites: []*ImportSite{
+				{ImportPath: "y", RepoPath: "b/b.go", Line: 4, Column: 2},
+				{ImportPath: "x", RepoPath: "b/b.go", Line: 3, Column: 9},
+				{ImportPath: "x", RepoPath: "b/b.go", Line: 3, Column: 2},
+				{ImportPath: "x", RepoPath: "b/a.go", Line: 5, Column: 2},
+			},
+			ParseErrors: []*ParseError{
+				{RepoPath: "b/c.go", Line: 1},
+				{RepoPath: "b/a.go", Line: 7, Column: 3},
+				{RepoPath: "b/a.go", Line: 2},
+			},
+		}, {
+			ImportPath: "r/a",
+		}},
+	}
+	want := &Repo{
+		Nested: []string{"a", "z"},
+		Conflicts: []*PackageConflict{
+			{Dir: "p"},
+			{Dir: "q", Files: []string{"q/a.go", "q/b.go"}, Packages: []string{"a", "b"}},
+		},
+		Packages: []*Package{{
+			ImportPath: "r/a",
+		}, {
+			ImportPath:    "r/b",
+			Imports:       []string{"x", "y"},
+			ImportClasses: []ImportClass{ImportClass_STDLIB, ImportClass_EXTERNAL},
+			ImportRefs:    []int64{1, 2},
+			Generates:     []string{"go run gen.go", "stringer -type=T"},
+			BuildTags:     []string{"cgo", "linux"},
+			BlankImports:  []string{"x", "y"},
+			DotImports:    []string{"x", "y"},
+			ImportSites: []*ImportSite{
+				{ImportPath: "x", RepoPath: "b/a.go", Line: 5, Column: 2},
+				{ImportPath: "x", RepoPath: "b/b.go", Line: 3, Column: 2},
+				{ImportPath: "x", RepoPath: "b/b.go", Line: 3, Column: 9},
+				{ImportPath: "y", RepoPath: "b/b.go", Line: 4, Column: 2},
+			},
+			ParseErrors: []*ParseError{
+				{RepoPath: "b/a.go", Line: 2},
+				{RepoPath: "b/a.go", Line: 7, Column: 3},
+				{RepoPath: "b/c.go", Line: 1},
+			},
+		}},
+	}
+	SortRepo(repo)
+	if !proto.Equal(repo, want) {
+		t.Errorf("SortRepo:\n got %v\nwant %v", repo, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+)
+
+// A sourceReader reads and parses the source files of a package directory.
+// The contents of each file are read into a single buffer that is reused for
+// the next file, and handed to the parser directly, so that reading a file
+// does not allocate a new copy of its contents. All the files share one
+// FileSet. A sourceReader is not safe for concurrent use; each worker
+// analyzing a directory uses its own, and releases it when done so that the
+// next directory can reuse its buffer.
+//
+// The parser copies the names and literals it reports, so the files it
+// returns do not refer to the buffer.
+//
+// Each file is parsed at most once per mode: the reader keeps the result, and
+// a request for less of a file than was already parsed reuses it. Unless
+// symbol analysis asks for the whole file, only the package clause and the
+// imports are parsed.
+type sourceReader struct {
+	bc     *build.Context
+	fset   *token.FileSet
+	buf    *bytes.Buffer
+	parsed map[string]*parsedFile // :: path → most complete parse
+}
+
+// A parsedFile records the result of parsing a file with a given mode.
+type parsedFile struct {
+	mode parser.Mode
+	file *ast.File
+	err  error
+}
+
+func newSourceReader(bc *build.Context) *sourceReader {
+	return &sourceReader{
+		bc:     bc,
+		fset:   token.NewFileSet(),
+		buf:    getBuffer(),
+		parsed: make(map[string]*parsedFile),
+	}
+}
+
+// release returns the buffer of r to the pool. The reader must not be used
+// after it is released.
+func (r *sourceReader) release() {
+	putBuffer(r.buf)
+	r.buf = nil
+	r.parsed = nil
+}
+
+// read returns the contents of the file at path. The result is valid only
+// until the next call to a method of r.
+func (r *sourceReader) read(path string) ([]byte, error) {
+	rc, err := openFile(r.bc, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	r.buf.Reset()
+	if _, err := r.buf.ReadFrom(rc); err != nil {
+		return nil, err
+	}
+	return r.buf.Bytes(), nil
+}
+
+// parse parses the Go source file at path with the given mode, which must be
+// 0, parser.ImportsOnly, or parser.PackageClauseOnly. If the file was already
+// parsed with a mode that covers this one, the earlier result is returned.
+// Positions in the result are relative to r.fset.
+func (r *sourceReader) parse(path string, mode parser.Mode) (*ast.File, error) {
+	if p, ok := r.parsed[path]; ok && parseLevel(p.mode) >= parseLevel(mode) {
+		return p.file, p.err
+	}
+	data, err := r.read(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(r.fset, path, data, mode)
+	r.parsed[path] = &parsedFile{mode: mode, file: f, err: err}
+	return f, err
+}
+
+// parseLevel orders parser modes by how much of a file they parse.
+func parseLevel(mode parser.Mode) int {
+	switch mode {
+	case parser.PackageClauseOnly:
+		return 0
+	case parser.ImportsOnly:
+		return 1
+	}
+	return 2
+}
+
+// hash returns the digest of the contents of the file at path using h.
+func (r *sourceReader) hash(path string, h HashAlgorithm) ([]byte, error) {
+	data, err := r.read(path)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(data), nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run mkstdlib.go
+
+// A StdlibCatalog lists the import paths of the standard library packages,
+// each mapped to the Go version that introduced it, for example "1.16" for
+// "embed". A package with an empty version is present in every version.
+type StdlibCatalog map[string]string
+
+// DefaultStdlib is the catalog of standard library packages built into this
+// package. It does not depend on the Go toolchain installed where it is used.
+var DefaultStdlib = StdlibCatalog(stdlibPackages)
+
+// Contains reports whether ipath is a standard library package in the given
+// Go version, written either as "1.21" or "go1.21". If version is empty, or
+// cannot be parsed, Contains reports whether ipath is in any version.
+func (c StdlibCatalog) Contains(ipath, version string) bool {
+	added, ok := c[ipath]
+	if !ok {
+		return false
+	}
+	want, ok := goMinor(version)
+	if !ok {
+		return true
+	}
+	have, ok := goMinor(added)
+	return !ok || have <= want
+}
+
+// goMinor returns the minor version number of a Go version string such as
+// "1.21", "go1.21", or "1.21.3", and reports whether it was valid.
+func goMinor(version string) (int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "go"), ".", 3)
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return minor, true
+}
+
+// ParseStdlibCatalog parses a catalog from text. Each line that is not blank
+// and does not begin with "#" gives the import path of a package, optionally
+// followed by the Go version that introduced it:
+//
+//	embed 1.16
+//	iter  1.23
+//	fmt
+func ParseStdlibCatalog(data []byte) (StdlibCatalog, error) {
+	cat := make(StdlibCatalog)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for ln := 1; s.Scan(); ln++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		} else if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: extra fields after version", ln)
+		}
+		var version string
+		if len(fields) == 2 {
+			if _, ok := goMinor(fields[1]); !ok {
+				return nil, fmt.Errorf("line %d: invalid Go version %q", ln, fields[1])
+			}
+			version = strings.TrimPrefix(fields[1], "go")
+		}
+		cat[fields[0]] = version
+	}
+	return cat, s.Err()
+}
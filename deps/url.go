@@ -0,0 +1,57 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import "strings"
+
+// CanonicalURL returns a canonical form of the repository URL s, so that the
+// same repository reached by different URLs has a single identity. The
+// canonical form omits the scheme and any user information, so that HTTPS,
+// SSH, and Git protocol URLs agree; the host name is folded to lower case;
+// and a trailing "/" or ".git" suffix is removed. Thus all of
+//
+//	https://github.com/foo/bar.git
+//	ssh://git@github.com/foo/bar
+//	git@GitHub.com:foo/bar.git
+//
+// have the canonical form "github.com/foo/bar", as does "GitHub.com/foo/bar".
+// A local file path is returned without its ".git" suffix, but is otherwise
+// unchanged.
+func CanonicalURL(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "://"); i >= 0 {
+		if s[:i] == "file" {
+			return trimRepoSuffix(s[i+3:])
+		}
+		s = s[i+3:]
+	} else if i := strings.Index(s, ":"); i > 0 && !strings.ContainsAny(s[:i], "/\\") {
+		// An scp-like address, user@host:path.
+		s = s[:i] + "/" + strings.TrimPrefix(s[i+1:], "/")
+	} else if first := strings.SplitN(s, "/", 2)[0]; !strings.Contains(first, ".") || strings.HasPrefix(first, ".") {
+		return trimRepoSuffix(s) // a local path
+	}
+	host, rest := s, ""
+	if i := strings.Index(s, "/"); i >= 0 {
+		host, rest = s[:i], s[i:]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	return trimRepoSuffix(strings.ToLower(host) + rest)
+}
+
+func trimRepoSuffix(s string) string {
+	return strings.TrimSuffix(strings.TrimRight(s, "/"), ".git")
+}
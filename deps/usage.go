@@ -0,0 +1,139 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deps
+
+import (
+	"go/ast"
+	"go/parser"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// symbolUses maps an import path to the number of references made to each
+// exported identifier of that package.
+type symbolUses map[string]map[string]int64
+
+// refs returns the total number of references made to ipath.
+func (s symbolUses) refs(ipath string) (n int64) {
+	for _, v := range s[ipath] {
+		n += v
+	}
+	return
+}
+
+// scanImportNames parses the imports of the named Go source files in dir, and
+// returns the import paths that are declared only as blank (_) imports, and
+// those that are declared as dot (.) imports in at least one file, in order of
+// first appearance.
+func scanImportNames(src *sourceReader, dir string, names []string) (blank, dot []string, err error) {
+	named := make(map[string]bool) // imported other than blank in some file
+	for _, name := range names {
+		f, err := src.parse(filepath.Join(dir, name), parser.ImportsOnly)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, spec := range f.Imports {
+			ip, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			switch {
+			case spec.Name != nil && spec.Name.Name == "_":
+				blank = appendNew(blank, ip)
+			case spec.Name != nil && spec.Name.Name == ".":
+				dot = appendNew(dot, ip)
+				named[ip] = true
+			default:
+				named[ip] = true
+			}
+		}
+	}
+	var onlyBlank []string
+	for _, ip := range blank {
+		if !named[ip] {
+			onlyBlank = append(onlyBlank, ip)
+		}
+	}
+	return onlyBlank, dot, nil
+}
+
+// countSymbolUses parses the named Go source files in dir and counts the
+// references each makes to the exported identifiers of its imports. This is a
+// syntactic approximation: a reference is a selector expression whose operand
+// is the name under which a package was imported, and that name is not
+// shadowed in the file. Dot imports cannot be attributed and are not counted.
+func countSymbolUses(src *sourceReader, dir string, names []string) (symbolUses, error) {
+	uses := make(symbolUses)
+	for _, name := range names {
+		f, err := src.parse(filepath.Join(dir, name), 0)
+		if err != nil {
+			return nil, err
+		}
+
+		byName := make(map[string]string) // :: local name → import path
+		for _, spec := range f.Imports {
+			ip, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			local := importName(ip)
+			if spec.Name != nil {
+				local = spec.Name.Name
+			}
+			if local != "_" && local != "." {
+				byName[local] = ip
+			}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok || !sel.Sel.IsExported() {
+				return true
+			}
+			id, ok := sel.X.(*ast.Ident)
+			if !ok || id.Obj != nil {
+				return true // not a package name, or shadowed by a local
+			}
+			if ip, ok := byName[id.Name]; ok {
+				if uses[ip] == nil {
+					uses[ip] = make(map[string]int64)
+				}
+				uses[ip][sel.Sel.Name]++
+			}
+			return true
+		})
+	}
+	return uses, nil
+}
+
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName guesses the name of the package imported as ipath, when it is
+// not renamed by the import. By convention this is the last element of the
+// path, ignoring a major version suffix, a gopkg.in version, and a "go-"
+// prefix or ".go" suffix.
+func importName(ipath string) string {
+	parts := strings.Split(ipath, "/")
+	name := parts[len(parts)-1]
+	if majorSuffix.MatchString(name) && len(parts) > 1 {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 && strings.HasPrefix(ipath, "gopkg.in/") {
+		name = name[:i]
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), ".go")
+	return strings.Replace(name, "-", "_", -1)
+}
@@ -0,0 +1,176 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// esMapping is the index mapping used for packages. Import paths are indexed
+// both as keywords, for exact and prefix queries, and as text, for full-text
+// search over their components. The description of the repository is indexed
+// as text.
+const esMapping = `{
+  "mappings": {
+    "properties": {
+      "import_path": {"type": "keyword", "fields": {"text": {"type": "text", "analyzer": "simple"}}},
+      "name":        {"type": "keyword"},
+      "repository":  {"type": "keyword"},
+      "module":      {"type": "keyword"},
+      "imports":     {"type": "keyword"},
+      "labels":      {"type": "object", "dynamic": true},
+      "description": {"type": "text"}
+    }
+  }
+}`
+
+// esSink indexes packages into an Elasticsearch or OpenSearch index using the
+// bulk API. Each package is a document whose ID is its import path, so that
+// rescanning a package replaces its document.
+type esSink struct {
+	base  string // the URL of the cluster
+	index string // the name of the index
+}
+
+type esDoc struct {
+	ImportPath string            `json:"import_path"`
+	Name       string            `json:"name,omitempty"`
+	Repository string            `json:"repository,omitempty"`
+	Module     string            `json:"module,omitempty"`
+	Imports    []string          `json:"imports,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+
+	Description string `json:"description,omitempty"`
+}
+
+// newESSink constructs a sink for the index named by the last path element of
+// target, and creates the index if it does not already exist.
+func newESSink(ctx context.Context, target string) (*esSink, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("elasticsearch sink requires an index URL, not %q", target)
+	}
+	index := path.Base(u.Path)
+	if index == "/" || index == "." {
+		return nil, fmt.Errorf("missing index name in %q", target)
+	}
+	u.Path = strings.TrimSuffix(path.Dir(u.Path), "/")
+	s := &esSink{base: u.String(), index: index}
+
+	req, err := http.NewRequest("PUT", s.base+"/"+index, strings.NewReader(esMapping))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 4096))
+	if rsp.StatusCode/100 != 2 && !bytes.Contains(msg, []byte("resource_already_exists_exception")) {
+		return nil, fmt.Errorf("creating index %q: %s: %s", index, rsp.Status, bytes.TrimSpace(msg))
+	}
+	return s, nil
+}
+
+func (s *esSink) Write(ctx context.Context, repos []*deps.Repo) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, repo := range repos {
+		var url string
+		if len(repo.Remotes) != 0 {
+			url = repo.Remotes[0].Url
+		}
+		for _, pkg := range repo.Packages {
+			var action struct {
+				Index struct {
+					Index string `json:"_index"`
+					ID    string `json:"_id"`
+				} `json:"index"`
+			}
+			action.Index.Index = s.index
+			action.Index.ID = pkg.ImportPath
+			if err := enc.Encode(action); err != nil {
+				return err
+			}
+			if err := enc.Encode(esDoc{
+				ImportPath: pkg.ImportPath,
+				Name:       pkg.Name,
+				Repository: url,
+				Module:     pkg.Module,
+				Imports:    pkg.Imports,
+				Labels:     repo.Labels,
+
+				Description: repo.Description,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	req, err := http.NewRequest("POST", s.base+"/_bulk", &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rsp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return fmt.Errorf("bulk indexing: %s: %s", rsp.Status, bytes.TrimSpace(msg))
+	}
+
+	// The bulk API reports success even if some of the actions failed.
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(rsp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding bulk response: %v", err)
+	} else if result.Errors {
+		for _, item := range result.Items {
+			for _, r := range item {
+				if len(r.Error) != 0 {
+					return fmt.Errorf("indexing %q: %s", r.ID, r.Error)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (*esSink) Close() error { return nil }
@@ -0,0 +1,253 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package github fetches repository metadata from the GitHub API.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creachadair/repodeps/auth"
+	"github.com/creachadair/repodeps/deps"
+)
+
+// DefaultMaxAge is the default maximum age of a cached result.
+const DefaultMaxAge = 24 * time.Hour
+
+// A Client fetches repository metadata from GitHub. Requests are spaced at
+// least Interval apart, and are paused when the API reports that the rate
+// limit has been exhausted. The zero value is ready for use, but makes
+// unauthenticated requests and does not cache results.
+type Client struct {
+	// If set, this token is used to authenticate requests.
+	Token string
+
+	// If set, this credential supplies the tokens used to authenticate
+	// requests, in place of Token.
+	Credential *auth.Credential
+
+	// If set, results are cached in this directory.
+	CacheDir string
+
+	// Cached results older than this are fetched again. If zero, use
+	// DefaultMaxAge.
+	MaxAge time.Duration
+
+	// The minimum interval between requests to the API.
+	Interval time.Duration
+
+	// The HTTP client to use. If nil, use http.DefaultClient.
+	HTTPClient *http.Client
+
+	mu   sync.Mutex
+	next time.Time // the earliest time the next request may be sent
+}
+
+// Enrich populates the GitHubInfo field of repo from the first of its remotes
+// that refers to a GitHub repository. If none do, Enrich does nothing.
+func (c *Client) Enrich(ctx context.Context, repo *deps.Repo) error {
+	for _, rem := range repo.Remotes {
+		if owner, name, ok := ParseURL(rem.Url); ok {
+			info, err := c.Lookup(ctx, owner, name)
+			if err != nil {
+				return err
+			}
+			repo.GithubInfo = info
+			return nil
+		}
+	}
+	return nil
+}
+
+// Lookup returns the metadata for the GitHub repository owner/name.
+func (c *Client) Lookup(ctx context.Context, owner, name string) (*deps.GitHubInfo, error) {
+	if info, ok := c.cached(owner, name); ok {
+		return info, nil
+	}
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", "https://api.github.com/repos/"+owner+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.mercy-preview+json") // for topics
+	token := c.Token
+	if c.Credential != nil {
+		token, err = c.Credential.HTTPToken(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("github: %v", err)
+		}
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	rsp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	c.updateLimit(rsp.Header)
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github: %s/%s: %s", owner, name, rsp.Status)
+	}
+	var meta struct {
+		FullName string   `json:"full_name"`
+		Stars    int64    `json:"stargazers_count"`
+		Forks    int64    `json:"forks_count"`
+		Archived bool     `json:"archived"`
+		Language string   `json:"language"`
+		Topics   []string `json:"topics"`
+		Desc     string   `json:"description"`
+	}
+	if err := json.NewDecoder(rsp.Body).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("github: decoding response: %v", err)
+	}
+	info := &deps.GitHubInfo{
+		Name:     meta.FullName,
+		Stars:    meta.Stars,
+		Forks:    meta.Forks,
+		Archived: meta.Archived,
+		Language: meta.Language,
+		Topics:   meta.Topics,
+
+		Description: meta.Desc,
+	}
+	c.store(owner, name, info)
+	return info, nil
+}
+
+// wait blocks until the next request is permitted or ctx ends.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	start := c.next
+	if start.Before(now) {
+		start = now
+	}
+	c.next = start.Add(c.Interval)
+	c.mu.Unlock()
+
+	if d := start.Sub(now); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return nil
+}
+
+// updateLimit defers further requests until the reset time reported by the
+// API if the rate limit has been exhausted.
+func (c *Client) updateLimit(h http.Header) {
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	secs, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	reset := time.Unix(secs, 0)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reset.After(c.next) {
+		c.next = reset
+	}
+}
+
+// cachePath returns the path of the cache file for owner/name, or "" if
+// caching is not enabled.
+func (c *Client) cachePath(owner, name string) string {
+	if c.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.CacheDir, strings.ToLower(owner), strings.ToLower(name)+".json")
+}
+
+// cached returns the cached result for owner/name, if there is a current one.
+func (c *Client) cached(owner, name string) (*deps.GitHubInfo, bool) {
+	path := c.cachePath(owner, name)
+	if path == "" {
+		return nil, false
+	}
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) > maxAge {
+		return nil, false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	info := new(deps.GitHubInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, false
+	}
+	return info, true
+}
+
+// store writes info to the cache for owner/name, if caching is enabled.
+// Errors are ignored, since the cache is only an optimization.
+func (c *Client) store(owner, name string, info *deps.GitHubInfo) {
+	path := c.cachePath(owner, name)
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+		ioutil.WriteFile(path, data, 0600)
+	}
+}
+
+// ParseURL reports whether url refers to a repository hosted on GitHub, and if
+// so returns its owner and name. It accepts URLs with or without a scheme, as
+// well as SSH-style remotes like "git@github.com:owner/name.git".
+func ParseURL(url string) (owner, name string, ok bool) {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	url = strings.TrimPrefix(url, "git@")
+	url = strings.TrimPrefix(url, "www.")
+	if !strings.HasPrefix(url, "github.com/") && !strings.HasPrefix(url, "github.com:") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(url[len("github.com/"):], ".git"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
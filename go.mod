@@ -9,6 +9,8 @@ require (
 	github.com/creachadair/fileinput v0.0.2
 	github.com/creachadair/taskgroup v0.1.0
 	github.com/golang/protobuf v1.3.1
+	github.com/mattn/go-sqlite3 v1.14.16
+	github.com/zeebo/blake3 v0.2.3
 	gopkg.in/src-d/go-billy-siva.v4 v4.5.1
 	gopkg.in/src-d/go-billy.v4 v4.3.0
 	gopkg.in/src-d/go-git.v4 v4.12.0
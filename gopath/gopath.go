@@ -0,0 +1,237 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gopath analyzes Go dependencies for packages stored in a GOPATH
+// source tree or a module download cache, without reference to version
+// control.
+package gopath
+
+import (
+	"context"
+	"errors"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// Load reads the packages in the GOPATH tree rooted at dir, which should be a
+// directory containing a "src" subdirectory. Packages are grouped into
+// repositories by the directories containing version control metadata; any
+// packages outside such a directory are grouped by their first import path
+// component.
+func Load(ctx context.Context, dir string, opts *deps.Options) ([]*deps.Repo, error) {
+	src := filepath.Join(dir, "src")
+	bc := build.Default
+	bc.GOPATH = dir
+
+	var results []*deps.Repo
+	repos := make(map[string]*deps.Repo) // :: root import path → repo
+	var roots []string                   // repository roots, innermost last
+	err := filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else if !fi.IsDir() {
+			return nil // nothing to do here
+		} else if base := filepath.Base(path); isVCSDir(base) || base == "vendor" || opts.Skipped(base) {
+			return filepath.SkipDir
+		}
+		ipath, _ := filepath.Rel(src, path)
+		ipath = filepath.ToSlash(ipath)
+		if ipath == "." {
+			return nil
+		}
+		for len(roots) != 0 && !within(ipath, roots[len(roots)-1]) {
+			roots = roots[:len(roots)-1]
+		}
+		if hasVCS(path) {
+			roots = append(roots, ipath)
+		}
+		root := strings.SplitN(ipath, "/", 2)[0]
+		if len(roots) != 0 {
+			root = roots[len(roots)-1]
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(ipath, root), "/")
+		if !opts.Included(rel) {
+			return nil // not selected by the caller
+		}
+
+		recs, conflict := deps.AnalyzeDir(&bc, filepath.Join(src, root), path, opts)
+		if len(recs) == 0 && conflict == nil {
+			return nil // nothing here; skip it
+		}
+		for _, rec := range recs {
+			deps.NameLocal(rec, root, rel)
+		}
+		repo, ok := repos[root]
+		if !ok {
+			repo = &deps.Repo{
+				From:    filepath.Join(src, root),
+				Remotes: []*deps.Remote{{Name: "gopath", Url: root}},
+			}
+			repos[root] = repo
+			results = append(results, repo)
+		}
+		repo.Packages = append(repo.Packages, recs...)
+		if conflict != nil {
+			repo.Conflicts = append(repo.Conflicts, conflict)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// LoadModules reads the packages in the module download cache rooted at dir,
+// which has the layout of $GOMODCACHE (by default $GOPATH/pkg/mod). Each
+// module@version directory in the cache is reported as a separate repository,
+// and its packages are attributed to that module version.
+func LoadModules(ctx context.Context, dir string, opts *deps.Options) ([]*deps.Repo, error) {
+	var results []*deps.Repo
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else if !fi.IsDir() {
+			return nil
+		} else if path == filepath.Join(dir, "cache") {
+			return filepath.SkipDir // the download cache, not sources
+		}
+		rel, _ := filepath.Rel(dir, path)
+		i := strings.LastIndex(rel, "@")
+		if i < 0 {
+			return nil // not yet inside a module directory
+		}
+		mpath, err := unescapePath(filepath.ToSlash(rel[:i]))
+		if err != nil {
+			return filepath.SkipDir // not a valid module cache directory
+		}
+		repo, err := loadModule(ctx, path, mpath, rel[i+1:], opts)
+		if err != nil {
+			return err
+		}
+		results = append(results, repo)
+		return filepath.SkipDir
+	})
+	return results, err
+}
+
+// loadModule reads the packages of a single module version rooted at dir.
+func loadModule(ctx context.Context, dir, mpath, version string, opts *deps.Options) (*deps.Repo, error) {
+	// The module cache records the go.mod file for each module version. We
+	// record its requirements, but the replace directives of a dependency do
+	// not apply to its packages, so they are not resolved.
+	mod := &deps.Module{Path: mpath, Dir: "."}
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		var mods deps.ModuleSet
+		if m, err := mods.Add(".", data); err == nil {
+			mod = m
+		}
+	}
+	mod.Path, mod.Version = mpath, version
+	repo := &deps.Repo{
+		From:    dir,
+		Remotes: []*deps.Remote{{Name: "module", Url: mpath}},
+		Modules: []*deps.Module{mod},
+	}
+	bc := build.Default
+	bc.GOPATH = ""
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else if !fi.IsDir() {
+			return nil
+		} else if base := filepath.Base(path); base == "vendor" || base == "testdata" || opts.Skipped(base) {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if !opts.Included(rel) {
+			return nil // not selected by the caller
+		}
+		recs, conflict := deps.AnalyzeDir(&bc, dir, path, opts)
+		if conflict != nil {
+			repo.Conflicts = append(repo.Conflicts, conflict)
+		}
+		for _, rec := range recs {
+			deps.NameLocal(rec, mpath, rel)
+			if rec.Language == "" {
+				rec.ImportPath = mpath
+				if rel != "." {
+					rec.ImportPath += "/" + rel
+				}
+				rec.Module = mpath + "@" + version
+			}
+			repo.Packages = append(repo.Packages, rec)
+		}
+		return nil
+	})
+	return repo, err
+}
+
+// unescapePath reverses the case-encoding used for module paths in the module
+// cache, in which each upper-case letter is written as "!" followed by the
+// corresponding lower-case letter.
+func unescapePath(s string) (string, error) {
+	var buf strings.Builder
+	bang := false
+	for _, c := range s {
+		if bang {
+			if c < 'a' || c > 'z' {
+				return "", errBadEscape
+			}
+			buf.WriteRune(unicode.ToUpper(c))
+			bang = false
+		} else if c == '!' {
+			bang = true
+		} else if unicode.IsUpper(c) {
+			return "", errBadEscape
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+	if bang {
+		return "", errBadEscape
+	}
+	return buf.String(), nil
+}
+
+var errBadEscape = errors.New("invalid escaped module path")
+
+func isVCSDir(name string) bool {
+	return name == ".git" || name == ".hg" || name == ".svn" || name == ".bzr"
+}
+
+// hasVCS reports whether dir contains version control metadata.
+func hasVCS(dir string) bool {
+	for _, name := range []string{".git", ".hg", ".svn", ".bzr"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether import path ipath is equal to or inside root.
+func within(ipath, root string) bool {
+	return ipath == root || strings.HasPrefix(ipath, root+"/")
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// A batch is a buffer of additions waiting to be applied to a graph.
+type batch struct {
+	size     int
+	interval time.Duration
+
+	mu    sync.Mutex
+	items []batchItem
+	timer *time.Timer // pending flush of items, or nil
+	err   error       // from applying a batch in the background
+}
+
+// A batchItem is a buffered addition. Exactly one of pkg and mod is set.
+type batchItem struct {
+	repo *deps.Repo
+	pkg  *deps.Package
+	mod  *deps.Module
+}
+
+// SetBatching arranges for Add and AddModule to buffer additions and apply them
+// together, when size additions have been buffered or interval has elapsed
+// since the first of them, whichever comes first. This allows many goroutines
+// to add packages without each waiting for its own storage writes. A caller
+// that finds the buffer full waits while it is applied, so the buffer does
+// not grow much beyond size when storage is slow. If size <= 1, batching is
+// disabled and additions are applied immediately; any that were buffered are
+// applied first.
+//
+// Buffered additions are not visible to methods that read the graph, and the
+// caller must call Flush to apply them before closing the storage. An error
+// from applying additions in the background is reported by the next call to
+// Add, AddModule, or Flush. SetBatching must not be called concurrently with
+// other methods of g.
+func (g *Graph) SetBatching(size int, interval time.Duration) error {
+	var err error
+	if g.batch != nil {
+		err = g.Flush(context.Background())
+	}
+	if size <= 1 {
+		g.batch = nil
+	} else {
+		g.batch = &batch{size: size, interval: interval}
+	}
+	return err
+}
+
+// Flush applies any additions buffered by Add and AddModule. It reports the
+// first error from applying them, or from applying earlier additions in the
+// background.
+func (g *Graph) Flush(ctx context.Context) error {
+	if g.batch == nil {
+		return nil
+	}
+	b := g.batch
+	err := g.flush(ctx, b)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		err, b.err = b.err, nil
+	}
+	return err
+}
+
+// enqueue buffers item, and applies the buffered items if the buffer is full.
+func (g *Graph) enqueue(ctx context.Context, item batchItem) error {
+	b := g.batch
+	b.mu.Lock()
+	if err := b.err; err != nil {
+		b.err = nil
+		b.mu.Unlock()
+		return err
+	}
+	b.items = append(b.items, item)
+	if len(b.items) < b.size {
+		if b.timer == nil && b.interval > 0 {
+			b.timer = time.AfterFunc(b.interval, func() {
+				if err := g.flush(context.Background(), b); err != nil {
+					b.mu.Lock()
+					if b.err == nil {
+						b.err = err
+					}
+					b.mu.Unlock()
+				}
+			})
+		}
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+	return g.flush(ctx, b)
+}
+
+// flush applies the items buffered in b in the order they were added, and
+// reports the first error from applying them. The items are taken from the
+// buffer while holding g.mu, so that batches are applied in order, and are
+// applied in a single pass under the lock that advances the version once.
+func (g *Graph) flush(ctx context.Context, b *batch) (first error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b.mu.Lock()
+	items := b.items
+	b.items = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+	if len(items) == 0 {
+		return nil
+	}
+
+	defer g.changed(ctx, &first)
+	now := time.Now().Unix()
+	for _, item := range items {
+		var err error
+		if item.pkg != nil {
+			err = g.add(ctx, item.repo, item.pkg, now)
+		} else {
+			err = g.addModule(ctx, item.repo, item.mod, now)
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+var batchRepo = &deps.Repo{Remotes: []*deps.Remote{{Url: "example.com/r"}}}
+
+// addPackage adds a package with the given import path to g.
+func addPackage(ctx context.Context, t *testing.T, g *Graph, ipath string) {
+	t.Helper()
+	if err := g.Add(ctx, batchRepo, &deps.Package{Name: "p", ImportPath: ipath}); err != nil {
+		t.Fatalf("Add %q: %v", ipath, err)
+	}
+}
+
+// checkRows verifies which of the given import paths have rows in g.
+func checkRows(ctx context.Context, t *testing.T, g *Graph, want map[string]bool) {
+	t.Helper()
+	for ipath, ok := range want {
+		_, err := g.Row(ctx, ipath)
+		if ok && err != nil {
+			t.Errorf("Row %q: unexpected error: %v", ipath, err)
+		} else if !ok && err != ErrNotFound {
+			t.Errorf("Row %q: got error %v, want %v", ipath, err, ErrNotFound)
+		}
+	}
+}
+
+func TestBatching(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	if err := g.SetBatching(3, 0); err != nil {
+		t.Fatalf("SetBatching: %v", err)
+	}
+	v0 := mustVersion(ctx, t, g)
+
+	// Additions are buffered until the batch is full, and then applied
+	// together as one version.
+	addPackage(ctx, t, g, "a")
+	addPackage(ctx, t, g, "b")
+	checkRows(ctx, t, g, map[string]bool{"a": false, "b": false})
+	if v := mustVersion(ctx, t, g); v != v0 {
+		t.Errorf("Version before the batch is full: got %d, want %d", v, v0)
+	}
+	if err := g.AddModule(ctx, batchRepo, &deps.Module{Path: "m", Version: "v1.0.0"}); err != nil {
+		t.Fatalf("AddModule: %v", err)
+	}
+	checkRows(ctx, t, g, map[string]bool{"a": true, "b": true})
+	if _, err := g.Module(ctx, "m", "v1.0.0"); err != nil {
+		t.Errorf("Module: unexpected error: %v", err)
+	}
+	if v := mustVersion(ctx, t, g); v != v0+1 {
+		t.Errorf("Version after the batch is full: got %d, want %d", v, v0+1)
+	}
+
+	// Flush applies a partial batch, and does nothing to an empty one.
+	addPackage(ctx, t, g, "c")
+	checkRows(ctx, t, g, map[string]bool{"c": false})
+	for i := 0; i < 2; i++ {
+		if err := g.Flush(ctx); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	}
+	checkRows(ctx, t, g, map[string]bool{"c": true})
+	if v := mustVersion(ctx, t, g); v != v0+2 {
+		t.Errorf("Version after Flush: got %d, want %d", v, v0+2)
+	}
+
+	// Disabling batching applies what was buffered, and later additions
+	// are applied at once.
+	addPackage(ctx, t, g, "d")
+	if err := g.SetBatching(1, 0); err != nil {
+		t.Fatalf("SetBatching: %v", err)
+	}
+	addPackage(ctx, t, g, "e")
+	checkRows(ctx, t, g, map[string]bool{"d": true, "e": true})
+}
+
+func TestBatchInterval(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	if err := g.SetBatching(100, 10*time.Millisecond); err != nil {
+		t.Fatalf("SetBatching: %v", err)
+	}
+	addPackage(ctx, t, g, "a")
+
+	// The buffered addition is applied in the background once the interval
+	// has elapsed, without a call to Flush.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := g.Row(ctx, "a"); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("Row: addition was not applied: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBatchConcurrent(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	if err := g.SetBatching(8, time.Millisecond); err != nil {
+		t.Fatalf("SetBatching: %v", err)
+	}
+	const numPackages = 100
+	var wg sync.WaitGroup
+	for i := 0; i < numPackages; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ipath := fmt.Sprintf("p%d", i)
+			if err := g.Add(ctx, batchRepo, &deps.Package{Name: "p", ImportPath: ipath}); err != nil {
+				t.Errorf("Add %q: %v", ipath, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := g.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	want := make(map[string]bool)
+	for i := 0; i < numPackages; i++ {
+		want[fmt.Sprintf("p%d", i)] = true
+	}
+	checkRows(ctx, t, g, want)
+}
@@ -0,0 +1,193 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A ProblemKind classifies an integrity problem found by Check.
+type ProblemKind int
+
+// Constants for the ProblemKind type.
+const (
+	Unreadable  ProblemKind = iota + 1 // the row could not be loaded
+	InvalidKey                         // the key is not a plausible import path
+	KeyMismatch                        // the row's import path differs from its key
+	Dangling                           // the row lists dependencies with no row
+)
+
+var problemNames = map[ProblemKind]string{
+	Unreadable:  "unreadable",
+	InvalidKey:  "invalid key",
+	KeyMismatch: "key mismatch",
+	Dangling:    "dangling edges",
+}
+
+func (k ProblemKind) String() string {
+	if s, ok := problemNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("ProblemKind(%d)", int(k))
+}
+
+// A Problem describes an integrity problem with a single row of the graph.
+type Problem struct {
+	Key  string
+	Kind ProblemKind
+	Row  *Row  // the row, if it could be loaded
+	Err  error // for Unreadable, the error from loading the row
+
+	// For Dangling, the dependencies that have no rows in the graph.
+	Missing []string
+}
+
+func (p *Problem) String() string {
+	switch p.Kind {
+	case Unreadable:
+		return fmt.Sprintf("%q: %v: %v", p.Key, p.Kind, p.Err)
+	case KeyMismatch:
+		return fmt.Sprintf("%q: %v: row has import path %q", p.Key, p.Kind, p.Row.ImportPath)
+	case Dangling:
+		return fmt.Sprintf("%q: %v: %s", p.Key, p.Kind, strings.Join(p.Missing, ", "))
+	}
+	return fmt.Sprintf("%q: %v", p.Key, p.Kind)
+}
+
+// Check scans the package rows of the graph for integrity problems, and calls
+// f for each problem found. A row may have more than one problem. If f reports
+// an error, checking terminates. If the error is ErrStopScan Check returns
+// nil; otherwise Check returns the error from f.
+func (g *Graph) Check(ctx context.Context, f func(*Problem) error) error {
+	// Collect the keys first, so that dangling edges can be identified in a
+	// single pass over the rows.
+	var keys []string
+	have := make(map[string]bool)
+	if err := g.st.Scan(ctx, "", func(key string) error {
+		if !isAux(key) {
+			keys = append(keys, key)
+			have[key] = true
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		var probs []*Problem
+		row, err := g.Row(ctx, key)
+		if _, ok := err.(*SchemaError); ok {
+			return err // do not misreport rows this package cannot interpret
+		} else if err != nil {
+			probs = append(probs, &Problem{Key: key, Kind: Unreadable, Err: err})
+		} else {
+			if !validKey(key) {
+				probs = append(probs, &Problem{Key: key, Kind: InvalidKey, Row: row})
+			} else if row.ImportPath != key {
+				probs = append(probs, &Problem{Key: key, Kind: KeyMismatch, Row: row})
+			}
+			var missing []string
+			for _, dep := range row.Directs {
+				if !have[dep] {
+					missing = append(missing, dep)
+				}
+			}
+			if len(missing) != 0 {
+				probs = append(probs, &Problem{Key: key, Kind: Dangling, Row: row, Missing: missing})
+			}
+		}
+		for _, p := range probs {
+			if err := f(p); err == ErrStopScan {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validKey reports whether key is plausible as an import path.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") || strings.Contains(key, "//") {
+		return false
+	}
+	for _, r := range key {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) || strings.ContainsRune(`"'\`+"`", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Quarantine moves the row stored under key to a separate table where it is
+// not visited by Scan, and returns the key where it was moved. The row need
+// not be readable.
+func (g *Graph) Quarantine(ctx context.Context, key string) (_ string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.changed(ctx, &err)
+	qkey := quarantinePrefix + key
+	if err := g.record(&JournalEntry{Op: JournalOp_QUARANTINE, Key: key}); err != nil {
+		return "", err
+	}
+	return qkey, g.st.Rename(ctx, key, qkey)
+}
+
+// Remove deletes the row stored under key.
+func (g *Graph) Remove(ctx context.Context, key string) (err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.changed(ctx, &err)
+	if err := g.record(&JournalEntry{Op: JournalOp_REMOVE, Key: key}); err != nil {
+		return err
+	}
+	return g.st.Delete(ctx, key)
+}
+
+// Put stores row under its import path, replacing any existing row. Like
+// Add, it updates the indexes and dependency history of the package, removes
+// its tombstone, and records its repository as a provider.
+func (g *Graph) Put(ctx context.Context, row *Row) error {
+	return g.putAt(ctx, row, time.Now().Unix())
+}
+
+// putAt stores row as Put does, as of the given time in seconds since the Unix
+// epoch.
+func (g *Graph) putAt(ctx context.Context, row *Row, now int64) (err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.changed(ctx, &err)
+	if err := g.record(&JournalEntry{Op: JournalOp_PUT, Key: row.ImportPath, Row: row, Time: now}); err != nil {
+		return err
+	}
+
+	// A row does not record the commit it was read from, so keep the commit
+	// already recorded for its repository, if any.
+	var commit string
+	ps, err := g.Providers(ctx, row.ImportPath)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	for _, p := range ps {
+		if p.Repository == row.Repository {
+			commit = p.Commit
+		}
+	}
+	return g.store(ctx, row, commit, now)
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+func TestPut(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	repo := &deps.Repo{Remotes: []*deps.Remote{{Url: "example.com/r"}}, Commit: "c0ffee"}
+	if err := g.Add(ctx, repo, &deps.Package{Name: "a", ImportPath: "r/a"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Delete(ctx, "r/a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Put maintains the same records as Add.
+	if err := g.putAt(ctx, &Row{Name: "a", ImportPath: "r/a", Repository: "example.com/r", Directs: []string{"r/b"}}, 100); err != nil {
+		t.Fatalf("Put: unexpected error: %v", err)
+	}
+	if ts, err := g.Tombstone(ctx, "r/a"); err != ErrNotFound {
+		t.Errorf("Tombstone after Put: got (%+v, %v), want %v", ts, err, ErrNotFound)
+	}
+	if h, err := g.EdgeHistory(ctx, "r/a"); err != nil {
+		t.Errorf("EdgeHistory: unexpected error: %v", err)
+	} else if e := h.Edge("r/b"); e == nil || e.FirstSeen != 100 {
+		t.Errorf("EdgeHistory: got edge %+v, want r/b first seen at 100", e)
+	}
+	if ps, err := g.Providers(ctx, "r/a"); err != nil {
+		t.Errorf("Providers: unexpected error: %v", err)
+	} else if len(ps) != 1 || ps[0].Repository != "example.com/r" || ps[0].Commit != "c0ffee" || ps[0].Updated != 100 {
+		t.Errorf("Providers: got %+v, want example.com/r at c0ffee updated at 100", ps)
+	}
+	if err := g.ReindexImporters(ctx); err != nil {
+		t.Fatalf("ReindexImporters: %v", err)
+	}
+	if err := g.Put(ctx, &Row{Name: "c", ImportPath: "r/c", Directs: []string{"r/b"}}); err != nil {
+		t.Fatalf("Put: unexpected error: %v", err)
+	}
+	if got := importers(ctx, t, g, "r/b", "", 0); len(got) != 2 || got[0] != "r/a" || got[1] != "r/c" {
+		t.Errorf("Importers after Put: got %q, want [r/a r/c]", got)
+	}
+	if _, err := g.Providers(ctx, "r/c"); err != ErrNotFound {
+		t.Errorf("Providers of a row without a repository: got %v, want %v", err, ErrNotFound)
+	}
+}
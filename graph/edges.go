@@ -0,0 +1,79 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"sort"
+)
+
+// EdgeKey returns the storage key for the edge history of the package with
+// the given import path.
+func EdgeKey(ipath string) string { return edgePrefix + ipath }
+
+// EdgeHistory returns the history of the dependencies of the package with the
+// given import path, including dependencies it no longer has. If the package
+// has no recorded history, EdgeHistory reports ErrNotFound.
+func (g *Graph) EdgeHistory(ctx context.Context, ipath string) (*EdgeHistory, error) {
+	var h EdgeHistory
+	if err := g.st.Load(ctx, EdgeKey(ipath), &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// Edge returns the history of the dependency on target, or nil if that
+// dependency has never been recorded.
+func (h *EdgeHistory) Edge(target string) *Edge {
+	i := sort.Search(len(h.Edges), func(i int) bool { return h.Edges[i].Target >= target })
+	if i < len(h.Edges) && h.Edges[i].Target == target {
+		return h.Edges[i]
+	}
+	return nil
+}
+
+// recordEdges updates the edge history of the package ipath to record that it
+// has the given direct dependencies as of now, in seconds since the Unix
+// epoch. A dependency that was absent from the previous scan begins a new run
+// of observations.
+func (g *Graph) recordEdges(ctx context.Context, ipath string, directs []string, now int64) error {
+	var h EdgeHistory
+	if err := g.st.Load(ctx, EdgeKey(ipath), &h); err != nil && err != ErrNotFound {
+		return err
+	}
+	seen := make(map[string]bool)
+	var added []*Edge
+	for _, dep := range directs {
+		seen[dep] = true
+		if e := h.Edge(dep); e == nil {
+			added = append(added, &Edge{Target: dep, FirstSeen: now, LastSeen: now})
+		} else {
+			if e.Dropped {
+				e.FirstSeen = now // the edge was dropped and has returned
+				e.Dropped = false
+			}
+			e.LastSeen = now
+		}
+	}
+	for _, e := range h.Edges {
+		if !seen[e.Target] {
+			e.Dropped = true
+		}
+	}
+	h.Edges = append(h.Edges, added...)
+	sort.Slice(h.Edges, func(i, j int) bool { return h.Edges[i].Target < h.Edges[j].Target })
+	h.Updated = now
+	return g.st.Store(ctx, EdgeKey(ipath), &h)
+}
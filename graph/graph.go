@@ -20,6 +20,11 @@ package graph
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/creachadair/repodeps/deps"
 	"github.com/golang/protobuf/proto"
@@ -28,36 +33,187 @@ import (
 //go:generate protoc --go_out=. graph.proto
 
 // TODO: Identifiable errors.
-// TODO: Reverse index.
 // TODO: RDF output.
 
-// A Graph is an interface to a package dependency graph.
+// SchemaVersion is the version of the Row schema written by this package.
+// It must be incremented when a change to the schema would cause older readers
+// to misinterpret new rows.
+const SchemaVersion = 1
+
+// A Graph is an interface to a package dependency graph. The methods of a
+// Graph are safe for concurrent use; methods that modify the graph are
+// applied one at a time.
 type Graph struct {
-	st Storage
+	// The version of the graph, or -1 if it has not been loaded. It is
+	// accessed atomically, so it comes first for 64-bit alignment.
+	version int64
+
+	st      Storage
+	journal *Journal // if not nil, mutations are recorded here
+	batch   *batch   // if not nil, additions are buffered here
+
+	mu sync.Mutex // serializes mutations
 }
 
 // New constructs a graph handle for the given storage.
-func New(st Storage) *Graph { return &Graph{st: st} }
+func New(st Storage) *Graph {
+	if _, ok := st.(Snapshotter); !ok {
+		st = &versioned{Storage: st}
+	}
+	return &Graph{st: st, version: -1}
+}
 
-// Add adds the specified package to the graph.
+// Version returns the version of the graph, a counter that increases with
+// each batch of mutations: each call that adds, replaces, or removes packages
+// or modules, or each flush of buffered additions (see SetBatching). A result
+// computed from the graph remains valid while Version reports the same value.
+// The version is kept in storage, so it does not decrease when the graph is
+// reopened; a graph that has never been modified has version 0.
+func (g *Graph) Version(ctx context.Context) (int64, error) {
+	if v := atomic.LoadInt64(&g.version); v >= 0 {
+		return v, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.loadVersion(ctx)
+}
+
+// loadVersion returns the version of g, reading it from storage if it has not
+// already been read. The caller must hold g.mu.
+func (g *Graph) loadVersion(ctx context.Context) (int64, error) {
+	if v := atomic.LoadInt64(&g.version); v >= 0 {
+		return v, nil
+	}
+	var gv GraphVersion
+	if err := g.st.Load(ctx, versionKey, &gv); err != nil && err != ErrNotFound {
+		return 0, err
+	}
+	atomic.StoreInt64(&g.version, gv.Version)
+	return gv.Version, nil
+}
+
+// changed advances the version of g after a batch of mutations, and records
+// the new version in storage. The caller must hold g.mu, and must call
+// changed once the batch is complete, whether or not it succeeded, since a
+// failed batch may have been partly applied. If the version cannot be
+// recorded and *errp == nil, the error is reported in *errp.
+func (g *Graph) changed(ctx context.Context, errp *error) {
+	v, err := g.loadVersion(ctx)
+	if err == nil {
+		v++
+		atomic.StoreInt64(&g.version, v)
+		err = g.st.Store(ctx, versionKey, &GraphVersion{Version: v, Updated: time.Now().Unix()})
+	}
+	if err != nil && *errp == nil {
+		*errp = err
+	}
+}
+
+// Add adds the specified package to the graph, records its repository in the
+// provider index for its import path, adds it to the search and reverse
+// dependency indexes, and updates the history of its dependencies. Any
+// tombstone left by a previous deletion of the package is removed.
+//
+// If batching is enabled, the addition may be buffered; see SetBatching.
 func (g *Graph) Add(ctx context.Context, repo *deps.Repo, pkg *deps.Package) error {
+	if g.batch != nil {
+		return g.enqueue(ctx, batchItem{repo: repo, pkg: pkg})
+	}
+	return g.addAt(ctx, repo, pkg, time.Now().Unix())
+}
+
+// addAt adds pkg as Add does without batching, as of the given time in
+// seconds since the Unix epoch.
+func (g *Graph) addAt(ctx context.Context, repo *deps.Repo, pkg *deps.Package, now int64) (err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.changed(ctx, &err)
+	return g.add(ctx, repo, pkg, now)
+}
+
+// add implements Add, as of the given time in seconds since the Unix epoch.
+// The caller must hold g.mu.
+func (g *Graph) add(ctx context.Context, repo *deps.Repo, pkg *deps.Package, now int64) error {
+	if err := g.recordAdd(repo, pkg, nil, now); err != nil {
+		return err
+	}
 	var url string
 	if len(repo.Remotes) != 0 {
 		url = repo.Remotes[0].Url
 	}
-	return g.st.Store(ctx, pkg.ImportPath, &Row{
+	var classes []ImportClass
+	for _, c := range pkg.ImportClasses {
+		classes = append(classes, ImportClass(c))
+	}
+	var sites []*ImportSite
+	for _, s := range pkg.ImportSites {
+		sites = append(sites, &ImportSite{
+			ImportPath: s.ImportPath,
+			RepoPath:   s.RepoPath,
+			Line:       s.Line,
+			Column:     s.Column,
+		})
+	}
+	var digest string
+	if len(pkg.Digest) != 0 {
+		digest = fmt.Sprintf("%s:%x", pkg.HashAlgorithm, pkg.Digest)
+	}
+	row := &Row{
 		Name:       pkg.Name,
 		ImportPath: pkg.ImportPath,
 		Repository: url,
 		Directs:    pkg.Imports,
-	})
+		StdDirects: pkg.StdImports,
+		Classes:    classes,
+		Owners:     pkg.Owners,
+		Refs:       pkg.ImportRefs,
+		Symbols:    pkg.ImportSymbols,
+		Language:   pkg.Language,
+		Labels:     repo.Labels,
+		Sites:      sites,
+		Digest:     digest,
+		License:    repo.License,
+		Schema:     SchemaVersion,
+		Module:     pkg.Module,
+		LastCommit: repo.GetActivity().GetLastCommit(),
+
+		BlankDirects: pkg.BlankImports,
+		DotDirects:   pkg.DotImports,
+
+		UsesUnsafe:  pkg.UsesUnsafe,
+		UsesReflect: pkg.UsesReflect,
+		UsesSyscall: pkg.UsesSyscall,
+	}
+	return g.store(ctx, row, repo.Commit, now)
 }
 
-// Row loads the complete row for the specified import path.
+// store stores row under its import path, and updates the indexes, the
+// dependency history, the tombstone, and the provider index to match, as of
+// the given time in seconds since the Unix epoch. The provider of the row is
+// recorded as of the given commit. The caller must hold g.mu.
+func (g *Graph) store(ctx context.Context, row *Row, commit string, now int64) error {
+	if err := g.st.Store(ctx, row.ImportPath, row); err != nil {
+		return err
+	} else if err := g.index(ctx, row); err != nil {
+		return err
+	} else if err := g.indexImporters(ctx, row); err != nil {
+		return err
+	} else if err := g.recordEdges(ctx, row.ImportPath, row.Directs, now); err != nil {
+		return err
+	} else if err := g.clearTombstone(ctx, row.ImportPath); err != nil {
+		return err
+	}
+	return g.addProvider(ctx, row.ImportPath, row.Repository, commit, now)
+}
+
+// Row loads the complete row for the specified import path. It reports an
+// error if the row was written with a newer schema than this package supports.
 func (g *Graph) Row(ctx context.Context, pkg string) (*Row, error) {
 	var row Row
 	if err := g.st.Load(ctx, pkg, &row); err != nil {
 		return nil, err
+	} else if row.Schema > SchemaVersion {
+		return nil, &SchemaError{Key: pkg, Version: row.Schema}
 	}
 	return &row, nil
 }
@@ -66,7 +222,17 @@ func (g *Graph) Row(ctx context.Context, pkg string) (*Row, error) {
 // If f reports an error, scanning terminates. If the error is ErrStopScan Scan
 // returns nil; otherwise Scan returns the error from f.
 func (g *Graph) Scan(ctx context.Context, prefix string, f func(*Row) error) error {
+	return g.ScanAfter(ctx, prefix, "", f)
+}
+
+// ScanAfter behaves as Scan, but skips the packages whose import paths are not
+// greater than after, without reading their rows. Passing the last import
+// path visited by a scan that was stopped resumes it where it left off.
+func (g *Graph) ScanAfter(ctx context.Context, prefix, after string, f func(*Row) error) error {
 	err := g.st.Scan(ctx, prefix, func(key string) error {
+		if key <= after || isAux(key) {
+			return nil // already visited, or not a package row
+		}
 		row, err := g.Row(ctx, key)
 		if err != nil {
 			return err
@@ -91,22 +257,153 @@ func (g *Graph) Imports(ctx context.Context, pkg string) ([]string, error) {
 }
 
 // Importers calls f with the import path of each package that directly depends
-// on pkg. The order of results is unspecified.
+// on pkg. The order of results is unspecified. Importers uses the reverse
+// dependency index if ReindexImporters has built it, and otherwise scans
+// every row of the graph.
 func (g *Graph) Importers(ctx context.Context, pkg string, f func(string)) error {
-	return g.Scan(ctx, "", func(row *Row) error {
+	return g.ImportersAfter(ctx, pkg, "", func(ipath string) error {
+		f(ipath)
+		return nil
+	})
+}
+
+// ImportersAfter calls f with the import path of each package that directly
+// depends on pkg and whose import path is greater than after, in order of
+// import path. If f reports an error, the search terminates. If the error is
+// ErrStopScan ImportersAfter returns nil; otherwise it returns the error from
+// f. Passing the last import path reported resumes a stopped search.
+func (g *Graph) ImportersAfter(ctx context.Context, pkg, after string, f func(string) error) error {
+	if ok, err := g.reverseReady(ctx); err != nil {
+		return err
+	} else if ok {
+		return g.indexedImporters(ctx, pkg, after, f)
+	}
+	return g.ScanAfter(ctx, "", after, func(row *Row) error {
 		for _, elt := range row.Directs {
 			if elt == pkg {
-				f(row.ImportPath)
-				break
+				return f(row.ImportPath)
 			}
 		}
 		return nil
 	})
 }
 
+// Unresolved returns the import paths that are listed as direct dependencies
+// of packages in the graph but do not have rows of their own, mapped to the
+// number of packages that import them.
+func (g *Graph) Unresolved(ctx context.Context) (map[string]int, error) {
+	have := make(map[string]bool)
+	want := make(map[string]int)
+	if err := g.Scan(ctx, "", func(row *Row) error {
+		have[row.ImportPath] = true
+		for _, dep := range row.Directs {
+			want[dep]++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for pkg := range have {
+		delete(want, pkg)
+	}
+	return want, nil
+}
+
+// Closure calls f with the row of each package in the transitive closure of
+// direct dependencies of the specified root packages, including the roots
+// themselves. Each package is visited once, in breadth-first order. Packages
+// that do not have rows in the graph are skipped. If f reports an error,
+// traversal terminates. If the error is ErrStopScan Closure returns nil;
+// otherwise Closure returns the error from f.
+//
+// Closure treats the graph as a closed world: the traversal stops at packages
+// that are not in the graph. Use OpenClosure to find out which they were.
+func (g *Graph) Closure(ctx context.Context, roots []string, f func(*Row) error) error {
+	_, err := g.OpenClosure(ctx, roots, f)
+	return err
+}
+
+// ClosureStats summarizes the completeness of a closure computation.
+type ClosureStats struct {
+	Visited int // the number of packages found in the graph
+
+	// The packages reached that are not in the graph, mapped to the number of
+	// packages in the closure that import them. Roots not in the graph have
+	// a count of zero unless they are also imported.
+	Missing map[string]int
+}
+
+// Complete reports whether every package reached was found in the graph.
+func (c *ClosureStats) Complete() bool { return len(c.Missing) == 0 }
+
+// OpenClosure behaves as Closure, but also reports the packages reached by the
+// traversal that do not have rows in the graph, so that the caller can tell how
+// complete the closure is. The stats are valid even if f stops the traversal
+// early, but then only cover the packages visited so far.
+func (g *Graph) OpenClosure(ctx context.Context, roots []string, f func(*Row) error) (*ClosureStats, error) {
+	stats := &ClosureStats{Missing: make(map[string]int)}
+	importers := make(map[string]int) // :: import path → visited importers
+	defer func() {
+		for pkg := range stats.Missing {
+			stats.Missing[pkg] = importers[pkg]
+		}
+	}()
+
+	seen := make(map[string]bool)
+	queue := append([]string(nil), roots...)
+	for _, root := range roots {
+		seen[root] = true
+	}
+	for len(queue) != 0 {
+		next := queue[0]
+		queue = queue[1:]
+		row, err := g.Row(ctx, next)
+		if err == ErrNotFound {
+			stats.Missing[next] = 0
+			continue
+		} else if err != nil {
+			return stats, err
+		}
+		stats.Visited++
+		if err := f(row); err == ErrStopScan {
+			return stats, nil
+		} else if err != nil {
+			return stats, err
+		}
+		for _, dep := range row.Directs {
+			importers[dep]++
+			if !seen[dep] {
+				seen[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return stats, nil
+}
+
+// Rows other than package rows are stored in the same key space as packages,
+// under keys that begin with a prefix that is not a valid import path.
+const (
+	auxPrefix        = "@"
+	modulePrefix     = auxPrefix + "module/"
+	quarantinePrefix = auxPrefix + "quarantine/"
+	providerPrefix   = auxPrefix + "provider/"
+	pendingPrefix    = auxPrefix + "pending/"
+	searchPrefix     = auxPrefix + "search/"
+	edgePrefix       = auxPrefix + "edges/"
+	tombstonePrefix  = auxPrefix + "deleted/"
+	importerPrefix   = auxPrefix + "importers/"
+	versionKey       = auxPrefix + "version"
+)
+
+// isAux reports whether key belongs to an auxiliary table rather than being
+// the key of a package row.
+func isAux(key string) bool { return strings.HasPrefix(key, auxPrefix) }
+
 // Storage represents the interface to persistent storage.
 type Storage interface {
 	// Load reads the data for the specified key and unmarshals it into val.
+	// If the key is not found, Load must report ErrNotFound.
 	Load(ctx context.Context, key string, val proto.Message) error
 
 	// Store marshals the data from value and stores it under key.
@@ -115,6 +412,30 @@ type Storage interface {
 	// Scan calls f with each key having the specified prefix. If f reports an
 	// error that error is propagated to the caller of Scan.
 	Scan(ctx context.Context, prefix string, f func(string) error) error
+
+	// Delete removes the specified key. If the key is not found, Delete must
+	// report ErrNotFound.
+	Delete(ctx context.Context, key string) error
+
+	// Rename moves the data stored under oldKey to newKey, replacing any
+	// existing data under newKey. The data need not be a valid message.
+	// If oldKey is not found, Rename must report ErrNotFound.
+	Rename(ctx context.Context, oldKey, newKey string) error
+}
+
+// ErrNotFound is reported by Storage.Load when the requested key is not found.
+var ErrNotFound = errors.New("key not found")
+
+// SchemaError is reported when a row was written with a newer schema than
+// this package supports.
+type SchemaError struct {
+	Key     string // the key of the row
+	Version int32  // the schema version of the row
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("row %q has schema version %d, newer than supported (%d)",
+		e.Key, e.Version, SchemaVersion)
 }
 
 // ErrStopScan is returned by the callback to Scan to signal that scanning
@@ -20,6 +20,83 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// An ImportClass describes the relationship between a package and one of its
+// direct dependencies. The values match deps.ImportClass.
+type ImportClass int32
+
+const (
+	ImportClass_UNCLASSIFIED    ImportClass = 0
+	ImportClass_STDLIB          ImportClass = 1
+	ImportClass_SAME_MODULE     ImportClass = 2
+	ImportClass_SAME_REPOSITORY ImportClass = 3
+	ImportClass_EXTERNAL        ImportClass = 4
+)
+
+var ImportClass_name = map[int32]string{
+	0: "UNCLASSIFIED",
+	1: "STDLIB",
+	2: "SAME_MODULE",
+	3: "SAME_REPOSITORY",
+	4: "EXTERNAL",
+}
+
+var ImportClass_value = map[string]int32{
+	"UNCLASSIFIED":    0,
+	"STDLIB":          1,
+	"SAME_MODULE":     2,
+	"SAME_REPOSITORY": 3,
+	"EXTERNAL":        4,
+}
+
+func (x ImportClass) String() string {
+	return proto.EnumName(ImportClass_name, int32(x))
+}
+
+func (ImportClass) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{0}
+}
+
+// A JournalOp identifies the graph mutation recorded by a journal entry.
+type JournalOp int32
+
+const (
+	JournalOp_UNKNOWN    JournalOp = 0
+	JournalOp_ADD        JournalOp = 1
+	JournalOp_ADD_MODULE JournalOp = 2
+	JournalOp_PUT        JournalOp = 3
+	JournalOp_REMOVE     JournalOp = 4
+	JournalOp_DELETE     JournalOp = 5
+	JournalOp_QUARANTINE JournalOp = 6
+)
+
+var JournalOp_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "ADD",
+	2: "ADD_MODULE",
+	3: "PUT",
+	4: "REMOVE",
+	5: "DELETE",
+	6: "QUARANTINE",
+}
+
+var JournalOp_value = map[string]int32{
+	"UNKNOWN":    0,
+	"ADD":        1,
+	"ADD_MODULE": 2,
+	"PUT":        3,
+	"REMOVE":     4,
+	"DELETE":     5,
+	"QUARANTINE": 6,
+}
+
+func (x JournalOp) String() string {
+	return proto.EnumName(JournalOp_name, int32(x))
+}
+
+func (JournalOp) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{1}
+}
+
 // A Row is a single row of the dependency graph adjacency list.
 type Row struct {
 	// The simple name and import path of the package whose row this is.
@@ -28,7 +105,55 @@ type Row struct {
 	// The repository where the package was defined.
 	Repository string `protobuf:"bytes,3,opt,name=repository,proto3" json:"repository,omitempty"`
 	// The import paths of the direct dependencies of source.
-	Directs              []string `protobuf:"bytes,4,rep,name=directs,proto3" json:"directs,omitempty"`
+	Directs []string `protobuf:"bytes,4,rep,name=directs,proto3" json:"directs,omitempty"`
+	// The module containing this package, as path@version, if known.
+	Module string `protobuf:"bytes,5,opt,name=module,proto3" json:"module,omitempty"`
+	// Whether the package directly imports these low-level packages.
+	UsesUnsafe  bool `protobuf:"varint,6,opt,name=uses_unsafe,json=usesUnsafe,proto3" json:"uses_unsafe,omitempty"`
+	UsesReflect bool `protobuf:"varint,7,opt,name=uses_reflect,json=usesReflect,proto3" json:"uses_reflect,omitempty"`
+	UsesSyscall bool `protobuf:"varint,8,opt,name=uses_syscall,json=usesSyscall,proto3" json:"uses_syscall,omitempty"`
+	// The direct dependencies that belong to the standard library. Depending on
+	// how the package was scanned, these may or may not also appear in directs.
+	StdDirects []string `protobuf:"bytes,9,rep,name=std_directs,json=stdDirects,proto3" json:"std_directs,omitempty"`
+	// The classification of each entry of directs, in the same order.
+	Classes []ImportClass `protobuf:"varint,10,rep,packed,name=classes,proto3,enum=graph.ImportClass" json:"classes,omitempty"`
+	// The owners of the package, if known.
+	Owners []string `protobuf:"bytes,11,rep,name=owners,proto3" json:"owners,omitempty"`
+	// The version of the schema this row was written with. Zero means the row
+	// predates schema versioning. See graph.SchemaVersion.
+	Schema int32 `protobuf:"varint,12,opt,name=schema,proto3" json:"schema,omitempty"`
+	// The number of references to exported identifiers of each entry of
+	// directs, in the same order, if known.
+	Refs []int64 `protobuf:"varint,13,rep,packed,name=refs,proto3" json:"refs,omitempty"`
+	// The number of distinct exported identifiers of each entry of directs that
+	// are referenced, in the same order, if known. A dependency of which only
+	// one or two names are used may be easy to remove.
+	Symbols []int64 `protobuf:"varint,14,rep,packed,name=symbols,proto3" json:"symbols,omitempty"`
+	// The language or package ecosystem of the package, if it is not a Go
+	// package, for example "npm".
+	Language string `protobuf:"bytes,15,opt,name=language,proto3" json:"language,omitempty"`
+	// Labels attached to the repository of the package when it was scanned,
+	// for example by an input manifest.
+	Labels map[string]string `protobuf:"bytes,16,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The location of each import declaration in the source files of the
+	// package, if they were recorded.
+	Sites []*ImportSite `protobuf:"bytes,17,rep,name=sites,proto3" json:"sites,omitempty"`
+	// The direct dependencies that are imported only for their side effects, as
+	// blank (_) imports, and those that are dot (.) imported in any file.
+	BlankDirects []string `protobuf:"bytes,18,rep,name=blank_directs,json=blankDirects,proto3" json:"blank_directs,omitempty"`
+	DotDirects   []string `protobuf:"bytes,19,rep,name=dot_directs,json=dotDirects,proto3" json:"dot_directs,omitempty"`
+	// A digest of the contents of the package, if one was recorded, as the name
+	// of the hash algorithm and the hex-encoded digest separated by a colon,
+	// for example "sha256:9f86...". Copies of a package have the same digest.
+	Digest string `protobuf:"bytes,20,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The SPDX identifier of the license of the repository, if known.
+	License string `protobuf:"bytes,21,opt,name=license,proto3" json:"license,omitempty"`
+	// When the most recent commit to the repository was made, in seconds since
+	// the Unix epoch, if its activity was recorded.
+	LastCommit int64 `protobuf:"varint,22,opt,name=last_commit,json=lastCommit,proto3" json:"last_commit,omitempty"`
+	// When the package was deleted from the graph, in seconds since the Unix
+	// epoch. This is set only on the tombstone that replaces a deleted row.
+	Deleted              int64    `protobuf:"varint,23,opt,name=deleted,proto3" json:"deleted,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -87,21 +212,1009 @@ func (m *Row) GetDirects() []string {
 	return nil
 }
 
+func (m *Row) GetModule() string {
+	if m != nil {
+		return m.Module
+	}
+	return ""
+}
+
+func (m *Row) GetUsesUnsafe() bool {
+	if m != nil {
+		return m.UsesUnsafe
+	}
+	return false
+}
+
+func (m *Row) GetUsesReflect() bool {
+	if m != nil {
+		return m.UsesReflect
+	}
+	return false
+}
+
+func (m *Row) GetUsesSyscall() bool {
+	if m != nil {
+		return m.UsesSyscall
+	}
+	return false
+}
+
+func (m *Row) GetStdDirects() []string {
+	if m != nil {
+		return m.StdDirects
+	}
+	return nil
+}
+
+func (m *Row) GetClasses() []ImportClass {
+	if m != nil {
+		return m.Classes
+	}
+	return nil
+}
+
+func (m *Row) GetOwners() []string {
+	if m != nil {
+		return m.Owners
+	}
+	return nil
+}
+
+func (m *Row) GetSchema() int32 {
+	if m != nil {
+		return m.Schema
+	}
+	return 0
+}
+
+func (m *Row) GetRefs() []int64 {
+	if m != nil {
+		return m.Refs
+	}
+	return nil
+}
+
+func (m *Row) GetSymbols() []int64 {
+	if m != nil {
+		return m.Symbols
+	}
+	return nil
+}
+
+func (m *Row) GetLanguage() string {
+	if m != nil {
+		return m.Language
+	}
+	return ""
+}
+
+func (m *Row) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Row) GetSites() []*ImportSite {
+	if m != nil {
+		return m.Sites
+	}
+	return nil
+}
+
+func (m *Row) GetBlankDirects() []string {
+	if m != nil {
+		return m.BlankDirects
+	}
+	return nil
+}
+
+func (m *Row) GetDotDirects() []string {
+	if m != nil {
+		return m.DotDirects
+	}
+	return nil
+}
+
+func (m *Row) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *Row) GetLicense() string {
+	if m != nil {
+		return m.License
+	}
+	return ""
+}
+
+func (m *Row) GetLastCommit() int64 {
+	if m != nil {
+		return m.LastCommit
+	}
+	return 0
+}
+
+func (m *Row) GetDeleted() int64 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
+// A Module is a single node of the module version graph. Each version of a
+// module has its own node, and edges record requirements on specific versions
+// of other modules.
+type Module struct {
+	Path                 string         `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version              string         `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Repository           string         `protobuf:"bytes,3,opt,name=repository,proto3" json:"repository,omitempty"`
+	Requires             []*Requirement `protobuf:"bytes,4,rep,name=requires,proto3" json:"requires,omitempty"`
+	Excludes             []*Requirement `protobuf:"bytes,5,rep,name=excludes,proto3" json:"excludes,omitempty"`
+	Replaces             []*Replacement `protobuf:"bytes,6,rep,name=replaces,proto3" json:"replaces,omitempty"`
+	GoVersion            string         `protobuf:"bytes,7,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	Toolchain            string         `protobuf:"bytes,8,opt,name=toolchain,proto3" json:"toolchain,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Module) Reset()         { *m = Module{} }
+func (m *Module) String() string { return proto.CompactTextString(m) }
+func (*Module) ProtoMessage()    {}
+func (*Module) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{1}
+}
+
+func (m *Module) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Module.Unmarshal(m, b)
+}
+func (m *Module) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Module.Marshal(b, m, deterministic)
+}
+func (m *Module) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Module.Merge(m, src)
+}
+func (m *Module) XXX_Size() int {
+	return xxx_messageInfo_Module.Size(m)
+}
+func (m *Module) XXX_DiscardUnknown() {
+	xxx_messageInfo_Module.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Module proto.InternalMessageInfo
+
+func (m *Module) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Module) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Module) GetRepository() string {
+	if m != nil {
+		return m.Repository
+	}
+	return ""
+}
+
+func (m *Module) GetRequires() []*Requirement {
+	if m != nil {
+		return m.Requires
+	}
+	return nil
+}
+
+func (m *Module) GetExcludes() []*Requirement {
+	if m != nil {
+		return m.Excludes
+	}
+	return nil
+}
+
+func (m *Module) GetReplaces() []*Replacement {
+	if m != nil {
+		return m.Replaces
+	}
+	return nil
+}
+
+func (m *Module) GetGoVersion() string {
+	if m != nil {
+		return m.GoVersion
+	}
+	return ""
+}
+
+func (m *Module) GetToolchain() string {
+	if m != nil {
+		return m.Toolchain
+	}
+	return ""
+}
+
+// A Requirement is a module path and version.
+type Requirement struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Indirect             bool     `protobuf:"varint,3,opt,name=indirect,proto3" json:"indirect,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Requirement) Reset()         { *m = Requirement{} }
+func (m *Requirement) String() string { return proto.CompactTextString(m) }
+func (*Requirement) ProtoMessage()    {}
+func (*Requirement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{2}
+}
+
+func (m *Requirement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Requirement.Unmarshal(m, b)
+}
+func (m *Requirement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Requirement.Marshal(b, m, deterministic)
+}
+func (m *Requirement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Requirement.Merge(m, src)
+}
+func (m *Requirement) XXX_Size() int {
+	return xxx_messageInfo_Requirement.Size(m)
+}
+func (m *Requirement) XXX_DiscardUnknown() {
+	xxx_messageInfo_Requirement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Requirement proto.InternalMessageInfo
+
+func (m *Requirement) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Requirement) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Requirement) GetIndirect() bool {
+	if m != nil {
+		return m.Indirect
+	}
+	return false
+}
+
+// A Replacement records a replace directive.
+type Replacement struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Version              string   `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	NewPath              string   `protobuf:"bytes,3,opt,name=new_path,json=newPath,proto3" json:"new_path,omitempty"`
+	NewVersion           string   `protobuf:"bytes,4,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Replacement) Reset()         { *m = Replacement{} }
+func (m *Replacement) String() string { return proto.CompactTextString(m) }
+func (*Replacement) ProtoMessage()    {}
+func (*Replacement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{3}
+}
+
+func (m *Replacement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Replacement.Unmarshal(m, b)
+}
+func (m *Replacement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Replacement.Marshal(b, m, deterministic)
+}
+func (m *Replacement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Replacement.Merge(m, src)
+}
+func (m *Replacement) XXX_Size() int {
+	return xxx_messageInfo_Replacement.Size(m)
+}
+func (m *Replacement) XXX_DiscardUnknown() {
+	xxx_messageInfo_Replacement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Replacement proto.InternalMessageInfo
+
+func (m *Replacement) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Replacement) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Replacement) GetNewPath() string {
+	if m != nil {
+		return m.NewPath
+	}
+	return ""
+}
+
+func (m *Replacement) GetNewVersion() string {
+	if m != nil {
+		return m.NewVersion
+	}
+	return ""
+}
+
+// Providers records the repositories that have defined a package with a given
+// import path. A package may be provided by several repositories, for example
+// by forks or copies of the same code.
+type Providers struct {
+	Providers            []*Provider `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Providers) Reset()         { *m = Providers{} }
+func (m *Providers) String() string { return proto.CompactTextString(m) }
+func (*Providers) ProtoMessage()    {}
+func (*Providers) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{4}
+}
+
+func (m *Providers) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Providers.Unmarshal(m, b)
+}
+func (m *Providers) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Providers.Marshal(b, m, deterministic)
+}
+func (m *Providers) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Providers.Merge(m, src)
+}
+func (m *Providers) XXX_Size() int {
+	return xxx_messageInfo_Providers.Size(m)
+}
+func (m *Providers) XXX_DiscardUnknown() {
+	xxx_messageInfo_Providers.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Providers proto.InternalMessageInfo
+
+func (m *Providers) GetProviders() []*Provider {
+	if m != nil {
+		return m.Providers
+	}
+	return nil
+}
+
+// A Provider records a repository that defines a package.
+type Provider struct {
+	Repository           string   `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	Commit               string   `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	Updated              int64    `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Provider) Reset()         { *m = Provider{} }
+func (m *Provider) String() string { return proto.CompactTextString(m) }
+func (*Provider) ProtoMessage()    {}
+func (*Provider) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{5}
+}
+
+func (m *Provider) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Provider.Unmarshal(m, b)
+}
+func (m *Provider) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Provider.Marshal(b, m, deterministic)
+}
+func (m *Provider) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Provider.Merge(m, src)
+}
+func (m *Provider) XXX_Size() int {
+	return xxx_messageInfo_Provider.Size(m)
+}
+func (m *Provider) XXX_DiscardUnknown() {
+	xxx_messageInfo_Provider.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Provider proto.InternalMessageInfo
+
+func (m *Provider) GetRepository() string {
+	if m != nil {
+		return m.Repository
+	}
+	return ""
+}
+
+func (m *Provider) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+func (m *Provider) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+// A Pending records a repository waiting in the crawl queue, because it is
+// expected to define packages that are imported but missing from the graph.
+type Pending struct {
+	Root                 string   `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Url                  string   `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Packages             []string `protobuf:"bytes,3,rep,name=packages,proto3" json:"packages,omitempty"`
+	Importers            int64    `protobuf:"varint,4,opt,name=importers,proto3" json:"importers,omitempty"`
+	Added                int64    `protobuf:"varint,5,opt,name=added,proto3" json:"added,omitempty"`
+	Attempted            int64    `protobuf:"varint,6,opt,name=attempted,proto3" json:"attempted,omitempty"`
+	Attempts             int64    `protobuf:"varint,7,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Error                string   `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Pending) Reset()         { *m = Pending{} }
+func (m *Pending) String() string { return proto.CompactTextString(m) }
+func (*Pending) ProtoMessage()    {}
+func (*Pending) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{6}
+}
+
+func (m *Pending) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Pending.Unmarshal(m, b)
+}
+func (m *Pending) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Pending.Marshal(b, m, deterministic)
+}
+func (m *Pending) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Pending.Merge(m, src)
+}
+func (m *Pending) XXX_Size() int {
+	return xxx_messageInfo_Pending.Size(m)
+}
+func (m *Pending) XXX_DiscardUnknown() {
+	xxx_messageInfo_Pending.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Pending proto.InternalMessageInfo
+
+func (m *Pending) GetRoot() string {
+	if m != nil {
+		return m.Root
+	}
+	return ""
+}
+
+func (m *Pending) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Pending) GetPackages() []string {
+	if m != nil {
+		return m.Packages
+	}
+	return nil
+}
+
+func (m *Pending) GetImporters() int64 {
+	if m != nil {
+		return m.Importers
+	}
+	return 0
+}
+
+func (m *Pending) GetAdded() int64 {
+	if m != nil {
+		return m.Added
+	}
+	return 0
+}
+
+func (m *Pending) GetAttempted() int64 {
+	if m != nil {
+		return m.Attempted
+	}
+	return 0
+}
+
+func (m *Pending) GetAttempts() int64 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *Pending) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// An ImportSite records where a source file imports a package.
+type ImportSite struct {
+	// The import path as written in the source file.
+	ImportPath string `protobuf:"bytes,1,opt,name=import_path,json=importPath,proto3" json:"import_path,omitempty"`
+	// The path of the file relative to the enclosing repository root.
+	RepoPath string `protobuf:"bytes,2,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	// The line and column (in bytes) where the import path begins, from 1.
+	Line                 int32    `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Column               int32    `protobuf:"varint,4,opt,name=column,proto3" json:"column,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportSite) Reset()         { *m = ImportSite{} }
+func (m *ImportSite) String() string { return proto.CompactTextString(m) }
+func (*ImportSite) ProtoMessage()    {}
+func (*ImportSite) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{7}
+}
+
+func (m *ImportSite) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportSite.Unmarshal(m, b)
+}
+func (m *ImportSite) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportSite.Marshal(b, m, deterministic)
+}
+func (m *ImportSite) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportSite.Merge(m, src)
+}
+func (m *ImportSite) XXX_Size() int {
+	return xxx_messageInfo_ImportSite.Size(m)
+}
+func (m *ImportSite) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportSite.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportSite proto.InternalMessageInfo
+
+func (m *ImportSite) GetImportPath() string {
+	if m != nil {
+		return m.ImportPath
+	}
+	return ""
+}
+
+func (m *ImportSite) GetRepoPath() string {
+	if m != nil {
+		return m.RepoPath
+	}
+	return ""
+}
+
+func (m *ImportSite) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *ImportSite) GetColumn() int32 {
+	if m != nil {
+		return m.Column
+	}
+	return 0
+}
+
+// An IndexEntry is stored under each key of an auxiliary index. The key itself
+// carries the indexed value and the import path of the package.
+type IndexEntry struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IndexEntry) Reset()         { *m = IndexEntry{} }
+func (m *IndexEntry) String() string { return proto.CompactTextString(m) }
+func (*IndexEntry) ProtoMessage()    {}
+func (*IndexEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{8}
+}
+
+func (m *IndexEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IndexEntry.Unmarshal(m, b)
+}
+func (m *IndexEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IndexEntry.Marshal(b, m, deterministic)
+}
+func (m *IndexEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexEntry.Merge(m, src)
+}
+func (m *IndexEntry) XXX_Size() int {
+	return xxx_messageInfo_IndexEntry.Size(m)
+}
+func (m *IndexEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IndexEntry proto.InternalMessageInfo
+
+// EdgeHistory records when each dependency of a package was first and last
+// observed, over repeated scans of the package.
+type EdgeHistory struct {
+	Updated              int64    `protobuf:"varint,1,opt,name=updated,proto3" json:"updated,omitempty"`
+	Edges                []*Edge  `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EdgeHistory) Reset()         { *m = EdgeHistory{} }
+func (m *EdgeHistory) String() string { return proto.CompactTextString(m) }
+func (*EdgeHistory) ProtoMessage()    {}
+func (*EdgeHistory) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{9}
+}
+
+func (m *EdgeHistory) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EdgeHistory.Unmarshal(m, b)
+}
+func (m *EdgeHistory) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EdgeHistory.Marshal(b, m, deterministic)
+}
+func (m *EdgeHistory) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EdgeHistory.Merge(m, src)
+}
+func (m *EdgeHistory) XXX_Size() int {
+	return xxx_messageInfo_EdgeHistory.Size(m)
+}
+func (m *EdgeHistory) XXX_DiscardUnknown() {
+	xxx_messageInfo_EdgeHistory.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EdgeHistory proto.InternalMessageInfo
+
+func (m *EdgeHistory) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+func (m *EdgeHistory) GetEdges() []*Edge {
+	if m != nil {
+		return m.Edges
+	}
+	return nil
+}
+
+// An Edge records the observations of one dependency of a package.
+type Edge struct {
+	Target               string   `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	FirstSeen            int64    `protobuf:"varint,2,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen             int64    `protobuf:"varint,3,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Dropped              bool     `protobuf:"varint,4,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Edge) Reset()         { *m = Edge{} }
+func (m *Edge) String() string { return proto.CompactTextString(m) }
+func (*Edge) ProtoMessage()    {}
+func (*Edge) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{10}
+}
+
+func (m *Edge) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Edge.Unmarshal(m, b)
+}
+func (m *Edge) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Edge.Marshal(b, m, deterministic)
+}
+func (m *Edge) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Edge.Merge(m, src)
+}
+func (m *Edge) XXX_Size() int {
+	return xxx_messageInfo_Edge.Size(m)
+}
+func (m *Edge) XXX_DiscardUnknown() {
+	xxx_messageInfo_Edge.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Edge proto.InternalMessageInfo
+
+func (m *Edge) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *Edge) GetFirstSeen() int64 {
+	if m != nil {
+		return m.FirstSeen
+	}
+	return 0
+}
+
+func (m *Edge) GetLastSeen() int64 {
+	if m != nil {
+		return m.LastSeen
+	}
+	return 0
+}
+
+func (m *Edge) GetDropped() bool {
+	if m != nil {
+		return m.Dropped
+	}
+	return false
+}
+
+// A JournalEntry records one mutation of the graph. A journal is a sequence of
+// entries in the order the mutations were applied.
+type JournalEntry struct {
+	Seq  int64     `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Time int64     `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	Op   JournalOp `protobuf:"varint,3,opt,name=op,proto3,enum=graph.JournalOp" json:"op,omitempty"`
+	Key  string    `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	// The encoded deps.Repo for ADD and ADD_MODULE, without its packages and
+	// modules, the encoded deps.Package for ADD, and the encoded deps.Module
+	// for ADD_MODULE.
+	Repo                 []byte   `protobuf:"bytes,5,opt,name=repo,proto3" json:"repo,omitempty"`
+	Package              []byte   `protobuf:"bytes,6,opt,name=package,proto3" json:"package,omitempty"`
+	Module               []byte   `protobuf:"bytes,7,opt,name=module,proto3" json:"module,omitempty"`
+	Row                  *Row     `protobuf:"bytes,8,opt,name=row,proto3" json:"row,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalEntry) Reset()         { *m = JournalEntry{} }
+func (m *JournalEntry) String() string { return proto.CompactTextString(m) }
+func (*JournalEntry) ProtoMessage()    {}
+func (*JournalEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{11}
+}
+
+func (m *JournalEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalEntry.Unmarshal(m, b)
+}
+func (m *JournalEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalEntry.Marshal(b, m, deterministic)
+}
+func (m *JournalEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalEntry.Merge(m, src)
+}
+func (m *JournalEntry) XXX_Size() int {
+	return xxx_messageInfo_JournalEntry.Size(m)
+}
+func (m *JournalEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalEntry proto.InternalMessageInfo
+
+func (m *JournalEntry) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *JournalEntry) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *JournalEntry) GetOp() JournalOp {
+	if m != nil {
+		return m.Op
+	}
+	return JournalOp_UNKNOWN
+}
+
+func (m *JournalEntry) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *JournalEntry) GetRepo() []byte {
+	if m != nil {
+		return m.Repo
+	}
+	return nil
+}
+
+func (m *JournalEntry) GetPackage() []byte {
+	if m != nil {
+		return m.Package
+	}
+	return nil
+}
+
+func (m *JournalEntry) GetModule() []byte {
+	if m != nil {
+		return m.Module
+	}
+	return nil
+}
+
+func (m *JournalEntry) GetRow() *Row {
+	if m != nil {
+		return m.Row
+	}
+	return nil
+}
+
+// A GraphVersion records the version of the graph, a counter that increases
+// with each batch of mutations, so that readers can tell whether the graph
+// has changed since they last read it.
+type GraphVersion struct {
+	Version              int64    `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Updated              int64    `protobuf:"varint,2,opt,name=updated,proto3" json:"updated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GraphVersion) Reset()         { *m = GraphVersion{} }
+func (m *GraphVersion) String() string { return proto.CompactTextString(m) }
+func (*GraphVersion) ProtoMessage()    {}
+func (*GraphVersion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3e4c656902fc0e6b, []int{12}
+}
+
+func (m *GraphVersion) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GraphVersion.Unmarshal(m, b)
+}
+func (m *GraphVersion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GraphVersion.Marshal(b, m, deterministic)
+}
+func (m *GraphVersion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GraphVersion.Merge(m, src)
+}
+func (m *GraphVersion) XXX_Size() int {
+	return xxx_messageInfo_GraphVersion.Size(m)
+}
+func (m *GraphVersion) XXX_DiscardUnknown() {
+	xxx_messageInfo_GraphVersion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GraphVersion proto.InternalMessageInfo
+
+func (m *GraphVersion) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *GraphVersion) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
 func init() {
+	proto.RegisterEnum("graph.ImportClass", ImportClass_name, ImportClass_value)
+	proto.RegisterEnum("graph.JournalOp", JournalOp_name, JournalOp_value)
 	proto.RegisterType((*Row)(nil), "graph.Row")
+	proto.RegisterMapType((map[string]string)(nil), "graph.Row.LabelsEntry")
+	proto.RegisterType((*Module)(nil), "graph.Module")
+	proto.RegisterType((*Requirement)(nil), "graph.Requirement")
+	proto.RegisterType((*Replacement)(nil), "graph.Replacement")
+	proto.RegisterType((*Providers)(nil), "graph.Providers")
+	proto.RegisterType((*Provider)(nil), "graph.Provider")
+	proto.RegisterType((*Pending)(nil), "graph.Pending")
+	proto.RegisterType((*ImportSite)(nil), "graph.ImportSite")
+	proto.RegisterType((*IndexEntry)(nil), "graph.IndexEntry")
+	proto.RegisterType((*EdgeHistory)(nil), "graph.EdgeHistory")
+	proto.RegisterType((*Edge)(nil), "graph.Edge")
+	proto.RegisterType((*JournalEntry)(nil), "graph.JournalEntry")
+	proto.RegisterType((*GraphVersion)(nil), "graph.GraphVersion")
 }
 
 func init() { proto.RegisterFile("graph.proto", fileDescriptor_3e4c656902fc0e6b) }
 
 var fileDescriptor_3e4c656902fc0e6b = []byte{
-	// 135 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4e, 0x2f, 0x4a, 0x2c,
-	0xc8, 0xd0, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0x73, 0x94, 0x4a, 0xb8, 0x98, 0x83,
-	0xf2, 0xcb, 0x85, 0x84, 0xb8, 0x58, 0xf2, 0x12, 0x73, 0x53, 0x25, 0x18, 0x15, 0x18, 0x35, 0x38,
-	0x83, 0xc0, 0x6c, 0x21, 0x79, 0x2e, 0xee, 0xcc, 0xdc, 0x82, 0xfc, 0xa2, 0x92, 0xf8, 0x82, 0xc4,
-	0x92, 0x0c, 0x09, 0x26, 0xb0, 0x14, 0x17, 0x44, 0x28, 0x20, 0xb1, 0x24, 0x43, 0x48, 0x8e, 0x8b,
-	0xab, 0x28, 0xb5, 0x20, 0xbf, 0x38, 0xb3, 0x24, 0xbf, 0xa8, 0x52, 0x82, 0x19, 0x22, 0x8f, 0x10,
-	0x11, 0x92, 0xe0, 0x62, 0x4f, 0xc9, 0x2c, 0x4a, 0x4d, 0x2e, 0x29, 0x96, 0x60, 0x51, 0x60, 0xd6,
-	0xe0, 0x0c, 0x82, 0x71, 0x93, 0xd8, 0xc0, 0x6e, 0x30, 0x06, 0x04, 0x00, 0x00, 0xff, 0xff, 0x6d,
-	0xc2, 0x78, 0x4f, 0x92, 0x00, 0x00, 0x00,
+	// 1166 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x95, 0x56, 0x5f, 0x73, 0xdb, 0x44,
+	0x10, 0xc7, 0xff, 0xed, 0x95, 0x9b, 0xb8, 0x6a, 0x09, 0xa2, 0x14, 0x08, 0xe2, 0x01, 0xa6, 0x03,
+	0x79, 0x08, 0x2f, 0xd0, 0xb7, 0x34, 0x16, 0xe0, 0xe2, 0x38, 0xe1, 0xec, 0xb4, 0x30, 0xc3, 0x8c,
+	0x47, 0xb1, 0x2e, 0x8e, 0x26, 0xb2, 0x4e, 0x95, 0xe4, 0xa4, 0x79, 0xe6, 0xa3, 0xf0, 0x75, 0x98,
+	0xe1, 0x2b, 0xb1, 0xbb, 0x77, 0x27, 0x27, 0xe9, 0x30, 0x4c, 0xdf, 0xee, 0xf7, 0xdb, 0xbd, 0xfd,
+	0x7f, 0x2b, 0x81, 0xb3, 0xcc, 0xc3, 0xec, 0x62, 0x2f, 0xcb, 0x55, 0xa9, 0xdc, 0x16, 0x03, 0xff,
+	0xcf, 0x36, 0x34, 0x84, 0xba, 0x76, 0x5d, 0x68, 0xa6, 0xe1, 0x4a, 0x7a, 0xb5, 0xdd, 0xda, 0xd7,
+	0x3d, 0xc1, 0x67, 0xf7, 0x73, 0x70, 0xe2, 0x55, 0xa6, 0xf2, 0x72, 0x9e, 0x85, 0xe5, 0x85, 0x57,
+	0x67, 0x11, 0x68, 0xea, 0x04, 0x19, 0xf7, 0x33, 0x80, 0x5c, 0x66, 0xaa, 0x88, 0x4b, 0x95, 0xdf,
+	0x78, 0x0d, 0x2d, 0xdf, 0x30, 0xae, 0x07, 0x9d, 0x28, 0xce, 0xe5, 0xa2, 0x2c, 0xbc, 0xe6, 0x6e,
+	0x03, 0x85, 0x16, 0xba, 0x3b, 0xd0, 0x5e, 0xa9, 0x68, 0x9d, 0x48, 0xaf, 0xc5, 0xb7, 0x0c, 0x22,
+	0x97, 0xeb, 0x42, 0x16, 0xf3, 0x75, 0x5a, 0x84, 0xe7, 0xd2, 0x6b, 0xa3, 0xb0, 0x2b, 0x80, 0xa8,
+	0x53, 0x66, 0xdc, 0x2f, 0xa0, 0xcf, 0x0a, 0xb9, 0x3c, 0x4f, 0xd0, 0x92, 0xd7, 0x61, 0x0d, 0xbe,
+	0x24, 0x34, 0x55, 0xa9, 0x14, 0x37, 0xc5, 0x22, 0x4c, 0x12, 0xaf, 0xbb, 0x51, 0x99, 0x6a, 0x8a,
+	0xdc, 0x14, 0x65, 0x34, 0xb7, 0xc1, 0xf5, 0x38, 0x38, 0x40, 0x6a, 0x68, 0xe2, 0xfb, 0x06, 0x3a,
+	0x8b, 0x24, 0x2c, 0xf0, 0x8a, 0x07, 0x28, 0xdc, 0xda, 0x77, 0xf7, 0x74, 0xf1, 0x46, 0x9c, 0xfd,
+	0x21, 0xc9, 0x84, 0x55, 0xa1, 0x6c, 0xd4, 0x75, 0x2a, 0xf3, 0xc2, 0x73, 0xd8, 0x92, 0x41, 0xc4,
+	0x17, 0x8b, 0x0b, 0xb9, 0x0a, 0xbd, 0x3e, 0xc6, 0xd0, 0x12, 0x06, 0x51, 0xb1, 0x31, 0xfe, 0xc2,
+	0x7b, 0x80, 0xda, 0x0d, 0xc1, 0x67, 0xaa, 0x55, 0x71, 0xb3, 0x3a, 0x53, 0x49, 0xe1, 0x6d, 0x31,
+	0x6d, 0xa1, 0xfb, 0x04, 0xba, 0x49, 0x98, 0x2e, 0xd7, 0xe1, 0x52, 0x7a, 0xdb, 0x5c, 0xad, 0x0a,
+	0xbb, 0x7b, 0xd0, 0x4e, 0xc2, 0x33, 0x89, 0x97, 0x06, 0x78, 0xc9, 0xd9, 0xdf, 0x31, 0x61, 0x62,
+	0x4b, 0xf7, 0xc6, 0x2c, 0x08, 0xd2, 0x32, 0xbf, 0x11, 0x46, 0xcb, 0xfd, 0x0a, 0x5a, 0xd8, 0x1b,
+	0xcc, 0xea, 0x21, 0xab, 0x3f, 0xbc, 0x93, 0xd5, 0x14, 0x25, 0x42, 0xcb, 0xdd, 0x2f, 0xe1, 0xc1,
+	0x19, 0x7a, 0xb9, 0xac, 0x6a, 0xe4, 0x72, 0x66, 0x7d, 0x26, 0x6d, 0x95, 0xb0, 0x8c, 0x91, 0x2a,
+	0x2b, 0x95, 0x47, 0xba, 0x8c, 0x48, 0x0d, 0x37, 0x6d, 0x8e, 0xe2, 0xa5, 0x2c, 0x4a, 0xef, 0xb1,
+	0x6e, 0xb3, 0x46, 0x94, 0x6c, 0x12, 0x2f, 0x64, 0x5a, 0x48, 0xef, 0x43, 0x16, 0x58, 0x48, 0x26,
+	0xb1, 0xa8, 0xe5, 0x7c, 0xa1, 0x56, 0xab, 0xb8, 0xf4, 0x76, 0x50, 0xda, 0x10, 0x40, 0xd4, 0x21,
+	0x33, 0x3c, 0x53, 0x32, 0x91, 0xa5, 0x8c, 0xbc, 0x8f, 0x58, 0x68, 0xe1, 0x93, 0x1f, 0xc0, 0xb9,
+	0x95, 0xb2, 0x3b, 0x80, 0xc6, 0xa5, 0xbc, 0x31, 0x03, 0x4d, 0x47, 0xf7, 0x31, 0xb4, 0xae, 0xc2,
+	0x64, 0x2d, 0xcd, 0x24, 0x6b, 0xf0, 0xbc, 0xfe, 0x7d, 0xcd, 0xff, 0xab, 0x0e, 0xed, 0x23, 0x3d,
+	0x81, 0xd8, 0x1b, 0x9e, 0x76, 0xf3, 0x10, 0xe8, 0x4c, 0x3e, 0xaf, 0xb0, 0x9f, 0xb1, 0x4a, 0xcd,
+	0x55, 0x0b, 0xff, 0xf7, 0x05, 0xec, 0x41, 0x37, 0x97, 0x6f, 0xd6, 0x58, 0x0e, 0xfd, 0x04, 0x9c,
+	0x6a, 0x90, 0x84, 0xa6, 0x57, 0x32, 0x2d, 0x45, 0xa5, 0x43, 0xfa, 0xf2, 0xed, 0x22, 0x59, 0x47,
+	0xa8, 0xdf, 0xfa, 0x6f, 0x7d, 0xab, 0xa3, 0xed, 0x67, 0x49, 0xb8, 0x40, 0xfd, 0xf6, 0x3d, 0x7d,
+	0xa6, 0xad, 0x7d, 0xad, 0xe3, 0x7e, 0x0a, 0xb0, 0x54, 0x73, 0x9b, 0x4c, 0x87, 0xe3, 0xed, 0x2d,
+	0xd5, 0x2b, 0x93, 0xce, 0x53, 0xe8, 0x95, 0x4a, 0x25, 0x8b, 0x8b, 0x30, 0x4e, 0xf9, 0xdd, 0xa0,
+	0xb4, 0x22, 0xfc, 0xd7, 0xe0, 0xdc, 0x8a, 0xe2, 0x3d, 0x2b, 0x85, 0x53, 0x1c, 0xa7, 0x7a, 0x52,
+	0xb8, 0x4e, 0x5d, 0x51, 0x61, 0xff, 0x9a, 0x0c, 0x57, 0xe1, 0xbe, 0xa7, 0xe1, 0x8f, 0xa1, 0x9b,
+	0xca, 0x6b, 0xbd, 0xa2, 0x74, 0x03, 0x3a, 0x88, 0x79, 0x3f, 0xe1, 0x30, 0x91, 0xc8, 0x5e, 0x6c,
+	0xea, 0xf6, 0x20, 0x65, 0xf2, 0xf5, 0x9f, 0x43, 0xef, 0x24, 0x57, 0x57, 0x71, 0x44, 0xaf, 0xf5,
+	0x5b, 0xe8, 0x65, 0x16, 0xa0, 0x6f, 0x2a, 0xe6, 0xb6, 0x29, 0xa6, 0x55, 0x12, 0x1b, 0x0d, 0xff,
+	0x0f, 0xe8, 0x5a, 0xfa, 0xde, 0x18, 0xd4, 0xde, 0x19, 0x03, 0x7c, 0x07, 0x66, 0xa0, 0x75, 0xf0,
+	0x06, 0x51, 0x56, 0xeb, 0x2c, 0x0a, 0x69, 0x98, 0x1b, 0x7a, 0x98, 0x0d, 0xf4, 0xff, 0xa9, 0x41,
+	0xe7, 0x44, 0x62, 0x81, 0xd2, 0x25, 0xaf, 0x0b, 0xa5, 0x4a, 0x5b, 0x0f, 0x3a, 0xd3, 0x74, 0xaf,
+	0xf3, 0xc4, 0x98, 0xa3, 0x23, 0x15, 0x38, 0x0b, 0x17, 0x97, 0xb8, 0x15, 0x0a, 0x34, 0x46, 0x2f,
+	0xb1, 0xc2, 0xd4, 0x57, 0xbd, 0xb6, 0x29, 0xb5, 0x26, 0x7b, 0xda, 0x10, 0xf4, 0x2e, 0xc2, 0x28,
+	0xc2, 0x18, 0x5a, 0x2c, 0xd1, 0x80, 0xee, 0x84, 0x65, 0x29, 0x57, 0x19, 0x45, 0xd7, 0xd6, 0x77,
+	0x2a, 0x82, 0xbc, 0x19, 0x50, 0xf0, 0x18, 0x35, 0x44, 0x85, 0xc9, 0x9e, 0xcc, 0x73, 0x95, 0x9b,
+	0x09, 0xd2, 0xc0, 0xbf, 0x02, 0xd8, 0xac, 0x99, 0xfb, 0xdf, 0x96, 0xda, 0x3b, 0xdf, 0x96, 0x4f,
+	0xa0, 0x47, 0x05, 0xbc, 0xfd, 0xe9, 0xa1, 0x31, 0x56, 0x2c, 0xc4, 0x8a, 0x24, 0x71, 0x2a, 0xb9,
+	0x68, 0x2d, 0xc1, 0x67, 0x5d, 0xe3, 0x64, 0xbd, 0xd2, 0x7d, 0x6e, 0x09, 0x83, 0xfc, 0x3e, 0xfa,
+	0x4d, 0x23, 0xf9, 0x96, 0xb7, 0x82, 0xff, 0x12, 0x9c, 0x20, 0x5a, 0xca, 0x9f, 0xe3, 0xc2, 0x7e,
+	0xa1, 0x6c, 0x03, 0x6a, 0x77, 0x1a, 0x80, 0x5f, 0x91, 0x96, 0x8c, 0xa8, 0x96, 0x75, 0x9e, 0x04,
+	0xc7, 0x4c, 0x02, 0x5d, 0x16, 0x5a, 0xe2, 0x97, 0xd0, 0x24, 0x48, 0x9e, 0xcb, 0x30, 0x5f, 0x4a,
+	0xdb, 0x21, 0x83, 0xe8, 0xb1, 0x9d, 0xc7, 0x39, 0x2e, 0xb3, 0x42, 0x4a, 0x3d, 0xb6, 0x58, 0x42,
+	0x66, 0xa6, 0x48, 0x50, 0x86, 0xbc, 0xea, 0x58, 0xaa, 0xdb, 0xdf, 0x25, 0x82, 0x85, 0xb4, 0xe6,
+	0x72, 0x95, 0x65, 0x18, 0x58, 0x93, 0x5f, 0x8b, 0x85, 0xfe, 0xdf, 0x35, 0xe8, 0xbf, 0x54, 0xeb,
+	0x3c, 0x0d, 0x93, 0x6a, 0xd1, 0x15, 0xf2, 0x8d, 0x89, 0x9f, 0x8e, 0x54, 0x9e, 0x32, 0x5e, 0x49,
+	0xe3, 0x92, 0xcf, 0xee, 0x2e, 0xd4, 0x55, 0xc6, 0x6e, 0xb6, 0xf6, 0x07, 0x26, 0x19, 0x63, 0xe6,
+	0x38, 0x13, 0x28, 0xb3, 0x0b, 0xb3, 0xb9, 0x59, 0x98, 0xfc, 0x9d, 0xca, 0x14, 0xcf, 0x45, 0x5f,
+	0xf0, 0x99, 0x02, 0x33, 0x63, 0xc5, 0x43, 0xd1, 0x17, 0x16, 0xde, 0xfa, 0xa6, 0x77, 0x58, 0x60,
+	0xbf, 0xe9, 0x4f, 0xa1, 0x91, 0xab, 0x6b, 0x1e, 0x06, 0x67, 0x1f, 0x36, 0x1f, 0x28, 0x41, 0xb4,
+	0xff, 0x02, 0xfa, 0x3f, 0x11, 0x63, 0x57, 0xd0, 0xad, 0x87, 0x6e, 0x3a, 0x72, 0xb5, 0x91, 0xd8,
+	0x5e, 0xd5, 0xef, 0xf4, 0xea, 0xd9, 0x1c, 0x9c, 0x5b, 0xdf, 0x65, 0x4c, 0xa4, 0x7f, 0x3a, 0x39,
+	0x1c, 0x1f, 0x4c, 0xa7, 0xa3, 0x1f, 0x47, 0xc1, 0x70, 0xf0, 0x81, 0x0b, 0xd0, 0x9e, 0xce, 0x86,
+	0xe3, 0xd1, 0x8b, 0x41, 0xcd, 0xdd, 0x06, 0x67, 0x7a, 0x70, 0x14, 0xcc, 0x8f, 0x8e, 0x87, 0xa7,
+	0xe3, 0x60, 0x50, 0x77, 0x1f, 0xc1, 0x36, 0x13, 0x22, 0x38, 0x39, 0x9e, 0x8e, 0x66, 0xc7, 0xe2,
+	0xf7, 0x41, 0xc3, 0xed, 0x43, 0x37, 0xf8, 0x6d, 0x16, 0x88, 0xc9, 0xc1, 0x78, 0xd0, 0x7c, 0x76,
+	0x06, 0xbd, 0xaa, 0x56, 0xae, 0x03, 0x9d, 0xd3, 0xc9, 0x2f, 0x93, 0xe3, 0xd7, 0x13, 0xb4, 0xdc,
+	0x81, 0xc6, 0xc1, 0x70, 0x88, 0x66, 0xb7, 0x00, 0xf0, 0xb0, 0xb1, 0x8a, 0x82, 0x93, 0xd3, 0x19,
+	0x5a, 0x42, 0xdf, 0x22, 0x38, 0x3a, 0x7e, 0x15, 0x0c, 0x9a, 0x74, 0x1e, 0x06, 0xe3, 0x60, 0x16,
+	0x0c, 0x5a, 0x74, 0xe1, 0xd7, 0xd3, 0x03, 0x71, 0x30, 0x99, 0x8d, 0x26, 0xc1, 0xa0, 0x7d, 0xd6,
+	0xe6, 0xff, 0xb2, 0xef, 0xfe, 0x05, 0x0d, 0x02, 0xe4, 0x41, 0xa6, 0x09, 0x00, 0x00,
 }
@@ -0,0 +1,80 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/internal/memstore"
+)
+
+// newStore returns an empty in-memory storage for tests.
+func newStore() *memstore.Store { return memstore.New(ErrNotFound) }
+
+// addImports adds a package with the given import path and direct
+// dependencies to g.
+func addImports(ctx context.Context, t *testing.T, g *Graph, ipath string, imports ...string) {
+	t.Helper()
+	pkg := &deps.Package{Name: "p", ImportPath: ipath, Imports: imports}
+	if err := g.Add(ctx, batchRepo, pkg); err != nil {
+		t.Fatalf("Add %q: %v", ipath, err)
+	}
+}
+
+// scanPaths returns the import paths of the rows of g with the given prefix,
+// in order.
+func scanPaths(ctx context.Context, t *testing.T, g *Graph, prefix string) []string {
+	t.Helper()
+	var paths []string
+	if err := g.Scan(ctx, prefix, func(row *Row) error {
+		paths = append(paths, row.ImportPath)
+		return nil
+	}); err != nil {
+		t.Fatalf("Scan %q: %v", prefix, err)
+	}
+	return paths
+}
+
+func TestVersion(t *testing.T) {
+	ctx := context.Background()
+	st := newStore()
+	g := New(st)
+	if v := mustVersion(ctx, t, g); v != 0 {
+		t.Errorf("Version of an empty graph: got %d, want 0", v)
+	}
+	addImports(ctx, t, g, "a")
+	addImports(ctx, t, g, "b", "a")
+	if err := g.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := g.Delete(ctx, "a"); err != ErrNotFound {
+		t.Fatalf("Delete again: got %v, want %v", err, ErrNotFound)
+	}
+	if v := mustVersion(ctx, t, g); v != 3 {
+		t.Errorf("Version after three changes: got %d, want 3", v)
+	}
+
+	// The version is kept in storage, so a new handle continues from it.
+	g = New(st)
+	if v := mustVersion(ctx, t, g); v != 3 {
+		t.Errorf("Version of a reopened graph: got %d, want 3", v)
+	}
+	addImports(ctx, t, g, "c")
+	if v := mustVersion(ctx, t, g); v != 4 {
+		t.Errorf("Version after reopening and adding: got %d, want 4", v)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/golang/protobuf/proto"
+)
+
+// A Journal is an append-only log of the mutations of a graph. When a graph
+// has a journal, each mutation is recorded before it is applied, so that the
+// package and module tables of the graph can be reconstructed by replaying
+// the journal into empty storage, and other programs can follow the changes
+// by reading the journal as it grows. The provider, search, and edge indexes
+// are rebuilt by the replay; the crawl queue is not recorded.
+//
+// Each entry is stored as a uvarint length followed by an encoded
+// JournalEntry message.
+type Journal struct {
+	// If true, each entry is flushed to stable storage before the mutation it
+	// records is applied. Otherwise a crash may lose the latest entries.
+	Sync bool
+
+	mu  sync.Mutex
+	f   *os.File
+	seq int64 // the sequence number of the last entry written
+}
+
+// OpenJournal opens the journal file at path for appending, creating it if it
+// does not exist. An incomplete entry at the end of the file, left by an
+// interrupted write, is discarded.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{f: f}
+	end, err := ReadJournal(f, func(e *JournalEntry) error {
+		j.seq = e.Seq
+		return nil
+	})
+	if err == nil {
+		err = f.Truncate(end)
+	}
+	if err == nil {
+		_, err = f.Seek(end, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading journal: %v", err)
+	}
+	return j, nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error { return j.f.Close() }
+
+// append assigns e the next sequence number and, unless it already has one,
+// the current time, and writes it to the journal.
+func (j *Journal) append(e *JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	e.Seq = j.seq + 1
+	if e.Time == 0 {
+		e.Time = time.Now().Unix()
+	}
+	bits, err := proto.Marshal(e)
+	if err != nil {
+		return err
+	}
+	var hdr [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(hdr[:], uint64(len(bits)))
+	if _, err := j.f.Write(append(hdr[:n], bits...)); err != nil {
+		return fmt.Errorf("writing journal: %v", err)
+	} else if j.Sync {
+		if err := j.f.Sync(); err != nil {
+			return fmt.Errorf("syncing journal: %v", err)
+		}
+	}
+	j.seq = e.Seq
+	return nil
+}
+
+// ReadJournal calls f with each complete entry of the journal read from r, in
+// order, and returns the number of bytes of r occupied by those entries. An
+// incomplete entry at the end of r is not reported, so that a journal that is
+// being written can be read up to its current end and later read again from
+// the returned offset. If f reports an error, ReadJournal stops and returns
+// it.
+func ReadJournal(r io.Reader, f func(*JournalEntry) error) (int64, error) {
+	br := bufio.NewReader(r)
+	var pos int64
+	for {
+		cr := &countingReader{r: br}
+		n, err := binary.ReadUvarint(cr)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return pos, nil
+		} else if err != nil {
+			return pos, err
+		}
+		bits := make([]byte, n)
+		if _, err := io.ReadFull(br, bits); err == io.EOF || err == io.ErrUnexpectedEOF {
+			return pos, nil
+		} else if err != nil {
+			return pos, err
+		}
+		var e JournalEntry
+		if err := proto.Unmarshal(bits, &e); err != nil {
+			return pos, fmt.Errorf("invalid entry at offset %d: %v", pos, err)
+		}
+		if err := f(&e); err != nil {
+			return pos, err
+		}
+		pos += int64(cr.n) + int64(n)
+	}
+}
+
+// countingReader counts the bytes read from a ByteReader.
+type countingReader struct {
+	r io.ByteReader
+	n int
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// SetJournal arranges for each mutation of g to be recorded in j before it is
+// applied. If j == nil, mutations are no longer recorded.
+func (g *Graph) SetJournal(j *Journal) { g.journal = j }
+
+// record writes e to the journal of g, if it has one.
+func (g *Graph) record(e *JournalEntry) error {
+	if g.journal == nil {
+		return nil
+	}
+	return g.journal.append(e)
+}
+
+// recordAdd records the addition of pkg or mod, from repo, in the journal of
+// g, if it has one. The entry has the given time, or if now == 0 the current
+// time.
+func (g *Graph) recordAdd(repo *deps.Repo, pkg *deps.Package, mod *deps.Module, now int64) error {
+	if g.journal == nil {
+		return nil
+	}
+	r := *repo
+	r.Packages, r.Modules = nil, nil // recorded separately
+	rbits, err := proto.Marshal(&r)
+	if err != nil {
+		return err
+	}
+	e := &JournalEntry{Repo: rbits, Time: now}
+	if pkg != nil {
+		e.Op, e.Key = JournalOp_ADD, pkg.ImportPath
+		e.Package, err = proto.Marshal(pkg)
+	} else {
+		e.Op, e.Key = JournalOp_ADD_MODULE, ModuleKey(mod.Path, mod.Version)
+		e.Module, err = proto.Marshal(mod)
+	}
+	if err != nil {
+		return err
+	}
+	return g.journal.append(e)
+}
+
+// Replay applies the mutation recorded by e to g. Package additions and
+// deletions take effect as of the time recorded in e, so that the history of
+// dependencies and the tombstones they leave match those of the original
+// graph. Additions are applied at once, even if batching is enabled.
+func (g *Graph) Replay(ctx context.Context, e *JournalEntry) error {
+	switch e.Op {
+	case JournalOp_ADD, JournalOp_ADD_MODULE:
+		var repo deps.Repo
+		if err := proto.Unmarshal(e.Repo, &repo); err != nil {
+			return fmt.Errorf("entry %d: invalid repository: %v", e.Seq, err)
+		}
+		if e.Op == JournalOp_ADD {
+			var pkg deps.Package
+			if err := proto.Unmarshal(e.Package, &pkg); err != nil {
+				return fmt.Errorf("entry %d: invalid package: %v", e.Seq, err)
+			}
+			return g.addAt(ctx, &repo, &pkg, e.Time)
+		}
+		var mod deps.Module
+		if err := proto.Unmarshal(e.Module, &mod); err != nil {
+			return fmt.Errorf("entry %d: invalid module: %v", e.Seq, err)
+		}
+		return g.addModuleAt(ctx, &repo, &mod, e.Time)
+	case JournalOp_PUT:
+		if e.Row == nil {
+			return fmt.Errorf("entry %d: missing row", e.Seq)
+		}
+		return g.putAt(ctx, e.Row, e.Time)
+	case JournalOp_REMOVE:
+		return g.Remove(ctx, e.Key)
+	case JournalOp_DELETE:
+		return g.deleteAt(ctx, e.Key, e.Time)
+	case JournalOp_QUARANTINE:
+		_, err := g.Quarantine(ctx, e.Key)
+		return err
+	}
+	return fmt.Errorf("entry %d: unknown operation %v", e.Seq, e.Op)
+}
@@ -0,0 +1,315 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// tempJournal opens a new journal in a temporary directory, and returns it
+// with its path and a function to clean up.
+func tempJournal(t *testing.T) (*Journal, string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	path := filepath.Join(dir, "journal")
+	j, err := OpenJournal(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	return j, path, func() { j.Close(); os.RemoveAll(dir) }
+}
+
+// readEntries returns the complete entries of the journal file at path.
+func readEntries(t *testing.T, path string) []*JournalEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	var es []*JournalEntry
+	if _, err := ReadJournal(f, func(e *JournalEntry) error {
+		es = append(es, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	return es
+}
+
+// populate applies a fixed sequence of mutations of each kind to g.
+func populate(ctx context.Context, t *testing.T, g *Graph) {
+	t.Helper()
+	repo := &deps.Repo{Remotes: []*deps.Remote{{Url: "example.com/r"}}, Commit: "abc"}
+	for _, step := range []struct {
+		desc string
+		err  error
+	}{
+		{"add a", g.Add(ctx, repo, &deps.Package{Name: "a", ImportPath: "r/a", Imports: []string{"r/b", "r/c"}})},
+		{"add b", g.Add(ctx, repo, &deps.Package{Name: "b", ImportPath: "r/b"})},
+		{"add module", g.AddModule(ctx, repo, &deps.Module{
+			Path: "r", Version: "v1.0.0",
+			Requires: []*deps.Requirement{{Path: "s", Version: "v0.1.0"}},
+		})},
+		{"put c", g.Put(ctx, &Row{Name: "c", ImportPath: "r/c", Directs: []string{"r/b"}})},
+		{"re-add a", g.Add(ctx, repo, &deps.Package{Name: "a", ImportPath: "r/a", Imports: []string{"r/b"}})},
+		{"delete b", g.Delete(ctx, "r/b")},
+		{"put junk", g.Put(ctx, &Row{ImportPath: "junk"})},
+		{"quarantine junk", func() error { _, err := g.Quarantine(ctx, "junk"); return err }()},
+		{"put d", g.Put(ctx, &Row{ImportPath: "r/d"})},
+		{"remove d", g.Remove(ctx, "r/d")},
+	} {
+		if step.err != nil {
+			t.Fatalf("%s: %v", step.desc, step.err)
+		}
+	}
+}
+
+func TestJournalEntries(t *testing.T) {
+	ctx := context.Background()
+	j, path, cleanup := tempJournal(t)
+	defer cleanup()
+
+	g := New(newStore())
+	g.SetJournal(j)
+	populate(ctx, t, g)
+
+	want := []struct {
+		op  JournalOp
+		key string
+	}{
+		{JournalOp_ADD, "r/a"},
+		{JournalOp_ADD, "r/b"},
+		{JournalOp_ADD_MODULE, ModuleKey("r", "v1.0.0")},
+		{JournalOp_PUT, "r/c"},
+		{JournalOp_ADD, "r/a"},
+		{JournalOp_DELETE, "r/b"},
+		{JournalOp_PUT, "junk"},
+		{JournalOp_QUARANTINE, "junk"},
+		{JournalOp_PUT, "r/d"},
+		{JournalOp_REMOVE, "r/d"},
+	}
+	es := readEntries(t, path)
+	if len(es) != len(want) {
+		t.Fatalf("Got %d entries, want %d", len(es), len(want))
+	}
+	for i, e := range es {
+		if e.Seq != int64(i+1) {
+			t.Errorf("Entry %d: got seq %d, want %d", i, e.Seq, i+1)
+		}
+		if e.Op != want[i].op || e.Key != want[i].key {
+			t.Errorf("Entry %d: got %v %q, want %v %q", i, e.Op, e.Key, want[i].op, want[i].key)
+		}
+		if e.Time == 0 {
+			t.Errorf("Entry %d: no time recorded", i)
+		}
+	}
+}
+
+func TestJournalReplay(t *testing.T) {
+	ctx := context.Background()
+	j, path, cleanup := tempJournal(t)
+	defer cleanup()
+
+	orig := newStore()
+	g := New(orig)
+	g.SetJournal(j)
+	populate(ctx, t, g)
+
+	// Replaying the journal into empty storage reconstructs the graph,
+	// including its indexes, histories, and tombstones. Only the time the
+	// version was last updated may differ. Replayed additions are applied at
+	// once, even though the new graph buffers additions.
+	es := readEntries(t, path)
+	copied := newStore()
+	r := New(copied)
+	if err := r.SetBatching(100, 0); err != nil {
+		t.Fatalf("SetBatching: %v", err)
+	}
+	for _, e := range es {
+		if err := r.Replay(ctx, e); err != nil {
+			t.Fatalf("Replay entry %d: %v", e.Seq, err)
+		}
+	}
+	want, got := orig.Contents(), copied.Contents()
+	delete(want, versionKey)
+	delete(got, versionKey)
+	for key, val := range want {
+		if bits, ok := got[key]; !ok {
+			t.Errorf("Replay: missing key %q", key)
+		} else if !bytes.Equal(bits, val) {
+			t.Errorf("Replay: key %q differs", key)
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			t.Errorf("Replay: extra key %q", key)
+		}
+	}
+	if v1, v2 := mustVersion(ctx, t, g), mustVersion(ctx, t, r); v1 != v2 {
+		t.Errorf("Replay: got version %d, want %d", v2, v1)
+	}
+}
+
+func TestReplayTimes(t *testing.T) {
+	ctx := context.Background()
+	j, path, cleanup := tempJournal(t)
+	defer cleanup()
+
+	g := New(newStore())
+	g.SetJournal(j)
+	populate(ctx, t, g)
+
+	// Move every entry into the past, so that a replay that used the current
+	// time instead of the recorded one would be noticed.
+	const offset = 1000000
+	es := readEntries(t, path)
+	r := New(newStore())
+	for _, e := range es {
+		e.Time -= offset
+		if err := r.Replay(ctx, e); err != nil {
+			t.Fatalf("Replay entry %d: %v", e.Seq, err)
+		}
+	}
+	addA, readdA, delB := es[0].Time, es[4].Time, es[5].Time
+
+	tomb, err := r.Tombstone(ctx, "r/b")
+	if err != nil {
+		t.Fatalf("Tombstone: %v", err)
+	} else if tomb.Deleted != delB {
+		t.Errorf("Tombstone: got deleted %d, want %d", tomb.Deleted, delB)
+	}
+
+	h, err := r.EdgeHistory(ctx, "r/a")
+	if err != nil {
+		t.Fatalf("EdgeHistory: %v", err)
+	}
+	want := []*Edge{
+		{Target: "r/b", FirstSeen: addA, LastSeen: readdA},
+		{Target: "r/c", FirstSeen: addA, LastSeen: addA, Dropped: true},
+	}
+	if h.Updated != readdA || !reflect.DeepEqual(h.Edges, want) {
+		t.Errorf("EdgeHistory: got %+v, want updated %d, edges %+v", h, readdA, want)
+	}
+
+	ps, err := r.Providers(ctx, "r/a")
+	if err != nil {
+		t.Fatalf("Providers: %v", err)
+	} else if len(ps) != 1 || ps[0].Updated != readdA {
+		t.Errorf("Providers: got %+v, want one updated at %d", ps, readdA)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	tests := []struct {
+		e    *JournalEntry
+		want string
+	}{
+		{&JournalEntry{Seq: 1, Op: JournalOp_UNKNOWN}, "entry 1: unknown operation"},
+		{&JournalEntry{Seq: 2, Op: JournalOp_PUT, Key: "x"}, "entry 2: missing row"},
+		{&JournalEntry{Seq: 3, Op: JournalOp_ADD, Repo: []byte{0xff}}, "entry 3: invalid repository"},
+		{&JournalEntry{Seq: 4, Op: JournalOp_ADD, Package: []byte{0xff}}, "entry 4: invalid package"},
+		{&JournalEntry{Seq: 5, Op: JournalOp_ADD_MODULE, Module: []byte{0xff}}, "entry 5: invalid module"},
+		{&JournalEntry{Seq: 6, Op: JournalOp_DELETE, Key: "nonesuch"}, ErrNotFound.Error()},
+	}
+	for _, test := range tests {
+		err := g.Replay(ctx, test.e)
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("Replay(%v): got error %v, want %q", test.e, err, test.want)
+		}
+	}
+}
+
+func TestJournalTruncated(t *testing.T) {
+	ctx := context.Background()
+	j, path, cleanup := tempJournal(t)
+	defer cleanup()
+
+	g := New(newStore())
+	g.SetJournal(j)
+	for _, ipath := range []string{"a", "b"} {
+		if err := g.Put(ctx, &Row{ImportPath: ipath}); err != nil {
+			t.Fatalf("Put %q: %v", ipath, err)
+		}
+	}
+	j.Close()
+
+	// Simulate an interrupted write: a length prefix promising more data
+	// than follows.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	f.Write([]byte{50, 1, 2, 3})
+	f.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	n, err := ReadJournal(bytes.NewReader(data), func(*JournalEntry) error { return nil })
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	} else if n != int64(len(data)-4) {
+		t.Errorf("ReadJournal: got offset %d, want %d", n, len(data)-4)
+	}
+
+	// Reopening the journal discards the incomplete entry, and continues
+	// the sequence.
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer j.Close()
+	g.SetJournal(j)
+	if err := g.Put(ctx, &Row{ImportPath: "c"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	es := readEntries(t, path)
+	var keys []string
+	for i, e := range es {
+		keys = append(keys, e.Key)
+		if e.Seq != int64(i+1) {
+			t.Errorf("Entry %d: got seq %d, want %d", i, e.Seq, i+1)
+		}
+	}
+	if got := strings.Join(keys, " "); got != "a b c" {
+		t.Errorf("Entries: got keys %q, want %q", got, "a b c")
+	}
+}
+
+func mustVersion(ctx context.Context, t *testing.T, g *Graph) int64 {
+	t.Helper()
+	v, err := g.Version(ctx)
+	if err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	return v
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// ModuleKey returns the storage key for the specified module version.
+func ModuleKey(path, version string) string { return modulePrefix + path + "@" + version }
+
+// AddModule adds the specified module version to the module graph. Each
+// version of a module is a separate node; an unversioned module (for example,
+// one read from a working tree) has an empty version.
+//
+// If batching is enabled, the addition may be buffered; see SetBatching.
+func (g *Graph) AddModule(ctx context.Context, repo *deps.Repo, mod *deps.Module) error {
+	if g.batch != nil {
+		return g.enqueue(ctx, batchItem{repo: repo, mod: mod})
+	}
+	return g.addModuleAt(ctx, repo, mod, time.Now().Unix())
+}
+
+// addModuleAt adds mod as AddModule does without batching, as of the given
+// time in seconds since the Unix epoch.
+func (g *Graph) addModuleAt(ctx context.Context, repo *deps.Repo, mod *deps.Module, now int64) (err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.changed(ctx, &err)
+	return g.addModule(ctx, repo, mod, now)
+}
+
+// addModule implements AddModule, as of the given time in seconds since the
+// Unix epoch. The caller must hold g.mu.
+func (g *Graph) addModule(ctx context.Context, repo *deps.Repo, mod *deps.Module, now int64) error {
+	if err := g.recordAdd(repo, nil, mod, now); err != nil {
+		return err
+	}
+	var url string
+	if len(repo.Remotes) != 0 {
+		url = repo.Remotes[0].Url
+	}
+	row := &Module{
+		Path:       mod.Path,
+		Version:    mod.Version,
+		Repository: url,
+		GoVersion:  mod.GoVersion,
+		Toolchain:  mod.Toolchain,
+	}
+	for _, req := range mod.Requires {
+		row.Requires = append(row.Requires, &Requirement{
+			Path:     req.Path,
+			Version:  req.Version,
+			Indirect: req.Indirect,
+		})
+	}
+	for _, ex := range mod.Excludes {
+		row.Excludes = append(row.Excludes, &Requirement{Path: ex.Path, Version: ex.Version})
+	}
+	for _, r := range mod.Replaces {
+		row.Replaces = append(row.Replaces, &Replacement{
+			Path:       r.Path,
+			Version:    r.Version,
+			NewPath:    r.NewPath,
+			NewVersion: r.NewVersion,
+		})
+	}
+	return g.st.Store(ctx, ModuleKey(mod.Path, mod.Version), row)
+}
+
+// SplitModule splits a module label of the form path@version, as stored in
+// the Module field of a Row, into its path and version. If the label has no
+// version, the version is empty.
+func SplitModule(label string) (path, version string) {
+	if i := strings.LastIndex(label, "@"); i >= 0 {
+		return label[:i], label[i+1:]
+	}
+	return label, ""
+}
+
+// Module loads the node for the specified module version.
+func (g *Graph) Module(ctx context.Context, path, version string) (*Module, error) {
+	var mod Module
+	if err := g.st.Load(ctx, ModuleKey(path, version), &mod); err != nil {
+		return nil, err
+	}
+	return &mod, nil
+}
+
+// ScanModules calls f with each module version in the graph whose path has
+// the specified prefix. If f reports an error, scanning terminates. If the
+// error is ErrStopScan ScanModules returns nil; otherwise ScanModules returns
+// the error from f.
+func (g *Graph) ScanModules(ctx context.Context, prefix string, f func(*Module) error) error {
+	err := g.st.Scan(ctx, modulePrefix+prefix, func(key string) error {
+		var mod Module
+		if err := g.st.Load(ctx, key, &mod); err != nil {
+			return err
+		}
+		return f(&mod)
+	})
+	if err == ErrStopScan {
+		return nil
+	}
+	return err
+}
+
+// Versions returns the versions of the module with the given path that are
+// recorded in the graph. The order of results is unspecified.
+func (g *Graph) Versions(ctx context.Context, path string) ([]string, error) {
+	var vs []string
+	err := g.st.Scan(ctx, modulePrefix+path+"@", func(key string) error {
+		vs = append(vs, strings.TrimPrefix(key, modulePrefix+path+"@"))
+		return nil
+	})
+	return vs, err
+}
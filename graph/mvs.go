@@ -0,0 +1,160 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"sort"
+
+	"github.com/creachadair/repodeps/semver"
+)
+
+// A BuildList is the result of minimal version selection for a root module.
+type BuildList struct {
+	// The selected version of each module in the build, including the root,
+	// ordered by path with the root first.
+	Selected []*Requirement
+
+	// Module versions that were selected but are not in the graph, so their
+	// own requirements could not be considered. The result may be incomplete
+	// if this is non-empty. Missing versions that were not selected are not
+	// reported, although their requirements could also have affected the
+	// selection.
+	Missing []*Requirement
+}
+
+// BuildList simulates Go's minimal version selection (MVS) algorithm for the
+// root module at the given version, using the requirements recorded in the
+// module graph. As in the go command, only the exclude and replace directives
+// of the root module are honored. A requirement that is replaced by a
+// directory is treated as having no further requirements.
+func (g *Graph) BuildList(ctx context.Context, path, version string) (*BuildList, error) {
+	root, err := g.Module(ctx, path, version)
+	if err != nil {
+		return nil, err
+	}
+	return g.buildList(ctx, root)
+}
+
+// buildList computes the build list for root, which need not be stored in the
+// graph.
+func (g *Graph) buildList(ctx context.Context, root *Module) (*BuildList, error) {
+	path, version := root.Path, root.Version
+	excluded := make(map[string]bool)
+	for _, ex := range root.Excludes {
+		excluded[ex.Path+"@"+ex.Version] = true
+	}
+
+	selected := map[string]string{path: version} // :: path → max version
+	missing := make(map[string]*Requirement)
+	visited := map[string]bool{path + "@" + version: true}
+	queue := []*Requirement{{Path: path, Version: version}}
+	for len(queue) != 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		var reqs []*Requirement
+		if next.Path == path && next.Version == version {
+			reqs = root.Requires
+		} else if rp, rv, ok := replacement(root, next.Path, next.Version); !ok {
+			continue // replaced by a directory; nothing more to do
+		} else if mod, err := g.Module(ctx, rp, rv); err == ErrNotFound {
+			missing[next.Path+"@"+next.Version] = next
+			continue
+		} else if err != nil {
+			return nil, err
+		} else {
+			reqs = mod.Requires
+		}
+
+		for _, req := range reqs {
+			if excluded[req.Path+"@"+req.Version] {
+				v, err := g.nextVersion(ctx, req.Path, req.Version, excluded)
+				if err != nil {
+					return nil, err
+				} else if v == "" {
+					continue // no acceptable version is known
+				}
+				req = &Requirement{Path: req.Path, Version: v, Indirect: req.Indirect}
+			}
+			if req.Path != path {
+				selected[req.Path] = semver.Max(selected[req.Path], req.Version)
+			}
+			if key := req.Path + "@" + req.Version; !visited[key] {
+				visited[key] = true
+				queue = append(queue, req)
+			}
+		}
+	}
+
+	out := &BuildList{Selected: []*Requirement{{Path: path, Version: version}}}
+	for p, v := range selected {
+		if p != path {
+			out.Selected = append(out.Selected, &Requirement{Path: p, Version: v})
+		}
+	}
+	sort.Slice(out.Selected[1:], func(i, j int) bool {
+		return out.Selected[i+1].Path < out.Selected[j+1].Path
+	})
+	for _, req := range missing {
+		// Report only the missing versions that were selected, not those a
+		// higher version of the same module superseded.
+		if selected[req.Path] == req.Version {
+			out.Missing = append(out.Missing, req)
+		}
+	}
+	sort.Slice(out.Missing, func(i, j int) bool {
+		return out.Missing[i].Path < out.Missing[j].Path
+	})
+	return out, nil
+}
+
+// replacement returns the module version whose requirements should be used
+// for path@version according to the replace directives of root. It reports
+// false if the replacement is a directory.
+func replacement(root *Module, path, version string) (string, string, bool) {
+	var best *Replacement
+	for _, r := range root.Replaces {
+		if r.Path != path {
+			continue
+		} else if r.Version == version || (r.Version == "" && best == nil) {
+			best = r
+		}
+	}
+	if best == nil {
+		return path, version, true
+	} else if best.NewVersion == "" {
+		return "", "", false
+	}
+	return best.NewPath, best.NewVersion, true
+}
+
+// nextVersion returns the lowest version of path in the graph that is higher
+// than version and not excluded, or "" if there is none.
+func (g *Graph) nextVersion(ctx context.Context, path, version string, excluded map[string]bool) (string, error) {
+	vs, err := g.Versions(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	var best string
+	for _, v := range vs {
+		if semver.Compare(v, version) <= 0 || excluded[path+"@"+v] {
+			continue
+		} else if best == "" || semver.Compare(v, best) < 0 {
+			best = v
+		}
+	}
+	return best, nil
+}
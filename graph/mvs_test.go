@@ -0,0 +1,112 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// reqs parses a list of requirements of the form "path@version".
+func reqs(ss ...string) []*deps.Requirement {
+	var out []*deps.Requirement
+	for _, s := range ss {
+		path, version := SplitModule(s)
+		out = append(out, &deps.Requirement{Path: path, Version: version})
+	}
+	return out
+}
+
+// labels renders a list of requirements as "path@version" strings.
+func labels(rs []*Requirement) string {
+	var out []string
+	for _, r := range rs {
+		out = append(out, r.Path+"@"+r.Version)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestBuildList(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	for _, mod := range []*deps.Module{
+		{Path: "m", Version: "v1.0.0",
+			Requires: reqs("a@v1.0.0", "b@v1.0.0"),
+			Excludes: reqs("c@v1.1.0"),
+			Replaces: []*deps.Replacement{
+				{Path: "d", NewPath: "d2", NewVersion: "v1.0.0"},
+				{Path: "e", NewPath: "./e"},
+			},
+		},
+		{Path: "a", Version: "v1.0.0", Requires: reqs("c@v1.1.0", "f@v0.9.0")},
+		{Path: "a", Version: "v1.1.0"},
+		{Path: "b", Version: "v1.0.0", Requires: reqs("a@v1.1.0", "d@v1.0.0", "e@v1.0.0", "f@v1.0.0")},
+		{Path: "c", Version: "v1.1.0", Requires: reqs("x@v1.0.0")},
+		{Path: "c", Version: "v1.2.0"},
+		{Path: "c", Version: "v1.3.0"},
+		{Path: "d", Version: "v1.0.0", Requires: reqs("y@v1.0.0")},
+		{Path: "d2", Version: "v1.0.0", Requires: reqs("h@v1.0.0")},
+		{Path: "e", Version: "v1.0.0", Requires: reqs("z@v1.0.0")},
+		{Path: "h", Version: "v1.0.0", Requires: reqs("m@v0.9.0")},
+
+		// The root module's replacements and exclusions apply, but not
+		// those of its dependencies.
+		{Path: "n", Version: "v1.0.0", Requires: reqs("m@v1.0.0")},
+	} {
+		if err := g.AddModule(ctx, &deps.Repo{}, mod); err != nil {
+			t.Fatalf("AddModule %s@%s: %v", mod.Path, mod.Version, err)
+		}
+	}
+
+	tests := []struct {
+		path, version     string
+		selected, missing string
+	}{
+		// The excluded c@v1.1.0 is replaced by the next version, d's
+		// requirements are those of its replacement d2, and e is replaced by a
+		// directory so its requirements are not followed. The missing f@v0.9.0
+		// does not matter, since f@v1.0.0 is selected; the root's own version
+		// is not raised by h's requirement of an older m.
+		{"m", "v1.0.0",
+			"m@v1.0.0 a@v1.1.0 b@v1.0.0 c@v1.2.0 d@v1.0.0 e@v1.0.0 f@v1.0.0 h@v1.0.0",
+			"f@v1.0.0"},
+
+		{"a", "v1.0.0", "a@v1.0.0 c@v1.1.0 f@v0.9.0 x@v1.0.0", "f@v0.9.0 x@v1.0.0"},
+		{"a", "v1.1.0", "a@v1.1.0", ""},
+		{"n", "v1.0.0",
+			"n@v1.0.0 a@v1.1.0 b@v1.0.0 c@v1.1.0 d@v1.0.0 e@v1.0.0 f@v1.0.0 m@v1.0.0 x@v1.0.0 y@v1.0.0 z@v1.0.0",
+			"f@v1.0.0 x@v1.0.0 y@v1.0.0 z@v1.0.0"},
+	}
+	for _, test := range tests {
+		bl, err := g.BuildList(ctx, test.path, test.version)
+		if err != nil {
+			t.Errorf("BuildList(%s@%s): unexpected error: %v", test.path, test.version, err)
+			continue
+		}
+		if got := labels(bl.Selected); got != test.selected {
+			t.Errorf("BuildList(%s@%s) selected:\n got %s\nwant %s", test.path, test.version, got, test.selected)
+		}
+		if got := labels(bl.Missing); got != test.missing {
+			t.Errorf("BuildList(%s@%s) missing:\n got %s\nwant %s", test.path, test.version, got, test.missing)
+		}
+	}
+
+	if bl, err := g.BuildList(ctx, "m", "v9.9.9"); err != ErrNotFound {
+		t.Errorf("BuildList(m@v9.9.9): got %+v, %v; want %v", bl, err, ErrNotFound)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"strings"
+)
+
+// PendingKey returns the storage key for the crawl queue entry of the
+// repository with the specified root.
+func PendingKey(root string) string { return pendingPrefix + root }
+
+// AddPending adds p to the crawl queue, replacing any existing entry for the
+// same root. The queue time and fetch history of an existing entry are
+// preserved, so that updating the packages of an entry does not reset its
+// position in the queue.
+func (g *Graph) AddPending(ctx context.Context, p *Pending) error {
+	old, err := g.Pending(ctx, p.Root)
+	if err == nil {
+		p.Added = old.Added
+		p.Attempted = old.Attempted
+		p.Attempts = old.Attempts
+		p.Error = old.Error
+	} else if err != ErrNotFound {
+		return err
+	}
+	return g.st.Store(ctx, PendingKey(p.Root), p)
+}
+
+// UpdatePending stores p in the crawl queue as given.
+func (g *Graph) UpdatePending(ctx context.Context, p *Pending) error {
+	return g.st.Store(ctx, PendingKey(p.Root), p)
+}
+
+// Pending loads the crawl queue entry for the repository with the specified
+// root.
+func (g *Graph) Pending(ctx context.Context, root string) (*Pending, error) {
+	var p Pending
+	if err := g.st.Load(ctx, PendingKey(root), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ScanPending calls f with each entry of the crawl queue, in order of root.
+// If f reports an error, scanning terminates. If the error is ErrStopScan
+// ScanPending returns nil; otherwise ScanPending returns the error from f.
+func (g *Graph) ScanPending(ctx context.Context, f func(*Pending) error) error {
+	err := g.st.Scan(ctx, pendingPrefix, func(key string) error {
+		p, err := g.Pending(ctx, strings.TrimPrefix(key, pendingPrefix))
+		if err != nil {
+			return err
+		}
+		return f(p)
+	})
+	if err == ErrStopScan {
+		return nil
+	}
+	return err
+}
+
+// RemovePending removes the crawl queue entry for the repository with the
+// specified root.
+func (g *Graph) RemovePending(ctx context.Context, root string) error {
+	return g.st.Delete(ctx, PendingKey(root))
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import "context"
+
+// ProviderKey returns the storage key for the provider index entry of the
+// specified import path.
+func ProviderKey(ipath string) string { return providerPrefix + ipath }
+
+// Providers returns the repositories that have provided a package with the
+// given import path, in the order they were first recorded. The package row
+// records only the most recent of these. If no repository has been recorded,
+// Providers reports ErrNotFound.
+func (g *Graph) Providers(ctx context.Context, ipath string) ([]*Provider, error) {
+	var ps Providers
+	if err := g.st.Load(ctx, ProviderKey(ipath), &ps); err != nil {
+		return nil, err
+	}
+	return ps.Providers, nil
+}
+
+// addProvider records that the repository at url, as of the given commit,
+// provides the package with import path ipath as of now, in seconds since the
+// Unix epoch. A repository without a URL has no identity, and is not
+// recorded.
+func (g *Graph) addProvider(ctx context.Context, ipath, url, commit string, now int64) error {
+	if url == "" {
+		return nil
+	}
+	var ps Providers
+	if err := g.st.Load(ctx, ProviderKey(ipath), &ps); err != nil && err != ErrNotFound {
+		return err
+	}
+	for _, p := range ps.Providers {
+		if p.Repository == url {
+			p.Commit = commit
+			p.Updated = now
+			return g.st.Store(ctx, ProviderKey(ipath), &ps)
+		}
+	}
+	ps.Providers = append(ps.Providers, &Provider{
+		Repository: url,
+		Commit:     commit,
+		Updated:    now,
+	})
+	return g.st.Store(ctx, ProviderKey(ipath), &ps)
+}
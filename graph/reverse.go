@@ -0,0 +1,133 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import "context"
+
+// The reverse dependency index records each direct dependency of each
+// package as a key of the form
+//
+//	@importers/<import-path> <importer>
+//
+// with an empty value. Like the search index, entries are added by Add and
+// Put but not removed when a package drops a dependency or is removed, so
+// Importers checks each entry against the row of its importer, and
+// ReindexImporters removes the stale entries.
+//
+// The index has no entries for rows stored before it existed, so Importers
+// uses it only after ReindexImporters has built it, which it records by
+// storing an empty value under reverseReadyKey.
+const reverseReadyKey = auxPrefix + "ready/importers"
+
+// importerKeys returns the reverse dependency index keys for row.
+func importerKeys(row *Row) []string {
+	keys := make([]string, len(row.Directs))
+	for i, dep := range row.Directs {
+		keys[i] = importerPrefix + dep + " " + row.ImportPath
+	}
+	return keys
+}
+
+// indexImporters adds the reverse dependency index entries for row.
+func (g *Graph) indexImporters(ctx context.Context, row *Row) error {
+	for _, key := range importerKeys(row) {
+		if err := g.st.Store(ctx, key, new(IndexEntry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseReady reports whether the reverse dependency index has been built.
+func (g *Graph) reverseReady(ctx context.Context) (bool, error) {
+	err := g.st.Load(ctx, reverseReadyKey, new(IndexEntry))
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// indexedImporters calls f with the import path of each package after the
+// given path that the reverse dependency index shows directly depends on pkg,
+// in order, as ImportersAfter describes.
+func (g *Graph) indexedImporters(ctx context.Context, pkg, after string, f func(string) error) error {
+	paths, err := g.indexed(ctx, importerPrefix+pkg+" ")
+	if err != nil {
+		return err
+	}
+	for _, ipath := range paths {
+		if ipath <= after {
+			continue
+		}
+		row, err := g.Row(ctx, ipath)
+		if err == ErrNotFound {
+			continue // stale index entry
+		} else if err != nil {
+			return err
+		}
+		for _, dep := range row.Directs {
+			if dep != pkg {
+				continue
+			} else if err := f(ipath); err == ErrStopScan {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// ReindexImporters builds the reverse dependency index from the package rows
+// of the graph in a single pass, adding the entries for rows stored before the
+// index existed and removing entries that no longer match any row. Once it
+// succeeds, Importers uses the index instead of scanning every row.
+//
+// Mutations of the graph wait until the rebuild is complete, since an entry
+// added after the rows were read would otherwise be removed as stale.
+func (g *Graph) ReindexImporters(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	want := make(map[string]bool)
+	if err := g.Scan(ctx, "", func(row *Row) error {
+		for _, key := range importerKeys(row) {
+			if !want[key] {
+				want[key] = true
+				if err := g.st.Store(ctx, key, new(IndexEntry)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	var stale []string
+	if err := g.st.Scan(ctx, importerPrefix, func(key string) error {
+		if !want[key] {
+			stale = append(stale, key)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, key := range stale {
+		if err := g.st.Delete(ctx, key); err != nil && err != ErrNotFound {
+			return err
+		}
+	}
+	return g.st.Store(ctx, reverseReadyKey, new(IndexEntry))
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// importers returns the importers of pkg after the given path, in the order
+// reported by ImportersAfter, stopping after limit results if limit > 0.
+func importers(ctx context.Context, t *testing.T, g *Graph, pkg, after string, limit int) []string {
+	t.Helper()
+	var got []string
+	if err := g.ImportersAfter(ctx, pkg, after, func(ipath string) error {
+		got = append(got, ipath)
+		if len(got) == limit {
+			return ErrStopScan
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("ImportersAfter(%q, %q): %v", pkg, after, err)
+	}
+	return got
+}
+
+func TestReverseIndex(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	addImports(ctx, t, g, "a", "c")
+	addImports(ctx, t, g, "b", "c", "d")
+	addImports(ctx, t, g, "c")
+
+	check := func(label string) {
+		t.Helper()
+		tests := []struct {
+			pkg, after string
+			limit      int
+			want       []string
+		}{
+			{"c", "", 0, []string{"a", "b", "e"}},
+			{"c", "a", 0, []string{"b", "e"}},
+			{"c", "", 2, []string{"a", "b"}},
+			{"d", "", 0, nil},
+			{"nonesuch", "", 0, nil},
+		}
+		for _, test := range tests {
+			got := importers(ctx, t, g, test.pkg, test.after, test.limit)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("%s: ImportersAfter(%q, %q) limit %d: got %q, want %q",
+					label, test.pkg, test.after, test.limit, got, test.want)
+			}
+		}
+	}
+
+	// Before the index is built, importers are found by scanning. Dropping a
+	// dependency and deleting a package leave stale entries behind.
+	addImports(ctx, t, g, "b", "c")
+	addImports(ctx, t, g, "e", "c", "d")
+	if err := g.Delete(ctx, "e"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	addImports(ctx, t, g, "e", "c")
+	if ok, err := g.reverseReady(ctx); err != nil || ok {
+		t.Errorf("reverseReady before ReindexImporters: got (%v, %v), want false", ok, err)
+	}
+	check("scanned")
+
+	// Once the index is built, importers are found by the index, and stale
+	// entries are not reported.
+	if err := g.ReindexImporters(ctx); err != nil {
+		t.Fatalf("ReindexImporters: %v", err)
+	}
+	if ok, err := g.reverseReady(ctx); err != nil || !ok {
+		t.Errorf("reverseReady after ReindexImporters: got (%v, %v), want true", ok, err)
+	}
+	check("indexed")
+
+	// Rebuilding the index removes the entries that match no row.
+	var keys []string
+	if err := g.st.Scan(ctx, importerPrefix, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		t.Fatalf("Scan index: %v", err)
+	}
+	want := []string{importerPrefix + "c a", importerPrefix + "c b", importerPrefix + "c e"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Index keys: got %q, want %q", keys, want)
+	}
+
+	// Entries added after the index is built are used, and those that are
+	// stale are checked against the rows of their importers.
+	addImports(ctx, t, g, "a")
+	addImports(ctx, t, g, "f", "c")
+	if got, want := importers(ctx, t, g, "c", "", 0), []string{"b", "e", "f"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportersAfter(c) after changes: got %q, want %q", got, want)
+	}
+}
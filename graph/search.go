@@ -0,0 +1,242 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The search index records the name of each package and the grams of its
+// import path, the substrings of up to gramLen bytes starting at each offset.
+// Each entry is a key of the form
+//
+//	@search/name/<name> <import-path>
+//	@search/gram/<gram> <import-path>
+//
+// with an empty value. A space cannot occur in an import path, so the indexed
+// value ends at the first space. Every occurrence of a string of up to gramLen
+// bytes in an import path is the beginning of one of its grams, so a prefix
+// scan of the grams finds all the paths containing the string.
+const (
+	searchNamePrefix = searchPrefix + "name/"
+	searchGramPrefix = searchPrefix + "gram/"
+
+	gramLen = 3
+)
+
+// A SearchKind selects how Search matches packages.
+type SearchKind int
+
+// Constants for the SearchKind type.
+const (
+	SearchPrefix    SearchKind = iota // the import path has the query as a prefix
+	SearchSubstring                   // the import path contains the query
+	SearchName                        // the package name equals the query
+)
+
+// ParseSearchKind parses the name of a search kind, one of "prefix",
+// "substring", or "name".
+func ParseSearchKind(s string) (SearchKind, error) {
+	switch s {
+	case "prefix", "":
+		return SearchPrefix, nil
+	case "substring":
+		return SearchSubstring, nil
+	case "name":
+		return SearchName, nil
+	}
+	return 0, fmt.Errorf("unknown search kind %q", s)
+}
+
+// Search calls f with the row of each package matching query, in order of
+// import path. Prefix searches read the package rows directly; other searches
+// use the search index, which Add and Put maintain and Reindex rebuilds. If f
+// reports an error, searching terminates. If the error is ErrStopScan Search
+// returns nil; otherwise Search returns the error from f.
+func (g *Graph) Search(ctx context.Context, kind SearchKind, query string, f func(*Row) error) error {
+	return g.SearchAfter(ctx, kind, query, "", f)
+}
+
+// SearchAfter behaves as Search, but skips the packages whose import paths are
+// not greater than after, without reading their rows. Passing the last import
+// path reported by a search that was stopped resumes it where it left off.
+func (g *Graph) SearchAfter(ctx context.Context, kind SearchKind, query, after string, f func(*Row) error) error {
+	if kind == SearchPrefix {
+		return g.ScanAfter(ctx, query, after, f)
+	}
+	var match func(*Row) bool
+	var paths []string
+	var err error
+	switch kind {
+	case SearchName:
+		match = func(row *Row) bool { return row.Name == query }
+		paths, err = g.indexed(ctx, searchNamePrefix+query+" ")
+	case SearchSubstring:
+		match = func(row *Row) bool { return strings.Contains(row.ImportPath, query) }
+		paths, err = g.containing(ctx, query)
+	default:
+		return fmt.Errorf("unknown search kind %d", kind)
+	}
+	if err != nil {
+		return err
+	}
+	for _, ipath := range paths {
+		if ipath <= after {
+			continue
+		}
+		row, err := g.Row(ctx, ipath)
+		if err == ErrNotFound {
+			continue // stale index entry
+		} else if err != nil {
+			return err
+		} else if !match(row) {
+			continue
+		}
+		if err := f(row); err == ErrStopScan {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containing returns the import paths whose grams show they may contain s, in
+// sorted order. The caller must check the paths against s.
+func (g *Graph) containing(ctx context.Context, s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	} else if len(s) <= gramLen {
+		return g.indexed(ctx, searchGramPrefix+s)
+	}
+	var paths []string
+	for i := 0; i+gramLen <= len(s); i++ {
+		next, err := g.indexed(ctx, searchGramPrefix+s[i:i+gramLen]+" ")
+		if err != nil {
+			return nil, err
+		} else if i != 0 {
+			next = intersect(paths, next)
+		}
+		if paths = next; len(paths) == 0 {
+			break
+		}
+	}
+	return paths, nil
+}
+
+// indexed returns the import paths of the search index entries with the given
+// key prefix, in sorted order without duplicates.
+func (g *Graph) indexed(ctx context.Context, prefix string) ([]string, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	if err := g.st.Scan(ctx, prefix, func(key string) error {
+		i := strings.Index(key, " ")
+		if i < 0 {
+			return nil // malformed entry
+		}
+		ipath := key[i+1:]
+		if !seen[ipath] {
+			seen[ipath] = true
+			paths = append(paths, ipath)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// intersect returns the strings in both a and b, which must be sorted.
+func intersect(a, b []string) []string {
+	var out []string
+	for len(a) != 0 && len(b) != 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			out = append(out, a[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+	return out
+}
+
+// searchKeys returns the search index keys for row.
+func searchKeys(row *Row) []string {
+	ipath := row.ImportPath
+	var keys []string
+	if row.Name != "" {
+		keys = append(keys, searchNamePrefix+row.Name+" "+ipath)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < len(ipath); i++ {
+		end := i + gramLen
+		if end > len(ipath) {
+			end = len(ipath)
+		}
+		if gram := ipath[i:end]; !seen[gram] {
+			seen[gram] = true
+			keys = append(keys, searchGramPrefix+gram+" "+ipath)
+		}
+	}
+	return keys
+}
+
+// index adds the search index entries for row. Entries for a previous version
+// of the row are not removed; Search skips them, and Reindex removes them.
+func (g *Graph) index(ctx context.Context, row *Row) error {
+	for _, key := range searchKeys(row) {
+		if err := g.st.Store(ctx, key, new(IndexEntry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reindex rebuilds the search index from the package rows of the graph, adding
+// the entries for rows stored before the index existed and removing entries
+// that no longer match any row.
+func (g *Graph) Reindex(ctx context.Context) error {
+	want := make(map[string]bool)
+	if err := g.Scan(ctx, "", func(row *Row) error {
+		for _, key := range searchKeys(row) {
+			want[key] = true
+		}
+		return g.index(ctx, row)
+	}); err != nil {
+		return err
+	}
+	var stale []string
+	if err := g.st.Scan(ctx, searchPrefix, func(key string) error {
+		if !want[key] {
+			stale = append(stale, key)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, key := range stale {
+		if err := g.st.Delete(ctx, key); err != nil && err != ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// A Snapshotter is a Storage that can provide a read-only view of its
+// contents as of the time Snapshot is called, unaffected by later writes. If
+// the storage given to New does not implement this interface, the graph
+// provides snapshots itself, by preserving the previous value of each key
+// written while a snapshot is open; that requires that Scan visit keys in
+// increasing lexicographic order.
+type Snapshotter interface {
+	Storage
+
+	// Snapshot returns a view of the storage as of the call. Writes to the
+	// view must fail. The caller must close the view when it is no longer
+	// needed, to release the resources it holds.
+	Snapshot(ctx context.Context) (Storage, io.Closer, error)
+}
+
+// ErrReadOnly is reported by writes to a snapshot.
+var ErrReadOnly = errors.New("snapshot is read-only")
+
+var errClosed = errors.New("snapshot is closed")
+
+// Snapshot returns a graph that reads a consistent view of g as of the call,
+// which is not affected by later writes to g. This allows a long analysis to
+// run while g is being updated. The caller must close the snapshot when it is
+// no longer needed; writes to g retain data for as long as it is open. The
+// snapshot cannot be written, and cannot be read once it is closed. Its
+// Version is the version of g as of the call.
+func (g *Graph) Snapshot(ctx context.Context) (*Graph, io.Closer, error) {
+	st, c, err := g.st.(Snapshotter).Snapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	view := &snapshotView{Storage: st, closer: c}
+	return &Graph{st: view, version: -1}, view, nil
+}
+
+// snapshotView wraps the storage of a graph snapshot so that reads fail once
+// the snapshot is closed, rather than reading whatever the storage then
+// holds. It implements Snapshotter and io.Closer.
+type snapshotView struct {
+	Storage
+	closer io.Closer
+	closed int32 // accessed atomically; nonzero once closed
+}
+
+func (v *snapshotView) isClosed() bool { return atomic.LoadInt32(&v.closed) != 0 }
+
+// Load implements part of the Storage interface.
+func (v *snapshotView) Load(ctx context.Context, key string, val proto.Message) error {
+	if v.isClosed() {
+		return errClosed
+	}
+	return v.Storage.Load(ctx, key, val)
+}
+
+// Scan implements part of the Storage interface. If the snapshot is closed
+// during the scan, the scan stops and reports an error.
+func (v *snapshotView) Scan(ctx context.Context, prefix string, f func(string) error) error {
+	if v.isClosed() {
+		return errClosed
+	}
+	return v.Storage.Scan(ctx, prefix, func(key string) error {
+		if v.isClosed() {
+			return errClosed
+		}
+		return f(key)
+	})
+}
+
+// Snapshot implements the Snapshotter interface. A snapshot of a snapshot is
+// the same view, and closing it has no effect.
+func (v *snapshotView) Snapshot(context.Context) (Storage, io.Closer, error) {
+	return v, nopCloser{}, nil
+}
+
+// Close implements the io.Closer interface. It closes the underlying snapshot
+// the first time it is called.
+func (v *snapshotView) Close() error {
+	if !atomic.CompareAndSwapInt32(&v.closed, 0, 1) {
+		return nil
+	}
+	return v.closer.Close()
+}
+
+// versioned wraps a Storage to implement Snapshotter, by saving the value of
+// each key before it is first modified while a snapshot is open.
+type versioned struct {
+	Storage
+
+	mu    sync.RWMutex
+	snaps map[*snapshot]bool
+}
+
+// snapshot is a view of a versioned storage. It implements Storage and
+// io.Closer.
+type snapshot struct {
+	base *versioned
+
+	// Guarded by base.mu.
+	saved map[string][]byte // :: key → value at the snapshot, nil if absent
+	keys  []string          // keys of saved with non-nil values, in order
+}
+
+// Snapshot implements the Snapshotter interface.
+func (v *versioned) Snapshot(_ context.Context) (Storage, io.Closer, error) {
+	s := &snapshot{base: v, saved: make(map[string][]byte)}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.snaps == nil {
+		v.snaps = make(map[*snapshot]bool)
+	}
+	v.snaps[s] = true
+	return s, s, nil
+}
+
+// preserve records the current value of each of the given keys in the open
+// snapshots that have not already recorded it. The caller must hold v.mu
+// exclusively.
+func (v *versioned) preserve(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		var val []byte
+		loaded := false
+		for s := range v.snaps {
+			if _, ok := s.saved[key]; ok {
+				continue
+			}
+			if !loaded {
+				var raw rawMessage
+				if err := v.Storage.Load(ctx, key, &raw); err == nil {
+					val = append([]byte{}, raw...) // non-nil even if empty
+				} else if err != ErrNotFound {
+					return err
+				}
+				loaded = true
+			}
+			s.saved[key] = val
+			if val != nil {
+				i := sort.SearchStrings(s.keys, key)
+				s.keys = append(s.keys, "")
+				copy(s.keys[i+1:], s.keys[i:])
+				s.keys[i] = key
+			}
+		}
+	}
+	return nil
+}
+
+// modify calls f to modify the given keys of the underlying storage, after
+// preserving their values for any open snapshots. While no snapshot is open,
+// nothing needs preserving and v.mu is held only shared, so that writes do not
+// exclude one another; holding it keeps a snapshot from opening until f is
+// done.
+func (v *versioned) modify(ctx context.Context, f func() error, keys ...string) error {
+	v.mu.RLock()
+	if len(v.snaps) == 0 {
+		defer v.mu.RUnlock()
+		return f()
+	}
+	v.mu.RUnlock()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.preserve(ctx, keys...); err != nil {
+		return err
+	}
+	return f()
+}
+
+// Store implements part of the Storage interface.
+func (v *versioned) Store(ctx context.Context, key string, val proto.Message) error {
+	return v.modify(ctx, func() error { return v.Storage.Store(ctx, key, val) }, key)
+}
+
+// Delete implements part of the Storage interface.
+func (v *versioned) Delete(ctx context.Context, key string) error {
+	return v.modify(ctx, func() error { return v.Storage.Delete(ctx, key) }, key)
+}
+
+// Rename implements part of the Storage interface.
+func (v *versioned) Rename(ctx context.Context, oldKey, newKey string) error {
+	return v.modify(ctx, func() error { return v.Storage.Rename(ctx, oldKey, newKey) }, oldKey, newKey)
+}
+
+// Load implements part of the Storage interface.
+func (s *snapshot) Load(ctx context.Context, key string, val proto.Message) error {
+	s.base.mu.RLock()
+	defer s.base.mu.RUnlock()
+	if s.saved == nil {
+		return errClosed
+	} else if old, ok := s.saved[key]; !ok {
+		return s.base.Storage.Load(ctx, key, val)
+	} else if old == nil {
+		return ErrNotFound
+	} else {
+		return proto.Unmarshal(old, val)
+	}
+}
+
+// Scan implements part of the Storage interface. It merges the keys of the
+// underlying storage, less those modified since the snapshot, with the keys
+// whose values the snapshot preserved.
+func (s *snapshot) Scan(ctx context.Context, prefix string, f func(string) error) error {
+	var last string // the greatest key reported so far
+	started := false
+
+	// pending returns the preserved keys with prefix after last, up to and
+	// including end, or all of them if end == "".
+	pending := func(end string) []string {
+		s.base.mu.RLock()
+		defer s.base.mu.RUnlock()
+		i := sort.SearchStrings(s.keys, prefix)
+		var out []string
+		for _, key := range s.keys[i:] {
+			if !strings.HasPrefix(key, prefix) || (end != "" && key > end) {
+				break
+			} else if !started || key > last {
+				out = append(out, key)
+			}
+		}
+		return out
+	}
+	modified := func(key string) bool {
+		s.base.mu.RLock()
+		defer s.base.mu.RUnlock()
+		_, ok := s.saved[key]
+		return ok
+	}
+	emit := func(key string) error {
+		last, started = key, true
+		return f(key)
+	}
+
+	if err := s.base.Storage.Scan(ctx, prefix, func(key string) error {
+		for _, old := range pending(key) {
+			if err := emit(old); err != nil {
+				return err
+			}
+		}
+		if (started && key <= last) || modified(key) {
+			return nil // reported above, or absent from the snapshot
+		}
+		return emit(key)
+	}); err != nil {
+		return err
+	}
+	for _, old := range pending("") {
+		if err := emit(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Snapshot implements the Snapshotter interface. A snapshot of a snapshot is
+// the same view, and closing it has no effect.
+func (s *snapshot) Snapshot(context.Context) (Storage, io.Closer, error) { return s, nopCloser{}, nil }
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Store implements part of the Storage interface. It reports ErrReadOnly.
+func (*snapshot) Store(context.Context, string, proto.Message) error { return ErrReadOnly }
+
+// Delete implements part of the Storage interface. It reports ErrReadOnly.
+func (*snapshot) Delete(context.Context, string) error { return ErrReadOnly }
+
+// Rename implements part of the Storage interface. It reports ErrReadOnly.
+func (*snapshot) Rename(context.Context, string, string) error { return ErrReadOnly }
+
+// Close implements the io.Closer interface. It releases the values preserved
+// for the snapshot.
+func (s *snapshot) Close() error {
+	s.base.mu.Lock()
+	defer s.base.mu.Unlock()
+	delete(s.base.snaps, s)
+	s.saved, s.keys = nil, nil
+	return nil
+}
+
+// rawMessage is a proto.Message that holds its encoding verbatim, so that a
+// stored value can be preserved without knowing its type.
+type rawMessage []byte
+
+func (r *rawMessage) Reset()         { *r = nil }
+func (r *rawMessage) String() string { return string(*r) }
+func (*rawMessage) ProtoMessage()    {}
+
+// Marshal implements the proto.Marshaler interface.
+func (r *rawMessage) Marshal() ([]byte, error) { return *r, nil }
+
+// Unmarshal implements the proto.Unmarshaler interface.
+func (r *rawMessage) Unmarshal(data []byte) error {
+	*r = append((*r)[:0], data...)
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	addImports(ctx, t, g, "a")
+	addImports(ctx, t, g, "c")
+	addImports(ctx, t, g, "e", "a")
+	v0 := mustVersion(ctx, t, g)
+
+	snap, closer, err := g.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot: unexpected error: %v", err)
+	}
+
+	// Modify the graph after the snapshot is taken: add keys before, between,
+	// and after the existing ones, and replace and remove existing ones.
+	addImports(ctx, t, g, "b")
+	addImports(ctx, t, g, "d")
+	addImports(ctx, t, g, "e", "c")
+	addImports(ctx, t, g, "f")
+	if err := g.Delete(ctx, "c"); err != nil {
+		t.Fatalf("Delete: unexpected error: %v", err)
+	}
+
+	if got, want := scanPaths(ctx, t, g, ""), []string{"a", "b", "d", "e", "f"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan graph: got %q, want %q", got, want)
+	}
+	if got, want := scanPaths(ctx, t, snap, ""), []string{"a", "c", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan snapshot: got %q, want %q", got, want)
+	}
+	if got, err := snap.Imports(ctx, "e"); err != nil {
+		t.Errorf("Snapshot Imports: unexpected error: %v", err)
+	} else if want := []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot Imports: got %q, want %q", got, want)
+	}
+	if _, err := snap.Row(ctx, "b"); err != ErrNotFound {
+		t.Errorf("Snapshot Row of a later package: got error %v, want %v", err, ErrNotFound)
+	}
+	if v := mustVersion(ctx, t, snap); v != v0 {
+		t.Errorf("Snapshot Version: got %d, want %d", v, v0)
+	}
+
+	// The snapshot cannot be written.
+	if err := snap.Add(ctx, batchRepo, &deps.Package{Name: "p", ImportPath: "z"}); err != ErrReadOnly {
+		t.Errorf("Snapshot Add: got error %v, want %v", err, ErrReadOnly)
+	}
+
+	// Once closed, the snapshot cannot be read, and closing it again has no
+	// effect.
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close: unexpected error: %v", err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close again: unexpected error: %v", err)
+	}
+	if row, err := snap.Row(ctx, "a"); err == nil {
+		t.Errorf("Row after Close: got %+v, want error", row)
+	}
+	if err := snap.Scan(ctx, "", func(*Row) error { return nil }); err == nil {
+		t.Error("Scan after Close: got nil, want error")
+	}
+
+	// With no snapshot open, nothing is preserved.
+	addImports(ctx, t, g, "g")
+	if n := len(g.st.(*versioned).snaps); n != 0 {
+		t.Errorf("Open snapshots after Close: got %d, want 0", n)
+	}
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	var want []string
+	for i := 0; i < 50; i += 2 {
+		ipath := fmt.Sprintf("p%02d", i)
+		addImports(ctx, t, g, ipath)
+		want = append(want, ipath)
+	}
+	snap, closer, err := g.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot: unexpected error: %v", err)
+	}
+	defer closer.Close()
+
+	// Scans of the snapshot see the same rows while the graph changes.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i < 50; i += 2 {
+			pkg := &deps.Package{Name: "p", ImportPath: fmt.Sprintf("p%02d", i)}
+			if err := g.Add(ctx, batchRepo, pkg); err != nil {
+				t.Errorf("Add %q: %v", pkg.ImportPath, err)
+			}
+			if err := g.Delete(ctx, fmt.Sprintf("p%02d", i-1)); err != nil {
+				t.Errorf("Delete: %v", err)
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		if got := scanPaths(ctx, t, snap, ""); !reflect.DeepEqual(got, want) {
+			t.Errorf("Scan snapshot #%d: got %q, want %q", i+1, got, want)
+		}
+	}
+	wg.Wait()
+	if got := scanPaths(ctx, t, snap, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan snapshot: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// TombstoneKey returns the storage key for the tombstone of the package with
+// the specified import path.
+func TombstoneKey(ipath string) string { return tombstonePrefix + ipath }
+
+// Delete removes the row for the package with import path ipath, and leaves a
+// tombstone in its place: a copy of the row with its Deleted field set to the
+// current time. Tombstones are not visited by Row or Scan, but can be read
+// with Tombstone, so that a package that was removed can be distinguished
+// from one that never existed. Adding the package again removes its
+// tombstone. If there is no row for ipath, Delete reports ErrNotFound.
+func (g *Graph) Delete(ctx context.Context, ipath string) error {
+	return g.deleteAt(ctx, ipath, time.Now().Unix())
+}
+
+// deleteAt deletes ipath as Delete does, as of the given time in seconds since
+// the Unix epoch.
+func (g *Graph) deleteAt(ctx context.Context, ipath string, now int64) (err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	row, err := g.Row(ctx, ipath)
+	if err != nil {
+		return err
+	}
+	defer g.changed(ctx, &err)
+	if err := g.record(&JournalEntry{Op: JournalOp_DELETE, Key: ipath, Time: now}); err != nil {
+		return err
+	}
+	row.Deleted = now
+	if err := g.st.Store(ctx, TombstoneKey(ipath), row); err != nil {
+		return err
+	}
+	return g.st.Delete(ctx, ipath)
+}
+
+// Tombstone returns the tombstone of the deleted package with import path
+// ipath. If the package has not been deleted, or was added again after it
+// was deleted, Tombstone reports ErrNotFound.
+func (g *Graph) Tombstone(ctx context.Context, ipath string) (*Row, error) {
+	var row Row
+	if err := g.st.Load(ctx, TombstoneKey(ipath), &row); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// ScanTombstones calls f with the tombstone of each deleted package whose
+// import path has the specified prefix. If f reports an error, scanning
+// terminates. If the error is ErrStopScan ScanTombstones returns nil;
+// otherwise ScanTombstones returns the error from f.
+func (g *Graph) ScanTombstones(ctx context.Context, prefix string, f func(*Row) error) error {
+	err := g.st.Scan(ctx, tombstonePrefix+prefix, func(key string) error {
+		row, err := g.Tombstone(ctx, strings.TrimPrefix(key, tombstonePrefix))
+		if err != nil {
+			return err
+		}
+		return f(row)
+	})
+	if err == ErrStopScan {
+		return nil
+	}
+	return err
+}
+
+// clearTombstone removes the tombstone for ipath, if there is one.
+func (g *Graph) clearTombstone(ctx context.Context, ipath string) error {
+	if err := g.st.Delete(ctx, TombstoneKey(ipath)); err != nil && err != ErrNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTombstones(t *testing.T) {
+	ctx := context.Background()
+	g := New(newStore())
+	addImports(ctx, t, g, "a/x", "b")
+	addImports(ctx, t, g, "a/y")
+	addImports(ctx, t, g, "b")
+
+	v0 := mustVersion(ctx, t, g)
+	if err := g.deleteAt(ctx, "a/x", 100); err != nil {
+		t.Fatalf("Delete: unexpected error: %v", err)
+	}
+	if v := mustVersion(ctx, t, g); v != v0+1 {
+		t.Errorf("Version after Delete: got %d, want %d", v, v0+1)
+	}
+	if err := g.Delete(ctx, "a/x"); err != ErrNotFound {
+		t.Errorf("Delete again: got error %v, want %v", err, ErrNotFound)
+	}
+	if err := g.Delete(ctx, "nonesuch"); err != ErrNotFound {
+		t.Errorf("Delete nonesuch: got error %v, want %v", err, ErrNotFound)
+	}
+
+	// The row is gone, and tombstones are not visited by Scan.
+	checkRows(ctx, t, g, map[string]bool{"a/x": false, "a/y": true, "b": true})
+	if got, want := scanPaths(ctx, t, g, ""), []string{"a/y", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan: got %q, want %q", got, want)
+	}
+
+	// The tombstone keeps the contents of the row and the time of deletion.
+	ts, err := g.Tombstone(ctx, "a/x")
+	if err != nil {
+		t.Fatalf("Tombstone: unexpected error: %v", err)
+	} else if ts.ImportPath != "a/x" || ts.Deleted != 100 || !reflect.DeepEqual(ts.Directs, []string{"b"}) {
+		t.Errorf("Tombstone: got %+v, want a/x deleted at 100 importing b", ts)
+	}
+	if _, err := g.Tombstone(ctx, "a/y"); err != ErrNotFound {
+		t.Errorf("Tombstone of a present package: got error %v, want %v", err, ErrNotFound)
+	}
+	for _, test := range []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"a/x"}},
+		{"a/", []string{"a/x"}},
+		{"b", nil},
+	} {
+		var got []string
+		if err := g.ScanTombstones(ctx, test.prefix, func(row *Row) error {
+			got = append(got, row.ImportPath)
+			return nil
+		}); err != nil {
+			t.Errorf("ScanTombstones(%q): unexpected error: %v", test.prefix, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ScanTombstones(%q): got %q, want %q", test.prefix, got, test.want)
+		}
+	}
+
+	// Adding the package again removes its tombstone.
+	addImports(ctx, t, g, "a/x")
+	checkRows(ctx, t, g, map[string]bool{"a/x": true})
+	if ts, err := g.Tombstone(ctx, "a/x"); err != ErrNotFound {
+		t.Errorf("Tombstone after Add: got (%+v, %v), want %v", ts, err, ErrNotFound)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/creachadair/repodeps/semver"
+)
+
+// An Impact estimates the changes one module needs to adopt an upgrade.
+type Impact struct {
+	Module     string // the path of the affected module
+	Repository string // the repository where the module is defined
+
+	// The version of the upgraded module now selected in the build list of
+	// the affected module, or the path@version of the module selected in its
+	// place if that has a different major version.
+	Current string
+
+	// Whether the affected module requires the upgraded module directly. If
+	// not, adopting the upgrade adds a requirement to its go.mod file.
+	Direct bool
+
+	// The number of packages of the affected module that import packages of
+	// the upgraded module. If the upgrade changes the major version, these
+	// packages must change their import paths.
+	Importers int
+
+	// Other modules whose selected versions the upgrade would raise, at
+	// their new versions, ordered by path.
+	Raised []*Requirement
+}
+
+// UpgradeImpact estimates the impact of upgrading the module with the given
+// path to version, for each unversioned module in the graph (such as those
+// scanned from working trees) whose build list selects an older version of
+// path. The upgraded build list is simulated by raising the requirement of
+// the affected module to path@version; if that version is not in the graph,
+// its own requirements are unknown and Raised is empty.
+//
+// A module that selects an older major version of the module, whose path
+// differs from path only in its major version suffix, is also affected, since
+// adopting the upgrade means changing its import paths.
+//
+// UpgradeImpact calls f with the impact for each affected module in order by
+// module path. If f reports an error, UpgradeImpact stops and returns it,
+// except that ErrStopScan stops without error.
+func (g *Graph) UpgradeImpact(ctx context.Context, path, version string, f func(*Impact) error) error {
+	family := pathFamily(path)
+
+	// Count the packages of each module that import packages of the family.
+	importers := make(map[string]int)
+	if err := g.Scan(ctx, "", func(row *Row) error {
+		for _, dep := range row.Directs {
+			if inFamily(dep, family) {
+				mpath, _ := SplitModule(row.Module)
+				importers[mpath]++
+				break
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var roots []*Module
+	if err := g.ScanModules(ctx, "", func(mod *Module) error {
+		if mod.Version == "" && mod.Path != path {
+			roots = append(roots, mod)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, root := range roots {
+		before, err := g.buildList(ctx, root)
+		if err != nil {
+			return err
+		}
+		var sel *Requirement
+		for _, req := range before.Selected[1:] {
+			if req.Path == path {
+				sel = req
+				break
+			} else if pathFamily(req.Path) == family && sel == nil {
+				sel = req
+			}
+		}
+		if sel == nil || semver.Compare(sel.Version, version) >= 0 {
+			continue // not affected
+		}
+		cur := sel.Version
+		if sel.Path != path {
+			cur = sel.Path + "@" + sel.Version
+		}
+		imp := &Impact{
+			Module:     root.Path,
+			Repository: root.Repository,
+			Current:    cur,
+			Importers:  importers[root.Path],
+		}
+
+		// Simulate the upgrade by raising (or adding) the requirement.
+		next := *root
+		next.Requires = nil
+		for _, req := range root.Requires {
+			if req.Path == path {
+				imp.Direct = true
+				continue
+			}
+			next.Requires = append(next.Requires, req)
+		}
+		next.Requires = append(next.Requires, &Requirement{Path: path, Version: version})
+		after, err := g.buildList(ctx, &next)
+		if err != nil {
+			return err
+		}
+		old := make(map[string]string)
+		for _, req := range before.Selected {
+			old[req.Path] = req.Version
+		}
+		for _, req := range after.Selected {
+			if req.Path == path || req.Path == root.Path {
+				continue
+			} else if v, ok := old[req.Path]; ok && semver.Compare(req.Version, v) > 0 {
+				imp.Raised = append(imp.Raised, req)
+			}
+		}
+		if err := f(imp); err == ErrStopScan {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathFamily returns path without its major version suffix, if any, so that
+// the module paths of all the major versions of a module have the same
+// family. A gopkg.in suffix such as ".v2" is also removed.
+func pathFamily(path string) string {
+	if i := strings.LastIndex(path, "/v"); i >= 0 && isMajor(path[i+2:]) {
+		return path[:i]
+	}
+	if strings.HasPrefix(path, "gopkg.in/") {
+		if i := strings.LastIndex(path, ".v"); i >= 0 && isMajor(path[i+2:]) {
+			return path[:i]
+		}
+	}
+	return path
+}
+
+// inFamily reports whether the import path ipath names a package of some
+// module whose path has the given family.
+func inFamily(ipath, family string) bool {
+	if ipath == family || strings.HasPrefix(ipath, family+"/") {
+		return true
+	}
+	return strings.HasPrefix(family, "gopkg.in/") && strings.HasPrefix(ipath, family+".v")
+}
+
+// isMajor reports whether s is a major version number of at least 2.
+func isMajor(s string) bool {
+	if s == "" || s[0] == '0' || s == "1" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
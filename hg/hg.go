@@ -0,0 +1,156 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hg analyzes Go dependencies for local Mercurial repositories.
+package hg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/local"
+)
+
+// Load reads the repository structure of a local Mercurial repository. Like
+// local.Load, it returns a single repository. Subrepositories and nested
+// repositories are not scanned.
+func Load(ctx context.Context, dir string, opts *deps.Options) ([]*deps.Repo, error) {
+	if opts == nil {
+		opts = new(deps.Options)
+	}
+	remotes, err := hgPaths(ctx, dir)
+	if err != nil {
+		return nil, err
+	} else if len(remotes) == 0 {
+		return nil, errors.New("no remotes defined")
+	}
+	repo := &deps.Repo{From: dir, Remotes: remotes}
+
+	// If a specific revision was requested, archive it into a temporary
+	// directory laid out like a GOPATH, as local.Load does for Git.
+	bc, root := build.Default, dir
+	if opts.Ref != "" {
+		tmp, err := ioutil.TempDir("", "repodeps")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		url := remotes[0].Url
+		if i := strings.Index(url, "://"); i >= 0 {
+			url = url[i+3:]
+		}
+		root = filepath.Join(tmp, "src", url)
+		if _, err := hg(ctx, dir, "archive", "--type", "files", "--rev", opts.Ref, root); err != nil {
+			return nil, fmt.Errorf("extracting %q: %v", opts.Ref, err)
+		}
+		os.Remove(filepath.Join(root, ".hg_archival.txt"))
+		bc.GOPATH = tmp
+	}
+
+	if err := local.LoadTree(ctx, repo, root, bc, opts); err != nil {
+		return nil, err
+	}
+	rev := opts.Ref
+	if rev == "" {
+		rev = "."
+	}
+	if out, err := hg(ctx, dir, "log", "--rev", rev, "--template", "{node}"); err == nil {
+		if node := strings.TrimSpace(string(out)); strings.Trim(node, "0") != "" {
+			repo.Commit = node // the null revision means there are no commits
+		}
+	}
+	if opts.Activity {
+		act, err := hgActivity(ctx, dir, opts.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("reading history: %v", err)
+		}
+		repo.Activity = act
+	}
+	return []*deps.Repo{repo}, nil
+}
+
+// hg runs a Mercurial command in the repository at dir and returns its output.
+func hg(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "hg", append([]string{"--repository", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "HGPLAIN=1") // disable user configuration of output
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) != 0 {
+			return nil, fmt.Errorf("hg %s: %v\n%s", args[0], err, ee.Stderr)
+		}
+		return nil, fmt.Errorf("hg %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// hgPaths returns the remote paths defined for the repository at dir, with
+// the default path first and the rest in order by name.
+func hgPaths(ctx context.Context, dir string) ([]*deps.Remote, error) {
+	out, err := hg(ctx, dir, "paths")
+	if err != nil {
+		return nil, fmt.Errorf("listing remotes: %v", err)
+	}
+	var rs []*deps.Remote
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, " = ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		rs = append(rs, &deps.Remote{Name: parts[0], Url: deps.CanonicalURL(parts[1])})
+	}
+	sort.SliceStable(rs, func(i, j int) bool {
+		if rs[i].Name == "default" || rs[j].Name == "default" {
+			return rs[i].Name == "default"
+		}
+		return rs[i].Name < rs[j].Name
+	})
+	return rs, nil
+}
+
+// hgActivity summarizes the history of the repository at dir reachable from
+// the revision ref, or from the working directory's parent if ref == "".
+func hgActivity(ctx context.Context, dir, ref string) (*deps.Activity, error) {
+	if ref == "" {
+		ref = "."
+	}
+	out, err := hg(ctx, dir, "log", "--rev", "::("+ref+")", "--template", "{date|hgdate} {author|email}\n")
+	if err != nil {
+		return nil, err
+	}
+	tally := deps.NewActivityTally(time.Now())
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// Each line has the form "<unix-seconds> <tz-offset> <email>".
+		parts := strings.SplitN(line, " ", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		sec, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		tally.Add(time.Unix(sec, 0), parts[2])
+	}
+	return tally.Activity(), nil
+}
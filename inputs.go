@@ -0,0 +1,365 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/trace"
+	"strings"
+	"sync"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/gopath"
+	"github.com/creachadair/repodeps/hg"
+	"github.com/creachadair/repodeps/local"
+	"github.com/creachadair/repodeps/siva"
+	"github.com/creachadair/repodeps/throttle"
+)
+
+// An input describes a single repository or archive to be scanned, along with
+// options that apply only to that input. Inputs given as plain paths have only
+// the Path field set.
+type input struct {
+	Path    string            `json:"path,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Ref     string            `json:"ref,omitempty"`
+	Include []string          `json:"include,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+func (in *input) String() string {
+	if in.Path == "" {
+		return in.URL
+	}
+	return in.Path
+}
+
+// labels returns the labels to attach to the repositories of in, namely those
+// given by -labels together with the labels of in itself, which take priority.
+func (in *input) labels() map[string]string {
+	if len(baseLabels) == 0 {
+		return in.Labels
+	}
+	labels := make(map[string]string)
+	for key, val := range baseLabels {
+		labels[key] = val
+	}
+	for key, val := range in.Labels {
+		labels[key] = val
+	}
+	return labels
+}
+
+// parseLabels parses a comma-separated list of key=value labels.
+func parseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, elt := range strings.Split(s, ",") {
+		if elt = strings.TrimSpace(elt); elt == "" {
+			continue
+		}
+		i := strings.Index(elt, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid label %q, want key=value", elt)
+		}
+		labels[strings.TrimSpace(elt[:i])] = strings.TrimSpace(elt[i+1:])
+	}
+	return labels, nil
+}
+
+// kind describes how in will be scanned: "url" for a remote repository, "gopath"
+// or "modcache" in those modes, "siva" for a .siva archive, or "git" or "hg" for
+// a local repository. A local path that is not a Git or Mercurial repository
+// is reported as "unknown", since loading it will fail.
+func (in *input) kind() string {
+	if in.Path == "" {
+		return "url"
+	} else if *doGOPATH {
+		return "gopath"
+	} else if *doModCache {
+		return "modcache"
+	} else if filepath.Ext(in.Path) == ".siva" {
+		return "siva"
+	} else if _, err := os.Stat(filepath.Join(in.Path, ".git")); err == nil {
+		return "git"
+	} else if isHg(in.Path) {
+		return "hg"
+	}
+	return "unknown"
+}
+
+// load reads the repositories described by in, using opts as the base options
+// for the scan.
+func (in *input) load(ctx context.Context, opts *deps.Options) ([]*deps.Repo, error) {
+	ctx, task := trace.NewTask(ctx, "scan")
+	defer task.End()
+	trace.Log(ctx, "input", in.String())
+
+	o := *opts
+	o.Ref = in.Ref
+	o.Include = in.Include
+
+	var repos []*deps.Repo
+	var err error
+	if in.Path == "" && clones != nil {
+		var dir string
+		if err := remote.Do(ctx, throttle.Host(in.URL), func() error {
+			defer trace.StartRegion(ctx, "fetch").End()
+			var ferr error
+			dir, o.Ref, ferr = clones.Fetch(ctx, in.URL, in.Ref, creds.Lookup(in.URL))
+			return ferr
+		}); err != nil {
+			return nil, err
+		}
+		repos, err = local.Load(ctx, dir, &o)
+		for _, repo := range repos {
+			repo.From = in.URL
+		}
+	} else if in.Path == "" {
+		// Clone the remote into a temporary directory. The clone checks out
+		// the requested ref directly, so it need not be extracted again.
+		tmp, terr := ioutil.TempDir("", "repodeps")
+		if terr != nil {
+			return nil, terr
+		}
+		defer os.RemoveAll(tmp)
+		dir := filepath.Join(tmp, "repo")
+		if err := remote.Do(ctx, throttle.Host(in.URL), func() error {
+			defer trace.StartRegion(ctx, "fetch").End()
+			// Discard whatever a failed attempt left behind.
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+			return local.CloneAuth(ctx, in.URL, dir, in.Ref, creds.Lookup(in.URL))
+		}); err != nil {
+			return nil, err
+		}
+		o.Ref = ""
+		repos, err = local.Load(ctx, dir, &o)
+		for _, repo := range repos {
+			repo.From = in.URL
+		}
+	} else {
+		path, perr := filepath.Abs(in.Path)
+		if perr != nil {
+			return nil, perr
+		}
+		if *doGOPATH {
+			repos, err = gopath.Load(ctx, path, &o)
+		} else if *doModCache {
+			repos, err = gopath.LoadModules(ctx, path, &o)
+		} else if filepath.Ext(path) == ".siva" {
+			repos, err = siva.Load(ctx, path, &o)
+		} else if isHg(path) {
+			repos, err = hg.Load(ctx, path, &o)
+		} else {
+			repos, err = local.Load(ctx, path, &o)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	labels := in.labels()
+	for _, repo := range repos {
+		repo.Labels = labels
+		repo.Schema = deps.SchemaVersion
+		deps.ClassifyImports(repo)
+	}
+	return repos, nil
+}
+
+// defaultRoot returns the default input path for the -gopath and -modcache
+// modes, based on the Go environment.
+func defaultRoot() string {
+	root := filepath.SplitList(build.Default.GOPATH)[0]
+	if !*doModCache {
+		return root
+	} else if mc := os.Getenv("GOMODCACHE"); mc != "" {
+		return mc
+	}
+	return filepath.Join(root, "pkg", "mod")
+}
+
+// parseInput parses a line of input, which is either a plain path or a JSON
+// object encoding an input.
+func parseInput(line string) (*input, error) {
+	if !strings.HasPrefix(line, "{") {
+		return &input{Path: line}, nil
+	}
+	in := new(input)
+	if err := json.Unmarshal([]byte(line), in); err != nil {
+		return nil, err
+	} else if (in.Path == "") == (in.URL == "") {
+		return nil, errors.New("exactly one of path or url must be set")
+	}
+	return in, nil
+}
+
+// inputs returns a channel that delivers the inputs to be processed and is
+// closed when no more are available.
+func inputs() <-chan *input {
+	ch := make(chan *input, len(flag.Args()))
+	recursive := *doRecursive && !*doGOPATH && !*doModCache
+	emit := func(in *input) {
+		if !recursive || in.Path == "" {
+			ch <- in
+		} else if err := findRepos(in.Path, func(repo string) {
+			cp := *in
+			cp.Path = repo
+			ch <- &cp
+		}); err != nil {
+			log.Printf("Searching %q failed: %v", in.Path, err)
+		}
+	}
+	go func() {
+		defer close(ch)
+		for _, arg := range flag.Args() {
+			emit(&input{Path: arg})
+		}
+		if *doReadInputs {
+			s := bufio.NewScanner(os.Stdin)
+			for s.Scan() {
+				line := strings.TrimSpace(s.Text())
+				if line == "" {
+					continue
+				}
+				in, err := parseInput(line)
+				if err != nil {
+					log.Printf("Invalid input %q: %v", line, err)
+					continue
+				}
+				emit(in)
+			}
+		}
+	}()
+	return ch
+}
+
+// isHg reports whether path is the root of a Mercurial repository.
+func isHg(path string) bool {
+	fi, err := os.Stat(filepath.Join(path, ".hg"))
+	return err == nil && fi.IsDir()
+}
+
+// findRepos walks the directory tree rooted at root and calls f with the path
+// of each Git or Mercurial repository or .siva file found. If root is not a
+// directory, f is called with root unchanged.
+func findRepos(root string, f func(string)) error {
+	return filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if !fi.IsDir() {
+			if path == root || filepath.Ext(path) == ".siva" {
+				f(path)
+			}
+			return nil
+		} else if fi.Name() == ".git" || fi.Name() == ".hg" {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil || isHg(path) {
+			f(path)
+			return filepath.SkipDir // don't look inside a repository
+		}
+		return nil
+	})
+}
+
+// dedup tracks which inputs and repositories have been processed during a
+// run, so that duplicates can be skipped. A dedup is safe for concurrent use.
+type dedup struct {
+	enabled bool
+
+	mu      sync.Mutex
+	paths   map[string]bool // :: abs path or URL → seen
+	urls    map[string]bool // :: remote URL@commit → seen
+	skipped int
+}
+
+func newDedup(enabled bool) *dedup {
+	return &dedup{
+		enabled: enabled,
+		paths:   make(map[string]bool),
+		urls:    make(map[string]bool),
+	}
+}
+
+// input reports whether in should be processed, and records it as seen.
+func (d *dedup) input(in *input) bool {
+	if !d.enabled {
+		return true
+	}
+	key := deps.CanonicalURL(in.URL)
+	if in.Path != "" {
+		key = in.Path
+		if abs, err := filepath.Abs(in.Path); err == nil {
+			key = abs
+			if real, err := filepath.EvalSymlinks(abs); err == nil {
+				key = real
+			}
+		}
+	}
+	key += "@" + in.Ref
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.paths[key] {
+		d.skipped++
+		return false
+	}
+	d.paths[key] = true
+	return true
+}
+
+// repos returns the subset of rs whose remote URLs and commits have not
+// already been seen together, and records them as seen, so that one
+// repository read at different revisions is kept once per revision.
+// Repositories without remotes are always kept, as they have no identity to
+// compare.
+func (d *dedup) repos(rs []*deps.Repo) []*deps.Repo {
+	if !d.enabled {
+		return rs
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var keep []*deps.Repo
+	for _, r := range rs {
+		if len(r.Remotes) != 0 {
+			url := r.Remotes[0].Url
+			key := url + "@" + r.Commit
+			if d.urls[key] {
+				log.Printf("Skipped duplicate repository %q in %q", url, r.From)
+				d.skipped++
+				continue
+			}
+			d.urls[key] = true
+		}
+		keep = append(keep, r)
+	}
+	return keep
+}
+
+func (d *dedup) numSkipped() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.skipped
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memstore implements the graph.Storage interface in memory, for
+// tests.
+//
+// This package does not import graph, so that the tests of package graph can
+// use it. The caller supplies the error to report for a missing key, which
+// must be graph.ErrNotFound.
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Store is an in-memory implementation of graph.Storage. It is safe for
+// concurrent use.
+type Store struct {
+	notFound error
+
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// New returns a new empty store that reports notFound for missing keys.
+func New(notFound error) *Store {
+	return &Store{notFound: notFound, data: make(map[string][]byte)}
+}
+
+// Load implements part of the graph.Storage interface.
+func (s *Store) Load(_ context.Context, key string, val proto.Message) error {
+	s.mu.Lock()
+	bits, ok := s.data[key]
+	s.mu.Unlock()
+	if !ok {
+		return s.notFound
+	}
+	return proto.Unmarshal(bits, val)
+}
+
+// Store implements part of the graph.Storage interface.
+func (s *Store) Store(_ context.Context, key string, val proto.Message) error {
+	bits, err := proto.Marshal(val)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = bits
+	return nil
+}
+
+// Scan implements part of the graph.Storage interface. It visits the keys in
+// increasing lexicographic order, as of the call.
+func (s *Store) Scan(_ context.Context, prefix string, f func(string) error) error {
+	var keys []string
+	s.mu.Lock()
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := f(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete implements part of the graph.Storage interface.
+func (s *Store) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[key]; !ok {
+		return s.notFound
+	}
+	delete(s.data, key)
+	return nil
+}
+
+// Rename implements part of the graph.Storage interface.
+func (s *Store) Rename(_ context.Context, oldKey, newKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bits, ok := s.data[oldKey]
+	if !ok {
+		return s.notFound
+	}
+	delete(s.data, oldKey)
+	s.data[newKey] = bits
+	return nil
+}
+
+// Contents returns a copy of the contents of s, mapping each key to the
+// encoding of its value.
+func (s *Store) Contents() map[string][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string][]byte, len(s.data))
+	for key, bits := range s.data {
+		out[key] = bits
+	}
+	return out
+}
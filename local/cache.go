@@ -0,0 +1,220 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/creachadair/repodeps/auth"
+	"github.com/creachadair/repodeps/deps"
+)
+
+// A CloneCache keeps bare clones of remote repositories on disk, keyed by the
+// canonical forms of their URLs, so that rescanning a repository fetches only
+// the objects that are new since it was last scanned. A CloneCache is safe
+// for concurrent use by multiple goroutines, but not by multiple processes.
+//
+// If Shared is true, the objects of all clones in the cache are kept in a
+// single common store, which each clone borrows from as a Git alternate. A
+// fetch then downloads only objects the store does not already have, and a
+// clone holds on its own only what the store lacks, so that many forks of the
+// same project cost about as much disk as one.
+type CloneCache struct {
+	Dir    string // the root directory of the cache
+	Shared bool   // share objects among clones via a common store
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // per clone directory
+}
+
+// NewCloneCache returns a CloneCache rooted at dir, which is created if it
+// does not exist.
+func NewCloneCache(dir string) (*CloneCache, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	} else if err := os.MkdirAll(abs, 0755); err != nil {
+		return nil, err
+	}
+	return &CloneCache{Dir: abs, locks: make(map[string]*sync.Mutex)}, nil
+}
+
+// Path returns the directory where the clone of url is cached.
+func (c *CloneCache) Path(url string) (string, error) {
+	path := filepath.Join(c.Dir, filepath.FromSlash(deps.CanonicalURL(url))) + ".git"
+	if !strings.HasPrefix(path, c.Dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid repository URL %q", url)
+	}
+	return path, nil
+}
+
+// sharedDir returns the directory of the common object store. Its name begins
+// with a dot, which no canonical URL host does, so it cannot collide with the
+// path of a clone.
+func (c *CloneCache) sharedDir() string { return filepath.Join(c.Dir, ".shared.git") }
+
+// Fetch brings the cached clone of the repository at url up to date with ref,
+// the name of a branch or tag, or with the default branch if ref == "". The
+// clone is created if it does not yet exist. Only the tip commit of ref is
+// fetched, unless c.Shared is true, in which case its full history is fetched
+// into the common store. Fetch returns the directory of the clone and the ID
+// of the fetched commit, which may be scanned with Load by setting the Ref
+// option. If cred != nil, it is used to authenticate to the remote.
+func (c *CloneCache) Fetch(ctx context.Context, url, ref string, cred *auth.Credential) (dir, commit string, err error) {
+	dir, err = c.Path(url)
+	if err != nil {
+		return "", "", err
+	}
+	lock := c.lock(dir)
+	lock.Lock()
+	defer lock.Unlock()
+
+	env, err := cred.GitEnv(ctx, url)
+	if err != nil {
+		return "", "", fmt.Errorf("authenticating to %q: %v", url, err)
+	}
+	git := func(args ...string) (string, error) { return runGit(ctx, dir, env, args...) }
+
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", "", err
+		} else if _, err := git("init", "--quiet", "--bare"); err != nil {
+			return "", "", err
+		} else if _, err := git("remote", "add", "origin", url); err != nil {
+			return "", "", err
+		}
+	} else if err != nil {
+		return "", "", err
+	} else if _, err := git("remote", "set-url", "origin", url); err != nil {
+		return "", "", err
+	}
+	if c.Shared {
+		if err := c.borrow(ctx, dir); err != nil {
+			return "", "", err
+		}
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	// Keep a local ref to what was fetched, so that the next fetch can tell
+	// the remote which objects are already present.
+	local := "refs/cache/" + ref
+	args := []string{"fetch", "--quiet"}
+	if !c.Shared {
+		args = append(args, "--depth=1")
+	} else if _, err := os.Stat(filepath.Join(dir, "shallow")); err == nil {
+		// History is fetched in full when it is shared, since clones cannot
+		// borrow from a shallow store.
+		args = append(args, "--unshallow")
+	}
+	if _, err := git(append(args, "origin", "+"+ref+":"+local)...); err != nil {
+		return "", "", fmt.Errorf("fetching %q: %v", url, err)
+	}
+	commit, err = git("rev-parse", "--verify", local+"^{commit}")
+	if err != nil {
+		return "", "", err
+	}
+	if c.Shared {
+		if err := c.share(ctx, url, dir, local); err != nil {
+			return "", "", err
+		}
+	}
+	return dir, commit, nil
+}
+
+// borrow makes the clone in dir use the common object store as an alternate,
+// creating the store if it does not yet exist.
+func (c *CloneCache) borrow(ctx context.Context, dir string) error {
+	shared := c.sharedDir()
+	lock := c.lock(shared)
+	lock.Lock()
+	defer lock.Unlock()
+	if _, err := os.Stat(filepath.Join(shared, "HEAD")); os.IsNotExist(err) {
+		if err := os.MkdirAll(shared, 0755); err != nil {
+			return err
+		} else if _, err := runGit(ctx, shared, nil, "init", "--quiet", "--bare"); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	info := filepath.Join(dir, "objects", "info")
+	if err := os.MkdirAll(info, 0755); err != nil {
+		return err
+	}
+	alt := filepath.Join(shared, "objects") + "\n"
+	return ioutil.WriteFile(filepath.Join(info, "alternates"), []byte(alt), 0644)
+}
+
+// share copies the objects reachable from ref in the clone in dir into the
+// common object store, and then discards the clone's own copies of them. The
+// store keeps a ref for each URL, so that the objects a clone borrows remain
+// reachable there.
+func (c *CloneCache) share(ctx context.Context, url, dir, ref string) error {
+	shared := c.sharedDir()
+	lock := c.lock(shared)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Name the ref by a digest of the URL, since a canonical URL may contain
+	// characters that are not valid in a ref name.
+	key := fmt.Sprintf("refs/clones/%x", sha1.Sum([]byte(deps.CanonicalURL(url))))
+	// Keep what is fetched packed, so that the clone can drop its loose copies.
+	if _, err := runGit(ctx, shared, nil, "-c", "fetch.unpackLimit=1",
+		"fetch", "--quiet", "--no-tags", dir, "+"+ref+":"+key); err != nil {
+		return fmt.Errorf("sharing objects of %q: %v", url, err)
+	}
+	// Repack the clone without the objects it can now borrow from the store.
+	_, err := runGit(ctx, dir, nil, "repack", "-a", "-d", "-l", "-q")
+	return err
+}
+
+// runGit runs git with the given arguments in dir, and returns its trimmed
+// output. If env != nil, it is added to the environment of the command.
+func runGit(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v\n%s", args[0], err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// lock returns the mutex that serializes updates to the clone in dir.
+func (c *CloneCache) lock(dir string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locks == nil {
+		c.locks = make(map[string]*sync.Mutex)
+	}
+	m, ok := c.locks[dir]
+	if !ok {
+		m = new(sync.Mutex)
+		c.locks[dir] = m
+	}
+	return m
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestCloneCachePath(t *testing.T) {
+	c := &CloneCache{Dir: "/cache"}
+	tests := []struct {
+		url, want string
+	}{
+		{"https://github.com/foo/bar.git", "/cache/github.com/foo/bar.git"},
+		{"git@GitHub.com:foo/bar", "/cache/github.com/foo/bar.git"},
+		{"file:///src/repo", "/cache/src/repo.git"},
+		{"../../etc", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		got, err := c.Path(test.url)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("Path(%q): got %q, want error", test.url, got)
+			}
+		} else if err != nil {
+			t.Errorf("Path(%q): unexpected error: %v", test.url, err)
+		} else if got != filepath.FromSlash(test.want) {
+			t.Errorf("Path(%q): got %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestCloneCacheFetch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	tmp, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+	ctx := context.Background()
+
+	// Two repositories share their first commit, as forks do.
+	src := filepath.Join(tmp, "src")
+	newRepo(t, src, "", map[string]string{"a.go": "package a\n"})
+	fork := filepath.Join(tmp, "fork")
+	testGit(t, tmp, "clone", "-q", src, fork)
+
+	for _, shared := range []bool{false, true} {
+		c, err := NewCloneCache(filepath.Join(tmp, "cache", strconv.FormatBool(shared)))
+		if err != nil {
+			t.Fatalf("NewCloneCache: %v", err)
+		}
+		c.Shared = shared
+
+		fetch := func(repo, ref string) string {
+			t.Helper()
+			url := "file://" + repo
+			dir, commit, err := c.Fetch(ctx, url, ref, nil)
+			if err != nil {
+				t.Fatalf("Fetch(%q, %q): unexpected error: %v", url, ref, err)
+			}
+			if want, _ := c.Path(url); dir != want {
+				t.Errorf("Fetch(%q, %q): got dir %q, want %q", url, ref, dir, want)
+			}
+			if want := headCommit(t, repo); commit != want {
+				t.Errorf("Fetch(%q, %q): got commit %q, want %q", url, ref, commit, want)
+			}
+			if _, err := runGit(ctx, dir, nil, "cat-file", "-e", commit+"^{tree}"); err != nil {
+				t.Errorf("Fetch(%q, %q): commit is not readable: %v", url, ref, err)
+			}
+			return dir
+		}
+
+		// Fetching again picks up new commits, by default branch or by name.
+		fetch(src, "")
+		testGit(t, src, "commit", "-q", "--allow-empty", "-m", "second")
+		dir := fetch(src, "")
+		fetch(src, "main")
+		fetch(fork, "main")
+
+		_, err = os.Stat(filepath.Join(dir, "objects", "info", "alternates"))
+		if shared && err != nil {
+			t.Errorf("Shared clone %q has no alternates: %v", dir, err)
+		} else if !shared && err == nil {
+			t.Errorf("Unshared clone %q has alternates", dir)
+		}
+	}
+
+	// A fetch that fails is reported.
+	c, err := NewCloneCache(filepath.Join(tmp, "cache", "missing"))
+	if err != nil {
+		t.Fatalf("NewCloneCache: %v", err)
+	}
+	url := "file://" + filepath.Join(tmp, "nonesuch")
+	if dir, commit, err := c.Fetch(ctx, url, "", nil); err == nil {
+		t.Errorf("Fetch(%q): got (%q, %q), want error", url, dir, commit)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// A listedPackage is the subset of the output of "go list -json" used to
+// resolve imports.
+type listedPackage struct {
+	Dir        string
+	ImportPath string
+	Imports    []string
+	Error      *struct{ Err string }
+}
+
+// goList runs "go list" for all the packages of the module rooted at dir, and
+// returns the packages it was able to load, keyed by directory. The go
+// command is not permitted to download modules, and does not modify go.mod.
+func goList(ctx context.Context, dir string) (map[string]*listedPackage, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-json", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GO111MODULE=on", "GOWORK=off")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	} else if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	pkgs := make(map[string]*listedPackage)
+	dec := json.NewDecoder(out)
+	for {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			cmd.Wait()
+			return nil, err
+		}
+		if pkg.Error == nil && pkg.Dir != "" {
+			pkgs[pkg.Dir] = &pkg
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("listing packages in %q: %v", dir, err)
+	}
+	return pkgs, nil
+}
+
+// resolvePrecise replaces the imports of the packages of repo, which were
+// found in the repository-relative directories pkgDirs, with those reported
+// by the go command for each of the given modules rooted under root. A module
+// the go command cannot list at all is skipped.
+func resolvePrecise(ctx context.Context, root string, repo *deps.Repo, pkgDirs []string, mods []*deps.Module, opts *deps.Options) error {
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real // the go command reports resolved directories
+	}
+	listed := make(map[string]*listedPackage) // :: repo-relative dir → package
+	for _, mod := range mods {
+		pkgs, err := goList(ctx, filepath.Join(root, filepath.FromSlash(mod.Dir)))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
+			continue
+		}
+		for dir, pkg := range pkgs {
+			if rel, err := filepath.Rel(root, dir); err == nil {
+				listed[filepath.ToSlash(rel)] = pkg
+			}
+		}
+	}
+	for i, pkg := range repo.Packages {
+		if lp, ok := listed[pkgDirs[i]]; ok && pkg.Language == "" {
+			pkg.SetImports(lp.Imports, opts)
+			pkg.Precise = true
+		}
+	}
+	return nil
+}
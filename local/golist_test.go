@@ -0,0 +1,92 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+func TestResolvePrecise(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not available")
+	}
+	root, err := ioutil.TempDir("", "golist")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(root)
+	for name, text := range map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.12\n",
+		"a/a.go": "package a\n\nimport (\n\t\"fmt\"\n\t_ \"example.com/m/b\"\n)\n\nvar _ = fmt.Sprint\n",
+		// Excluded by its build constraint, so the go command does not report
+		// its imports.
+		"a/x.go":         "// +build ignore\n\npackage a\n\nimport _ \"example.com/m/c\"\n",
+		"b/b.go":         "package b\n",
+		"broken/go.mod":  "module\n", // invalid, so it cannot be listed
+		"broken/x/x.go":  "package x\n",
+		"web/index.json": "{}\n",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	// The imports found by scanning are replaced for the packages the go
+	// command can list, and left alone for the others.
+	stale := []string{"example.com/m/b", "example.com/m/c", "fmt"}
+	repo := &deps.Repo{Packages: []*deps.Package{
+		{Name: "a", ImportPath: "example.com/m/a", Imports: stale},
+		{Name: "b", ImportPath: "example.com/m/b"},
+		{Name: "x", ImportPath: "example.com/broken/x", Imports: stale},
+		{Name: "web", ImportPath: "web", Language: "npm", Imports: stale},
+	}}
+	pkgDirs := []string{"a", "b", "broken/x", "web"}
+	mods := []*deps.Module{
+		{Path: "example.com/m", Dir: "."},
+		{Path: "example.com/broken", Dir: "broken"},
+	}
+	if err := resolvePrecise(context.Background(), root, repo, pkgDirs, mods, nil); err != nil {
+		t.Fatalf("resolvePrecise: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		imports []string
+		precise bool
+	}{
+		{[]string{"example.com/m/b", "fmt"}, true},
+		{nil, true},
+		{stale, false},
+		{stale, false},
+	}
+	for i, test := range tests {
+		pkg := repo.Packages[i]
+		if !reflect.DeepEqual(pkg.Imports, test.imports) || pkg.Precise != test.precise {
+			t.Errorf("Package %q: got imports %q, precise %v; want %q, %v",
+				pkg.ImportPath, pkg.Imports, pkg.Precise, test.imports, test.precise)
+		}
+	}
+}
@@ -16,16 +16,23 @@
 package local
 
 import (
+	"archive/tar"
 	"context"
 	"errors"
 	"fmt"
 	"go/build"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime/trace"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/creachadair/repodeps/auth"
 	"github.com/creachadair/repodeps/deps"
 )
 
@@ -36,7 +43,11 @@ func Load(ctx context.Context, dir string, opts *deps.Options) ([]*deps.Repo, er
 		opts = new(deps.Options)
 	}
 	// Find the URLs for the remotes defined for this repository.
-	remotes, err := gitRemotes(ctx, dir)
+	var remotes []*deps.Remote
+	err := inRegion(ctx, "git", func() (err error) {
+		remotes, err = gitRemotes(ctx, dir)
+		return
+	})
 	if err != nil {
 		return nil, fmt.Errorf("listing remotes: %v", err)
 	} else if len(remotes) == 0 {
@@ -45,43 +56,544 @@ func Load(ctx context.Context, dir string, opts *deps.Options) ([]*deps.Repo, er
 
 	repo := &deps.Repo{From: dir, Remotes: remotes}
 
+	// If requested, check out the submodules so their contents are visible
+	// to the scan below. Submodules are not included in an extracted ref.
+	if opts.Submodules && opts.Ref == "" {
+		subs, err := gitSubmodules(ctx, dir)
+		if err != nil {
+			return nil, fmt.Errorf("loading submodules: %v", err)
+		}
+		repo.Submodules = subs
+	}
+
+	// If a specific revision was requested, extract its tree into a temporary
+	// directory laid out like a GOPATH, so that import paths are resolved
+	// relative to the remote URL.
+	bc, root := build.Default, dir
+	if opts.Ref != "" {
+		tmp, err := ioutil.TempDir("", "repodeps")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		root = filepath.Join(tmp, "src", trimScheme(remotes[0].Url))
+		if err := inRegion(ctx, "git", func() error {
+			return extractTree(ctx, dir, opts.Ref, root)
+		}); err != nil {
+			return nil, fmt.Errorf("extracting %q: %v", opts.Ref, err)
+		}
+		bc.GOPATH = tmp
+	}
+
+	if err := inRegion(ctx, "parse", func() error {
+		return LoadTree(ctx, repo, root, bc, opts)
+	}); err != nil {
+		return nil, err
+	}
+	if err := inRegion(ctx, "git", func() error {
+		repo.Commit = gitCommit(ctx, dir, opts.Ref)
+		if opts.Activity {
+			act, err := gitActivity(ctx, dir, opts.Ref)
+			if err != nil {
+				return fmt.Errorf("reading history: %v", err)
+			}
+			repo.Activity = act
+		}
+		if opts.PseudoVersions && repo.Commit != "" {
+			if rev, err := gitRevision(ctx, dir, repo.Commit); err != nil {
+				log.Printf("Leaving the modules of %q unversioned: reading tags: %v", dir, err)
+			} else {
+				deps.SetModuleVersions(repo, rev)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	repos := []*deps.Repo{repo}
+	if opts.ScanNested {
+		// Submodules that were checked out without -submodules are not
+		// separate repositories, so they are not scanned as nested ones.
+		subs, err := gitSubmodulePaths(ctx, dir)
+		if err != nil {
+			return nil, fmt.Errorf("listing submodules: %v", err)
+		}
+		for _, rel := range repo.Nested {
+			if subs[rel] {
+				continue
+			}
+			nested, err := Load(ctx, filepath.Join(dir, rel), opts)
+			if err != nil {
+				log.Printf("Skipped nested repository %q in %q: %v", rel, dir, err)
+				continue
+			}
+			repos = append(repos, nested...)
+		}
+	}
+	return repos, nil
+}
+
+// inRegion calls f inside an execution trace region with the given type, so
+// that a trace of the scan separates Git operations from parsing.
+func inRegion(ctx context.Context, name string, f func() error) error {
+	defer trace.StartRegion(ctx, name).End()
+	return f()
+}
+
+// LoadTree scans the directory tree rooted at root, which holds the files of
+// repo, and adds the packages and modules it finds to repo. The build context
+// bc is used to load Go packages. Version control metadata directories are
+// skipped, and other repositories nested inside root are recorded in
+// repo.Nested rather than scanned. The first remote of repo, if any, is used
+// to name packages that do not name themselves.
+func LoadTree(ctx context.Context, repo *deps.Repo, root string, bc build.Context, opts *deps.Options) error {
+	if opts == nil {
+		opts = new(deps.Options)
+	}
+	var url string
+	if len(repo.Remotes) != 0 {
+		url = repo.Remotes[0].Url
+	}
+
 	// Find the import paths of the packages defined by this repository, and the
 	// import paths of their dependencies. This is basically "go list".
-	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		} else if !fi.IsDir() {
-			return nil // nothing to do here
-		} else if base := filepath.Base(path); base == ".git" || base == "vendor" {
+	if opts.Symlinks == deps.SkipLinks {
+		bc.ReadDir = readDirNoLinks
+	}
+	var mods deps.ModuleSet
+	var owners deps.OwnerSet
+	for _, rel := range deps.CodeownersPaths {
+		if data, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(rel))); err == nil {
+			owners.AddCodeowners(data)
+			break
+		}
+	}
+	for _, rel := range deps.ReadmePaths {
+		if data, err := ioutil.ReadFile(filepath.Join(root, rel)); err == nil {
+			repo.Description = deps.ReadmeTitle(data)
+			break
+		}
+	}
+	for _, rel := range deps.LicensePaths {
+		if data, err := ioutil.ReadFile(filepath.Join(root, rel)); err == nil {
+			repo.License = deps.DetectLicense(data)
+			break
+		}
+	}
+	var pkgDirs []string // parallel to repo.Packages
+	err := walkDirs(root, opts.Symlinks, func(path string) error {
+		if base := filepath.Base(path); isVCSDir(base) || base == "vendor" || opts.Skipped(base) {
+			return filepath.SkipDir
+		}
+		reldir, _ := filepath.Rel(root, path)
+		reldir = filepath.ToSlash(reldir)
+		if path != root && isNested(path, repo.Submodules, reldir) {
+			repo.Nested = append(repo.Nested, reldir)
 			return filepath.SkipDir
 		}
-		pkg, err := build.Default.ImportDir(path, 0)
+		if data, err := ioutil.ReadFile(filepath.Join(path, "go.mod")); err == nil {
+			mods.Add(reldir, data) // N.B. invalid module files are ignored
+		}
+		if data, err := ioutil.ReadFile(filepath.Join(path, "OWNERS")); err == nil {
+			owners.AddOwners(reldir, data)
+		}
+		if !opts.Included(reldir) {
+			return nil // not selected by the caller
+		} else if opts.SummaryOnly {
+			return summarizeDir(repo, path)
+		}
+		recs, conflict := deps.AnalyzeDir(&bc, root, path, opts)
+		if conflict != nil {
+			repo.Conflicts = append(repo.Conflicts, conflict)
+		}
+		for _, rec := range recs {
+			deps.NameLocal(rec, url, reldir)
+			rec.Submodule = findSubmodule(repo.Submodules, reldir)
+			repo.Packages = append(repo.Packages, rec)
+			pkgDirs = append(pkgDirs, reldir)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Resolve import paths relative to the enclosing modules, if any.
+	repo.Modules = mods.Modules()
+	if opts.Precise {
+		if err := resolvePrecise(ctx, root, repo, pkgDirs, repo.Modules, opts); err != nil {
+			return err
+		}
+	}
+	for i, pkg := range repo.Packages {
+		mods.Resolve(pkg, pkgDirs[i])
+		pkg.Owners = owners.Owners(pkgDirs[i])
+	}
+	return nil
+}
+
+// isVCSDir reports whether name is the name of a version control metadata
+// directory.
+func isVCSDir(name string) bool { return name == ".git" || name == ".hg" }
+
+// summarizeDir adds the files in dir to the summary for repo.
+func summarizeDir(repo *deps.Repo, dir string) error {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, fi := range fis {
+		if fi.Mode().IsRegular() {
+			names = append(names, fi.Name())
+		}
+	}
+	if repo.Summary == nil {
+		repo.Summary = new(deps.Summary)
+	}
+	repo.Summary.AddDir(names)
+	return nil
+}
+
+// isNested reports whether dir is the root of a Git or Mercurial repository
+// other than one of the given submodules.
+func isNested(dir string, subs []*deps.Submodule, rel string) bool {
+	_, gerr := os.Lstat(filepath.Join(dir, ".git"))
+	_, herr := os.Lstat(filepath.Join(dir, ".hg"))
+	if gerr != nil && herr != nil {
+		return false
+	}
+	for _, sub := range subs {
+		if sub.Path == rel {
+			return false
+		}
+	}
+	return true
+}
+
+// walkDirs calls f for each directory in the tree rooted at root, in lexical
+// order, handling symbolic links according to policy. If f returns
+// filepath.SkipDir, the contents of that directory are not visited. When links
+// are followed, each directory is visited at most once, which prevents cycles.
+func walkDirs(root string, policy deps.LinkPolicy, f func(string) error) error {
+	seen := make(map[string]bool) // :: real path → visited
+	var walk func(string) error
+	walk = func(path string) error {
+		if policy == deps.FollowLinks {
+			real, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return err
+			} else if seen[real] {
+				return nil // already visited by another path
+			}
+			seen[real] = true
+		}
+		if err := f(path); err == filepath.SkipDir {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fis, err := ioutil.ReadDir(path)
 		if err != nil {
-			return nil // no importable go package here; skip it
-		}
-		rec := &deps.Package{
-			Name:       pkg.Name,
-			ImportPath: pkg.ImportPath,
-			Imports:    pkg.Imports,
-		}
-		if opts.HashSourceFiles {
-			for _, name := range pkg.GoFiles {
-				fpath := filepath.Join(path, name)
-				hash, err := hashFile(fpath)
-				if err != nil {
-					log.Printf("Hashing %q failed: %v", path, err)
+			return err
+		}
+		for _, fi := range fis {
+			sub := filepath.Join(path, fi.Name())
+			if fi.Mode()&os.ModeSymlink != 0 {
+				if policy == deps.RejectLinks {
+					return fmt.Errorf("symbolic link %q is not allowed", sub)
+				} else if policy != deps.FollowLinks {
+					continue
+				} else if ti, err := os.Stat(sub); err != nil || !ti.IsDir() {
+					continue // dangling, or not a directory
 				}
-				rel, _ := filepath.Rel(dir, fpath)
-				rec.Sources = append(rec.Sources, &deps.File{
-					RepoPath: rel,
-					Digest:   hash,
-				})
+			} else if !fi.IsDir() {
+				continue
+			}
+			if err := walk(sub); err != nil {
+				return err
 			}
 		}
-		repo.Packages = append(repo.Packages, rec)
 		return nil
-	})
-	return []*deps.Repo{repo}, err
+	}
+	return walk(root)
+}
+
+// readDirNoLinks implements the ReadDir method of a build.Context, omitting
+// symbolic links from the results.
+func readDirNoLinks(dir string) ([]os.FileInfo, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var keep []os.FileInfo
+	for _, fi := range fis {
+		if fi.Mode()&os.ModeSymlink == 0 {
+			keep = append(keep, fi)
+		}
+	}
+	return keep, nil
+}
+
+// gitSubmodules initializes the submodules of the repository at dir,
+// recursively, and returns a description of each.
+func gitSubmodules(ctx context.Context, dir string) ([]*deps.Submodule, error) {
+	update := exec.CommandContext(ctx, "git", "submodule", "update", "--init", "--recursive")
+	update.Dir = dir
+	if out, err := update.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("updating submodules: %v\n%s", err, out)
+	}
+	list := exec.CommandContext(ctx, "git", "submodule", "foreach", "--quiet", "--recursive",
+		`echo "$sha1 $displaypath"`)
+	list.Dir = dir
+	bits, err := list.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing submodules: %v", err)
+	}
+	var subs []*deps.Submodule
+	for _, line := range strings.Split(strings.TrimSpace(string(bits)), "\n") {
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		sub := &deps.Submodule{Commit: parts[0], Path: parts[1]}
+		if rs, err := gitRemotes(ctx, filepath.Join(dir, sub.Path)); err == nil && len(rs) != 0 {
+			sub.Url = rs[0].Url
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// gitSubmodulePaths returns the set of paths of the submodules declared by the
+// .gitmodules file of the repository at dir, whether or not they are checked
+// out. Nested submodules are not included.
+func gitSubmodulePaths(ctx context.Context, dir string) (map[string]bool, error) {
+	paths := make(map[string]bool)
+	if _, err := os.Stat(filepath.Join(dir, ".gitmodules")); os.IsNotExist(err) {
+		return paths, nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "config", "--file", ".gitmodules",
+		"--get-regexp", `^submodule\..*\.path$`)
+	cmd.Dir = dir
+	bits, err := cmd.Output()
+	if err != nil {
+		if e, ok := err.(*exec.ExitError); ok && e.ExitCode() == 1 {
+			return paths, nil // no submodules are declared
+		}
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(bits)), "\n") {
+		if parts := strings.SplitN(line, " ", 2); len(parts) == 2 {
+			paths[filepath.ToSlash(filepath.Clean(parts[1]))] = true
+		}
+	}
+	return paths, nil
+}
+
+// findSubmodule returns the path of the innermost submodule containing the
+// repository-relative directory dir, or "" if there is none.
+func findSubmodule(subs []*deps.Submodule, dir string) string {
+	var best string
+	for _, sub := range subs {
+		if (dir == sub.Path || strings.HasPrefix(dir, sub.Path+"/")) && len(sub.Path) > len(best) {
+			best = sub.Path
+		}
+	}
+	return best
+}
+
+// Clone makes a shallow clone of the repository at url into dir, which must
+// not already exist or must be empty. If ref != "" it names the branch, tag,
+// or commit to check out; otherwise the default branch is used. A commit is
+// named by its full or abbreviated hexadecimal ID; since a shallow clone may
+// not include it, the clone of a commit has the full history.
+func Clone(ctx context.Context, url, dir, ref string) error {
+	return CloneAuth(ctx, url, dir, ref, nil)
+}
+
+// CloneAuth is as Clone, but authenticates to the remote with cred, which may
+// be nil if no credential is needed.
+func CloneAuth(ctx context.Context, url, dir, ref string, cred *auth.Credential) error {
+	env, err := cred.GitEnv(ctx, url)
+	if err != nil {
+		return fmt.Errorf("authenticating to %q: %v", url, err)
+	}
+	args := []string{"clone", "--quiet"}
+	isCommit := isCommitID(ref)
+	if isCommit {
+		args = append(args, "--no-checkout") // git clone --branch rejects commits
+	} else if args = append(args, "--depth=1"); ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(args, url, dir)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cloning %q: %v\n%s", url, err, out)
+	}
+	if isCommit {
+		co := exec.CommandContext(ctx, "git", "checkout", "--quiet", "--detach", ref)
+		co.Dir = dir
+		if out, err := co.CombinedOutput(); err != nil {
+			return fmt.Errorf("checking out %q: %v\n%s", ref, err, out)
+		}
+	}
+	return nil
+}
+
+// isCommitID reports whether ref has the form of a full or abbreviated
+// hexadecimal commit ID.
+func isCommitID(ref string) bool {
+	if len(ref) < 7 || len(ref) > 64 {
+		return false
+	}
+	for _, c := range ref {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// extractTree writes the files of the tree at ref in the repository at dir
+// into the directory dst, which is created if it does not exist.
+func extractTree(ctx context.Context, dir, ref, dst string) error {
+	cmd := exec.CommandContext(ctx, "git", "archive", "--format=tar", ref)
+	cmd.Dir = dir
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	} else if err := cmd.Start(); err != nil {
+		return err
+	}
+	tr := tar.NewReader(pipe)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			cmd.Wait()
+			return err
+		}
+		path := filepath.Join(dst, filepath.FromSlash(hdr.Name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg:
+			err = writeFile(path, tr)
+		default:
+			continue // skip links and other special files
+		}
+		if err != nil {
+			cmd.Wait()
+			return err
+		}
+	}
+	return cmd.Wait()
+}
+
+func writeFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// gitCommit returns the commit ID of ref in the repository at dir, or of HEAD
+// if ref == "". It returns "" if there is no such commit.
+func gitCommit(ctx context.Context, dir, ref string) string {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitActivity summarizes the commit history of the repository in dir, at the
+// specified ref or at HEAD if ref == "". The history of a shallow clone
+// includes only the commits it contains, and a repository with no commits
+// has an empty history.
+func gitActivity(ctx context.Context, dir, ref string) (*deps.Activity, error) {
+	head := ref == ""
+	if head {
+		ref = "HEAD"
+	}
+	check := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	check.Dir = dir
+	if err := check.Run(); err != nil {
+		// With --quiet, rev-parse fails with status 1 only if ref does not
+		// name a commit, which for HEAD means there are no commits yet.
+		if e, ok := err.(*exec.ExitError); ok && e.ExitCode() == 1 {
+			if head {
+				return new(deps.Activity), nil
+			}
+			return nil, fmt.Errorf("%q is not a commit", ref)
+		}
+		return nil, fmt.Errorf("resolving %q: %v", ref, err)
+	}
+	cmd := exec.CommandContext(ctx, "git", "log", "--format=%ct %ae", ref, "--")
+	cmd.Dir = dir
+	bits, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	tally := deps.NewActivityTally(time.Now())
+	for _, line := range strings.Split(strings.TrimSpace(string(bits)), "\n") {
+		parts := strings.SplitN(line, " ", 2)
+		sec, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+		tally.Add(time.Unix(sec, 0), parts[1])
+	}
+	return tally.Activity(), nil
+}
+
+// gitRevision describes the given commit of the repository in dir, with the
+// tags on the commit and its ancestors.
+func gitRevision(ctx context.Context, dir, commit string) (*deps.Revision, error) {
+	git := func(args ...string) ([]string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		bits, err := cmd.Output()
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(string(bits)), nil
+	}
+	stamp, err := git("show", "-s", "--format=%ct", commit)
+	if err != nil {
+		return nil, err
+	} else if len(stamp) != 1 {
+		return nil, fmt.Errorf("no commit time for %q", commit)
+	}
+	sec, err := strconv.ParseInt(stamp[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit time: %v", err)
+	}
+	rev := &deps.Revision{Commit: commit, Time: time.Unix(sec, 0)}
+	if rev.Tags, err = git("tag", "--merged", commit); err != nil {
+		return nil, err
+	}
+	if rev.Exact, err = git("tag", "--points-at", commit); err != nil {
+		return nil, err
+	}
+	return rev, nil
 }
 
 func gitRemotes(ctx context.Context, dir string) ([]*deps.Remote, error) {
@@ -101,28 +613,15 @@ func gitRemotes(ctx context.Context, dir string) ([]*deps.Remote, error) {
 		if err != nil {
 			return nil, fmt.Errorf("getting remote URL for %q: %v", name, err)
 		}
-		rs = append(rs, &deps.Remote{Name: name, Url: parseRemote(bits)})
+		rs = append(rs, &deps.Remote{Name: name, Url: deps.CanonicalURL(string(bits))})
 	}
 	return rs, nil
 }
 
-func parseRemote(bits []byte) string {
-	url := strings.TrimSpace(string(bits))
-	if trim := strings.TrimPrefix(url, "git@"); trim != url {
-		parts := strings.SplitN(trim, ":", 2)
-		url = parts[0]
-		if len(parts) == 2 {
-			url += "/" + parts[1]
-		}
-	}
-	return strings.TrimSuffix(url, ".git")
-}
-
-func hashFile(path string) ([]byte, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// trimScheme removes a URL scheme prefix such as "https://" from url.
+func trimScheme(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		return url[i+3:]
 	}
-	defer f.Close()
-	return deps.Hash(f), nil
+	return url
 }
@@ -0,0 +1,238 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// testGit runs git with the given arguments in dir.
+func testGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com",
+		"-c", "init.defaultBranch=main", "-c", "protocol.file.allow=always",
+	}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %q: %v\n%s", args, err, out)
+	}
+}
+
+// newRepo creates a Git repository at dir with one commit containing the
+// given files. If url != "", it is the URL of the origin remote.
+func newRepo(t *testing.T, dir, url string, files map[string]string) {
+	t.Helper()
+	for name, text := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	testGit(t, dir, "init", "-q")
+	if url != "" {
+		testGit(t, dir, "remote", "add", "origin", url)
+	}
+	testGit(t, dir, "add", "-A")
+	testGit(t, dir, "commit", "-q", "-m", "initial")
+}
+
+func TestLoadNested(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	tmp, err := ioutil.TempDir("", "local")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// The outer repository has a submodule, checked out without -submodules,
+	// and two other repositories nested in its worktree, one of which cannot
+	// be loaded because it has no remotes.
+	sub := filepath.Join(tmp, "sub")
+	newRepo(t, sub, "https://example.com/sub", map[string]string{"s.go": "package s\n"})
+	outer := filepath.Join(tmp, "outer")
+	newRepo(t, outer, "https://example.com/outer", map[string]string{"o.go": "package o\n"})
+	testGit(t, outer, "submodule", "add", "-q", sub, "lib/sub")
+	testGit(t, outer, "commit", "-q", "-m", "add submodule")
+	newRepo(t, filepath.Join(outer, "a"), "https://example.com/a", map[string]string{"a.go": "package a\n"})
+	newRepo(t, filepath.Join(outer, "b"), "", map[string]string{"b.go": "package b\n"})
+
+	repos, err := Load(context.Background(), outer, &deps.Options{ScanNested: true})
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	var got []string
+	for _, repo := range repos {
+		got = append(got, repo.Remotes[0].Url)
+	}
+	want := []string{"example.com/outer", "example.com/a"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Load: got repositories %q, want %q", got, want)
+	}
+}
+
+// headCommit returns the commit ID checked out in the repository at dir.
+func headCommit(t *testing.T, dir string) string {
+	t.Helper()
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git rev-parse: %v", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	tmp, err := ioutil.TempDir("", "local")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	origin := filepath.Join(tmp, "origin")
+	newRepo(t, origin, "", map[string]string{"a.go": "package a\n"})
+	first := headCommit(t, origin)
+	testGit(t, origin, "tag", "v1.0.0")
+	if err := ioutil.WriteFile(filepath.Join(origin, "b.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	testGit(t, origin, "add", "-A")
+	testGit(t, origin, "commit", "-q", "-m", "second")
+	second := headCommit(t, origin)
+
+	ctx := context.Background()
+	tests := []struct {
+		ref, want string
+	}{
+		{"", second},
+		{"main", second},
+		{"v1.0.0", first},
+		{first, first},
+		{first[:10], first},
+		{second, second},
+	}
+	for i, test := range tests {
+		dir := filepath.Join(tmp, "clone"+strconv.Itoa(i))
+		if err := Clone(ctx, "file://"+origin, dir, test.ref); err != nil {
+			t.Errorf("Clone %q: unexpected error: %v", test.ref, err)
+		} else if got := headCommit(t, dir); got != test.want {
+			t.Errorf("Clone %q: got commit %s, want %s", test.ref, got, test.want)
+		}
+	}
+	if err := Clone(ctx, "file://"+origin, filepath.Join(tmp, "bad"), "nonesuch"); err == nil {
+		t.Error("Clone of a nonexistent branch: got nil error")
+	}
+}
+
+func TestGitActivity(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	tmp, err := ioutil.TempDir("", "local")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+	ctx := context.Background()
+
+	// A repository with no commits has an empty history.
+	empty := filepath.Join(tmp, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	testGit(t, empty, "init", "-q")
+	if act, err := gitActivity(ctx, empty, ""); err != nil {
+		t.Errorf("gitActivity with no commits: unexpected error: %v", err)
+	} else if act.TotalCommits != 0 {
+		t.Errorf("gitActivity with no commits: got %+v, want empty", act)
+	}
+
+	repo := filepath.Join(tmp, "repo")
+	newRepo(t, repo, "", map[string]string{"a.go": "package a\n"})
+	for _, ref := range []string{"", "main", "HEAD"} {
+		if act, err := gitActivity(ctx, repo, ref); err != nil {
+			t.Errorf("gitActivity(%q): unexpected error: %v", ref, err)
+		} else if act.TotalCommits != 1 || act.Authors != 1 {
+			t.Errorf("gitActivity(%q): got %+v, want 1 commit by 1 author", ref, act)
+		}
+	}
+
+	// A ref that does not name a commit, or a directory that is not a
+	// repository, is an error.
+	if act, err := gitActivity(ctx, repo, "nonesuch"); err == nil {
+		t.Errorf("gitActivity(nonesuch): got %+v, want error", act)
+	}
+	if act, err := gitActivity(ctx, tmp, ""); err == nil {
+		t.Errorf("gitActivity outside a repository: got %+v, want error", act)
+	}
+}
+
+func TestLoadVersions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	tmp, err := ioutil.TempDir("", "local")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "r")
+	newRepo(t, dir, "https://example.com/r", map[string]string{
+		"go.mod": "module example.com/r\n",
+		"r.go":   "package r\n",
+	})
+	testGit(t, dir, "tag", "v1.2.3")
+
+	load := func() *deps.Repo {
+		t.Helper()
+		repos, err := Load(context.Background(), dir, &deps.Options{PseudoVersions: true})
+		if err != nil {
+			t.Fatalf("Load: unexpected error: %v", err)
+		} else if len(repos) != 1 || len(repos[0].Modules) != 1 {
+			t.Fatalf("Load: got %+v, want one repository with one module", repos)
+		}
+		return repos[0]
+	}
+	if v := load().Modules[0].Version; v != "v1.2.3" {
+		t.Errorf("Module version: got %q, want v1.2.3", v)
+	}
+
+	// If the tags cannot be read, the repository is loaded without versions.
+	bad := filepath.Join(dir, ".git", "refs", "tags", "broken")
+	if err := ioutil.WriteFile(bad, []byte(strings.Repeat("1234", 10)+"\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if v := load().Modules[0].Version; v != "" {
+		t.Errorf("Module version with broken tags: got %q, want none", v)
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modfile implements a parser for the parts of the go.mod file format
+// needed to record module dependencies.
+package modfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/creachadair/repodeps/semver"
+)
+
+// A File is the parsed content of a go.mod file.
+type File struct {
+	Module    string     // the module path
+	Go        string     // the go directive, if present
+	Toolchain string     // the toolchain directive, if present
+	Require   []*Require // require directives
+	Exclude   []Version  // exclude directives
+	Replace   []*Replace // replace directives
+}
+
+// A Version is a module path and version.
+type Version struct {
+	Path    string
+	Version string // may be empty, e.g., for the target of a directory replace
+}
+
+func (v Version) String() string {
+	if v.Version == "" {
+		return v.Path
+	}
+	return v.Path + "@" + v.Version
+}
+
+// A Require is a single requirement.
+type Require struct {
+	Version
+	Indirect bool // marked "// indirect"
+}
+
+// A Replace is a single replacement. If Old.Version is empty, the replacement
+// applies to all versions of Old.Path.
+type Replace struct {
+	Old, New Version
+}
+
+// IsLocal reports whether the replacement target is a filesystem path rather
+// than a module path.
+func (r *Replace) IsLocal() bool { return IsLocalPath(r.New.Path) }
+
+// IsLocalPath reports whether path is a filesystem path as used on the
+// right-hand side of a replace directive.
+func IsLocalPath(path string) bool {
+	return path == "." || path == ".." || strings.HasPrefix(path, "/") ||
+		strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../")
+}
+
+// SplitPathVersion splits a module path into a prefix and its major version
+// suffix, e.g., "github.com/foo/bar/v2" into "github.com/foo/bar" and "/v2".
+// For gopkg.in paths the suffix has the form ".vN". If the path has no major
+// version suffix, the suffix is empty.
+func SplitPathVersion(path string) (prefix, major string) {
+	if strings.HasPrefix(path, "gopkg.in/") {
+		if i := strings.LastIndex(path, ".v"); i >= 0 && isMajor(path[i+2:], 0) {
+			return path[:i], path[i:]
+		}
+		return path, ""
+	}
+	if i := strings.LastIndex(path, "/v"); i >= 0 && isMajor(path[i+2:], 2) {
+		return path[:i], path[i:]
+	}
+	return path, ""
+}
+
+// CheckPathMajor reports an error if the major version of the given version
+// string is inconsistent with the major version suffix of the module path:
+// Versions v2 and above require a matching /vN suffix, unless they are marked
+// "+incompatible", and versions v0 and v1 require that there be no suffix.
+// An empty or invalid version is not checked.
+func CheckPathMajor(path, version string) error {
+	if !semver.IsValid(version) {
+		return nil
+	}
+	major := semver.Major(version)
+	_, suffix := SplitPathVersion(path)
+	want := strings.TrimLeft(suffix, "./")
+	if strings.HasPrefix(path, "gopkg.in/") {
+		if want != major && !(want == "v0" && major == "v1") {
+			return fmt.Errorf("version %s does not match gopkg.in suffix %q", version, suffix)
+		}
+		return nil
+	}
+	if strings.HasSuffix(version, "+incompatible") {
+		if suffix != "" {
+			return fmt.Errorf("version %s is marked incompatible but path has suffix %q", version, suffix)
+		} else if major == "v0" || major == "v1" {
+			return fmt.Errorf("version %s is marked incompatible but is below v2", version)
+		}
+		return nil
+	}
+	switch {
+	case suffix == "" && major != "v0" && major != "v1":
+		return fmt.Errorf("version %s requires a /%s path suffix", version, major)
+	case suffix != "" && want != major:
+		return fmt.Errorf("version %s does not match path suffix %q", version, suffix)
+	}
+	return nil
+}
+
+// CompareGo compares two Go release versions such as "1.21", "1.21rc2", and
+// "1.21.3", returning -1, 0, or +1 according to whether v < w, v == w, or
+// v > w. A language version like "1.21" orders before any of its release
+// candidates, which order before the corresponding ".0" release. A "go" prefix
+// on either argument is ignored.
+func CompareGo(v, w string) int {
+	pv, pw := parseGo(v), parseGo(w)
+	for i := range pv {
+		if pv[i] < pw[i] {
+			return -1
+		} else if pv[i] > pw[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseGo parses a Go version into a tuple of major, minor, kind, and number,
+// where kind is 0 for a language version, 1 for beta, 2 for rc, and 3 for a
+// release, and number is the patch, beta, or rc number. Unparseable fields
+// are treated as zero.
+func parseGo(v string) (out [4]int) {
+	v = strings.TrimPrefix(v, "go")
+	parts := strings.SplitN(v, ".", 3)
+	out[0], _ = strconv.Atoi(parts[0])
+	if len(parts) < 2 {
+		return
+	}
+	minor := parts[1]
+	for _, kind := range []struct {
+		tag  string
+		rank int
+	}{{"beta", 1}, {"rc", 2}} {
+		if i := strings.Index(minor, kind.tag); i >= 0 {
+			out[1], _ = strconv.Atoi(minor[:i])
+			out[2] = kind.rank
+			out[3], _ = strconv.Atoi(minor[i+len(kind.tag):])
+			return
+		}
+	}
+	out[1], _ = strconv.Atoi(minor)
+	if len(parts) == 3 {
+		out[2] = 3
+		out[3], _ = strconv.Atoi(parts[2])
+	}
+	return
+}
+
+// isMajor reports whether s is a decimal major version number at least min,
+// without leading zeroes.
+func isMajor(s string, min int) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= min && strconv.Itoa(n) == s
+}
+
+// Parse parses the contents of a go.mod file. Unknown directives are ignored.
+func Parse(data []byte) (*File, error) {
+	f := new(File)
+	var block string // the verb of the current block, if any
+	for i, line := range strings.Split(string(data), "\n") {
+		toks, comment, err := tokenize(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		} else if len(toks) == 0 {
+			continue
+		}
+		if block != "" {
+			if toks[0] == ")" {
+				block = ""
+				continue
+			}
+			toks = append([]string{block}, toks...)
+		} else if len(toks) == 2 && toks[1] == "(" {
+			block = toks[0]
+			continue
+		}
+		if err := f.add(toks, comment); err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+	}
+	if f.Module == "" {
+		return nil, fmt.Errorf("no module directive")
+	}
+	return f, nil
+}
+
+func (f *File) add(toks []string, comment string) error {
+	verb, args := toks[0], toks[1:]
+	switch verb {
+	case "module":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: module path")
+		}
+		f.Module = args[0]
+	case "go":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: go version")
+		}
+		f.Go = args[0]
+	case "toolchain":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: toolchain name")
+		}
+		f.Toolchain = args[0]
+	case "require", "exclude":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: %s path version", verb)
+		}
+		v := Version{Path: args[0], Version: args[1]}
+		if verb == "exclude" {
+			f.Exclude = append(f.Exclude, v)
+		} else {
+			f.Require = append(f.Require, &Require{
+				Version:  v,
+				Indirect: strings.TrimSpace(comment) == "indirect",
+			})
+		}
+	case "replace":
+		i := indexOf(args, "=>")
+		if i < 1 || i > 2 || len(args)-i-1 < 1 || len(args)-i-1 > 2 {
+			return fmt.Errorf("usage: replace path [version] => path [version]")
+		}
+		r := &Replace{Old: Version{Path: args[0]}, New: Version{Path: args[i+1]}}
+		if i == 2 {
+			r.Old.Version = args[1]
+		}
+		if len(args) == i+3 {
+			r.New.Version = args[i+2]
+		}
+		f.Replace = append(f.Replace, r)
+	}
+	return nil
+}
+
+// tokenize splits a line into tokens, and returns the text of its trailing
+// comment, if any, separately.
+func tokenize(line string) (toks []string, comment string, err error) {
+	s := strings.TrimSpace(line)
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, "//"):
+			return toks, s[2:], nil
+		case s[0] == '"' || s[0] == '`':
+			n := quotedLen(s)
+			tok, err := strconv.Unquote(s[:n])
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid quoted string %s", s[:n])
+			}
+			toks = append(toks, tok)
+			s = s[n:]
+		case s[0] == '(' || s[0] == ')':
+			toks = append(toks, s[:1])
+			s = s[1:]
+		default:
+			i := strings.IndexAny(s, " \t\"`()")
+			if j := strings.Index(s, "//"); j >= 0 && (i < 0 || j < i) {
+				i = j
+			}
+			if i < 0 {
+				i = len(s)
+			}
+			toks = append(toks, s[:i])
+			s = s[i:]
+		}
+		s = strings.TrimSpace(s)
+	}
+	return toks, "", nil
+}
+
+// quotedLen returns the length of the quoted string at the beginning of s,
+// including its quotes, or len(s) if the string is not terminated.
+func quotedLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == s[0] {
+			return i + 1
+		} else if s[i] == '\\' && s[0] == '"' {
+			i++
+		}
+	}
+	return len(s)
+}
+
+func indexOf(ss []string, s string) int {
+	for i, elt := range ss {
+		if elt == s {
+			return i
+		}
+	}
+	return -1
+}
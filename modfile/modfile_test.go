@@ -0,0 +1,236 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		desc, input string
+		want        *File
+	}{
+		{"module only", `module example.com/m`, &File{Module: "example.com/m"}},
+
+		{"quoted module path", `module "example.com/m" // comment`, &File{Module: "example.com/m"}},
+
+		{"single-line directives", `
+// A leading comment.
+module example.com/m
+
+go 1.21
+toolchain go1.21.3
+require golang.org/x/text v0.3.0 // indirect
+exclude golang.org/x/net v1.2.3
+replace golang.org/x/text => ../text
+`, &File{
+			Module:    "example.com/m",
+			Go:        "1.21",
+			Toolchain: "go1.21.3",
+			Require: []*Require{
+				{Version: Version{Path: "golang.org/x/text", Version: "v0.3.0"}, Indirect: true},
+			},
+			Exclude: []Version{{Path: "golang.org/x/net", Version: "v1.2.3"}},
+			Replace: []*Replace{
+				{Old: Version{Path: "golang.org/x/text"}, New: Version{Path: "../text"}},
+			},
+		}},
+
+		{"blocks", `module example.com/m
+
+require (
+	example.com/a v1.0.0
+	example.com/b/v2 v2.1.0 // indirect
+	// A comment line inside the block.
+
+	example.com/c v0.0.0-20190102150405-abcdefabcdef // not indirect
+)
+
+replace (
+	example.com/a v1.0.0 => example.com/fork v1.0.1
+	example.com/b/v2 => ./b
+)
+`, &File{
+			Module: "example.com/m",
+			Require: []*Require{
+				{Version: Version{Path: "example.com/a", Version: "v1.0.0"}},
+				{Version: Version{Path: "example.com/b/v2", Version: "v2.1.0"}, Indirect: true},
+				{Version: Version{Path: "example.com/c", Version: "v0.0.0-20190102150405-abcdefabcdef"}},
+			},
+			Replace: []*Replace{
+				{Old: Version{Path: "example.com/a", Version: "v1.0.0"}, New: Version{Path: "example.com/fork", Version: "v1.0.1"}},
+				{Old: Version{Path: "example.com/b/v2"}, New: Version{Path: "./b"}},
+			},
+		}},
+
+		{"unknown directives", `module example.com/m
+retract v1.0.0
+godebug default=go1.21
+`, &File{Module: "example.com/m"}},
+
+		{"backquoted and spaced", "module `example.com/m`\nrequire \"example.com/a b\" v1.0.0\n",
+			&File{
+				Module:  "example.com/m",
+				Require: []*Require{{Version: Version{Path: "example.com/a b", Version: "v1.0.0"}}},
+			}},
+	}
+	for _, test := range tests {
+		got, err := Parse([]byte(test.input))
+		if err != nil {
+			t.Errorf("%s: Parse: unexpected error: %v", test.desc, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Parse:\n got %+v\nwant %+v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"", "no module directive"},
+		{"go 1.21", "no module directive"},
+		{"module", "line 1: usage: module path"},
+		{"module a b", "line 1: usage: module path"},
+		{"module m\ngo", "line 2: usage: go version"},
+		{"module m\ntoolchain", "line 2: usage: toolchain name"},
+		{"module m\nrequire a", "line 2: usage: require path version"},
+		{"module m\nexclude a b c", "line 2: usage: exclude path version"},
+		{"module m\nrequire (\n\ta\n)", "line 3: usage: require path version"},
+		{"module m\nreplace a b", "line 2: usage: replace path [version] => path [version]"},
+		{"module m\nreplace a =>", "line 2: usage: replace path [version] => path [version]"},
+		{"module m\nreplace => b", "line 2: usage: replace path [version] => path [version]"},
+		{"module m\nreplace a v1 v2 => b", "line 2: usage: replace path [version] => path [version]"},
+		{`module "m`, "line 1: invalid quoted string"},
+	}
+	for _, test := range tests {
+		f, err := Parse([]byte(test.input))
+		if err == nil {
+			t.Errorf("Parse(%q): got %+v, want error", test.input, f)
+		} else if !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("Parse(%q): got error %q, want %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestSplitPathVersion(t *testing.T) {
+	tests := []struct {
+		path, prefix, major string
+	}{
+		{"github.com/foo/bar", "github.com/foo/bar", ""},
+		{"github.com/foo/bar/v2", "github.com/foo/bar", "/v2"},
+		{"github.com/foo/bar/v10", "github.com/foo/bar", "/v10"},
+		{"github.com/foo/bar/v1", "github.com/foo/bar/v1", ""},
+		{"github.com/foo/bar/v0", "github.com/foo/bar/v0", ""},
+		{"github.com/foo/bar/v02", "github.com/foo/bar/v02", ""},
+		{"github.com/foo/v2/bar", "github.com/foo/v2/bar", ""},
+		{"github.com/foo/vendor", "github.com/foo/vendor", ""},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml", ".v2"},
+		{"gopkg.in/yaml.v0", "gopkg.in/yaml", ".v0"},
+		{"gopkg.in/yaml", "gopkg.in/yaml", ""},
+	}
+	for _, test := range tests {
+		prefix, major := SplitPathVersion(test.path)
+		if prefix != test.prefix || major != test.major {
+			t.Errorf("SplitPathVersion(%q): got (%q, %q), want (%q, %q)",
+				test.path, prefix, major, test.prefix, test.major)
+		}
+	}
+}
+
+func TestCheckPathMajor(t *testing.T) {
+	tests := []struct {
+		path, version string
+		ok            bool
+	}{
+		{"example.com/m", "v0.1.0", true},
+		{"example.com/m", "v1.2.3", true},
+		{"example.com/m", "v2.0.0", false},
+		{"example.com/m", "v2.0.0+incompatible", true},
+		{"example.com/m", "v1.0.0+incompatible", false},
+		{"example.com/m/v2", "v2.0.0", true},
+		{"example.com/m/v2", "v3.0.0", false},
+		{"example.com/m/v2", "v1.0.0", false},
+		{"example.com/m/v2", "v2.0.0+incompatible", false},
+		{"gopkg.in/yaml.v2", "v2.4.0", true},
+		{"gopkg.in/yaml.v2", "v3.0.0", false},
+		{"gopkg.in/yaml.v0", "v1.0.0", true},
+		{"example.com/m", "", true},
+		{"example.com/m", "latest", true},
+	}
+	for _, test := range tests {
+		err := CheckPathMajor(test.path, test.version)
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("CheckPathMajor(%q, %q): got error %v, want ok=%v", test.path, test.version, err, test.ok)
+		}
+	}
+}
+
+func TestCompareGo(t *testing.T) {
+	// Each version is less than the ones after it.
+	order := []string{
+		"1.9",
+		"1.20",
+		"1.21",
+		"1.21beta1",
+		"1.21rc1",
+		"1.21rc2",
+		"1.21.0",
+		"1.21.3",
+		"1.21.10",
+		"1.22",
+		"2",
+	}
+	for i, v := range order {
+		for j, w := range order {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := CompareGo(v, w); got != want {
+				t.Errorf("CompareGo(%q, %q): got %d, want %d", v, w, got, want)
+			}
+		}
+	}
+	if got := CompareGo("go1.21.3", "1.21.3"); got != 0 {
+		t.Errorf(`CompareGo("go1.21.3", "1.21.3"): got %d, want 0`, got)
+	}
+}
+
+func TestIsLocalPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".", true},
+		{"..", true},
+		{"./x", true},
+		{"../x", true},
+		{"/abs/x", true},
+		{"example.com/x", false},
+		{".x", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := IsLocalPath(test.path); got != test.want {
+			t.Errorf("IsLocalPath(%q): got %v, want %v", test.path, got, test.want)
+		}
+	}
+}
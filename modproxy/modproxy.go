@@ -0,0 +1,213 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modproxy fetches the published versions of Go modules from a module
+// proxy, such as proxy.golang.org.
+package modproxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/creachadair/repodeps/semver"
+)
+
+// DefaultURL is the base URL of the default module proxy.
+const DefaultURL = "https://proxy.golang.org"
+
+// DefaultMaxAge is the default maximum age of a cached result.
+const DefaultMaxAge = 24 * time.Hour
+
+// ErrNotFound is reported when the proxy does not know a module.
+var ErrNotFound = errors.New("module not found")
+
+// A Client fetches module versions from a module proxy. The zero value is
+// ready for use, and queries DefaultURL without caching results.
+type Client struct {
+	// The base URL of the proxy. If empty, use DefaultURL.
+	URL string
+
+	// If set, results are cached in this directory.
+	CacheDir string
+
+	// Cached results older than this are fetched again. If zero, use
+	// DefaultMaxAge.
+	MaxAge time.Duration
+
+	// The HTTP client to use. If nil, use http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Versions returns the published versions of the module with the given path,
+// in increasing order. A module that has no tagged versions reports only the
+// version the proxy resolves as its latest, typically a pseudo-version.
+func (c *Client) Versions(ctx context.Context, path string) ([]string, error) {
+	esc, err := EscapePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if vs, ok := c.cached(esc); ok {
+		return vs, nil
+	}
+	data, err := c.get(ctx, esc+"/@v/list")
+	if err != nil {
+		return nil, err
+	}
+	var vs []string
+	for _, v := range strings.Fields(string(data)) {
+		if semver.IsValid(v) {
+			vs = append(vs, v)
+		}
+	}
+	if len(vs) == 0 {
+		data, err := c.get(ctx, esc+"/@latest")
+		if err != nil {
+			return nil, err
+		}
+		var info struct{ Version string }
+		if err := json.Unmarshal(data, &info); err != nil {
+			return nil, fmt.Errorf("modproxy: decoding latest version of %q: %v", path, err)
+		} else if semver.IsValid(info.Version) {
+			vs = append(vs, info.Version)
+		}
+	}
+	sort.Slice(vs, func(i, j int) bool { return semver.Compare(vs[i], vs[j]) < 0 })
+	c.store(esc, vs)
+	return vs, nil
+}
+
+// Latest returns the latest version in vs, as sorted by Versions: the highest
+// release version, or if there are none the highest prerelease. It returns ""
+// if vs is empty.
+func Latest(vs []string) string {
+	for i := len(vs) - 1; i >= 0; i-- {
+		if semver.Prerelease(vs[i]) == "" {
+			return vs[i]
+		}
+	}
+	if len(vs) == 0 {
+		return ""
+	}
+	return vs[len(vs)-1]
+}
+
+// Behind returns the number of release versions in vs that are newer than v.
+func Behind(vs []string, v string) int {
+	var n int
+	for _, w := range vs {
+		if semver.Prerelease(w) == "" && semver.Compare(w, v) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// EscapePath returns the case-encoded form of a module path used in proxy
+// URLs, in which each upper-case letter is replaced by "!" and its lower-case
+// equivalent.
+func EscapePath(path string) (string, error) {
+	if path == "" || strings.ContainsAny(path, "!@ ") {
+		return "", fmt.Errorf("modproxy: invalid module path %q", path)
+	}
+	var buf strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			buf.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String(), nil
+}
+
+// get fetches the proxy resource at the given escaped path.
+func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+	base := c.URL
+	if base == "" {
+		base = DefaultURL
+	}
+	req, err := http.NewRequest("GET", strings.TrimSuffix(base, "/")+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	rsp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	switch rsp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(rsp.Body)
+	case http.StatusNotFound, http.StatusGone:
+		return nil, ErrNotFound
+	}
+	return nil, fmt.Errorf("modproxy: %s: %s", path, rsp.Status)
+}
+
+// cachePath returns the path of the cache file for the escaped module path,
+// or "" if caching is not enabled.
+func (c *Client) cachePath(esc string) string {
+	if c.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.CacheDir, filepath.FromSlash(esc), "@v.list")
+}
+
+// cached returns the cached versions for the escaped module path, if there
+// is a current result.
+func (c *Client) cached(esc string) ([]string, bool) {
+	path := c.cachePath(esc)
+	if path == "" {
+		return nil, false
+	}
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) > maxAge {
+		return nil, false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return strings.Fields(string(data)), true
+}
+
+// store writes vs to the cache for the escaped module path, if caching is
+// enabled. Errors are ignored, since the cache is only an optimization.
+func (c *Client) store(esc string, vs []string) {
+	path := c.cachePath(esc)
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+		ioutil.WriteFile(path, []byte(strings.Join(vs, "\n")), 0600)
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/creachadair/repodeps/graph"
+)
+
+// A Match is a package selected by a query.
+type Match struct {
+	Row   *graph.Row
+	Depth int // the depth of the package in the traversal that selected it
+}
+
+// Eval evaluates q against g, and returns the selected packages in order of
+// their import paths.
+func (q *Query) Eval(ctx context.Context, g *graph.Graph) ([]*Match, error) {
+	e := &env{rows: make(map[string]*graph.Row)}
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		e.rows[row.ImportPath] = row
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	set := e.eval(q.root)
+	ms := make([]*Match, 0, len(set))
+	for pkg, depth := range set {
+		ms = append(ms, &Match{Row: e.rows[pkg], Depth: depth})
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Row.ImportPath < ms[j].Row.ImportPath
+	})
+	return ms, nil
+}
+
+// A set maps the import paths of selected packages to their depths.
+type set map[string]int
+
+// env holds the state of an evaluation.
+type env struct {
+	rows      map[string]*graph.Row // :: import path → row
+	importers map[string][]string   // :: import path → direct importers
+}
+
+// isFilter reports whether n can be evaluated one package at a time, which is
+// true unless n contains a traversal.
+func isFilter(n node) bool {
+	switch t := n.(type) {
+	case *andNode:
+		return isFilter(t.lhs) && isFilter(t.rhs)
+	case *orNode:
+		return isFilter(t.lhs) && isFilter(t.rhs)
+	case *notNode:
+		return isFilter(t.arg)
+	case *cmpNode:
+		return true
+	}
+	return false
+}
+
+// match reports whether the package described by row at the given depth
+// satisfies n, which must be a filter.
+func match(n node, row *graph.Row, depth int) bool {
+	switch t := n.(type) {
+	case *andNode:
+		return match(t.lhs, row, depth) && match(t.rhs, row, depth)
+	case *orNode:
+		return match(t.lhs, row, depth) || match(t.rhs, row, depth)
+	case *notNode:
+		return !match(t.arg, row, depth)
+	case *cmpNode:
+		return t.match(row, depth)
+	}
+	panic("match called on a non-filter")
+}
+
+func (e *env) eval(n node) set {
+	if isFilter(n) {
+		return e.filter(e.all(), n)
+	}
+	switch t := n.(type) {
+	case *andNode:
+		if isFilter(t.rhs) {
+			return e.filter(e.eval(t.lhs), t.rhs)
+		} else if isFilter(t.lhs) {
+			return e.filter(e.eval(t.rhs), t.lhs)
+		}
+		lhs, rhs := e.eval(t.lhs), e.eval(t.rhs)
+		out := make(set)
+		for pkg, d := range lhs {
+			if d2, ok := rhs[pkg]; ok {
+				out[pkg] = min(d, d2)
+			}
+		}
+		return out
+
+	case *orNode:
+		out := e.eval(t.lhs)
+		for pkg, d := range e.eval(t.rhs) {
+			if d2, ok := out[pkg]; !ok || d < d2 {
+				out[pkg] = d
+			}
+		}
+		return out
+
+	case *notNode:
+		arg := e.eval(t.arg)
+		out := make(set)
+		for pkg := range e.rows {
+			if _, ok := arg[pkg]; !ok {
+				out[pkg] = 0
+			}
+		}
+		return out
+
+	case *callNode:
+		next := e.imports
+		if t.name == "importers" {
+			next = e.importersOf
+		}
+		return e.traverse(e.eval(t.arg), next)
+	}
+	panic("unknown node type")
+}
+
+// all returns the set of all packages in the graph, at depth 0.
+func (e *env) all() set {
+	out := make(set, len(e.rows))
+	for pkg := range e.rows {
+		out[pkg] = 0
+	}
+	return out
+}
+
+// filter returns the elements of s that satisfy the filter n.
+func (e *env) filter(s set, n node) set {
+	out := make(set)
+	for pkg, d := range s {
+		if match(n, e.rows[pkg], d) {
+			out[pkg] = d
+		}
+	}
+	return out
+}
+
+// traverse returns the packages reachable from the elements of start by one or
+// more steps of next, with their distances from start. Packages without rows
+// are neither visited nor selected.
+func (e *env) traverse(start set, next func(string) []string) set {
+	var frontier []string
+	for pkg := range start {
+		frontier = append(frontier, pkg)
+	}
+	out := make(set)
+	for depth := 1; len(frontier) != 0; depth++ {
+		var reached []string
+		for _, cur := range frontier {
+			for _, pkg := range next(cur) {
+				if _, ok := e.rows[pkg]; !ok {
+					continue // not in the graph
+				} else if _, seen := out[pkg]; !seen {
+					out[pkg] = depth
+					reached = append(reached, pkg)
+				}
+			}
+		}
+		frontier = reached
+	}
+	return out
+}
+
+// imports returns the direct dependencies of pkg.
+func (e *env) imports(pkg string) []string { return e.rows[pkg].Directs }
+
+// importersOf returns the packages that directly import pkg.
+func (e *env) importersOf(pkg string) []string {
+	if e.importers == nil {
+		e.importers = make(map[string][]string)
+		for ipath, row := range e.rows {
+			for _, dep := range row.Directs {
+				e.importers[dep] = append(e.importers[dep], ipath)
+			}
+		}
+		for _, v := range e.importers {
+			sort.Strings(v)
+		}
+	}
+	return e.importers[pkg]
+}
+
+// match reports whether the package described by row at the given depth
+// satisfies the comparison.
+func (n *cmpNode) match(row *graph.Row, depth int) bool {
+	switch n.field {
+	case "depth":
+		return compare(depth, n.op, n.num)
+	case "imports":
+		return compare(len(row.Directs), n.op, n.num)
+	case "owner":
+		// A negated comparison matches if no owner matches the positive one.
+		pos := *n
+		pos.op = strings.TrimPrefix(n.op, "!")
+		for _, owner := range row.Owners {
+			if pos.matchText(owner) {
+				return pos.op == n.op
+			}
+		}
+		return pos.op != n.op
+	}
+	return n.matchText(fieldText(row, n.field))
+}
+
+// fieldText returns the value of the named text field of row.
+func fieldText(row *graph.Row, field string) string {
+	switch field {
+	case "path":
+		return row.ImportPath
+	case "name":
+		return row.Name
+	case "repo":
+		return row.Repository
+	case "module":
+		return row.Module
+	case "digest":
+		return row.Digest
+	case "language":
+		if row.Language == "" {
+			return "go"
+		}
+		return row.Language
+	case "unsafe":
+		return strconv.FormatBool(row.UsesUnsafe)
+	case "reflect":
+		return strconv.FormatBool(row.UsesReflect)
+	case "syscall":
+		return strconv.FormatBool(row.UsesSyscall)
+	}
+	return row.Labels[strings.TrimPrefix(field, "label.")]
+}
+
+// matchText reports whether s satisfies the comparison, which must be one of
+// the text operators.
+func (n *cmpNode) matchText(s string) bool {
+	switch n.op {
+	case "=":
+		return s == n.value
+	case "!=":
+		return s != n.value
+	case "~":
+		return n.glob.MatchString(s)
+	case "!~":
+		return !n.glob.MatchString(s)
+	}
+	return false
+}
+
+// compare reports whether a op b.
+func compare(a int, op string, b int) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/internal/memstore"
+)
+
+func TestEval(t *testing.T) {
+	ctx := context.Background()
+	g := graph.New(memstore.New(graph.ErrNotFound))
+
+	// app → lib → util → ext (no row)
+	// cmd → lib, cmd → app
+	// js (npm) → lib
+	for _, row := range []*graph.Row{
+		{ImportPath: "x/app", Name: "app", Directs: []string{"x/lib"}, Owners: []string{"ann"}},
+		{ImportPath: "x/cmd", Name: "main", Directs: []string{"x/lib", "x/app"}, Owners: []string{"ann", "bob"}},
+		{ImportPath: "x/lib", Name: "lib", Directs: []string{"x/util"}, UsesUnsafe: true,
+			Labels: map[string]string{"team": "core"}},
+		{ImportPath: "x/util", Name: "util", Directs: []string{"y/ext"}},
+		{ImportPath: "js", Name: "js", Directs: []string{"x/lib"}, Language: "npm"},
+	} {
+		if err := g.Put(ctx, row); err != nil {
+			t.Fatalf("Put %q: %v", row.ImportPath, err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string // path:depth, in order
+	}{
+		{"path = x/lib", "x/lib:0"},
+		{`path ~ "x/*"`, "x/app:0 x/cmd:0 x/lib:0 x/util:0"},
+		{`path !~ "x/*"`, "js:0"},
+		{"name = main", "x/cmd:0"},
+		{"language = go", "x/app:0 x/cmd:0 x/lib:0 x/util:0"},
+		{"language != go", "js:0"},
+		{"unsafe = true", "x/lib:0"},
+		{"label.team = core", "x/lib:0"},
+		{`label.team = ""`, "js:0 x/app:0 x/cmd:0 x/util:0"},
+		{"imports >= 2", "x/cmd:0"},
+		{"imports = 1", "js:0 x/app:0 x/lib:0 x/util:0"},
+		{"owner = bob", "x/cmd:0"},
+		{"owner != ann", "js:0 x/lib:0 x/util:0"},
+		{"owner !~ a*", "js:0 x/lib:0 x/util:0"},
+		{"path = y/ext", ""}, // no row of its own
+		{"not path ~ x/*", "js:0"},
+		{"name = app or name = lib", "x/app:0 x/lib:0"},
+
+		// Traversals report the shortest distance from their argument, and
+		// do not reach packages without rows.
+		{"imports(path = x/cmd)", "x/app:1 x/lib:1 x/util:2"},
+		{"imports(path = x/util)", ""},
+		{"importers(path = x/util)", "js:2 x/app:2 x/cmd:2 x/lib:1"},
+		{"importers(path = x/lib)", "js:1 x/app:1 x/cmd:1"},
+		{"imports(imports(path = x/app))", "x/util:1"},
+
+		// A comparison operand of "and" filters the other operand, so depth
+		// refers to the traversal.
+		{"importers(path = x/util) and depth <= 1", "x/lib:1"},
+		{"depth >= 2 and importers(path = x/util)", "js:2 x/app:2 x/cmd:2"},
+		{"importers(path = x/util) and language = go", "x/app:2 x/cmd:2 x/lib:1"},
+		{"importers(path = x/util) and not depth = 2", "x/lib:1"},
+
+		// Outside any traversal, depth is 0.
+		{"depth = 0", "js:0 x/app:0 x/cmd:0 x/lib:0 x/util:0"},
+		{"depth > 0", ""},
+
+		// Two traversals intersect, at the lesser depth.
+		{"importers(path = x/util) and importers(path = x/lib)", "js:1 x/app:1 x/cmd:1"},
+
+		// Union keeps the lesser depth, and not of a traversal is depth 0.
+		{"importers(path = x/lib) or imports(path = x/cmd)", "js:1 x/app:1 x/cmd:1 x/lib:1 x/util:2"},
+		{"not importers(path = x/lib)", "x/lib:0 x/util:0"},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.query)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", test.query, err)
+			continue
+		}
+		ms, err := q.Eval(ctx, g)
+		if err != nil {
+			t.Errorf("Eval(%q): unexpected error: %v", test.query, err)
+			continue
+		}
+		var got []string
+		for _, m := range ms {
+			got = append(got, fmt.Sprintf("%s:%d", m.Row.ImportPath, m.Depth))
+		}
+		if s := strings.Join(got, " "); s != test.want {
+			t.Errorf("Eval(%q): got %q, want %q", test.query, s, test.want)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package query implements a small language for selecting packages from a
+// dependency graph.
+//
+// A query is an expression that denotes a set of packages. The simplest
+// expression is a comparison of a field of each package with a value:
+//
+//	path ~ "github.com/creachadair/*"
+//	language = npm
+//	label.team = infra
+//	imports > 20
+//
+// selects the packages whose field satisfies the comparison. The fields are:
+//
+//	path        the import path of the package
+//	name        the package name
+//	repo        the repository URL
+//	module      the module label, path@version
+//	digest      the content digest of the package, algorithm:hex ("" if
+//	            it was not recorded)
+//	language    the language of the package ("go" for Go packages)
+//	owner       an owner of the package (matches if any owner does)
+//	label.KEY   the value of label KEY ("" if it is not set)
+//	unsafe      whether the package imports unsafe ("true" or "false")
+//	reflect     whether the package imports reflect
+//	syscall     whether the package imports syscall
+//	imports     the number of direct dependencies of the package
+//	depth       the distance of the package from the argument of the
+//	            nearest enclosing traversal, or 0 outside any traversal
+//
+// The operators are = and != for equality, ~ and !~ for glob matching, where
+// "*" matches any sequence of characters including "/" and "?" matches any
+// single character, and <, <=, >, and >= for numeric comparison. A value is
+// either a bare word, such as infra or 2, or a double-quoted string.
+//
+// The traversals imports(expr) and importers(expr) denote the packages that
+// are transitively imported by, or that transitively import, the packages
+// denoted by expr. Each package they select has a depth, the length of the
+// shortest path to it from a package of expr.
+//
+// Expressions may be combined with "and", "or", and "not", in decreasing
+// order of precedence, and grouped with parentheses. When one operand of
+// "and" is a comparison, it filters the packages selected by the other, so
+//
+//	importers(path ~ "github.com/org/*") and depth <= 2
+//
+// selects packages that import something under github.com/org/ directly or
+// through at most one intermediary.
+//
+// Queries treat the graph as a closed world: a package that is imported but
+// has no row of its own in the graph is never selected, and traversals do not
+// pass through it.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A Query is a parsed query expression.
+type Query struct {
+	root node
+}
+
+// Parse parses a query expression.
+func Parse(s string) (*Query, error) {
+	toks, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	} else if p.pos < len(p.toks) {
+		return nil, p.errorf("unexpected %s", p.toks[p.pos])
+	}
+	return &Query{root: root}, nil
+}
+
+// String renders q in a canonical form, which parses to an equivalent query.
+func (q *Query) String() string { return q.root.String() }
+
+// A node is an element of the syntax tree of a query.
+type node interface {
+	String() string
+}
+
+type andNode struct{ lhs, rhs node }
+type orNode struct{ lhs, rhs node }
+type notNode struct{ arg node }
+
+// A callNode is a traversal, either "imports" or "importers".
+type callNode struct {
+	name string
+	arg  node
+}
+
+// A cmpNode is a comparison of a field with a value.
+type cmpNode struct {
+	field, op, value string
+	num              int            // value as an integer, for numeric fields
+	glob             *regexp.Regexp // value as a pattern, for ~ and !~
+}
+
+func (n *andNode) String() string { return "(" + n.lhs.String() + " and " + n.rhs.String() + ")" }
+func (n *orNode) String() string  { return "(" + n.lhs.String() + " or " + n.rhs.String() + ")" }
+func (n *notNode) String() string { return "not " + n.arg.String() }
+
+func (n *callNode) String() string { return n.name + "(" + n.arg.String() + ")" }
+
+func (n *cmpNode) String() string {
+	if numericFields[n.field] {
+		return n.field + " " + n.op + " " + strconv.Itoa(n.num)
+	}
+	return n.field + " " + n.op + " " + strconv.Quote(n.value)
+}
+
+// numericFields are the fields whose values are integers.
+var numericFields = map[string]bool{"imports": true, "depth": true}
+
+// textFields are the fields whose values are strings. Fields of the form
+// label.KEY are also text fields.
+var textFields = map[string]bool{
+	"path": true, "name": true, "repo": true, "module": true, "digest": true,
+	"language": true, "owner": true, "unsafe": true, "reflect": true, "syscall": true,
+}
+
+// traversals are the names of the traversal functions.
+var traversals = map[string]bool{"imports": true, "importers": true}
+
+type tokenKind int
+
+const (
+	tokWord   tokenKind = iota // a bare word
+	tokString                  // a quoted string, already unquoted
+	tokOp                      // a comparison operator
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // byte offset in the input
+}
+
+func (t token) String() string {
+	if t.kind == tokString {
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// isWordRune reports whether r may occur in a bare word.
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`()=!~<>"`, r)
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			toks = append(toks, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case strings.ContainsRune("=!~<>", rune(c)):
+			op := s[i : i+1]
+			if i+1 < len(s) && s[i+1] == '=' && c != '=' && c != '~' {
+				op = s[i : i+2] // !=, <=, >=
+			} else if c == '!' && i+1 < len(s) && s[i+1] == '~' {
+				op = "!~"
+			} else if c == '!' {
+				return nil, fmt.Errorf("offset %d: invalid operator %q", i, op)
+			}
+			toks = append(toks, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("offset %d: unterminated string", i)
+			}
+			text, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("offset %d: invalid string: %v", i, err)
+			}
+			toks = append(toks, token{kind: tokString, text: text, pos: i})
+			i = end + 1
+		default:
+			end := strings.IndexFunc(s[i:], func(r rune) bool { return !isWordRune(r) })
+			if end < 0 {
+				end = len(s) - i
+			} else if end == 0 {
+				return nil, fmt.Errorf("offset %d: unexpected %q", i, s[i:i+1])
+			}
+			toks = append(toks, token{kind: tokWord, text: s[i : i+end], pos: i})
+			i += end
+		}
+	}
+	return toks, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) errorf(msg string, args ...interface{}) error {
+	if p.pos < len(p.toks) {
+		return fmt.Errorf("offset %d: %s", p.toks[p.pos].pos, fmt.Sprintf(msg, args...))
+	}
+	return fmt.Errorf("at end of query: %s", fmt.Sprintf(msg, args...))
+}
+
+// peekWord reports whether the next token is the bare word w.
+func (p *parser) peekWord(w string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == tokWord && p.toks[p.pos].text == w
+}
+
+// next returns the next token, or reports an error at the end of input.
+func (p *parser) next(want string) (token, error) {
+	if p.pos >= len(p.toks) {
+		return token{}, p.errorf("missing %s", want)
+	}
+	p.pos++
+	return p.toks[p.pos-1], nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	lhs, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("or") {
+		p.pos++
+		rhs, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &orNode{lhs: lhs, rhs: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	lhs, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekWord("and") {
+		p.pos++
+		rhs, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &andNode{lhs: lhs, rhs: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peekWord("not") {
+		p.pos++
+		arg, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{arg: arg}, nil
+	}
+	return p.parseAtom()
+}
+
+func (p *parser) parseAtom() (node, error) {
+	tok, err := p.next("expression")
+	if err != nil {
+		return nil, err
+	}
+	switch tok.kind {
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		} else if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+
+	case tokWord:
+		if p.pos < len(p.toks) && p.toks[p.pos].kind == tokLParen {
+			if !traversals[tok.text] {
+				p.pos--
+				return nil, p.errorf("unknown function %q", tok.text)
+			}
+			p.pos++
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			} else if err := p.expect(tokRParen, ")"); err != nil {
+				return nil, err
+			}
+			return &callNode{name: tok.text, arg: arg}, nil
+		}
+		return p.parseComparison(tok)
+	}
+	p.pos--
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+func (p *parser) expect(kind tokenKind, want string) error {
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != kind {
+		return p.errorf("missing %s", want)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) parseComparison(field token) (node, error) {
+	isLabel := strings.HasPrefix(field.text, "label.") && len(field.text) > len("label.")
+	if !numericFields[field.text] && !textFields[field.text] && !isLabel {
+		p.pos--
+		return nil, p.errorf("unknown field %q", field.text)
+	}
+	op, err := p.next("operator")
+	if err != nil {
+		return nil, err
+	} else if op.kind != tokOp {
+		p.pos--
+		return nil, p.errorf("expected operator after %q, got %s", field.text, op)
+	}
+	val, err := p.next("value")
+	if err != nil {
+		return nil, err
+	} else if val.kind != tokWord && val.kind != tokString {
+		p.pos--
+		return nil, p.errorf("expected value after %q, got %s", op.text, val)
+	}
+	n := &cmpNode{field: field.text, op: op.text, value: val.text}
+	if numericFields[n.field] {
+		if n.op == "~" || n.op == "!~" {
+			return nil, fmt.Errorf("offset %d: operator %q is not valid for %q", op.pos, n.op, n.field)
+		}
+		v, err := strconv.Atoi(n.value)
+		if err != nil {
+			return nil, fmt.Errorf("offset %d: value of %q must be an integer", val.pos, n.field)
+		}
+		n.num = v
+	} else if n.op == "~" || n.op == "!~" {
+		n.glob = globRegexp(n.value)
+	} else if n.op != "=" && n.op != "!=" {
+		return nil, fmt.Errorf("offset %d: operator %q is not valid for %q", op.pos, n.op, n.field)
+	}
+	return n, nil
+}
+
+// globRegexp compiles a glob pattern in which "*" matches any sequence of
+// characters and "?" matches any single character.
+func globRegexp(pat string) *regexp.Regexp {
+	var buf strings.Builder
+	buf.WriteString("^")
+	for _, c := range pat {
+		switch c {
+		case '*':
+			buf.WriteString(".*")
+		case '?':
+			buf.WriteString(".")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.MustCompile(buf.String())
+}
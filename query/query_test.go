@@ -0,0 +1,187 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string // kind:text for each token
+	}{
+		{"", nil},
+		{"  \t\n", nil},
+		{"path = x", []string{"word:path", "op:=", "word:x"}},
+		{"a!=b", []string{"word:a", "op:!=", "word:b"}},
+		{"a !~ b", []string{"word:a", "op:!~", "word:b"}},
+		{"a<=1 a>=2 a<3 a>4", []string{
+			"word:a", "op:<=", "word:1", "word:a", "op:>=", "word:2",
+			"word:a", "op:<", "word:3", "word:a", "op:>", "word:4",
+		}},
+		{"a == b", []string{"word:a", "op:=", "op:=", "word:b"}},
+		{"a ~= b", []string{"word:a", "op:~", "op:=", "word:b"}},
+		{`name = "a b"`, []string{"word:name", "op:=", "string:a b"}},
+		{`name = "say \"hi\""`, []string{"word:name", "op:=", `string:say "hi"`}},
+		{`name = "back\\slash"`, []string{"word:name", "op:=", `string:back\slash`}},
+		{`name = ""`, []string{"word:name", "op:=", "string:"}},
+		{"imports(path=x)", []string{"word:imports", "lparen:(", "word:path", "op:=", "word:x", "rparen:)"}},
+		{"label.team=infra", []string{"word:label.team", "op:=", "word:infra"}},
+		{"github.com/*/x", []string{"word:github.com/*/x"}},
+	}
+	kinds := map[tokenKind]string{
+		tokWord: "word", tokString: "string", tokOp: "op", tokLParen: "lparen", tokRParen: "rparen",
+	}
+	for _, test := range tests {
+		toks, err := tokenize(test.input)
+		if err != nil {
+			t.Errorf("tokenize(%q): unexpected error: %v", test.input, err)
+			continue
+		}
+		var got []string
+		for _, tok := range toks {
+			got = append(got, kinds[tok.kind]+":"+tok.text)
+		}
+		if strings.Join(got, " | ") != strings.Join(test.want, " | ") {
+			t.Errorf("tokenize(%q):\n got %q\nwant %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"a ! b", `offset 2: invalid operator "!"`},
+		{"a !", `offset 2: invalid operator "!"`},
+		{`name = "abc`, "offset 7: unterminated string"},
+		{`name = "abc\"`, "offset 7: unterminated string"},
+		{`name = "\q"`, "offset 7: invalid string"},
+	}
+	for _, test := range tests {
+		toks, err := tokenize(test.input)
+		if err == nil {
+			t.Errorf("tokenize(%q): got %v, want error", test.input, toks)
+		} else if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("tokenize(%q): got error %q, want %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"path = x", `path = "x"`},
+		{`path ~ "github.com/*"`, `path ~ "github.com/*"`},
+		{"imports >= 020", "imports >= 20"},
+		{"depth != 0", "depth != 0"},
+		{"label.team = infra", `label.team = "infra"`},
+		{"owner !~ bob*", `owner !~ "bob*"`},
+
+		// Precedence: not binds tighter than and, which binds tighter than or.
+		{"name = a or name = b and name = c", `(name = "a" or (name = "b" and name = "c"))`},
+		{"name = a and name = b or name = c", `((name = "a" and name = "b") or name = "c")`},
+		{"not name = a and name = b", `(not name = "a" and name = "b")`},
+		{"not not name = a", `not not name = "a"`},
+		{"(name = a or name = b) and name = c", `((name = "a" or name = "b") and name = "c")`},
+		{"name = a and name = b and name = c", `((name = "a" and name = "b") and name = "c")`},
+
+		{"importers(path = x) and depth <= 2", `(importers(path = "x") and depth <= 2)`},
+		{"imports(imports(name = a))", `imports(imports(name = "a"))`},
+		{"not (imports(name = a))", `not imports(name = "a")`},
+
+		// Keywords are only special in their positions.
+		{"name = and", `name = "and"`},
+		{`name = "say \"hi\""`, `name = "say \"hi\""`},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", test.input, err)
+			continue
+		}
+		got := q.String()
+		if got != test.want {
+			t.Errorf("Parse(%q): got %s, want %s", test.input, got, test.want)
+		}
+
+		// The canonical form must parse to the same query.
+		q2, err := Parse(got)
+		if err != nil {
+			t.Errorf("Parse(%q): re-parsing %s failed: %v", test.input, got, err)
+		} else if s := q2.String(); s != got {
+			t.Errorf("Parse(%q): %s re-parses as %s", test.input, got, s)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"", "at end of query: missing expression"},
+		{"path", "at end of query: missing operator"},
+		{"path =", "at end of query: missing value"},
+		{"path == x", `offset 6: expected value after "=", got "="`},
+		{"path x", `offset 5: expected operator after "path", got "x"`},
+		{"size = 3", `offset 0: unknown field "size"`},
+		{"label. = x", `offset 0: unknown field "label."`},
+		{"imports = many", `offset 10: value of "imports" must be an integer`},
+		{"depth ~ 1", `offset 6: operator "~" is not valid for "depth"`},
+		{"name < a", `offset 5: operator "<" is not valid for "name"`},
+		{"closure(name = a)", `offset 0: unknown function "closure"`},
+		{"(name = a", "at end of query: missing )"},
+		{"imports(name = a", "at end of query: missing )"},
+		{"name = a)", `offset 8: unexpected ")"`},
+		{"name = a name = b", `offset 9: unexpected "name"`},
+		{"name = a and", "at end of query: missing expression"},
+		{"not", "at end of query: missing expression"},
+		{"= x", `offset 0: unexpected "="`},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.input)
+		if err == nil {
+			t.Errorf("Parse(%q): got %s, want error", test.input, q)
+		} else if err.Error() != test.want {
+			t.Errorf("Parse(%q): got error %q, want %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern, input string
+		want           bool
+	}{
+		{"*", "", true},
+		{"*", "a/b/c", true},
+		{"github.com/*", "github.com/a/b", true},
+		{"github.com/*", "github.com", false},
+		{"a?c", "abc", true},
+		{"a?c", "a/c", true},
+		{"a?c", "ac", false},
+		{"a.c", "abc", false},
+		{"a+b", "a+b", true},
+		{"x", "xx", false},
+	}
+	for _, test := range tests {
+		if got := globRegexp(test.pattern).MatchString(test.input); got != test.want {
+			t.Errorf("glob %q match %q: got %v, want %v", test.pattern, test.input, got, test.want)
+		}
+	}
+}
@@ -17,33 +17,87 @@
 package main
 
 import (
-	"bufio"
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
 	"path/filepath"
+	"runtime/trace"
+	"sort"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
+	"github.com/creachadair/repodeps/auth"
 	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/github"
 	"github.com/creachadair/repodeps/local"
-	"github.com/creachadair/repodeps/siva"
+	"github.com/creachadair/repodeps/throttle"
 	"github.com/creachadair/taskgroup"
 )
 
 var (
 	doReadInputs = flag.Bool("stdin", false, "Read input filenames from stdin")
-	doSourceHash = flag.Bool("sourcehash", false, "Record the names and digests of source files")
+	doRecursive  = flag.Bool("recursive", false, "Search input directories recursively for repositories")
+	doSourceHash = flag.Bool("sourcehash", false, "Record the names and digests of Go and other source files")
+	hashAlg      = flag.String("hashalg", "sha256", `Digest for -sourcehash ("sha256", "blake3", or "git")`)
+	doCountRefs  = flag.Bool("refs", false, "Count references to the exported names of each import")
+	analyzerList = flag.String("analyzers", "go", `Comma-separated analyzers to apply ("all" for all)`)
+	doBuildTags  = flag.Bool("buildtags", false, "Record the build tags used by each package's constraints")
+	doDirectives = flag.Bool("directives", false, "Record go:embed patterns and go:generate commands")
+	doFileImps   = flag.Bool("fileimports", false, "Record the imports declared by each source file")
+	doImportPos  = flag.Bool("importpos", false, "Record the file, line, and column of each import")
+	doDedup      = flag.Bool("dedup", true, "Skip duplicate inputs and repositories")
+	doSubmodules = flag.Bool("submodules", false, "Initialize and scan Git submodules")
+	doNested     = flag.Bool("nested", false, "Scan repositories nested inside other repositories separately")
+	linkPolicy   = flag.String("symlinks", "skip", `How to handle symbolic links ("skip", "follow", or "error")`)
+	stdlibPolicy = flag.String("stdlib", "include", `How to record standard library imports ("include", "exclude", or "separate")`)
+	goVersion    = flag.String("goversion", "", "Identify standard library imports as of this Go version (e.g., 1.21)")
+	stdlibFile   = flag.String("stdlib-catalog", "", "Read the standard library packages from this file")
+	doGOPATH     = flag.Bool("gopath", false, "Treat inputs as GOPATH roots rather than repositories")
+	doModCache   = flag.Bool("modcache", false, "Treat inputs as module cache roots rather than repositories")
+	doActivity   = flag.Bool("activity", false, "Summarize the commit history of each repository")
+	doPseudo     = flag.Bool("pseudoversions", false, "Version modules by the tag or pseudo-version of the scanned commit")
+	doGitHub     = flag.Bool("github", false, "Fetch repository metadata from GitHub")
+	gitHubCache  = flag.String("github-cache", "", "Cache GitHub metadata in this directory")
+	outFormat    = flag.String("format", "", "Format output records with this text/template rather than as JSON")
+	formatEach   = flag.String("per", "package", `Apply -format to each "package" or each "repo"`)
+	doPrecise    = flag.Bool("precise", false, "Resolve the imports of module packages with the go command (slow)")
+	doSummary    = flag.Bool("summary", false, "Count the files and packages in each repository without loading them")
+	doDryRun     = flag.Bool("n", false, "List the inputs that would be scanned without scanning them")
+	doSorted     = flag.Bool("sorted", false, "Write output in a deterministic order after all inputs are processed")
+	selectExpr   = flag.String("select", "", "Trim JSON output to these comma-separated fields")
+	labelSpec    = flag.String("labels", "", "Attach these comma-separated key=value labels to every repository")
 	concurrency  = flag.Int("concurrency", 32, "Maximum concurrent workers")
+	hostInterval = flag.Duration("host-interval", 0, "Minimum interval between remote operations on each host")
+	hostActive   = flag.Int("host-limit", 4, "Maximum concurrent remote operations on each host (0 for no limit)")
+	numRetries   = flag.Int("retries", 0, "Number of times to retry a failed remote operation")
+	retryBackoff = flag.Duration("backoff", throttle.DefaultBackoff, "Delay before the first retry of a remote operation")
+	authConfig   = flag.String("auth", os.Getenv("REPODEPS_AUTH"), "Read credentials for remote hosts from this JSON file")
+	cacheDir     = flag.String("clone-cache", "", "Keep clones of remote repositories in this directory")
+	cacheShare   = flag.Bool("clone-share", false, "Share objects among clones in the -clone-cache directory")
+	pprofAddr    = flag.String("pprof", "", "Serve net/http/pprof profiles at this address during the scan")
+	traceFile    = flag.String("trace", "", "Write an execution trace of the scan to this file")
 
-	out = &struct {
-		sync.Mutex
-		io.Writer
-	}{Writer: os.Stdout}
+	remote *throttle.Limiter // paces remote operations, per -host-limit etc.
+	creds  *auth.Config      // credentials for remote hosts, per -auth
+	clones *local.CloneCache // cached clones, per -clone-cache, or nil
+
+	outTemplate  *template.Template // parsed from -format, if set
+	outSelection *selection         // parsed from -select, if set
+	baseLabels   map[string]string  // parsed from -labels, if set
+
+	outputSpec = flag.String("output", "json=-", "Comma-separated output sinks (see below)")
+	storeQueue = flag.Int("store-queue", 64, "Repositories to queue for a store sink before scanning waits")
+	storeBatch = flag.Int("store-batch", 256, "Packages to buffer before writing to a store sink (1 to write each at once)")
+	storeFlush = flag.Duration("store-flush", time.Second, "Maximum time a package is buffered for a store sink")
+
+	sinks []sink // parsed from -output
 )
 
 func init() {
@@ -54,17 +108,311 @@ Search the specified Git repositories for Go source packages, and record the
 names and package dependencies of each package found. Each non-flag argument
 should be either a Git directory path, or the path of a .siva archive that
 contains a rooted collection of Git repositories as generated by Borges[1].
-Output is streamed to stdout as JSON.
+Local Mercurial repositories are also supported, if the hg command is
+installed; submodules and nested repositories are not scanned for these.
+By default, output is streamed to stdout as JSON.
 
 If -stdin is set, then each line of stdin is read after all the non-flag
-arguments are processed.
+arguments are processed. Each line is either a plain path, or a JSON object
+describing an input and options for how it should be scanned:
+
+  {
+    "path":    "/path/to/repo",  // local directory or .siva path, or...
+    "url":     "https://...",    // remote repository URL to clone
+    "ref":     "v1.2.0",         // branch, tag, or revision to scan
+    "include": ["cmd/..."],      // directory patterns to record
+    "labels":  {"team": "foo"}   // labels attached to the output
+  }
+
+Exactly one of "path" or "url" must be set. The other fields are optional.
+
+Lines are read from stdin as they arrive, so another process can feed inputs
+to a running scan. The results of each input are written as soon as it has
+been scanned, without waiting for other inputs: in this mode the store and
+bigquery sinks flush after every input instead of buffering a batch. (With
+-sorted, output is still held until stdin is closed.)
+
+The results of each input are written at most once. An input that cannot be
+scanned is logged and skipped, not retried; retries of a remote fetch happen
+before anything is written; and if writing to a sink fails, the run stops. If
+a run stops while inputs are in flight, their results may have reached some
+sinks but not others, so a feeder that needs every input should resubmit the
+ones whose results it did not see; repeated inputs are skipped unless -dedup
+is false.
+
+Labels given by -labels are attached to every repository, and the labels of a
+manifest entry are added to them, replacing any with the same key. Labels are
+included in the output and stored with each package written to a graph, where
+the -label flag of listdeps, statdeps, and exportdeps can select them.
+
+A "url" input is cloned into a temporary directory, which is discarded after
+it has been scanned. If -clone-cache is set, bare clones are kept in that
+directory instead, named by the canonical URL of each repository, and a later
+scan of the same repository fetches only the objects that have changed.
+With -clone-share, the cached clones also share a single object store, so
+that forks of the same project download and store their common history once.
+
+Remote operations, namely cloning a "url" input and fetching metadata with
+-github, are paced separately for each host: at most -host-limit operations
+on a host run at once, and each starts at least -host-interval after the one
+before. A failed operation is retried up to -retries times, waiting -backoff
+before the first retry and twice as long before each retry after that, with
+random jitter. A server that answers 429 or a 5xx status with a Retry-After
+header holds off further requests to its host for that long.
+
+To clone private repositories, set -auth (or $REPODEPS_AUTH) to the path of a
+JSON file assigning credentials to hosts, or to host/path prefixes:
+
+  {"hosts": {
+    "github.com": {"token_env": "GITHUB_TOKEN"},
+    "github.com/org": {"github_app": {"app_id": 1, "installation_id": 2,
+                                      "private_key": "/path/to/app.pem"}},
+    "git.example.com": {"ssh_key": "/path/to/id_ed25519", "token": "..."}
+  }}
+
+The most specific matching entry is used for each repository. Tokens (given
+directly, named by "token_env", or issued to a GitHub App installation) are
+sent for HTTPS remotes, and SSH keys are used for SSH remotes. Without -auth,
+$GITHUB_TOKEN and $GITLAB_TOKEN are used for github.com and gitlab.com if set.
+The github.com credential is also used for -github metadata requests.
+
+If -fileimports is set, each source file of a package is listed along with the
+imports it declares, so that a change to a file can be mapped to the
+dependencies it introduces. This may be combined with -sourcehash.
+
+If -importpos is set, the file, line, and column of each import declaration
+of a package are recorded, and stored with the package in a graph, so that
+tools such as linters and codemods can find the imports behind each edge.
+
+A Go source file whose imports cannot be parsed is left out of its package,
+which is loaded from the remaining files, and is listed with the position and
+text of its first error in the "parse_errors" of the package.
+
+A directory whose Go source files declare more than one package name, not
+counting external test packages, contributes no Go package. Instead it is
+listed in the "conflicts" of its repository, with the files and the package
+name declared by each.
+
+The -analyzers flag selects which kinds of packages are loaded from each
+directory. By default only Go packages are loaded. Packages found by analyzers
+for other languages or ecosystems are marked with the name of the analyzer in
+their "language" field. Use -analyzers=all to apply all available analyzers.
+
+  bazel   Go packages defined by go_library and go_binary rules in Bazel BUILD
+          files, with dependencies resolved from their labels (including
+          go_repository rules in the WORKSPACE); use this instead of "go" for
+          repositories that do not follow the standard Go layout
+  cargo   Rust crates defined by Cargo.toml, named "crates:<name>"; normal and
+          build dependencies (but not dev-dependencies) are recorded as
+          imports, and versions resolved by Cargo.lock in "versions"; target
+          directories are not scanned
+  go      Go packages (the default)
+  jvm     Maven (pom.xml) and Gradle (build.gradle, build.gradle.kts) projects,
+          named "maven:<group>:<artifact>"; dependencies outside test scopes
+          are recorded as imports and their versions in "versions"; a Gradle
+          project without a group is named "jvm:<repository>/<dir>"
+  npm     npm packages defined by package.json, named "npm:<name>"; direct
+          dependencies (but not devDependencies) are recorded as imports, and
+          the versions of the package and of the dependencies resolved by
+          package-lock.json are recorded in "versions"; node_modules
+          directories are not scanned
+  python  Python projects defined by pyproject.toml, setup.cfg, or
+          requirements.txt, named "pypi:<name>"; a directory with only a
+          requirements file is named "python:<repository>/<dir>"; exact pins
+          (name==version) are recorded in "versions"
+
+If -buildtags is set, each package records in "build_tags" the tags named by
+the build constraints of all its source files, including test files and files
+the default build excludes, along with the operating systems and architectures
+implied by file names such as "x_linux_amd64.go".
+
+If -directives is set, each package records the patterns of its //go:embed
+directives in "embeds" and the commands of its //go:generate directives in
+"generates". Embedded files and generators are dependencies that do not appear
+among the imports.
+
+If -refs is set, each package records the number of references its source
+files make to the exported names of each of its imports, in "import_refs", and
+the number of distinct names referenced, in "import_symbols", both parallel to
+"imports". This is a syntactic count: a reference is a selector
+such as "pkg.Name" where pkg is the name of an import. Uses through dot imports
+are not counted. The counts are stored in the graph, where they can be used as
+edge weights.
+
+If -precise is set, the imports of packages inside a module are resolved by
+running "go list" in each module of a local repository, so that build
+constraints and cgo are handled exactly as the go command would. The go command
+is not allowed to download anything, and packages it cannot load keep the
+imports found by the ordinary scan. Packages resolved this way are marked
+"precise" in the output. This option requires a Go toolchain and has no effect
+for siva archives, -gopath, or -modcache.
+
+If -summary is set, packages are not loaded. Instead, each repository records a
+summary of the numbers of files, Go source and test files, Go packages, and
+go.mod files it contains, as a quick way to decide which repositories are worth
+a full scan. Summaries are not supported with -gopath or -modcache.
+
+If -n is set, the inputs that would be scanned are listed on stdout, after any
+recursive search and deduplication, along with how each would be scanned
+("git", "hg", "siva", "url", "gopath", or "modcache"; or "unknown" for a path
+that is not a repository), but nothing is scanned or written to the outputs.
+
+If -recursive is set, each input that is a directory is searched recursively
+for Git and Mercurial repositories and .siva files, and each of those is
+processed in place of the directory itself. The search does not descend into
+a repository once it is found.
 
 If -sourcehash is set, the repository-relative paths and content digests of the
-Go source file in each packge are also captured.
+Go source file in each packge are also captured. Other source files that are
+part of the package, such as assembly, C, and C++ files, are recorded in the
+same way under "other_sources".
+
+The -hashalg flag selects how the digests are computed: "sha256" (the default)
+or "blake3" hash the contents of each file, and "git" computes the object ID
+Git assigns to the file as a blob, so that files can be matched against the
+trees of a repository. The algorithm used is recorded in "hash_algorithm".
+Each package also records a "digest" of its contents, computed over the
+sorted digests of its files, which is the same for every copy of a package
+regardless of where it is found, and is stored in the graph.
+
+If -submodules is set, the submodules of each local repository are initialized
+(if necessary) and scanned along with it. Packages found inside a submodule are
+marked with the path of that submodule. Submodules are not supported for .siva
+archives or when scanning a specific ref.
+
+Git repositories nested inside the worktree of a local repository (other than
+scanned submodules) are not included in the enclosing repository. By default
+they are skipped, and their paths are recorded; if -nested is set, they are
+scanned as separate repositories instead.
+
+The -symlinks flag controls how symbolic links inside local repositories are
+handled: "skip" ignores them, "follow" follows them (visiting each target
+directory at most once, so that cycles and duplicate trees are harmless), and
+"error" causes any repository containing a symbolic link to be skipped.
+
+Imports of standard library packages are also listed separately in the
+std_imports field of each package. The -stdlib flag controls whether they are
+listed among the other imports: "include" keeps them there, "separate" lists
+them only in std_imports, and "exclude" drops them from the output entirely.
+
+By default, any import path whose first element has no dot is taken to be in
+the standard library. If -goversion is set, standard library imports are
+instead those in a catalog of the packages of each Go release, built in to
+this program, as of that version, regardless of the installed toolchain. The
+-stdlib-catalog flag replaces the built-in catalog with one read from a file,
+in which each line gives an import path and optionally the Go version that
+introduced it:
 
-Inputs are processed concurrently with up to -concurrency in parallel.
+  embed 1.16
+  fmt
+
+Each import listed for a package is classified in the parallel import_classes
+field as one of 1 (standard library), 2 (same module), 3 (same repository), or
+4 (external), following the ImportClass enumeration in deps/deps.proto.
+
+If -gopath is set, each input is treated as the root of a GOPATH tree, and the
+packages under its src directory are scanned without reference to version
+control. If -modcache is set, each input is treated as the root of a module
+download cache (such as $GOMODCACHE), and each module@version directory is
+scanned as a separate repository. If no inputs are given for these modes, the
+defaults from the Go environment are used.
+
+Each package is attributed to the owners named for its directory by the
+CODEOWNERS file of its repository, or failing that by the OWNERS file in the
+nearest enclosing directory.
+
+If -activity is set, the commit history of each repository is summarized with
+the time of the newest commit, the number of commits in the past year, and the
+total numbers of commits and distinct authors. Repositories cloned from a URL
+at a branch or tag are shallow, so their history includes only the newest
+commit.
+
+If -pseudoversions is set, each module defined by a repository scanned at a
+commit is versioned as the go command would resolve that commit: by the
+highest version tagged on the commit, or else by a pseudo-version such as
+v1.2.4-0.20190102150405-abcdefabcdef derived from the commit time and ID and
+the highest version tagged on its ancestors. The modules and package rows
+then carry versions that module proxies accept, in place of no version.
+Repositories cloned from a URL at a branch or tag are shallow, so tags on
+older commits are not seen.
+
+Each repository records in "description" the title of the README file at its
+root, if it has one, as a human-readable summary of the repository, and in
+"license" the SPDX identifier of the license in its LICENSE or COPYING file,
+or "unknown" if the license is not one of the common licenses recognized.
+
+If -github is set, each repository with a GitHub remote is annotated with its
+star and fork counts, archived status, primary language, topics, and
+description, fetched from the GitHub API. Set GITHUB_TOKEN to authenticate
+these requests, which raises the API rate limit. Requests are spaced out to
+stay within the limit, and if -github-cache is set, results are cached there
+for a day.
+
+By default, inputs that resolve to the same path, and repositories with the
+same remote URL at the same commit, are only processed once per run even if
+they are reached by different paths or symbolic links or occur in different
+.siva files. Set -dedup=false to disable this and process every input as
+given.
+
+Remote URLs are recorded in a canonical form, without a scheme, user name, or
+".git" suffix, and with the host name in lower case, so that a repository has
+the same identity whether it was cloned over HTTPS or SSH. For example, both
+"https://github.com/foo/bar.git" and "git@github.com:foo/bar" are recorded as
+"github.com/foo/bar".
+
+By default each batch of repositories is written as a JSON array. If -format is
+set, it is parsed as a text/template[2] and executed for each package (or each
+repository if -per=repo), and the results are written instead, one per line.
+For packages the template sees the fields of the package record, plus .Repo for
+the enclosing repository; for example:
+
+  -format '{{.ImportPath}} {{len .Imports}}'
+  -format '{{.ImportPath}} {{join .Imports ","}}'
+  -per repo -format '{{.From}} {{len .Packages}}'
+
+The -output flag selects where results are written. Its value is a comma-
+separated list of sinks, each of the form kind=target, and every result is
+written to all of them:
+
+  json=-          JSON (or -format/-select output) to stdout
+  json=path       the same, to the named file
+  store=path      packages and modules added to the graph store at path, in
+                  batches per -store-batch and -store-flush; when -store-queue
+                  repositories are waiting to be added, scanning pauses
+  kafka=url       one message per repository, published to a Kafka topic via
+                  the REST proxy topic URL (e.g., http://host:8082/topics/deps)
+  kafka-pkg=url   the same, but one message per package
+  es=url          packages indexed into Elasticsearch or OpenSearch, where url
+                  names the index (e.g., http://host:9200/packages)
+  bigquery=dir    newline-delimited JSON tables of packages, edges, and
+                  repositories, with BigQuery schema files, written to dir
+
+For example, "-output json=scan.json,store=deps.db" saves the JSON output and
+updates a graph in the same pass.
+
+The -select flag trims the JSON output to chosen fields. Its value is a comma-
+separated list of dotted field paths, where arrays are traversed implicitly.
+If any paths are given, only those fields are kept; paths prefixed with "-"
+are removed. For example:
+
+  -select 'from,packages.import_path,packages.imports'
+  -select '-packages.sources,-packages.std_imports'
+
+Inputs are processed concurrently with up to -concurrency in parallel, so the
+order of the output varies from run to run. If -sorted is set, the output is
+buffered until all inputs are processed, and then written one repository per
+line, with repositories, packages, imports, and other lists sorted, so that
+the output of separate runs can be compared directly.
+
+To find where the time of a scan goes, -pprof serves CPU, heap, and other
+profiles over HTTP while it runs (see /debug/pprof/ at that address), and
+-trace writes an execution trace for "go tool trace". In the trace, each input
+is a "scan" task, divided into "fetch" (cloning remote repositories), "git"
+(reading Git metadata and extracting trees), "siva" (reading Siva archives),
+and "parse" (analyzing source files) regions.
 
 [1]: https://github.com/src-d/borges
+[2]: https://golang.org/pkg/text/template
 
 Options:
 `, filepath.Base(os.Args[0]))
@@ -74,80 +422,282 @@ Options:
 
 func main() {
 	flag.Parse()
-	if flag.NArg() == 0 && !*doReadInputs {
-		log.Fatalf("Usage: %s <repo-dir> ...", filepath.Base(os.Args[0]))
+	if *doGOPATH && *doModCache {
+		log.Fatal("At most one of -gopath and -modcache may be set")
+	} else if flag.NArg() == 0 && !*doReadInputs {
+		if !*doGOPATH && !*doModCache {
+			log.Fatalf("Usage: %s <repo-dir> ...", filepath.Base(os.Args[0]))
+		}
+		flag.CommandLine.Parse([]string{defaultRoot()})
+	}
+	links, err := deps.ParseLinkPolicy(*linkPolicy)
+	if err != nil {
+		log.Fatalf("Invalid -symlinks: %v", err)
+	}
+	stdlib, err := deps.ParseStdlibPolicy(*stdlibPolicy)
+	if err != nil {
+		log.Fatalf("Invalid -stdlib: %v", err)
+	}
+	hash, err := deps.ParseHashAlgorithm(*hashAlg)
+	if err != nil {
+		log.Fatalf("Invalid -hashalg: %v", err)
+	}
+	var catalog deps.StdlibCatalog
+	if *stdlibFile != "" {
+		data, err := ioutil.ReadFile(*stdlibFile)
+		if err != nil {
+			log.Fatalf("Reading -stdlib-catalog: %v", err)
+		}
+		catalog, err = deps.ParseStdlibCatalog(data)
+		if err != nil {
+			log.Fatalf("Invalid -stdlib-catalog: %v", err)
+		}
+	} else if *goVersion != "" {
+		catalog = deps.DefaultStdlib
+	}
+	analyzers, err := deps.ParseAnalyzers(*analyzerList)
+	if err != nil {
+		log.Fatalf("Invalid -analyzers: %v", err)
+	}
+	if *outFormat != "" {
+		if *formatEach != "package" && *formatEach != "repo" {
+			log.Fatalf("Invalid -per: %q", *formatEach)
+		}
+		t, err := template.New("format").Funcs(template.FuncMap{
+			"join": strings.Join,
+		}).Parse(*outFormat)
+		if err != nil {
+			log.Fatalf("Invalid -format: %v", err)
+		}
+		outTemplate = t
+	}
+	if *selectExpr != "" {
+		if *outFormat != "" {
+			log.Fatal("At most one of -format and -select may be set")
+		}
+		sel, err := parseSelection(*selectExpr)
+		if err != nil {
+			log.Fatalf("Invalid -select: %v", err)
+		}
+		outSelection = sel
+	}
+	if *labelSpec != "" {
+		labels, err := parseLabels(*labelSpec)
+		if err != nil {
+			log.Fatalf("Invalid -labels: %v", err)
+		}
+		baseLabels = labels
+	}
+	if *doSummary && (*doGOPATH || *doModCache) {
+		log.Fatal("The -summary flag cannot be used with -gopath or -modcache")
+	}
+	if *doDryRun {
+		listInputs(newDedup(*doDedup))
+		return
+	}
+	if *pprofAddr != "" {
+		go func() {
+			log.Printf("Serving profiles at http://%s/debug/pprof/", *pprofAddr)
+			log.Printf("Profile server failed: %v", http.ListenAndServe(*pprofAddr, nil))
+		}()
+	}
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			log.Fatalf("Creating trace: %v", err)
+		} else if err := trace.Start(f); err != nil {
+			log.Fatalf("Starting trace: %v", err)
+		}
+		defer func() {
+			trace.Stop()
+			if err := f.Close(); err != nil {
+				log.Printf("Writing trace: %v", err)
+			}
+		}()
+	}
+	sinks, err = parseSinks(*outputSpec)
+	if err != nil {
+		log.Fatalf("Invalid -output: %v", err)
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	opts := &deps.Options{
 		HashSourceFiles: *doSourceHash,
+		FileImports:     *doFileImps,
+		ImportSites:     *doImportPos,
+		CountRefs:       *doCountRefs,
+		Directives:      *doDirectives,
+		BuildTags:       *doBuildTags,
+		Submodules:      *doSubmodules,
+		Symlinks:        links,
+		ScanNested:      *doNested,
+		Stdlib:          stdlib,
+		StdlibCatalog:   catalog,
+		GoVersion:       *goVersion,
+		Hash:            hash,
+		Activity:        *doActivity,
+		PseudoVersions:  *doPseudo,
+		SummaryOnly:     *doSummary,
+		Precise:         *doPrecise,
+		Analyzers:       analyzers,
+		Interner:        new(deps.Interner),
 	}
 	defer cancel()
 
+	remote = &throttle.Limiter{
+		Interval:  *hostInterval,
+		MaxActive: *hostActive,
+		Retries:   *numRetries,
+		Backoff:   *retryBackoff,
+	}
+	creds, err = auth.FromEnv(*authConfig)
+	if err != nil {
+		log.Fatalf("Loading credentials: %v", err)
+	}
+	if *cacheDir != "" {
+		clones, err = local.NewCloneCache(*cacheDir)
+		if err != nil {
+			log.Fatalf("Opening clone cache: %v", err)
+		}
+		clones.Shared = *cacheShare
+	}
+	var gh *github.Client
+	if *doGitHub {
+		gh = &github.Client{
+			Token:      os.Getenv("GITHUB_TOKEN"),
+			Credential: creds.Lookup("github.com"),
+			CacheDir:   *gitHubCache,
+			Interval:   750 * time.Millisecond, // 4800 requests per hour
+			HTTPClient: &http.Client{Transport: remote.Transport(nil)},
+		}
+	}
+
 	g, run := taskgroup.New(taskgroup.Trigger(cancel)).Limit(*concurrency)
 
 	// Each argument is either a directory path or a .siva file path.
 	// Currently only rooted siva files are supported.
 	var numRepos int
 	start := time.Now()
-	for dir := range inputs() {
-		dir := dir
-		path, err := filepath.Abs(dir)
-		if err != nil {
-			log.Fatalf("Resolving path: %v", err)
+	seen := newDedup(*doDedup)
+	var sorted struct {
+		sync.Mutex
+		repos []*deps.Repo
+	}
+	for in := range inputs() {
+		in := in
+		if !seen.input(in) {
+			log.Printf("Skipped duplicate input %q", in)
+			continue
 		}
 		numRepos++
 		run(func() error {
-			log.Printf("Processing %q...", dir)
-
-			var repos []*deps.Repo
-			if filepath.Ext(path) == ".siva" {
-				repos, err = siva.Load(ctx, path, opts)
-			} else {
-				repos, err = local.Load(ctx, path, opts)
-			}
+			log.Printf("Processing %q...", in)
+			repos, err := in.load(ctx, opts)
 			if err != nil {
-				log.Printf("Skipped %q:\n  %v", dir, err)
+				log.Printf("Skipped %q:\n  %v", in, err)
 				return nil
 			}
-
-			return writeRepos(ctx, path, repos)
+			if repos = seen.repos(repos); len(repos) == 0 {
+				return nil // everything was a duplicate
+			}
+			if gh != nil {
+				for _, repo := range repos {
+					if err := gh.Enrich(ctx, repo); err != nil {
+						log.Printf("Fetching GitHub metadata for %q: %v", repo.From, err)
+					}
+				}
+			}
+			if *doSorted {
+				sorted.Lock()
+				defer sorted.Unlock()
+				sorted.repos = append(sorted.repos, repos...)
+				return nil
+			}
+			return writeRepos(ctx, repos)
 		})
 	}
 	if err := g.Wait(); err != nil {
+		// Close the sinks anyway, so that the results written before the
+		// failure are flushed and stored.
+		if cerr := closeSinks(); cerr != nil {
+			log.Printf("Closing output: %v", cerr)
+		}
 		log.Fatalf("Analysis failed: %v", err)
 	}
+	if *doSorted {
+		if err := writeSorted(ctx, sorted.repos); err != nil {
+			if cerr := closeSinks(); cerr != nil {
+				log.Printf("Closing output: %v", cerr)
+			}
+			log.Fatalf("Writing output: %v", err)
+		}
+	}
+	if err := closeSinks(); err != nil {
+		log.Fatalf("Closing output: %v", err)
+	}
 	log.Printf("Analysis complete for %d inputs [%v elapsed]", numRepos, time.Since(start))
+	if n := seen.numSkipped(); n > 0 {
+		log.Printf("Skipped %d duplicate inputs and repositories", n)
+	}
 }
 
-func writeRepos(ctx context.Context, path string, repos []*deps.Repo) error {
-	bits, err := json.Marshal(repos)
-	if err != nil {
-		return err
+// closeSinks closes all the output sinks, and reports the first error among
+// them, if any.
+func closeSinks() error {
+	var err error
+	for _, s := range sinks {
+		if cerr := s.Close(); err == nil {
+			err = cerr
+		}
 	}
-	bits = append(bits, '\n')
-	out.Lock()
-	defer out.Unlock()
-	_, err = out.Write(bits)
 	return err
 }
 
-// inputs returns a channel that delivers the paths of inputs and is closed
-// when no more are available.
-func inputs() <-chan string {
-	ch := make(chan string, len(flag.Args()))
-	for _, arg := range flag.Args() {
-		ch <- arg
+// listInputs prints the kind and location of each input that would be
+// scanned, skipping duplicates according to seen.
+func listInputs(seen *dedup) {
+	for in := range inputs() {
+		if !seen.input(in) {
+			continue
+		}
+		desc := in.String()
+		if in.Ref != "" {
+			desc += " @" + in.Ref
+		}
+		if len(in.Include) != 0 {
+			desc += " [" + strings.Join(in.Include, ",") + "]"
+		}
+		fmt.Printf("%s\t%s\n", in.kind(), desc)
 	}
-	if *doReadInputs {
-		s := bufio.NewScanner(os.Stdin)
-		go func() {
-			defer close(ch)
-			for s.Scan() {
-				ch <- s.Text()
-			}
-		}()
-	} else {
-		close(ch)
+	if n := seen.numSkipped(); n > 0 {
+		log.Printf("Skipped %d duplicate inputs", n)
+	}
+}
+
+// writeRepos writes repos to each of the output sinks.
+func writeRepos(ctx context.Context, repos []*deps.Repo) error {
+	for _, s := range sinks {
+		if err := s.Write(ctx, repos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSorted writes repos to the output in a deterministic order, one
+// repository per record.
+func writeSorted(ctx context.Context, repos []*deps.Repo) error {
+	key := func(r *deps.Repo) string {
+		if len(r.Remotes) != 0 {
+			return r.From + "\x00" + r.Remotes[0].Url
+		}
+		return r.From
+	}
+	sort.Slice(repos, func(i, j int) bool { return key(repos[i]) < key(repos[j]) })
+	for _, repo := range repos {
+		deps.SortRepo(repo)
+		if err := writeRepos(ctx, []*deps.Repo{repo}); err != nil {
+			return err
+		}
 	}
-	return ch
+	return nil
 }
@@ -0,0 +1,137 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// A selection trims JSON output records to chosen fields. Fields are named by
+// dotted paths of JSON field names, such as "packages.import_path"; arrays are
+// traversed implicitly, so the path applies to each element. If any paths are
+// included, only the named fields are kept. Paths prefixed with "-" are then
+// removed.
+type selection struct {
+	include fieldSet // nil means everything
+	exclude fieldSet
+}
+
+// A fieldSet is a tree of field names. A nil subtree selects the whole value
+// of the field.
+type fieldSet map[string]fieldSet
+
+// add adds the dotted field path to s.
+func (s fieldSet) add(path string) {
+	cur := s
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		sub, ok := cur[part]
+		if i == len(parts)-1 {
+			cur[part] = nil // the whole field, subsuming any subfields
+			return
+		} else if ok && sub == nil {
+			return // the whole field is already selected
+		} else if !ok {
+			sub = make(fieldSet)
+			cur[part] = sub
+		}
+		cur = sub
+	}
+}
+
+// parseSelection parses a comma-separated list of field paths.
+func parseSelection(expr string) (*selection, error) {
+	sel := &selection{exclude: make(fieldSet)}
+	for _, path := range strings.Split(expr, ",") {
+		path = strings.TrimSpace(path)
+		set := sel.include
+		if trim := strings.TrimPrefix(path, "-"); trim != path {
+			path, set = trim, sel.exclude
+		} else if set == nil {
+			sel.include = make(fieldSet)
+			set = sel.include
+		}
+		if path == "" || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") || strings.Contains(path, "..") {
+			return nil, fmt.Errorf("invalid field path %q", path)
+		}
+		set.add(path)
+	}
+	return sel, nil
+}
+
+// apply returns a copy of the JSON encoding of v trimmed according to s.
+func (s *selection) apply(v interface{}) ([]byte, error) {
+	bits, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bits))
+	dec.UseNumber() // preserve integer values exactly
+	var val interface{}
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	if s.include != nil {
+		val = keepFields(val, s.include)
+	}
+	dropFields(val, s.exclude)
+	return json.Marshal(val)
+}
+
+// keepFields returns a copy of v containing only the fields selected by set.
+func keepFields(v interface{}, set fieldSet) interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, elt := range t {
+			out[i] = keepFields(elt, set)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		for key, sub := range set {
+			if val, ok := t[key]; !ok {
+				continue
+			} else if sub == nil {
+				out[key] = val
+			} else {
+				out[key] = keepFields(val, sub)
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// dropFields removes the fields selected by set from v in place.
+func dropFields(v interface{}, set fieldSet) {
+	switch t := v.(type) {
+	case []interface{}:
+		for _, elt := range t {
+			dropFields(elt, set)
+		}
+	case map[string]interface{}:
+		for key, sub := range set {
+			if sub == nil {
+				delete(t, key)
+			} else if val, ok := t[key]; ok {
+				dropFields(val, sub)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package semver implements comparison of semantic version strings in the
+// form used by Go modules, "vMAJOR[.MINOR[.PATCH[-PRERELEASE][+BUILD]]]".
+package semver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A parsed version. Omitted minor and patch numbers are treated as zero.
+type parsed struct {
+	major, minor, patch string
+	pre                 string
+	build               string
+}
+
+// parse parses v, reporting false if it is not a valid version.
+func parse(v string) (p parsed, ok bool) {
+	if !strings.HasPrefix(v, "v") {
+		return p, false
+	}
+	v = v[1:]
+	if i := strings.Index(v, "+"); i >= 0 {
+		p.build, v = v[i+1:], v[:i]
+		if p.build == "" {
+			return p, false
+		}
+	}
+	if i := strings.Index(v, "-"); i >= 0 {
+		p.pre, v = v[i+1:], v[:i]
+		if p.pre == "" {
+			return p, false
+		}
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 || (len(parts) < 3 && (p.pre != "" || p.build != "")) {
+		return p, false
+	}
+	for _, part := range parts {
+		if !isNum(part) {
+			return p, false
+		}
+	}
+	p.major, p.minor, p.patch = parts[0], "0", "0"
+	if len(parts) > 1 {
+		p.minor = parts[1]
+	}
+	if len(parts) > 2 {
+		p.patch = parts[2]
+	}
+	return p, true
+}
+
+// IsValid reports whether v is a valid semantic version string.
+func IsValid(v string) bool {
+	_, ok := parse(v)
+	return ok
+}
+
+// Major returns the major version prefix of v ("v2" for "v2.1.0"), or "" if
+// v is not a valid version.
+func Major(v string) string {
+	p, ok := parse(v)
+	if !ok {
+		return ""
+	}
+	return "v" + p.major
+}
+
+// Prerelease returns the prerelease suffix of v, including its leading "-",
+// or "" if v has none or is not valid.
+func Prerelease(v string) string {
+	p, ok := parse(v)
+	if !ok || p.pre == "" {
+		return ""
+	}
+	return "-" + p.pre
+}
+
+// Compare returns -1, 0, or +1 according to whether v < w, v == w, or v > w.
+// Build metadata is ignored. An invalid version is considered less than any
+// valid version, and equal to other invalid versions.
+func Compare(v, w string) int {
+	pv, okv := parse(v)
+	pw, okw := parse(w)
+	if !okv || !okw {
+		if okv {
+			return 1
+		} else if okw {
+			return -1
+		}
+		return 0
+	}
+	if c := compareNum(pv.major, pw.major); c != 0 {
+		return c
+	} else if c := compareNum(pv.minor, pw.minor); c != 0 {
+		return c
+	} else if c := compareNum(pv.patch, pw.patch); c != 0 {
+		return c
+	}
+	return comparePre(pv.pre, pw.pre)
+}
+
+// Max returns the larger of v and w according to Compare.
+func Max(v, w string) string {
+	if Compare(v, w) < 0 {
+		return w
+	}
+	return v
+}
+
+// comparePre compares prerelease strings. A version without a prerelease
+// orders after any version with one.
+func comparePre(x, y string) int {
+	if x == y {
+		return 0
+	} else if x == "" {
+		return 1
+	} else if y == "" {
+		return -1
+	}
+	xs, ys := strings.Split(x, "."), strings.Split(y, ".")
+	for i := 0; i < len(xs) && i < len(ys); i++ {
+		a, b := xs[i], ys[i]
+		if a == b {
+			continue
+		}
+		an, bn := isNum(a), isNum(b)
+		switch {
+		case an && bn:
+			return compareNum(a, b)
+		case an:
+			return -1 // numeric identifiers order before alphanumeric ones
+		case bn:
+			return 1
+		case a < b:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return compareNum(strconv.Itoa(len(xs)), strconv.Itoa(len(ys)))
+}
+
+// compareNum compares decimal strings without leading zeroes by value.
+func compareNum(x, y string) int {
+	if len(x) != len(y) {
+		if len(x) < len(y) {
+			return -1
+		}
+		return 1
+	} else if x < y {
+		return -1
+	} else if x > y {
+		return 1
+	}
+	return 0
+}
+
+func isNum(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input      string
+		valid      bool
+		major, pre string
+	}{
+		{"v1.2.3", true, "v1", ""},
+		{"v0.0.0", true, "v0", ""},
+		{"v2", true, "v2", ""},
+		{"v2.1", true, "v2", ""},
+		{"v10.20.30", true, "v10", ""},
+		{"v1.2.3-rc.1", true, "v1", "-rc.1"},
+		{"v1.2.3-rc-1", true, "v1", "-rc-1"},
+		{"v1.2.3+build.5", true, "v1", ""},
+		{"v1.2.3-beta+build", true, "v1", "-beta"},
+		{"v2.0.0+incompatible", true, "v2", ""},
+		{"v0.0.0-20190102150405-abcdefabcdef", true, "v0", "-20190102150405-abcdefabcdef"},
+
+		{"", false, "", ""},
+		{"v", false, "", ""},
+		{"1.2.3", false, "", ""},
+		{"v1.2.3.4", false, "", ""},
+		{"v01.2.3", false, "", ""},
+		{"v1.02.3", false, "", ""},
+		{"v1.2.x", false, "", ""},
+		{"v1.2.3-", false, "", ""},
+		{"v1.2.3+", false, "", ""},
+		{"v1.2-rc.1", false, "", ""},
+		{"v1+build", false, "", ""},
+		{"v1..3", false, "", ""},
+	}
+	for _, test := range tests {
+		if got := IsValid(test.input); got != test.valid {
+			t.Errorf("IsValid(%q): got %v, want %v", test.input, got, test.valid)
+		}
+		if got := Major(test.input); got != test.major {
+			t.Errorf("Major(%q): got %q, want %q", test.input, got, test.major)
+		}
+		if got := Prerelease(test.input); got != test.pre {
+			t.Errorf("Prerelease(%q): got %q, want %q", test.input, got, test.pre)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Each version is less than the ones after it, except where noted.
+	order := []string{
+		"bogus",
+		"v0.0.0-20190102150405-abcdefabcdef",
+		"v0.0.0",
+		"v0.1.0",
+		"v1.0.0-1",
+		"v1.0.0-2",
+		"v1.0.0-10",
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.2.0",
+		"v1.10.0",
+		"v2.0.0+incompatible",
+		"v10.0.0",
+	}
+	for i, v := range order {
+		for j, w := range order {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := Compare(v, w); got != want {
+				t.Errorf("Compare(%q, %q): got %d, want %d", v, w, got, want)
+			}
+		}
+	}
+
+	equal := [][2]string{
+		{"v1", "v1.0.0"},
+		{"v1.2", "v1.2.0"},
+		{"v1.2.3+a", "v1.2.3+b"},
+		{"v1.2.3+a", "v1.2.3"},
+		{"bogus", ""},
+		{"1.2.3", "v1.2.3.4"},
+	}
+	for _, p := range equal {
+		if got := Compare(p[0], p[1]); got != 0 {
+			t.Errorf("Compare(%q, %q): got %d, want 0", p[0], p[1], got)
+		}
+	}
+}
+
+func TestMax(t *testing.T) {
+	tests := []struct {
+		v, w, want string
+	}{
+		{"v1.0.0", "v1.0.1", "v1.0.1"},
+		{"v1.0.1", "v1.0.0", "v1.0.1"},
+		{"v1.0.0-rc.1", "v1.0.0", "v1.0.0"},
+		{"bogus", "v0.0.0", "v0.0.0"},
+		{"v1.0.0", "v1.0.0+meta", "v1.0.0"},
+		{"v1", "v1.0.0", "v1"},
+	}
+	for _, test := range tests {
+		if got := Max(test.v, test.w); got != test.want {
+			t.Errorf("Max(%q, %q): got %q, want %q", test.v, test.w, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// A Role determines which methods a caller may call.
+type Role int
+
+// Constants for the Role type, in order of increasing privilege.
+const (
+	RoleNone  Role = iota // no methods
+	RoleRead              // methods that do not modify the graph
+	RoleWrite             // all methods
+)
+
+var roleNames = []string{"none", "read", "write"}
+
+func (r Role) String() string {
+	if r >= 0 && int(r) < len(roleNames) {
+		return roleNames[r]
+	}
+	return fmt.Sprintf("Role(%d)", int(r))
+}
+
+// ParseRole parses the name of a role, one of "none", "read", or "write".
+func ParseRole(s string) (Role, error) {
+	for i, name := range roleNames {
+		if s == name {
+			return Role(i), nil
+		}
+	}
+	return RoleNone, fmt.Errorf("unknown role %q", s)
+}
+
+// ParseTokens parses a list of access tokens and their roles from r. Each
+// line has the form
+//
+//	<role> <token>
+//
+// Blank lines and lines beginning with "#" are ignored.
+func ParseTokens(r io.Reader) (map[string]Role, error) {
+	tokens := make(map[string]Role)
+	s := bufio.NewScanner(r)
+	for ln := 1; s.Scan(); ln++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want <role> <token>", ln)
+		}
+		role, err := ParseRole(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", ln, err)
+		}
+		tokens[fields[1]] = role
+	}
+	return tokens, s.Err()
+}
+
+// role returns the role of the caller of req and the access token it
+// presented, and reports whether the caller's credentials, if any, are valid.
+// The token is "" unless it is one of the configured tokens. If no tokens are
+// configured, every caller has RoleWrite, and no token is valid.
+func (s *Service) role(req *http.Request) (Role, string, bool) {
+	if len(s.tokens) == 0 {
+		return RoleWrite, "", true
+	}
+	auth := req.Header.Get("Authorization")
+	if auth == "" {
+		return s.anonymous, "", true
+	}
+	const prefix = "Bearer "
+	if !strings.HasPrefix(auth, prefix) {
+		return RoleNone, "", false
+	}
+	tok := strings.TrimPrefix(auth, prefix)
+
+	// Compare against every token, so that the time taken does not depend on
+	// which token matches.
+	role, ok := RoleNone, false
+	for t, r := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(tok), []byte(t)) == 1 {
+			role, ok = r, true
+		}
+	}
+	if !ok {
+		return RoleNone, "", false
+	}
+	return role, tok, true
+}
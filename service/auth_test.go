@@ -0,0 +1,156 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// reply is a decoded JSON-RPC response.
+type reply struct {
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// postToken sends body to the handler of s with the given Authorization
+// header, if it is not empty, and returns the status and decoded reply. The
+// reply is nil unless the status is 200.
+func postToken(t *testing.T, s *Service, auth, body string) (int, *reply) {
+	t.Helper()
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	req, err := http.NewRequest("POST", srv.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	defer rsp.Body.Close()
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatalf("Reading response: %v", err)
+	} else if rsp.StatusCode != http.StatusOK {
+		return rsp.StatusCode, nil
+	}
+	var r reply
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatalf("Decoding response %q: %v", data, err)
+	}
+	return rsp.StatusCode, &r
+}
+
+func TestParseRole(t *testing.T) {
+	for _, role := range []Role{RoleNone, RoleRead, RoleWrite} {
+		got, err := ParseRole(role.String())
+		if err != nil || got != role {
+			t.Errorf("ParseRole(%q): got (%v, %v), want %v", role.String(), got, err, role)
+		}
+	}
+	if got, err := ParseRole("admin"); err == nil {
+		t.Errorf("ParseRole(admin): got %v, want error", got)
+	}
+	if got, want := Role(7).String(), "Role(7)"; got != want {
+		t.Errorf("Role(7).String(): got %q, want %q", got, want)
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	got, err := ParseTokens(strings.NewReader(`
+# Comments and blank lines are ignored.
+read  tok1
+
+write tok2
+  none tok3
+`))
+	if err != nil {
+		t.Fatalf("ParseTokens: unexpected error: %v", err)
+	}
+	want := map[string]Role{"tok1": RoleRead, "tok2": RoleWrite, "tok3": RoleNone}
+	if len(got) != len(want) {
+		t.Errorf("ParseTokens: got %v, want %v", got, want)
+	}
+	for tok, role := range want {
+		if got[tok] != role {
+			t.Errorf("ParseTokens: token %q has role %v, want %v", tok, got[tok], role)
+		}
+	}
+
+	for _, bad := range []string{"read\n", "read tok extra\n", "admin tok\n"} {
+		if got, err := ParseTokens(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseTokens(%q): got %v, want error", bad, got)
+		}
+	}
+}
+
+func TestRoles(t *testing.T) {
+	const (
+		version = `{"jsonrpc":"2.0","id":1,"method":"Version"}`
+		remove  = `{"jsonrpc":"2.0","id":1,"method":"Remove","params":{"package":"a"}}`
+	)
+	tokens := map[string]Role{"rtok": RoleRead, "wtok": RoleWrite}
+	tests := []struct {
+		opts   *Options
+		auth   string
+		body   string
+		status int
+		code   Code // 0 for success
+	}{
+		// Callers without a token have the anonymous role.
+		{&Options{Tokens: tokens}, "", version, http.StatusOK, CodeUnauthorized},
+		{&Options{Tokens: tokens, Anonymous: RoleRead}, "", version, http.StatusOK, 0},
+		{&Options{Tokens: tokens, Anonymous: RoleRead}, "", remove, http.StatusOK, CodeUnauthorized},
+
+		// Callers with a token have its role.
+		{&Options{Tokens: tokens}, "Bearer rtok", version, http.StatusOK, 0},
+		{&Options{Tokens: tokens}, "Bearer rtok", remove, http.StatusOK, CodeUnauthorized},
+		{&Options{Tokens: tokens}, "Bearer wtok", remove, http.StatusOK, 0},
+
+		// Unknown or malformed credentials are rejected.
+		{&Options{Tokens: tokens, Anonymous: RoleRead}, "Bearer bogus", version, http.StatusUnauthorized, 0},
+		{&Options{Tokens: tokens, Anonymous: RoleRead}, "Basic cnRvaw==", version, http.StatusUnauthorized, 0},
+		{&Options{Tokens: tokens}, "Bearer ", version, http.StatusUnauthorized, 0},
+
+		// Without tokens, every caller may write.
+		{nil, "", remove, http.StatusOK, 0},
+		{nil, "Bearer anything", remove, http.StatusOK, 0},
+	}
+	for _, test := range tests {
+		s := New(testGraph(t, "a"), test.opts)
+		status, r := postToken(t, s, test.auth, test.body)
+		if status != test.status {
+			t.Errorf("Call %s with %q: got status %d, want %d", test.body, test.auth, status, test.status)
+			continue
+		} else if r == nil {
+			continue
+		}
+		var code Code
+		if r.Error != nil {
+			code = r.Error.Code
+		}
+		if code != test.code {
+			t.Errorf("Call %s with %q: got error %v, want code %d", test.body, test.auth, r.Error, test.code)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// A resultCache holds the results of recent calls to methods whose results
+// depend only on their parameters and the contents of the graph. Every entry
+// was computed at the same version of the graph (see graph.Version), and the
+// cache is emptied when the version changes, so that a write invalidates all
+// the results it may have changed. A nil *resultCache caches nothing.
+type resultCache struct {
+	size int // the maximum number of entries
+
+	mu      sync.Mutex
+	version int64
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[cacheKey]*list.Element
+}
+
+type cacheKey struct {
+	method string
+	params string // the encoded parameters
+}
+
+type cacheEntry struct {
+	key cacheKey
+	val interface{}
+}
+
+func newResultCache(size int) *resultCache {
+	if size <= 0 {
+		return nil
+	}
+	return &resultCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[cacheKey]*list.Element),
+	}
+}
+
+// get returns the cached result for key at the given graph version, if any.
+func (c *resultCache) get(version int64, key cacheKey) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sync(version)
+	if elt, ok := c.entries[key]; ok && version == c.version {
+		c.lru.MoveToFront(elt)
+		return elt.Value.(*cacheEntry).val, true
+	}
+	return nil, false
+}
+
+// put caches val as the result for key at the given graph version, evicting
+// the least recently used entry if the cache is full. A result for a version
+// older than the cached results is discarded.
+func (c *resultCache) put(version int64, key cacheKey, val interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sync(version)
+	if version != c.version {
+		return
+	} else if elt, ok := c.entries[key]; ok {
+		elt.Value.(*cacheEntry).val = val
+		c.lru.MoveToFront(elt)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, val: val})
+	if c.lru.Len() > c.size {
+		last := c.lru.Remove(c.lru.Back()).(*cacheEntry)
+		delete(c.entries, last.key)
+	}
+}
+
+// sync empties the cache if version is newer than the version of its
+// entries. The caller must hold c.mu.
+func (c *resultCache) sync(version int64) {
+	if version > c.version {
+		c.version = version
+		c.lru.Init()
+		c.entries = make(map[cacheKey]*list.Element)
+	}
+}
+
+// cached returns the result of calling the named method with the given
+// parameters. If the result is cached for the current version of the graph,
+// it is returned without calling f; otherwise cached calls f to compute the
+// result, and caches it if f succeeds. Cached results are shared, and must
+// not be modified.
+func (s *Service) cached(ctx context.Context, method string, params interface{}, f func() (interface{}, error)) (interface{}, error) {
+	if s.cache == nil {
+		return f()
+	}
+	version, err := s.g.Version(ctx)
+	if err != nil {
+		return f() // without a version, the result cannot be cached
+	}
+	bits, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	key := cacheKey{method: method, params: string(bits)}
+	if val, ok := s.cache.get(version, key); ok {
+		return val, nil
+	}
+	val, err := f()
+	if err == nil {
+		s.cache.put(version, key, val)
+	}
+	return val, err
+}
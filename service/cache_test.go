@@ -0,0 +1,108 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestResultCache(t *testing.T) {
+	if c := newResultCache(0); c != nil {
+		t.Errorf("newResultCache(0): got %+v, want nil", c)
+	}
+	key := func(s string) cacheKey { return cacheKey{method: "M", params: s} }
+	check := func(c *resultCache, version int64, k string, want interface{}) {
+		t.Helper()
+		got, ok := c.get(version, key(k))
+		if want == nil && ok {
+			t.Errorf("get(%d, %q): got %v, want no entry", version, k, got)
+		} else if want != nil && (!ok || got != want) {
+			t.Errorf("get(%d, %q): got (%v, %v), want %v", version, k, got, ok, want)
+		}
+	}
+
+	c := newResultCache(2)
+	c.put(1, key("a"), 1)
+	c.put(1, key("b"), 2)
+	check(c, 1, "a", 1) // now a is the most recently used
+	c.put(1, key("c"), 3)
+	check(c, 1, "b", nil) // evicted
+	check(c, 1, "a", 1)
+	check(c, 1, "c", 3)
+	c.put(1, key("c"), 4)
+	check(c, 1, "c", 4)
+
+	// A result for an older version is not returned or stored, and a newer
+	// version empties the cache.
+	check(c, 0, "a", nil)
+	c.put(2, key("b"), 5)
+	check(c, 2, "a", nil)
+	check(c, 2, "b", 5)
+	c.put(1, key("a"), 6)
+	check(c, 2, "a", nil)
+}
+
+func TestCachedResults(t *testing.T) {
+	ctx := context.Background()
+	s := New(testGraph(t, "a", "b", "c"), &Options{CacheSize: 10})
+
+	importers := func() *ImportersResult {
+		t.Helper()
+		res, err := s.Importers(ctx, &ImportersRequest{Package: "c"})
+		if err != nil {
+			t.Fatalf("Importers: unexpected error: %v", err)
+		}
+		return res
+	}
+	closure := func() *ClosureResult {
+		t.Helper()
+		res, err := s.Closure(ctx, &ClosureRequest{Roots: []string{"a"}})
+		if err != nil {
+			t.Fatalf("Closure: unexpected error: %v", err)
+		}
+		return res
+	}
+
+	// Repeated calls share the cached result.
+	i1, c1 := importers(), closure()
+	if i2 := importers(); i2 != i1 {
+		t.Errorf("Importers was not cached: got %p, want %p", i2, i1)
+	}
+	if c2 := closure(); c2 != c1 {
+		t.Errorf("Closure was not cached: got %p, want %p", c2, c1)
+	}
+	if res, err := s.Importers(ctx, &ImportersRequest{Package: "b"}); err != nil {
+		t.Fatalf("Importers b: unexpected error: %v", err)
+	} else if want := []string{"a"}; !reflect.DeepEqual(res.Packages, want) {
+		t.Errorf("Importers b: got %q, want %q", res.Packages, want)
+	}
+
+	// A change to the graph invalidates the cached results.
+	if _, err := s.Remove(ctx, &PackageRequest{Package: "b"}); err != nil {
+		t.Fatalf("Remove: unexpected error: %v", err)
+	}
+	if got, want := importers().Packages, []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Importers after Remove: got %q, want %q", got, want)
+	}
+	res := closure()
+	if want := []string{"a", "c"}; !reflect.DeepEqual(res.Packages, want) {
+		t.Errorf("Closure after Remove: got %q, want %q", res.Packages, want)
+	}
+	if want := map[string]int{"b": 1}; !reflect.DeepEqual(res.Missing, want) {
+		t.Errorf("Closure after Remove: got missing %v, want %v", res.Missing, want)
+	}
+}
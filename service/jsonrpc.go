@@ -0,0 +1,374 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Code is a JSON-RPC error code.
+type Code int
+
+// Error codes defined by JSON-RPC 2.0, and by this service.
+const (
+	CodeParseError     Code = -32700 // the request is not valid JSON
+	CodeInvalidRequest Code = -32600 // the request is not a valid request object
+	CodeMethodNotFound Code = -32601 // no such method
+	CodeInvalidParams  Code = -32602 // the parameters are not valid for the method
+	CodeInternalError  Code = -32603 // the method failed
+
+	CodeNotFound     Code = -32001 // the requested package is not in the graph
+	CodeUnauthorized Code = -32002 // the caller's role does not permit the method
+	CodeTooLarge     Code = -32003 // the result exceeds the configured limit
+	CodeRateLimited  Code = -32004 // the caller has exceeded its rate limit
+)
+
+// An Error is the error object of a JSON-RPC response.
+type Error struct {
+	Code    Code            `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("[%d] %s", e.Code, e.Message) }
+
+func errorf(code Code, msg string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(msg, args...)}
+}
+
+// A request is a JSON-RPC request object. A request without an ID is a
+// notification, to which no response is sent.
+type request struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// A response is a JSON-RPC response object.
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// A method calls a method of the service with encoded parameters.
+type method struct {
+	role Role // the role required to call the method
+	call func(context.Context, json.RawMessage) (interface{}, error)
+}
+
+var (
+	ctxType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// newMethod adapts fn, which must be a function of the form
+//
+//	func(context.Context, *T) (R, error)
+//
+// to a method requiring role that decodes its parameters into a new T.
+func newMethod(role Role, fn interface{}) method {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.In(0) != ctxType ||
+		t.In(1).Kind() != reflect.Ptr || t.NumOut() != 2 || t.Out(1) != errType {
+		panic(fmt.Sprintf("invalid method type %v", t))
+	}
+	arg := t.In(1).Elem()
+	return method{role: role, call: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		in := reflect.New(arg)
+		if len(params) != 0 {
+			dec := json.NewDecoder(bytes.NewReader(params))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(in.Interface()); err != nil {
+				return nil, errorf(CodeInvalidParams, "invalid parameters: %v", err)
+			}
+		}
+		out := v.Call([]reflect.Value{reflect.ValueOf(ctx), in})
+		if err := out[1].Interface(); err != nil {
+			return nil, err.(error)
+		}
+		return out[0].Interface(), nil
+	}}
+}
+
+// methodTable returns the method table of s.
+func (s *Service) methodTable() map[string]method {
+	return map[string]method{
+		"Add":       newMethod(RoleWrite, s.Add),
+		"Remove":    newMethod(RoleWrite, s.Remove),
+		"Row":       newMethod(RoleRead, s.Row),
+		"Imports":   newMethod(RoleRead, s.Imports),
+		"Importers": newMethod(RoleRead, s.Importers),
+		"Closure":   newMethod(RoleRead, s.Closure),
+		"Scan":      newMethod(RoleRead, s.Scan),
+		"Search":    newMethod(RoleRead, s.Search),
+		"Version":   newMethod(RoleRead, s.Version),
+	}
+}
+
+const (
+	maxRequestBytes = 64 << 20 // the maximum size of a request body
+	maxBatchSize    = 1000     // the maximum number of requests in a batch
+	batchWorkers    = 8        // the number of requests of a batch handled at once
+)
+
+// Handler returns an HTTP handler that serves the methods of s. Each POST
+// request carries a JSON-RPC request object, or a batch of them as an array,
+// and the response body carries the response object or array of responses.
+// The requests of a batch are handled concurrently, a few at a time, and a
+// batch of more than maxBatchSize requests is rejected. If every request of a
+// call is a notification, the response has status 204 and no body. A request
+// with an unknown access token is rejected with status 401.
+//
+// A method that does not modify the graph may also be called with a GET
+// request, with the method name, the parameters as a JSON object, and the ID
+// in the query parameters "method", "params", and "id"; the ID defaults to 0.
+// A successful response carries the version of the graph as its ETag, and a
+// request whose If-None-Match header has the current version gets status 304
+// with no body, so that clients and HTTP caches can reuse earlier results.
+func (s *Service) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost && req.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		role, token, ok := s.role(req)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid access token", http.StatusUnauthorized)
+			return
+		}
+		c := &caller{role: role, key: clientKey(req, token)}
+		if req.Method == http.MethodGet {
+			s.serveGet(w, req, c)
+			return
+		}
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxRequestBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		out := s.serve(req.Context(), c, body)
+		if out == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(out)
+	})
+}
+
+// A caller describes the caller of a request.
+type caller struct {
+	role Role
+	key  string // for rate limiting
+}
+
+// serve handles the encoded call in body, which is a single request or a
+// batch, and returns the encoded response, or nil if there is none.
+func (s *Service) serve(ctx context.Context, c *caller, body []byte) []byte {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		rsp := s.handle(ctx, c, body)
+		if rsp == nil {
+			return nil
+		}
+		return encode(rsp)
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return encode(errResponse(nil, errorf(CodeParseError, "invalid batch: %v", err)))
+	} else if len(batch) == 0 {
+		return encode(errResponse(nil, errorf(CodeInvalidRequest, "empty batch")))
+	} else if len(batch) > maxBatchSize {
+		return encode(errResponse(nil, errorf(CodeInvalidRequest,
+			"batch of %d requests exceeds the limit of %d", len(batch), maxBatchSize)))
+	}
+	rsps := make([]*response, len(batch))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < batchWorkers && w < len(batch); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				rsps[i] = s.handle(ctx, c, batch[i])
+			}
+		}()
+	}
+	for i := range batch {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var out []*response
+	for _, rsp := range rsps {
+		if rsp != nil {
+			out = append(out, rsp)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return encode(out)
+}
+
+// handle handles a single encoded request from c, and returns its response,
+// or nil if the request is a notification.
+func (s *Service) handle(ctx context.Context, c *caller, msg json.RawMessage) *response {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return errResponse(nil, errorf(CodeParseError, "%v", err))
+		}
+		return errResponse(nil, errorf(CodeInvalidRequest, "%v", err))
+	} else if req.Version != "2.0" {
+		return errResponse(req.ID, errorf(CodeInvalidRequest, "invalid version %q", req.Version))
+	} else if req.Method == "" {
+		return errResponse(req.ID, errorf(CodeInvalidRequest, "missing method name"))
+	}
+
+	var result interface{}
+	m, err := s.lookup(c, req.Method)
+	if err == nil {
+		result, err = m.call(ctx, req.Params)
+	}
+	if len(req.ID) == 0 {
+		return nil // notification
+	} else if err != nil {
+		return errResponse(req.ID, err)
+	}
+	return &response{Version: "2.0", ID: req.ID, Result: result}
+}
+
+// lookup returns the named method, if c may call it now. Each lookup counts
+// as a call toward the rate limit of c.
+func (s *Service) lookup(c *caller, name string) (method, error) {
+	if wait, ok := s.limiter.allow(c.key); !ok {
+		return method{}, errorf(CodeRateLimited, "rate limit exceeded; retry after %v", wait.Round(time.Millisecond))
+	}
+	m, ok := s.methods[name]
+	if !ok {
+		return method{}, errorf(CodeMethodNotFound, "no such method %q", name)
+	} else if c.role < m.role {
+		return method{}, errorf(CodeUnauthorized, "method %q requires role %s", name, m.role)
+	}
+	return m, nil
+}
+
+// serveGet handles a call made with a GET request, as described by Handler.
+func (s *Service) serveGet(w http.ResponseWriter, req *http.Request, c *caller) {
+	ctx := req.Context()
+	id := json.RawMessage("0")
+	if v := req.FormValue("id"); v != "" {
+		id = json.RawMessage(v)
+	}
+	reply := func(rsp *response) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(encode(rsp))
+	}
+	if !json.Valid(id) {
+		reply(errResponse(nil, errorf(CodeInvalidRequest, "invalid id %q", id)))
+		return
+	}
+	var params json.RawMessage
+	if v := req.FormValue("params"); v != "" {
+		if !json.Valid([]byte(v)) {
+			reply(errResponse(id, errorf(CodeParseError, "invalid params %q", v)))
+			return
+		}
+		params = json.RawMessage(v)
+	}
+	name := req.FormValue("method")
+	m, err := s.lookup(c, name)
+	if err == nil && m.role > RoleRead {
+		err = errorf(CodeInvalidRequest, "method %q modifies the graph, and requires POST", name)
+	}
+	if err != nil {
+		reply(errResponse(id, err))
+		return
+	}
+
+	// Read the version before the call, so that if the graph changes during
+	// the call, the result is not tagged with a version it may not match.
+	version, err := s.g.Version(ctx)
+	if err != nil {
+		reply(errResponse(id, err))
+		return
+	}
+	etag := fmt.Sprintf(`"%d"`, version)
+	if matchETag(req.Header.Get("If-None-Match"), etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	result, err := m.call(ctx, params)
+	if err != nil {
+		reply(errResponse(id, err))
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	reply(&response{Version: "2.0", ID: id, Result: result})
+}
+
+// matchETag reports whether the value of an If-None-Match header matches
+// etag.
+func matchETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// errResponse returns a response reporting err for the request with the given
+// ID. Errors other than *Error are reported with CodeInternalError.
+func errResponse(id json.RawMessage, err error) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	e, ok := err.(*Error)
+	if !ok {
+		e = &Error{Code: CodeInternalError, Message: err.Error()}
+	}
+	return &response{Version: "2.0", ID: id, Error: e}
+}
+
+func encode(v interface{}) []byte {
+	bits, err := json.Marshal(v)
+	if err != nil {
+		bits, _ = json.Marshal(errResponse(nil, err))
+	}
+	return bits
+}
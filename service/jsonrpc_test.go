@@ -0,0 +1,239 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/internal/memstore"
+)
+
+// testGraph returns a graph in which each of the given import paths has a
+// row, importing the import paths that follow it.
+func testGraph(t *testing.T, ipaths ...string) *graph.Graph {
+	t.Helper()
+	g := graph.New(memstore.New(graph.ErrNotFound))
+	for i, ip := range ipaths {
+		row := &graph.Row{ImportPath: ip, Name: "p", Repository: "example.com/r"}
+		row.Directs = append(row.Directs, ipaths[i+1:]...)
+		if err := g.Put(context.Background(), row); err != nil {
+			t.Fatalf("Put %q: %v", ip, err)
+		}
+	}
+	return g
+}
+
+// post sends body to the handler of s, and returns the status and body of
+// the response.
+func post(t *testing.T, s *Service, body string) (int, string) {
+	t.Helper()
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	rsp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	defer rsp.Body.Close()
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatalf("Reading response: %v", err)
+	}
+	return rsp.StatusCode, strings.TrimSpace(string(data))
+}
+
+func TestCalls(t *testing.T) {
+	s := New(testGraph(t, "a", "b"), nil)
+	tests := []struct {
+		body   string
+		status int
+		want   string
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"Imports","params":{"package":"a"}}`, http.StatusOK,
+			`{"jsonrpc":"2.0","id":1,"result":["b"]}`},
+		{`{"jsonrpc":"2.0","id":"x","method":"Imports","params":{"package":"nonesuch"}}`, http.StatusOK,
+			`{"jsonrpc":"2.0","id":"x","error":{"code":-32001,"message":"package \"nonesuch\" not found"}}`},
+		{`{"jsonrpc":"2.0","method":"Imports","params":{"package":"a"}}`, http.StatusNoContent, ``},
+
+		// A batch reports its responses in order, omitting notifications.
+		{`[{"jsonrpc":"2.0","id":1,"method":"Imports","params":{"package":"a"}},
+		   {"jsonrpc":"2.0","method":"Imports","params":{"package":"a"}},
+		   {"jsonrpc":"2.0","id":2,"method":"Nonesuch"},
+		   {"jsonrpc":"2.0","id":3,"method":"Imports","params":{"package":"b"}}]`, http.StatusOK,
+			`[{"jsonrpc":"2.0","id":1,"result":["b"]},` +
+				`{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"no such method \"Nonesuch\""}},` +
+				`{"jsonrpc":"2.0","id":3,"result":[]}]`},
+		{`[{"jsonrpc":"2.0","method":"Imports","params":{"package":"a"}}]`, http.StatusNoContent, ``},
+
+		{`[]`, http.StatusOK, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"empty batch"}}`},
+		{`{`, http.StatusOK, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"unexpected end of JSON input"}}`},
+		{`{"jsonrpc":"1.0","id":1,"method":"Version"}`, http.StatusOK,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"invalid version \"1.0\""}}`},
+		{`{"jsonrpc":"2.0","id":1,"method":"Imports","params":{"pkg":"a"}}`, http.StatusOK,
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid parameters: json: unknown field \"pkg\""}}`},
+	}
+	for _, test := range tests {
+		status, got := post(t, s, test.body)
+		if status != test.status || got != test.want {
+			t.Errorf("Call %s:\n got %d %s\nwant %d %s", test.body, status, got, test.status, test.want)
+		}
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+	s := New(testGraph(t, "a"), nil)
+	batch := func(n int) string {
+		reqs := make([]string, n)
+		for i := range reqs {
+			reqs[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"Version"}`, i)
+		}
+		return "[" + strings.Join(reqs, ",") + "]"
+	}
+
+	// A batch of the maximum size is handled in full, with its responses in
+	// the order of the requests.
+	_, out := post(t, s, batch(maxBatchSize))
+	var rsps []struct {
+		ID    int    `json:"id"`
+		Error *Error `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(out), &rsps); err != nil {
+		t.Fatalf("Decoding batch response: %v", err)
+	} else if len(rsps) != maxBatchSize {
+		t.Fatalf("Batch: got %d responses, want %d", len(rsps), maxBatchSize)
+	}
+	for i, rsp := range rsps {
+		if rsp.ID != i || rsp.Error != nil {
+			t.Errorf("Response %d: got id %d, error %v", i, rsp.ID, rsp.Error)
+		}
+	}
+
+	// A larger batch is rejected without handling any of it.
+	_, out = post(t, s, batch(maxBatchSize+1))
+	want := fmt.Sprintf(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch of %d requests exceeds the limit of %d"}}`,
+		maxBatchSize+1, maxBatchSize)
+	if out != want {
+		t.Errorf("Oversized batch:\n got %s\nwant %s", out, want)
+	}
+}
+
+// get sends a GET request with the given query to the handler of s, with an
+// If-None-Match header if etag != "", and returns the response and its body.
+func get(t *testing.T, s *Service, query, etag string) (*http.Response, string) {
+	t.Helper()
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	req, err := http.NewRequest("GET", srv.URL+"?"+query, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rsp.Body.Close()
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		t.Fatalf("Reading response: %v", err)
+	}
+	return rsp, strings.TrimSpace(string(data))
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	s := New(testGraph(t, "a", "b"), nil)
+	v, err := s.g.Version(ctx)
+	if err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	etag := fmt.Sprintf(`"%d"`, v)
+	imports := url.Values{"method": {"Imports"}, "params": {`{"package":"a"}`}}.Encode()
+
+	// A successful call is tagged with the version of the graph.
+	rsp, body := get(t, s, imports, "")
+	if rsp.StatusCode != http.StatusOK || body != `{"jsonrpc":"2.0","id":0,"result":["b"]}` {
+		t.Errorf("Get: got %d %s", rsp.StatusCode, body)
+	}
+	if got := rsp.Header.Get("ETag"); got != etag {
+		t.Errorf("Get: got ETag %q, want %q", got, etag)
+	}
+	if got := rsp.Header.Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Get: got Cache-Control %q, want no-cache", got)
+	}
+
+	// A request for a version the caller already has is not answered again.
+	for _, match := range []string{etag, "W/" + etag, `"x", ` + etag, "*"} {
+		rsp, body := get(t, s, imports, match)
+		if rsp.StatusCode != http.StatusNotModified || body != "" {
+			t.Errorf("Get with If-None-Match %s: got %d %q, want %d", match, rsp.StatusCode, body, http.StatusNotModified)
+		}
+	}
+
+	// Once the graph changes, the old version no longer matches.
+	if _, err := s.Remove(ctx, &PackageRequest{Package: "b"}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	rsp, _ = get(t, s, imports, etag)
+	if rsp.StatusCode != http.StatusOK {
+		t.Errorf("Get with a stale version: got status %d, want %d", rsp.StatusCode, http.StatusOK)
+	} else if got, want := rsp.Header.Get("ETag"), fmt.Sprintf(`"%d"`, v+1); got != want {
+		t.Errorf("Get with a stale version: got ETag %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"method=Version&id=%22v%22", `{"jsonrpc":"2.0","id":"v","result":{"version":` + fmt.Sprint(v+1) + `,"changed":true}}`},
+		{"method=Remove&params=%7B%22package%22:%22a%22%7D",
+			`{"jsonrpc":"2.0","id":0,"error":{"code":-32600,"message":"method \"Remove\" modifies the graph, and requires POST"}}`},
+		{"method=Row&params=%7B%22package%22:%22b%22%7D",
+			`{"jsonrpc":"2.0","id":0,"error":{"code":-32001,"message":"package \"b\" not found"}}`},
+		{"method=Version&id=x", `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid id \"x\""}}`},
+		{"method=Row&params=%7B", `{"jsonrpc":"2.0","id":0,"error":{"code":-32700,"message":"invalid params \"{\""}}`},
+	}
+	for _, test := range tests {
+		if _, got := get(t, s, test.query, ""); got != test.want {
+			t.Errorf("Get %s:\n got %s\nwant %s", test.query, got, test.want)
+		}
+	}
+
+	// Other HTTP methods are not allowed.
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	req, err := http.NewRequest("PUT", srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	rsp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusMethodNotAllowed || rsp.Header.Get("Allow") != "GET, POST" {
+		t.Errorf("Put: got %d, Allow %q", rsp.StatusCode, rsp.Header.Get("Allow"))
+	}
+}
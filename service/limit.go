@@ -0,0 +1,119 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A rateLimiter limits the rate of calls by each client, with a token bucket
+// per client. A nil *rateLimiter imposes no limit.
+type rateLimiter struct {
+	rate  float64 // tokens added per second
+	burst float64 // the capacity of each bucket
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweep   int // sweep idle buckets when there are this many
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time // when tokens was last updated
+}
+
+// minSweep is the smallest number of buckets at which idle buckets are swept.
+const minSweep = 1024
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(rate + 0.5)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		sweep:   minSweep,
+	}
+}
+
+// allow reports whether the named client may make a call now, and if so
+// charges the call to the client. If not, it also returns how long the client
+// must wait before it may.
+func (r *rateLimiter) allow(client string) (time.Duration, bool) {
+	if r == nil {
+		return 0, true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	b := r.buckets[client]
+	if b == nil {
+		if len(r.buckets) >= r.sweep {
+			r.sweepIdle(now)
+		}
+		b = &bucket{tokens: r.burst, last: now}
+		r.buckets[client] = b
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * r.rate
+		if b.tokens > r.burst {
+			b.tokens = r.burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / r.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// sweepIdle discards the buckets that would be full at now, since they are
+// equivalent to new ones. The caller must hold r.mu.
+func (r *rateLimiter) sweepIdle(now time.Time) {
+	for client, b := range r.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*r.rate >= r.burst {
+			delete(r.buckets, client)
+		}
+	}
+	r.sweep = 2 * len(r.buckets)
+	if r.sweep < minSweep {
+		r.sweep = minSweep
+	}
+}
+
+// clientKey returns the name by which the caller of req is rate limited: its
+// access token if it presented a valid one, otherwise its network address
+// without the port. Only a token validated by role may be used, lest a caller
+// evade its limit by presenting a new token with each request.
+func clientKey(req *http.Request, token string) string {
+	if token != "" {
+		return "token " + token
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
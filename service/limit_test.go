@@ -0,0 +1,129 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if r := newRateLimiter(0, 5); r != nil {
+		t.Errorf("newRateLimiter(0, 5): got %+v, want nil", r)
+	}
+	if _, ok := (*rateLimiter)(nil).allow("x"); !ok {
+		t.Error("A nil limiter refused a call")
+	}
+	for _, test := range []struct {
+		rate  float64
+		burst int
+		want  float64
+	}{{2.6, 0, 3}, {0.1, 0, 1}, {1, 4, 4}} {
+		if r := newRateLimiter(test.rate, test.burst); r.burst != test.want {
+			t.Errorf("newRateLimiter(%v, %d): got burst %v, want %v", test.rate, test.burst, r.burst, test.want)
+		}
+	}
+
+	r := newRateLimiter(1, 2)
+	for i := 0; i < 2; i++ {
+		if _, ok := r.allow("a"); !ok {
+			t.Errorf("Call %d of a burst was refused", i+1)
+		}
+	}
+	wait, ok := r.allow("a")
+	if ok {
+		t.Error("A call after the burst was allowed")
+	} else if wait <= 0 || wait > time.Second {
+		t.Errorf("Wait after the burst: got %v, want (0, 1s]", wait)
+	}
+	if _, ok := r.allow("b"); !ok {
+		t.Error("A call by another client was refused")
+	}
+
+	// Tokens accrue with time, up to the burst.
+	r.buckets["a"].last = time.Now().Add(-time.Hour)
+	for i := 0; i < 2; i++ {
+		if _, ok := r.allow("a"); !ok {
+			t.Errorf("Call %d after a wait was refused", i+1)
+		}
+	}
+	if _, ok := r.allow("a"); ok {
+		t.Error("A call after the refilled burst was allowed")
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	r := newRateLimiter(1, 1)
+	r.sweep = 3
+	r.allow("a")
+	r.allow("b")
+	r.allow("c")
+	r.buckets["a"].last = time.Now().Add(-time.Hour) // idle, and full again
+
+	// Adding a bucket when there are enough sweeps the idle ones.
+	r.allow("d")
+	if _, ok := r.buckets["a"]; ok {
+		t.Error("The idle bucket was not swept")
+	}
+	for _, c := range []string{"b", "c", "d"} {
+		if _, ok := r.buckets[c]; !ok {
+			t.Errorf("The bucket for %q was swept", c)
+		}
+	}
+	if r.sweep != minSweep {
+		t.Errorf("Sweep threshold: got %d, want %d", r.sweep, minSweep)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if got, want := clientKey(req, ""), "192.0.2.1"; got != want {
+		t.Errorf("clientKey without token: got %q, want %q", got, want)
+	}
+	if got, want := clientKey(req, "tok"), "token tok"; got != want {
+		t.Errorf("clientKey with token: got %q, want %q", got, want)
+	}
+	req.RemoteAddr = "pipe"
+	if got, want := clientKey(req, ""), "pipe"; got != want {
+		t.Errorf("clientKey without port: got %q, want %q", got, want)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	// Each request of a batch counts toward the rate limit.
+	s := New(testGraph(t, "a"), &Options{Rate: 0.001, Burst: 2})
+	_, out := post(t, s, `[{"jsonrpc":"2.0","id":1,"method":"Version"},{"jsonrpc":"2.0","id":2,"method":"Version"}]`)
+	if len(out) == 0 || out[0] != '[' {
+		t.Errorf("Batch within the limit: got %s", out)
+	}
+	status, r := postToken(t, s, "", `{"jsonrpc":"2.0","id":3,"method":"Version"}`)
+	if status != http.StatusOK || r.Error == nil || r.Error.Code != CodeRateLimited {
+		t.Errorf("Call over the limit: got %d %+v, want code %d", status, r, CodeRateLimited)
+	}
+
+	// A closure larger than the maximum result is refused.
+	s = New(testGraph(t, "a", "b", "c"), &Options{MaxResults: 2})
+	_, r = postToken(t, s, "", `{"jsonrpc":"2.0","id":1,"method":"Closure","params":{"roots":["a"]}}`)
+	if r.Error == nil || r.Error.Code != CodeTooLarge {
+		t.Errorf("Closure over the limit: got %+v, want code %d", r, CodeTooLarge)
+	}
+	_, r = postToken(t, s, "", `{"jsonrpc":"2.0","id":1,"method":"Closure","params":{"roots":["b"]}}`)
+	if r.Error != nil {
+		t.Errorf("Closure within the limit: unexpected error: %v", r.Error)
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package service exposes the operations of a dependency graph as a JSON-RPC
+// 2.0 service.
+//
+// Each method takes a single object of named parameters and returns a single
+// result. The methods are:
+//
+//	Add(AddRequest) AddResult                     add the packages of a repository
+//	Remove(PackageRequest) RemoveResult           delete a package (see graph.Delete)
+//	Row(PackageRequest) graph.Row                 the row of a package
+//	Imports(PackageRequest) []string              direct dependencies of a package
+//	Importers(ImportersRequest) ImportersResult   direct importers of a package
+//	Closure(ClosureRequest) ClosureResult         transitive dependencies
+//	Scan(ScanRequest) RowsResult                  rows with an import path prefix
+//	Search(SearchRequest) RowsResult              rows found by a graph search
+//	Version(VersionRequest) VersionResult         the version of the graph
+//
+// A package that is not in the graph is reported with code CodeNotFound.
+//
+// The version of the graph (see graph.Version) increases with each change to
+// the graph, so a client can tell whether results it holds are still current
+// by calling Version. The read-only methods can also be called with HTTP GET
+// requests, whose responses carry the version as an ETag; see Handler.
+//
+// The results of Importers, Scan, and Search are in order of import path, and
+// may be split into pages. A request may set a limit on the number of results
+// in a page. If more results remain, the result includes a cursor, which the
+// next request passes to get the next page.
+//
+// If access tokens are configured (see Options), each caller has a Role
+// determined by the bearer token of its HTTP request. Add and Remove require
+// RoleWrite, and the other methods require RoleRead. A call the caller's role
+// does not permit is reported with code CodeUnauthorized.
+//
+// Options can also limit the rate of calls by each caller, reporting calls in
+// excess with code CodeRateLimited, and the number of results of a call. A
+// Closure result that would be larger is reported with code CodeTooLarge, and
+// the results of other methods are split into pages of at most that size.
+//
+// Package client implements a Go client for the service.
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+)
+
+// A Service implements the methods of the service over a graph.
+type Service struct {
+	g          *graph.Graph
+	tokens     map[string]Role
+	anonymous  Role
+	limiter    *rateLimiter
+	maxResults int
+	cache      *resultCache
+	methods    map[string]method
+}
+
+// Options control the behaviour of a Service. A nil *Options is ready for use
+// and provides default values as described.
+type Options struct {
+	// Access tokens and the roles they grant. A caller presents a token in
+	// the Authorization header of its request, as "Bearer <token>", and a
+	// request with an unknown token is rejected. If there are no tokens,
+	// every caller has RoleWrite.
+	Tokens map[string]Role
+
+	// The role of a caller that presents no token, when there are tokens.
+	// The default is RoleNone.
+	Anonymous Role
+
+	// The maximum rate of calls by each caller, in calls per second, and the
+	// number of calls a caller may make at once after a quiet period. Each
+	// request of a batch is a call. Callers are told apart by their access
+	// tokens, or if Tokens is empty or they have none, by their network
+	// addresses. If Rate is zero, there is no limit; if Burst is zero, it is
+	// Rate rounded, or 1.
+	Rate  float64
+	Burst int
+
+	// The maximum number of results of a call. A call to Closure that would
+	// return more fails without finishing its traversal, and the results of
+	// Importers, Scan, and Search are split into pages of at most this size.
+	// If zero, there is no limit.
+	MaxResults int
+
+	// The maximum number of results of Closure and Importers calls to cache.
+	// Cached results are discarded whenever the graph is modified. If zero,
+	// results are not cached.
+	CacheSize int
+}
+
+// New constructs a Service for g.
+func New(g *graph.Graph, opts *Options) *Service {
+	s := &Service{g: g}
+	if opts != nil {
+		s.tokens = opts.Tokens
+		s.anonymous = opts.Anonymous
+		s.limiter = newRateLimiter(opts.Rate, opts.Burst)
+		s.maxResults = opts.MaxResults
+		s.cache = newResultCache(opts.CacheSize)
+	}
+	s.methods = s.methodTable()
+	return s
+}
+
+// PackageRequest is the parameter to methods that concern one package.
+type PackageRequest struct {
+	Package string `json:"package"` // import path
+}
+
+// AddRequest is the parameter to the Add method.
+type AddRequest struct {
+	// The repository to add, as written by the repodeps tool. All its packages
+	// and modules are added to the graph.
+	Repo *deps.Repo `json:"repo"`
+}
+
+// AddResult is the result of the Add method.
+type AddResult struct {
+	Packages int `json:"packages"` // the number of packages added
+	Modules  int `json:"modules"`  // the number of modules added
+}
+
+// RemoveResult is the result of the Remove method.
+type RemoveResult struct {
+	Removed bool `json:"removed"` // false if the package was not in the graph
+}
+
+// ImportersRequest is the parameter to the Importers method.
+type ImportersRequest struct {
+	Package string `json:"package"`          // import path
+	Cursor  string `json:"cursor,omitempty"` // from the previous page, if any
+	Limit   int    `json:"limit,omitempty"`  // the page size; 0 for the maximum
+}
+
+// ImportersResult is the result of the Importers method.
+type ImportersResult struct {
+	// The import paths of the importers in this page, in order.
+	Packages []string `json:"packages"`
+
+	// If not empty, more importers remain, and this is the cursor for the
+	// request for the next page.
+	Next string `json:"next,omitempty"`
+}
+
+// ClosureRequest is the parameter to the Closure method.
+type ClosureRequest struct {
+	Roots []string `json:"roots"` // import paths
+}
+
+// ClosureResult is the result of the Closure method.
+type ClosureResult struct {
+	// The import paths of the packages in the closure, in breadth-first order
+	// from the roots.
+	Packages []string `json:"packages"`
+
+	// The packages reached that are not in the graph, mapped to the number of
+	// packages in the closure that import them.
+	Missing map[string]int `json:"missing,omitempty"`
+}
+
+// ScanRequest is the parameter to the Scan method.
+type ScanRequest struct {
+	Prefix string `json:"prefix,omitempty"` // import path prefix; "" for all
+	Cursor string `json:"cursor,omitempty"` // from the previous page, if any
+	Limit  int    `json:"limit,omitempty"`  // the page size; 0 for the maximum
+}
+
+// SearchRequest is the parameter to the Search method.
+type SearchRequest struct {
+	Kind   string `json:"kind,omitempty"` // see graph.ParseSearchKind
+	Query  string `json:"query"`
+	Cursor string `json:"cursor,omitempty"` // from the previous page, if any
+	Limit  int    `json:"limit,omitempty"`  // the page size; 0 for the maximum
+}
+
+// VersionRequest is the parameter to the Version method.
+type VersionRequest struct {
+	// If set, the result reports whether the graph has changed since it had
+	// this version.
+	Since int64 `json:"since,omitempty"`
+}
+
+// VersionResult is the result of the Version method.
+type VersionResult struct {
+	Version int64 `json:"version"` // the current version of the graph
+	Changed bool  `json:"changed"` // whether Version is later than Since
+}
+
+// RowsResult is the result of the Scan and Search methods.
+type RowsResult struct {
+	// The rows in this page, in order of import path.
+	Rows []*graph.Row `json:"rows"`
+
+	// If not empty, more rows remain, and this is the cursor for the request
+	// for the next page.
+	Next string `json:"next,omitempty"`
+}
+
+// Add adds the packages and modules of a repository to the graph.
+func (s *Service) Add(ctx context.Context, req *AddRequest) (*AddResult, error) {
+	if req.Repo == nil {
+		return nil, errorf(CodeInvalidParams, "missing repo")
+	} else if err := deps.CheckSchema(req.Repo); err != nil {
+		return nil, errorf(CodeInvalidParams, "%v", err)
+	}
+	var res AddResult
+	for _, pkg := range req.Repo.Packages {
+		if err := s.g.Add(ctx, req.Repo, pkg); err != nil {
+			return nil, fmt.Errorf("adding package %q: %v", pkg.ImportPath, err)
+		}
+		res.Packages++
+	}
+	for _, mod := range req.Repo.Modules {
+		if err := s.g.AddModule(ctx, req.Repo, mod); err != nil {
+			return nil, fmt.Errorf("adding module %q: %v", mod.Path, err)
+		}
+		res.Modules++
+	}
+	return &res, nil
+}
+
+// Remove deletes a package from the graph, leaving a tombstone.
+func (s *Service) Remove(ctx context.Context, req *PackageRequest) (*RemoveResult, error) {
+	if err := s.g.Delete(ctx, req.Package); err == graph.ErrNotFound {
+		return &RemoveResult{Removed: false}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &RemoveResult{Removed: true}, nil
+}
+
+// Row returns the row of a package.
+func (s *Service) Row(ctx context.Context, req *PackageRequest) (*graph.Row, error) {
+	row, err := s.g.Row(ctx, req.Package)
+	if err != nil {
+		return nil, notFound(err, req.Package)
+	}
+	return row, nil
+}
+
+// Imports returns the direct dependencies of a package.
+func (s *Service) Imports(ctx context.Context, req *PackageRequest) ([]string, error) {
+	imps, err := s.g.Imports(ctx, req.Package)
+	if err != nil {
+		return nil, notFound(err, req.Package)
+	}
+	return nonNil(imps), nil
+}
+
+// Importers returns a page of the packages that directly depend on a package,
+// in order of import path.
+func (s *Service) Importers(ctx context.Context, req *ImportersRequest) (*ImportersResult, error) {
+	size, err := s.pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.cached(ctx, "Importers", req, func() (interface{}, error) {
+		return s.importers(ctx, req, size)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*ImportersResult), nil
+}
+
+func (s *Service) importers(ctx context.Context, req *ImportersRequest, size int) (*ImportersResult, error) {
+	res := &ImportersResult{Packages: []string{}}
+	if err := s.g.ImportersAfter(ctx, req.Package, req.Cursor, func(pkg string) error {
+		if size > 0 && len(res.Packages) == size {
+			res.Next = res.Packages[size-1]
+			return graph.ErrStopScan
+		}
+		res.Packages = append(res.Packages, pkg)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Closure returns the transitive dependencies of a set of packages.
+func (s *Service) Closure(ctx context.Context, req *ClosureRequest) (*ClosureResult, error) {
+	res, err := s.cached(ctx, "Closure", req, func() (interface{}, error) {
+		return s.closure(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*ClosureResult), nil
+}
+
+func (s *Service) closure(ctx context.Context, req *ClosureRequest) (*ClosureResult, error) {
+	res := &ClosureResult{Packages: []string{}}
+	stats, err := s.g.OpenClosure(ctx, req.Roots, func(row *graph.Row) error {
+		if err := s.checkSize(len(res.Packages)); err != nil {
+			return err
+		}
+		res.Packages = append(res.Packages, row.ImportPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(stats.Missing) != 0 {
+		res.Missing = stats.Missing
+	}
+	return res, nil
+}
+
+// Scan returns a page of the rows whose import paths have a given prefix, in
+// order of import path.
+func (s *Service) Scan(ctx context.Context, req *ScanRequest) (*RowsResult, error) {
+	size, err := s.pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res := &RowsResult{Rows: []*graph.Row{}}
+	if err := s.g.ScanAfter(ctx, req.Prefix, req.Cursor, res.collect(size)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Search returns a page of the rows found by a search of the graph, in order
+// of import path.
+func (s *Service) Search(ctx context.Context, req *SearchRequest) (*RowsResult, error) {
+	kind, err := graph.ParseSearchKind(req.Kind)
+	if err != nil {
+		return nil, errorf(CodeInvalidParams, "%v", err)
+	}
+	size, err := s.pageSize(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	res := &RowsResult{Rows: []*graph.Row{}}
+	if err := s.g.SearchAfter(ctx, kind, req.Query, req.Cursor, res.collect(size)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Version returns the current version of the graph.
+func (s *Service) Version(ctx context.Context, req *VersionRequest) (*VersionResult, error) {
+	v, err := s.g.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionResult{Version: v, Changed: v > req.Since}, nil
+}
+
+// collect returns a function that adds each row it is passed to r, until r
+// has size rows; then it sets the cursor for the next page and stops the
+// scan. If size == 0, every row is added.
+func (r *RowsResult) collect(size int) func(*graph.Row) error {
+	return func(row *graph.Row) error {
+		if size > 0 && len(r.Rows) == size {
+			r.Next = r.Rows[size-1].ImportPath
+			return graph.ErrStopScan
+		}
+		r.Rows = append(r.Rows, row)
+		return nil
+	}
+}
+
+// pageSize returns the number of results in a page for a request whose limit
+// is limit, or 0 if there is no limit.
+func (s *Service) pageSize(limit int) (int, error) {
+	if limit < 0 {
+		return 0, errorf(CodeInvalidParams, "invalid limit %d", limit)
+	} else if s.maxResults > 0 && (limit == 0 || limit > s.maxResults) {
+		return s.maxResults, nil
+	}
+	return limit, nil
+}
+
+// checkSize reports an error with code CodeTooLarge if a result that is not
+// paged and already has n elements may not have another.
+func (s *Service) checkSize(n int) error {
+	if s.maxResults > 0 && n >= s.maxResults {
+		return errorf(CodeTooLarge, "the result has more than %d elements", s.maxResults)
+	}
+	return nil
+}
+
+// notFound converts graph.ErrNotFound for pkg into an error with code
+// CodeNotFound, and returns other errors unchanged.
+func notFound(err error, pkg string) error {
+	if err == graph.ErrNotFound {
+		return errorf(CodeNotFound, "package %q not found", pkg)
+	}
+	return err
+}
+
+// nonNil returns ss, or an empty slice if ss is nil, so that an empty result
+// is encoded as [] rather than null.
+func nonNil(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// pages calls fetch with the cursor of each page in turn, starting from "",
+// and returns the results of all the pages.
+func pages(t *testing.T, fetch func(cursor string) ([]string, string, error)) [][]string {
+	t.Helper()
+	var all [][]string
+	for cursor := ""; ; {
+		page, next, err := fetch(cursor)
+		if err != nil {
+			t.Fatalf("Fetching page after %q: unexpected error: %v", cursor, err)
+		}
+		all = append(all, page)
+		if next == "" {
+			return all
+		} else if len(all) > 10 {
+			t.Fatalf("Too many pages: %q", all)
+		}
+		cursor = next
+	}
+}
+
+func rowPaths(res *RowsResult) []string {
+	paths := []string{}
+	for _, row := range res.Rows {
+		paths = append(paths, row.ImportPath)
+	}
+	return paths
+}
+
+func TestPaging(t *testing.T) {
+	ctx := context.Background()
+	g := testGraph(t, "x/a", "x/b", "x/c", "x/d", "y")
+
+	importers := func(s *Service, limit int) func(string) ([]string, string, error) {
+		return func(cursor string) ([]string, string, error) {
+			res, err := s.Importers(ctx, &ImportersRequest{Package: "y", Cursor: cursor, Limit: limit})
+			if err != nil {
+				return nil, "", err
+			}
+			return res.Packages, res.Next, nil
+		}
+	}
+	scan := func(s *Service, limit int) func(string) ([]string, string, error) {
+		return func(cursor string) ([]string, string, error) {
+			res, err := s.Scan(ctx, &ScanRequest{Prefix: "x/", Cursor: cursor, Limit: limit})
+			if err != nil {
+				return nil, "", err
+			}
+			return rowPaths(res), res.Next, nil
+		}
+	}
+	search := func(s *Service, limit int) func(string) ([]string, string, error) {
+		return func(cursor string) ([]string, string, error) {
+			res, err := s.Search(ctx, &SearchRequest{Kind: "substring", Query: "x/", Cursor: cursor, Limit: limit})
+			if err != nil {
+				return nil, "", err
+			}
+			return rowPaths(res), res.Next, nil
+		}
+	}
+
+	unlimited := New(g, nil)
+	capped := New(g, &Options{MaxResults: 3})
+	tests := []struct {
+		label string
+		fetch func(string) ([]string, string, error)
+		want  [][]string
+	}{
+		{"Importers", importers(unlimited, 0), [][]string{{"x/a", "x/b", "x/c", "x/d"}}},
+		{"Importers limit 2", importers(unlimited, 2), [][]string{{"x/a", "x/b"}, {"x/c", "x/d"}}},
+		{"Importers limit 4", importers(unlimited, 4), [][]string{{"x/a", "x/b", "x/c", "x/d"}}},
+		{"Importers capped", importers(capped, 0), [][]string{{"x/a", "x/b", "x/c"}, {"x/d"}}},
+		{"Importers capped limit 5", importers(capped, 5), [][]string{{"x/a", "x/b", "x/c"}, {"x/d"}}},
+
+		{"Scan", scan(unlimited, 0), [][]string{{"x/a", "x/b", "x/c", "x/d"}}},
+		{"Scan limit 3", scan(unlimited, 3), [][]string{{"x/a", "x/b", "x/c"}, {"x/d"}}},
+		{"Scan capped limit 1", scan(capped, 1), [][]string{{"x/a"}, {"x/b"}, {"x/c"}, {"x/d"}}},
+
+		{"Search", search(unlimited, 0), [][]string{{"x/a", "x/b", "x/c", "x/d"}}},
+		{"Search limit 3", search(unlimited, 3), [][]string{{"x/a", "x/b", "x/c"}, {"x/d"}}},
+		{"Search capped", search(capped, 0), [][]string{{"x/a", "x/b", "x/c"}, {"x/d"}}},
+	}
+	for _, test := range tests {
+		if got := pages(t, test.fetch); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got pages %q, want %q", test.label, got, test.want)
+		}
+	}
+
+	// An empty result is a single empty page.
+	res, err := unlimited.Scan(ctx, &ScanRequest{Prefix: "z"})
+	if err != nil || len(res.Rows) != 0 || res.Next != "" {
+		t.Errorf("Scan z: got (%+v, %v), want an empty page", res, err)
+	}
+
+	// A negative limit is invalid.
+	_, err = unlimited.Importers(ctx, &ImportersRequest{Package: "y", Limit: -1})
+	if e, ok := err.(*Error); !ok || e.Code != CodeInvalidParams {
+		t.Errorf("Importers with limit -1: got error %v, want code %d", err, CodeInvalidParams)
+	}
+	_, err = unlimited.Search(ctx, &SearchRequest{Kind: "bogus", Query: "x"})
+	if e, ok := err.(*Error); !ok || e.Code != CodeInvalidParams {
+		t.Errorf("Search of kind bogus: got error %v, want code %d", err, CodeInvalidParams)
+	}
+}
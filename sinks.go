@@ -0,0 +1,381 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+// A sink receives the repositories produced by the scan. The Write method of
+// a sink may be called concurrently.
+type sink interface {
+	// Write delivers a batch of repositories to the sink.
+	Write(ctx context.Context, repos []*deps.Repo) error
+
+	// Close flushes any pending output and releases the sink's resources.
+	Close() error
+}
+
+// parseSinks parses a comma-separated list of sink specifications, each of
+// the form kind=target.
+func parseSinks(spec string) ([]sink, error) {
+	var out []sink
+	for _, elt := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(elt), "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid sink %q", elt)
+		}
+		s, err := newSink(parts[0], parts[1])
+		if err != nil {
+			for _, prev := range out {
+				prev.Close()
+			}
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// newSink constructs a sink of the given kind writing to target.
+func newSink(kind, target string) (sink, error) {
+	switch kind {
+	case "json":
+		if target == "-" {
+			return &jsonSink{w: os.Stdout}, nil
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSink{w: f, c: f}, nil
+
+	case "kafka", "kafka-pkg":
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			return nil, fmt.Errorf("kafka sink requires a REST proxy URL, not %q", target)
+		}
+		return &kafkaSink{url: target, perPackage: kind == "kafka-pkg"}, nil
+
+	case "es":
+		return newESSink(context.Background(), target)
+
+	case "bigquery":
+		s, err := newBigQuerySink(target)
+		if err != nil {
+			return nil, err
+		}
+		s.flushEach = *doReadInputs
+		return s, nil
+
+	case "store":
+		g, c, err := tools.OpenGraph(target)
+		if err != nil {
+			return nil, err
+		}
+		g.SetBatching(*storeBatch, *storeFlush)
+		s := newStoreSink(g, c, *storeQueue)
+		s.flushEach = *doReadInputs
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown sink type %q", kind)
+}
+
+// jsonSink writes encoded repositories to a writer, formatted according to
+// the -format or -select flags if they are set.
+type jsonSink struct {
+	mu sync.Mutex
+	w  io.Writer
+	c  io.Closer // may be nil
+}
+
+func (s *jsonSink) Write(_ context.Context, repos []*deps.Repo) error {
+	buf := encodePool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledEncoding {
+			encodePool.Put(buf)
+		}
+	}()
+	buf.Reset()
+	if err := encodeRepos(buf, repos); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// encodePool holds the buffers into which jsonSink encodes each batch, so
+// that a steady stream of writes reuses a few buffers.
+var encodePool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledEncoding is the largest buffer returned to encodePool.
+const maxPooledEncoding = 4 << 20
+
+func (s *jsonSink) Close() error {
+	if s.c != nil {
+		return s.c.Close()
+	}
+	return nil
+}
+
+// storeSink adds packages and modules to a graph. Repositories are queued
+// and added by a separate goroutine, and the graph buffers the additions, so
+// that the workers are not serialized on storage. The queue is bounded: when
+// it is full, Write waits for the graph to catch up, so that a slow store
+// slows the scan instead of accumulating results in memory.
+//
+// If flushEach is true, the graph is flushed after the repositories of each
+// write are added, so that they are stored without waiting for a batch to
+// fill.
+type storeSink struct {
+	g         *graph.Graph
+	c         io.Closer
+	flushEach bool
+
+	queue chan []*deps.Repo
+	done  chan struct{} // closed when the queue has been drained
+
+	mu      sync.Mutex
+	err     error         // the first error from adding to the graph
+	waiting time.Duration // total time Write waited for the queue
+}
+
+func newStoreSink(g *graph.Graph, c io.Closer, size int) *storeSink {
+	s := &storeSink{
+		g:     g,
+		c:     c,
+		queue: make(chan []*deps.Repo, size),
+		done:  make(chan struct{}),
+	}
+	go s.drain()
+	return s
+}
+
+func (s *storeSink) Write(ctx context.Context, repos []*deps.Repo) error {
+	if err := s.failed(); err != nil {
+		return err
+	}
+	select {
+	case s.queue <- repos:
+		return nil
+	default:
+	}
+
+	// The queue is full; wait for space.
+	start := time.Now()
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.waiting += time.Since(start)
+	}()
+	select {
+	case s.queue <- repos:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain adds the queued repositories to the graph until the queue is closed.
+// After an error, the remaining repositories are discarded.
+func (s *storeSink) drain() {
+	defer close(s.done)
+	ctx := context.Background()
+	for repos := range s.queue {
+		if s.failed() != nil {
+			continue
+		}
+		err := s.add(ctx, repos)
+		if err == nil && s.flushEach {
+			err = s.g.Flush(ctx)
+		}
+		if err != nil {
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *storeSink) add(ctx context.Context, repos []*deps.Repo) error {
+	for _, repo := range repos {
+		for _, pkg := range repo.Packages {
+			if err := s.g.Add(ctx, repo, pkg); err != nil {
+				return fmt.Errorf("adding package %q: %v", pkg.ImportPath, err)
+			}
+		}
+		for _, mod := range repo.Modules {
+			if err := s.g.AddModule(ctx, repo, mod); err != nil {
+				return fmt.Errorf("adding module %q: %v", mod.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// failed returns the first error from adding to the graph, if any.
+func (s *storeSink) failed() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *storeSink) Close() error {
+	close(s.queue)
+	<-s.done
+	if s.waiting > 0 {
+		log.Printf("Scanning waited %v for the graph store", s.waiting.Round(time.Millisecond))
+	}
+	err := s.failed()
+	if ferr := s.g.Flush(context.Background()); err == nil {
+		err = ferr
+	}
+	if cerr := s.c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// kafkaSink publishes results to a Kafka topic via the HTTP interface of a
+// Kafka REST proxy. The url is that of the topic, for example
+// http://localhost:8082/topics/repodeps. Each repository (or each package, if
+// perPackage is true) is published as a separate message, keyed by its remote
+// URL or import path.
+type kafkaSink struct {
+	url        string
+	perPackage bool
+}
+
+type kafkaRecord struct {
+	Key   string      `json:"key,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+func (s *kafkaSink) Write(ctx context.Context, repos []*deps.Repo) error {
+	var recs []kafkaRecord
+	for _, repo := range repos {
+		var key string
+		if len(repo.Remotes) != 0 {
+			key = repo.Remotes[0].Url
+		}
+		if !s.perPackage {
+			recs = append(recs, kafkaRecord{Key: key, Value: repo})
+			continue
+		}
+		for _, pkg := range repo.Packages {
+			recs = append(recs, kafkaRecord{Key: pkg.ImportPath, Value: struct {
+				*deps.Package
+				Repository string `json:"repository,omitempty"`
+			}{pkg, key}})
+		}
+	}
+	if len(recs) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(struct {
+		Records []kafkaRecord `json:"records"`
+	}{Records: recs})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.url, "application/vnd.kafka.json.v2+json", body)
+}
+
+func (*kafkaSink) Close() error { return nil }
+
+// postJSON sends an HTTP POST request with the given body to url, and reports
+// an error if the request fails or the response is not successful.
+func postJSON(ctx context.Context, url, ctype string, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ctype)
+	rsp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return fmt.Errorf("posting to %s: %s: %s", url, rsp.Status, bytes.TrimSpace(msg))
+	}
+	io.Copy(ioutil.Discard, rsp.Body)
+	return nil
+}
+
+// encodeRepos appends to buf the encoding of repos as a line of JSON, or as
+// rendered by the -format or -select flags if they are set.
+func encodeRepos(buf *bytes.Buffer, repos []*deps.Repo) error {
+	if outTemplate != nil {
+		return formatRepos(buf, repos)
+	} else if outSelection != nil {
+		bits, err := outSelection.apply(repos)
+		if err == nil {
+			buf.Write(bits)
+			buf.WriteByte('\n')
+		}
+		return err
+	}
+	return json.NewEncoder(buf).Encode(repos)
+}
+
+// formatRepos appends to buf the rendering of repos using outTemplate, once
+// per package or per repository according to the -per flag, with each result
+// on its own line.
+func formatRepos(buf *bytes.Buffer, repos []*deps.Repo) error {
+	emit := func(data interface{}) error {
+		if err := outTemplate.Execute(buf, data); err != nil {
+			return err
+		}
+		if b := buf.Bytes(); len(b) != 0 && b[len(b)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		return nil
+	}
+	for _, repo := range repos {
+		if *formatEach == "repo" {
+			if err := emit(repo); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, pkg := range repo.Packages {
+			if err := emit(struct {
+				*deps.Package
+				Repo *deps.Repo
+			}{pkg, repo}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siva
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+)
+
+// archiveFS is the filesystem from which archives are read. Files opened for
+// reading are memory-mapped where the platform supports it, so that reading
+// the index and objects of an archive does not require a system call for
+// each read. Otherwise, and for files opened with other flags, it behaves
+// like the host filesystem.
+type archiveFS struct {
+	billy.Filesystem
+}
+
+func newArchiveFS() archiveFS { return archiveFS{Filesystem: osfs.New("/")} }
+
+// Open opens the named file for reading, mapping it into memory if possible.
+func (a archiveFS) Open(name string) (billy.File, error) {
+	name = filepath.Join("/", name) // as osfs resolves it
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	data, unmap, err := mmapFile(f, fi.Size())
+	f.Close() // the mapping, if any, remains valid
+	if err != nil {
+		return a.Filesystem.Open(name)
+	}
+	return &mappedFile{
+		name:   name,
+		Reader: bytes.NewReader(data),
+		unmap:  unmap,
+	}, nil
+}
+
+var errReadOnly = errors.New("file is read-only")
+
+// mappedFile is a read-only billy.File whose contents are mapped into memory.
+// Reads copy from the mapping, so no slice of it escapes, and it may be
+// unmapped when the file is closed.
+type mappedFile struct {
+	name string
+	*bytes.Reader
+	unmap func() error
+}
+
+func (m *mappedFile) Name() string            { return m.name }
+func (*mappedFile) Write([]byte) (int, error) { return 0, errReadOnly }
+func (*mappedFile) Truncate(int64) error      { return errReadOnly }
+func (*mappedFile) Lock() error               { return nil }
+func (*mappedFile) Unlock() error             { return nil }
+
+// Close unmaps the contents of the file. The file must not be used after it
+// is closed; closing it again has no effect.
+func (m *mappedFile) Close() error {
+	unmap := m.unmap
+	m.Reader, m.unmap = bytes.NewReader(nil), nil
+	if unmap == nil {
+		return nil
+	}
+	return unmap()
+}
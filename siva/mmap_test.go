@@ -0,0 +1,51 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package siva
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveFSClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "siva")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.siva")
+	const want = "some archive contents"
+	if err := ioutil.WriteFile(path, []byte(want), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	f, err := newArchiveFS().Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", path, err)
+	}
+	got, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Errorf("Reading %q: %v", path, err)
+	} else if string(got) != want {
+		t.Errorf("Reading %q: got %q, want %q", path, got, want)
+	}
+	for i := 0; i < 2; i++ {
+		if err := f.Close(); err != nil {
+			t.Errorf("Close #%d: unexpected error: %v", i+1, err)
+		}
+	}
+}
@@ -19,16 +19,17 @@ import (
 	"fmt"
 	"go/build"
 	"io"
+	"io/ioutil"
+	"log"
 	"os"
 	"path/filepath"
+	"runtime/trace"
 	"strings"
 	"time"
 
 	"github.com/creachadair/repodeps/deps"
 
 	sivafs "gopkg.in/src-d/go-billy-siva.v4"
-	"gopkg.in/src-d/go-billy.v4/memfs"
-	"gopkg.in/src-d/go-billy.v4/osfs"
 	git "gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/cache"
@@ -39,15 +40,16 @@ import (
 
 // Load reads the repository structure of a Siva archive file.  This may return
 // multiple repositories, if the file is rooted.
-func Load(_ context.Context, path string, opts *deps.Options) ([]*deps.Repo, error) {
+func Load(ctx context.Context, path string, opts *deps.Options) ([]*deps.Repo, error) {
 	if opts == nil {
 		opts = new(deps.Options)
 	}
-	fs := osfs.New("/")
-	sfs, err := sivafs.NewFilesystem(fs, path, memfs.New())
+	defer trace.StartRegion(ctx, "siva").End()
+	sfs, err := sivafs.NewFilesystemReadOnly(newArchiveFS(), path, 0)
 	if err != nil {
 		return nil, fmt.Errorf("opening siva filesystem: %v", err)
 	}
+	defer sfs.Sync() // closes the archive
 
 	// N.B.: The cache parameter must be non-nil for any task where object
 	// contents must be read.
@@ -70,7 +72,7 @@ func Load(_ context.Context, path string, opts *deps.Options) ([]*deps.Repo, err
 			From: path,
 			Remotes: []*deps.Remote{{
 				Name: rem.Name,
-				Url:  fixURL(rem.URLs[0]),
+				Url:  deps.CanonicalURL(rem.URLs[0]),
 			}},
 		}
 		repos[rem.Name] = r
@@ -97,6 +99,8 @@ func Load(_ context.Context, path string, opts *deps.Options) ([]*deps.Repo, err
 		name := string(ref.Name())
 		if i := strings.LastIndex(name, "/"); i < 0 {
 			return nil // skip un-rooted reference
+		} else if !matchRef(opts.Ref, name[:i]) {
+			return nil // not the requested reference
 		} else if uuid := name[i+1:]; uuid == cur {
 			return nil // we already have a ref for this repo
 		} else {
@@ -105,6 +109,7 @@ func Load(_ context.Context, path string, opts *deps.Options) ([]*deps.Repo, err
 
 		// Load the tree for the tip comment and scan its files.
 		here := repos[cur]
+		here.Commit = ref.Hash().String()
 		comm, err := repo.CommitObject(ref.Hash())
 		if err != nil {
 			return err
@@ -125,32 +130,86 @@ func Load(_ context.Context, path string, opts *deps.Options) ([]*deps.Repo, err
 			return err
 		}
 
-		bc := vfs.buildContext()
-		for dir := range vfs.dirs {
-			pkg, err := bc.ImportDir(dir, 0)
+		// Record any module definitions, so that import paths can be resolved
+		// relative to them.
+		var mods deps.ModuleSet
+		var owners deps.OwnerSet
+		for path := range vfs.files {
+			if base := filepath.Base(path); base != "go.mod" && base != "OWNERS" {
+				continue
+			}
+			data, err := vfs.readFile(path)
 			if err != nil {
-				continue // no importable go package here; skip it
+				return fmt.Errorf("reading file: %v", err)
+			}
+			rel := vfs.rel(here.Remotes[0].Url, filepath.Dir(path))
+			if filepath.Base(path) == "go.mod" {
+				mods.Add(rel, data)
+			} else {
+				owners.AddOwners(rel, data)
+			}
+		}
+		for _, rel := range deps.CodeownersPaths {
+			if data, err := vfs.readFile(filepath.Join(vfs.prefix, rel)); err == nil {
+				owners.AddCodeowners(data)
+				break
 			}
-			rec := &deps.Package{
-				Name:       pkg.Name,
-				ImportPath: pkg.ImportPath,
-				Imports:    pkg.Imports,
+		}
+		for _, rel := range deps.ReadmePaths {
+			if data, err := vfs.readFile(filepath.Join(vfs.prefix, rel)); err == nil {
+				here.Description = deps.ReadmeTitle(data)
+				break
+			}
+		}
+		for _, rel := range deps.LicensePaths {
+			if data, err := vfs.readFile(filepath.Join(vfs.prefix, rel)); err == nil {
+				here.License = deps.DetectLicense(data)
+				break
 			}
-			if opts.HashSourceFiles {
-				for _, name := range pkg.GoFiles {
-					fpath := filepath.Join(dir, name)
-					r, err := vfs.open(fpath)
-					if err != nil {
-						return fmt.Errorf("reading file: %v", err)
-					}
-					rec.Sources = append(rec.Sources, &deps.File{
-						RepoPath: vfs.rel(here.Remotes[0].Url, fpath),
-						Digest:   deps.Hash(r),
-					})
-					r.Close()
+		}
+		here.Modules = mods.Modules()
+
+		if opts.Activity {
+			act, err := commitActivity(repo, ref.Hash())
+			if err != nil {
+				return fmt.Errorf("reading history: %v", err)
+			}
+			here.Activity = act
+		}
+
+		// Parsing reads file contents from the archive as needed, so the
+		// "parse" region includes some extraction.
+		bc := vfs.buildContext()
+		parse := trace.StartRegion(ctx, "parse")
+		for dir := range vfs.dirs {
+			reldir := vfs.rel(here.Remotes[0].Url, dir)
+			if !opts.Included(reldir) || opts.Skipped(reldir) {
+				continue // not selected by the caller
+			} else if opts.SummaryOnly {
+				if here.Summary == nil {
+					here.Summary = new(deps.Summary)
 				}
+				here.Summary.AddDir(vfs.dirs[dir])
+				continue
+			}
+			recs, conflict := deps.AnalyzeDir(&bc, vfs.prefix, dir, opts)
+			if conflict != nil {
+				here.Conflicts = append(here.Conflicts, conflict)
+			}
+			for _, rec := range recs {
+				deps.NameLocal(rec, here.Remotes[0].Url, reldir)
+				mods.Resolve(rec, reldir)
+				rec.Owners = owners.Owners(reldir)
+				here.Packages = append(here.Packages, rec)
+			}
+		}
+		parse.End()
+		if opts.PseudoVersions {
+			if rev, err := commitRevision(repo, cur, comm); err != nil {
+				log.Printf("Leaving the modules of %q unversioned: reading tags: %v", here.From, err)
+			} else {
+				deps.SetModuleVersions(here, rev)
 			}
-			here.Packages = append(here.Packages, rec)
 		}
 		return nil
 	}); err != nil {
@@ -159,6 +218,62 @@ func Load(_ context.Context, path string, opts *deps.Options) ([]*deps.Repo, err
 	return results, nil
 }
 
+// commitActivity summarizes the history of repo reachable from the commit
+// with the given hash.
+func commitActivity(repo *git.Repository, from plumbing.Hash) (*deps.Activity, error) {
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, err
+	}
+	tally := deps.NewActivityTally(time.Now())
+	if err := iter.ForEach(func(c *object.Commit) error {
+		tally.Add(c.Committer.When, c.Author.Email)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tally.Activity(), nil
+}
+
+// commitRevision describes comm, the tip commit of the repository with the
+// given UUID in the rooted repository repo, with the tags of that repository
+// on the commit and its ancestors. Rooted tags have the form NAME/UUID.
+func commitRevision(repo *git.Repository, uuid string, comm *object.Commit) (*deps.Revision, error) {
+	rev := &deps.Revision{Commit: comm.Hash.String(), Time: comm.Committer.When}
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := strings.TrimPrefix(string(ref.Name()), "refs/tags/")
+		if !strings.HasSuffix(name, "/"+uuid) {
+			return nil // another repository's tag
+		}
+		name = strings.TrimSuffix(name, "/"+uuid)
+
+		// An annotated tag refers to a tag object, which refers to the commit.
+		var target *object.Commit
+		if tag, err := repo.TagObject(ref.Hash()); err == nil {
+			target, err = tag.Commit()
+			if err != nil {
+				return nil // not a commit tag
+			}
+		} else if target, err = repo.CommitObject(ref.Hash()); err != nil {
+			return nil // not a commit tag
+		}
+		if target.Hash == comm.Hash {
+			rev.Exact = append(rev.Exact, name)
+			rev.Tags = append(rev.Tags, name)
+		} else if ok, err := target.IsAncestor(comm); err != nil {
+			return err
+		} else if ok {
+			rev.Tags = append(rev.Tags, name)
+		}
+		return nil
+	})
+	return rev, err
+}
+
 // vfile wraps a go-git File object to implement the os.FileInfo interface.
 type vfile struct {
 	f *object.File
@@ -225,6 +340,15 @@ func (v *vfs) open(path string) (io.ReadCloser, error) {
 	return f.f.Blob.Reader()
 }
 
+func (v *vfs) readFile(path string) ([]byte, error) {
+	r, err := v.open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
 func (v *vfs) readDir(path string) ([]os.FileInfo, error) {
 	lst, ok := v.dirs[path]
 	if !ok {
@@ -245,12 +369,10 @@ func (v *vfs) isDir(path string) bool {
 	return ok
 }
 
-func fixURL(s string) string {
-	const urlKey = "://"
-
-	s = strings.TrimSuffix(s, ".git")
-	if i := strings.Index(s, urlKey); i >= 0 {
-		s = s[i+len(urlKey):]
-	}
-	return s
+// matchRef reports whether name matches the requested reference. An empty
+// request matches any name; otherwise want may be a full reference name or the
+// short name of a branch or tag.
+func matchRef(want, name string) bool {
+	return want == "" || name == want ||
+		name == "refs/heads/"+want || name == "refs/tags/"+want
 }
@@ -34,7 +34,9 @@ type storage struct {
 // Load implements part of the graph.Storage interface.
 func (s storage) Load(ctx context.Context, key string, val proto.Message) error {
 	bits, err := s.bs.Get(ctx, key)
-	if err != nil {
+	if err == blob.ErrKeyNotFound {
+		return graph.ErrNotFound
+	} else if err != nil {
 		return err
 	}
 	return proto.Unmarshal(bits, val)
@@ -64,3 +66,30 @@ func (s storage) Scan(ctx context.Context, prefix string, f func(string) error)
 		return nil
 	})
 }
+
+// Delete implements part of the graph.Storage interface.
+func (s storage) Delete(ctx context.Context, key string) error {
+	err := s.bs.Delete(ctx, key)
+	if err == blob.ErrKeyNotFound {
+		return graph.ErrNotFound
+	}
+	return err
+}
+
+// Rename implements part of the graph.Storage interface.
+func (s storage) Rename(ctx context.Context, oldKey, newKey string) error {
+	bits, err := s.bs.Get(ctx, oldKey)
+	if err == blob.ErrKeyNotFound {
+		return graph.ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	if err := s.bs.Put(ctx, blob.PutOptions{
+		Key:     newKey,
+		Data:    bits,
+		Replace: true,
+	}); err != nil {
+		return err
+	}
+	return s.bs.Delete(ctx, oldKey)
+}
@@ -0,0 +1,253 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package throttle paces operations on remote hosts, so that a large crawl
+// does not trip the abuse detection of hosting providers.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+)
+
+// A Limiter paces operations on remote hosts. Operations on each host are
+// started at least Interval apart, at most MaxActive of them run at once, and
+// a failed operation is retried up to Retries times, after an exponentially
+// increasing delay with random jitter. Each host is paced separately.
+//
+// The zero value is ready for use and imposes no limits. A nil *Limiter is
+// also valid, and runs every operation once without delay.
+type Limiter struct {
+	// The minimum interval between the starts of operations on a host.
+	Interval time.Duration
+
+	// The maximum number of concurrent operations on a host. If zero, there
+	// is no limit.
+	MaxActive int
+
+	// The number of times a failed operation is retried.
+	Retries int
+
+	// The delay before the first retry. Each later retry waits twice as long
+	// as the one before. The actual delay is chosen at random between half
+	// and one and a half times the nominal value. If zero, use DefaultBackoff.
+	Backoff time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// DefaultBackoff is the default delay before the first retry of a failed
+// operation.
+const DefaultBackoff = time.Second
+
+type host struct {
+	next time.Time     // the earliest time the next operation may start
+	sem  chan struct{} // holds a token for each active operation
+}
+
+// Host returns the name of the host for the repository at url, for use as a
+// key to Do. URLs of any form accepted by deps.CanonicalURL are understood. An
+// absolute or explicitly relative local path yields "".
+func Host(url string) string {
+	host := strings.SplitN(deps.CanonicalURL(url), "/", 2)[0]
+	if strings.HasPrefix(host, ".") {
+		return ""
+	}
+	return host
+}
+
+// Do calls f, subject to the limits for the given host, and retries it while
+// it fails until the retries are exhausted or ctx ends. Do returns the error
+// from the last call of f, or the error from ctx.
+func (l *Limiter) Do(ctx context.Context, host string, f func() error) error {
+	if l == nil || host == "" {
+		return f()
+	}
+	for try := 0; ; try++ {
+		err := l.do(ctx, host, f)
+		if err == nil || try >= l.Retries || ctx.Err() != nil {
+			return err
+		} else if err := sleep(ctx, l.backoff(try)); err != nil {
+			return err
+		}
+	}
+}
+
+// do calls f once, subject to the limits for the given host.
+func (l *Limiter) do(ctx context.Context, name string, f func() error) error {
+	h := l.host(name)
+	if h.sem != nil {
+		select {
+		case h.sem <- struct{}{}:
+			defer func() { <-h.sem }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := h.next
+	if start.Before(now) {
+		start = now
+	}
+	h.next = start.Add(l.Interval)
+	l.mu.Unlock()
+
+	if err := sleep(ctx, start.Sub(now)); err != nil {
+		return err
+	}
+	return f()
+}
+
+// host returns the state for the named host, creating it if necessary.
+func (l *Limiter) host(name string) *host {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.hosts[name]
+	if !ok {
+		h = new(host)
+		if l.MaxActive > 0 {
+			h.sem = make(chan struct{}, l.MaxActive)
+		}
+		if l.hosts == nil {
+			l.hosts = make(map[string]*host)
+		}
+		l.hosts[name] = h
+	}
+	return h
+}
+
+// backoff returns a jittered delay before retry number try, counting from 0.
+func (l *Limiter) backoff(try int) time.Duration {
+	d := l.Backoff
+	if d <= 0 {
+		d = DefaultBackoff
+	}
+	d <<= uint(try)
+	return d/2 + time.Duration(rand.Int63n(int64(d)+1))
+}
+
+// deferUntil delays further operations on the named host until at least t, for
+// example when the host has asked the client to slow down.
+func (l *Limiter) deferUntil(name string, t time.Time) {
+	h := l.host(name)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(h.next) {
+		h.next = t
+	}
+}
+
+// sleep blocks for d or until ctx ends, whichever is first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Transport returns an HTTP transport that sends requests through base,
+// subject to the limits of l for the host of each request. Requests are
+// retried if they fail, or if the server responds with status 429 or a 5xx
+// status; a Retry-After header in the response delays further requests to
+// that host accordingly. A request with a body is retried only if its GetBody
+// field is set, and each retry sends a new copy of the body; otherwise it is
+// sent once. If base == nil, http.DefaultTransport is used.
+func (l *Limiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transport{l: l, base: base}
+}
+
+type transport struct {
+	l    *Limiter
+	base http.RoundTripper
+}
+
+// errRetry reports a response that should be retried.
+var errRetry = errors.New("retryable response")
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.l == nil {
+		return t.base.RoundTrip(req)
+	}
+	name := strings.ToLower(req.URL.Host)
+	hasBody := req.Body != nil && req.Body != http.NoBody
+	var last *http.Response
+	sent := false
+	send := func() error {
+		if last != nil {
+			last.Body.Close()
+			last = nil
+		}
+		r := req
+		if sent && hasBody {
+			// The body of the previous attempt has been consumed.
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			cp := *req
+			cp.Body = body
+			r = &cp
+		}
+		sent = true
+		rsp, err := t.base.RoundTrip(r)
+		if err != nil {
+			return err
+		}
+		last = rsp
+		if rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= 500 {
+			if secs, err := strconv.Atoi(rsp.Header.Get("Retry-After")); err == nil {
+				t.l.deferUntil(name, time.Now().Add(time.Duration(secs)*time.Second))
+			}
+			return errRetry
+		}
+		return nil
+	}
+	var err error
+	if hasBody && req.GetBody == nil {
+		err = t.l.do(req.Context(), name, send) // the body cannot be replayed
+	} else {
+		err = t.l.Do(req.Context(), name, send)
+	}
+	if err == errRetry {
+		return last, nil // retries exhausted; report the final response
+	} else if err != nil {
+		if last != nil {
+			last.Body.Close()
+		}
+		return nil, err
+	}
+	return last, nil
+}
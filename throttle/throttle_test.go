@@ -0,0 +1,224 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package throttle
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHost(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://github.com/foo/bar", "github.com"},
+		{"git@github.com:foo/bar.git", "github.com"},
+		{"github.com/foo/bar", "github.com"},
+		{"./local/repo", ""},
+		{"../local/repo", ""},
+	}
+	for _, test := range tests {
+		if got := Host(test.url); got != test.want {
+			t.Errorf("Host(%q): got %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestDoRetries(t *testing.T) {
+	ctx := context.Background()
+	l := &Limiter{Retries: 2, Backoff: time.Millisecond}
+	errFail := errors.New("failed")
+
+	calls := 0
+	err := l.Do(ctx, "h", func() error { calls++; return errFail })
+	if err != errFail || calls != 3 {
+		t.Errorf("Do failing: got %v after %d calls, want %v after 3", err, calls, errFail)
+	}
+
+	calls = 0
+	err = l.Do(ctx, "h", func() error {
+		if calls++; calls < 2 {
+			return errFail
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Do succeeding: got %v after %d calls, want nil after 2", err, calls)
+	}
+
+	// A nil limiter, or an empty host, calls f once.
+	var nl *Limiter
+	for _, test := range []struct {
+		l    *Limiter
+		host string
+	}{{nl, "h"}, {l, ""}} {
+		calls = 0
+		if err := test.l.Do(ctx, test.host, func() error { calls++; return errFail }); err != errFail || calls != 1 {
+			t.Errorf("Do(%q) without limits: got %v after %d calls, want %v after 1", test.host, err, calls, errFail)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	l := &Limiter{Backoff: 100 * time.Millisecond}
+	for try := 0; try < 4; try++ {
+		nominal := l.Backoff << uint(try)
+		for i := 0; i < 20; i++ {
+			if d := l.backoff(try); d < nominal/2 || d > nominal*3/2 {
+				t.Errorf("backoff(%d): got %v, want between %v and %v", try, d, nominal/2, nominal*3/2)
+			}
+		}
+	}
+}
+
+func TestInterval(t *testing.T) {
+	ctx := context.Background()
+	const interval = 20 * time.Millisecond
+	l := &Limiter{Interval: interval}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Do(ctx, "h", func() error { return nil }); err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("Three operations took %v, want at least %v", elapsed, 2*interval)
+	}
+
+	// Hosts are paced separately.
+	start = time.Now()
+	if err := l.Do(ctx, "other", func() error { return nil }); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("Operation on another host took %v, want less than %v", elapsed, interval)
+	}
+}
+
+func TestMaxActive(t *testing.T) {
+	ctx := context.Background()
+	l := &Limiter{MaxActive: 2}
+	var mu sync.Mutex
+	active, peak := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Do(ctx, "h", func() error {
+				mu.Lock()
+				if active++; active > peak {
+					peak = active
+				}
+				mu.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mu.Lock()
+				active--
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if peak != 2 {
+		t.Errorf("Peak concurrent operations: got %d, want 2", peak)
+	}
+}
+
+// failServer returns a server that responds with status 503 to the first
+// fails requests, and then with status 200. It records the bodies of the
+// requests it receives.
+func failServer(fails int) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		bodies = append(bodies, string(data))
+		n := len(bodies)
+		mu.Unlock()
+		if n <= fails {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	return srv, &bodies
+}
+
+func TestTransport(t *testing.T) {
+	l := &Limiter{Retries: 3, Backoff: time.Millisecond}
+	cli := &http.Client{Transport: l.Transport(nil)}
+
+	tests := []struct {
+		desc   string
+		req    func(url string) *http.Request
+		status int
+		bodies string // as received by the server, space-separated
+	}{
+		{"GET", func(url string) *http.Request {
+			req, _ := http.NewRequest("GET", url, nil)
+			return req
+		}, http.StatusOK, "  "},
+
+		// A body that can be replayed is sent again with each retry.
+		{"POST with GetBody", func(url string) *http.Request {
+			req, _ := http.NewRequest("POST", url, strings.NewReader("data"))
+			return req
+		}, http.StatusOK, "data data data"},
+
+		// Otherwise the request is sent once, and its response reported.
+		{"POST without GetBody", func(url string) *http.Request {
+			req, _ := http.NewRequest("POST", url, ioutil.NopCloser(strings.NewReader("data")))
+			return req
+		}, http.StatusServiceUnavailable, "data"},
+	}
+	for _, test := range tests {
+		srv, bodies := failServer(2)
+		rsp, err := cli.Do(test.req(srv.URL))
+		srv.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.desc, err)
+			continue
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode != test.status {
+			t.Errorf("%s: got status %d, want %d", test.desc, rsp.StatusCode, test.status)
+		}
+		if got := strings.Join(*bodies, " "); got != test.bodies {
+			t.Errorf("%s: server got bodies %q, want %q", test.desc, got, test.bodies)
+		}
+	}
+
+	// When the retries are exhausted, the last response is reported.
+	srv, bodies := failServer(10)
+	defer srv.Close()
+	rsp, err := cli.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: unexpected error: %v", err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusServiceUnavailable || len(*bodies) != 4 {
+		t.Errorf("GET: got status %d after %d tries, want %d after 4",
+			rsp.StatusCode, len(*bodies), http.StatusServiceUnavailable)
+	}
+}
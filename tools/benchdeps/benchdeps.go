@@ -0,0 +1,227 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program benchdeps measures the throughput of scanning a synthetic corpus of
+// repositories, for performance regression testing.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/creachadair/repodeps/corpus"
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/local"
+	"github.com/creachadair/repodeps/siva"
+	"github.com/creachadair/repodeps/tools"
+	"github.com/creachadair/taskgroup"
+)
+
+var (
+	cfg = corpus.Defaults()
+
+	corpusDir   = flag.String("dir", "", "Generate the corpus in this directory and keep it (default is a temporary directory)")
+	useSiva     = flag.Bool("siva", false, "Pack the repositories into Siva archives and scan those")
+	numRuns     = flag.Int("runs", 3, "Number of times to scan the corpus")
+	concurrency = flag.Int("concurrency", runtime.NumCPU(), "Maximum concurrent workers")
+	doHash      = flag.Bool("sourcehash", false, "Record source file digests while scanning")
+	doRefs      = flag.Bool("refs", false, "Count references to imports while scanning")
+	doWrite     = flag.Bool("write", false, "Also add the results to a temporary graph store")
+	doJSON      = flag.Bool("json", false, "Print the result of each run as JSON")
+)
+
+func init() {
+	flag.IntVar(&cfg.Repos, "repos", cfg.Repos, "Number of repositories in the corpus")
+	flag.IntVar(&cfg.Packages, "packages", cfg.Packages, "Packages per repository")
+	flag.IntVar(&cfg.Files, "files", cfg.Files, "Source files per package")
+	flag.IntVar(&cfg.Funcs, "funcs", cfg.Funcs, "Functions per source file")
+	flag.IntVar(&cfg.Imports, "imports", cfg.Imports, "Imports per file from the same repository")
+	flag.IntVar(&cfg.External, "external", cfg.External, "Imports per file from other repositories")
+	flag.BoolVar(&cfg.Modules, "modules", cfg.Modules, "Give each repository a go.mod file")
+	flag.Int64Var(&cfg.Seed, "seed", cfg.Seed, "Seed for generating the corpus")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options]
+
+Generate a synthetic corpus of Git repositories (or, with -siva, Siva
+archives), scan it -runs times, and print the throughput of each run in
+repositories, packages, source files, and megabytes of source per second.
+The same options always generate the same corpus, so the results of separate
+runs of the program can be compared to detect performance regressions.
+
+With -write, each run also adds the packages to a new graph store, so that the
+cost of storage is included. With -json, each run is printed as a JSON object.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+// A result is the outcome of one scan of the corpus.
+type result struct {
+	Run      int           `json:"run"`
+	Elapsed  time.Duration `json:"elapsed"`
+	Repos    int           `json:"repos"`
+	Packages int           `json:"packages"`
+	Files    int           `json:"files"`
+	Bytes    int64         `json:"bytes"`
+}
+
+func (r result) rate(n float64) float64 { return n / r.Elapsed.Seconds() }
+
+func main() {
+	flag.Parse()
+	if *numRuns <= 0 {
+		log.Fatal("The -runs value must be positive")
+	}
+	ctx := context.Background()
+	dir := *corpusDir
+	if dir == "" {
+		tmp, err := ioutil.TempDir("", "benchdeps")
+		if err != nil {
+			log.Fatalf("Creating corpus directory: %v", err)
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatalf("Creating corpus directory: %v", err)
+	}
+
+	start := time.Now()
+	repos, err := corpus.Generate(ctx, dir, cfg)
+	if err != nil {
+		log.Fatalf("Generating corpus: %v", err)
+	}
+	inputs := make([]string, len(repos))
+	for i, r := range repos {
+		inputs[i] = r.Dir
+		if *useSiva {
+			inputs[i] = r.Dir + ".siva"
+			if err := corpus.PackSiva(ctx, r, inputs[i]); err != nil {
+				log.Fatalf("Packing %s: %v", r.Dir, err)
+			}
+		}
+	}
+	log.Printf("Generated %d repositories in %s [%v elapsed]",
+		len(repos), dir, time.Since(start).Round(time.Millisecond))
+
+	opts := &deps.Options{HashSourceFiles: *doHash, CountRefs: *doRefs}
+	var results []result
+	for i := 1; i <= *numRuns; i++ {
+		res, err := scan(ctx, inputs, opts)
+		if err != nil {
+			log.Fatalf("Run %d failed: %v", i, err)
+		}
+		res.Run = i
+		for _, r := range repos {
+			res.Files += r.Files
+			res.Bytes += r.Bytes
+		}
+		results = append(results, res)
+	}
+
+	if *doJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, res := range results {
+			if err := enc.Encode(res); err != nil {
+				log.Fatalf("Writing output: %v", err)
+			}
+		}
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 4, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "RUN\tELAPSED\tREPOS/S\tPKGS/S\tFILES/S\tMB/S\t\n")
+	for _, res := range results {
+		fmt.Fprintf(tw, "%d\t%v\t%.1f\t%.1f\t%.1f\t%.2f\t\n", res.Run, res.Elapsed.Round(time.Millisecond),
+			res.rate(float64(res.Repos)), res.rate(float64(res.Packages)),
+			res.rate(float64(res.Files)), res.rate(float64(res.Bytes))/1e6)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Elapsed < results[j].Elapsed })
+	med := results[len(results)/2]
+	fmt.Fprintf(tw, "median\t%v\t%.1f\t%.1f\t%.1f\t%.2f\t\n", med.Elapsed.Round(time.Millisecond),
+		med.rate(float64(med.Repos)), med.rate(float64(med.Packages)),
+		med.rate(float64(med.Files)), med.rate(float64(med.Bytes))/1e6)
+	tw.Flush()
+}
+
+// scan loads each of the inputs, and, with -write, adds the results to a new
+// graph store. The result counts the repositories and packages loaded.
+func scan(ctx context.Context, inputs []string, opts *deps.Options) (result, error) {
+	var g *graph.Graph
+	if *doWrite {
+		tmp, err := ioutil.TempDir("", "benchdeps-store")
+		if err != nil {
+			return result{}, err
+		}
+		defer os.RemoveAll(tmp)
+		st, c, err := tools.OpenGraph(tmp)
+		if err != nil {
+			return result{}, err
+		}
+		defer c.Close()
+		g = st
+	}
+
+	var mu sync.Mutex
+	var res result
+	start := time.Now()
+	tasks, run := taskgroup.New(nil).Limit(*concurrency)
+	for _, path := range inputs {
+		path := path
+		run(func() error {
+			var repos []*deps.Repo
+			var err error
+			if *useSiva {
+				repos, err = siva.Load(ctx, path, opts)
+			} else {
+				repos, err = local.Load(ctx, path, opts)
+			}
+			if err != nil {
+				return fmt.Errorf("scanning %s: %v", path, err)
+			}
+			var npkg int
+			for _, repo := range repos {
+				for _, pkg := range repo.Packages {
+					npkg++
+					if g != nil {
+						if err := g.Add(ctx, repo, pkg); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			res.Repos += len(repos)
+			res.Packages += npkg
+			return nil
+		})
+	}
+	err := tasks.Wait()
+	res.Elapsed = time.Since(start)
+	return res, err
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program buildlist simulates minimal version selection for a module in the
+// module graph, and prints the resulting build list.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/creachadair/repodeps/tools"
+)
+
+var storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] <module>[@<version>]...
+
+Simulate minimal version selection (MVS) for each specified root module using
+the module graph, and print the selected version of each module in its build
+list. If no version is given, the unversioned module (as scanned from a
+working tree) is used.
+
+Module versions that are required but missing from the graph are reported
+with a "(missing)" annotation; the build list may be incomplete if any are.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatalf("Usage: %s <module>[@<version>]...", filepath.Base(os.Args[0]))
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	for _, arg := range flag.Args() {
+		path, version := arg, ""
+		if i := strings.LastIndex(arg, "@"); i >= 0 {
+			path, version = arg[:i], arg[i+1:]
+		}
+		bl, err := g.BuildList(ctx, path, version)
+		if err != nil {
+			log.Fatalf("Build list for %q failed: %v", arg, err)
+		}
+		missing := make(map[string]bool)
+		for _, req := range bl.Missing {
+			missing[req.Path] = true
+		}
+		for _, req := range bl.Selected {
+			line := strings.TrimSpace(req.Path + " " + req.Version)
+			if missing[req.Path] {
+				line += " (missing)"
+			}
+			fmt.Println(line)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program clusterdeps partitions the packages of a dependency graph into
+// communities by label propagation, and reports the community of each package.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	useStdlib = flag.Bool("stdlib", false, "Include standard library packages")
+	maxRounds = flag.Int("rounds", 50, "Maximum number of propagation rounds")
+	randSeed  = flag.Int64("seed", 1, "Random seed for the visiting order")
+	doSummary = flag.Bool("summary", false, "Report community sizes rather than assignments")
+	minSize   = flag.Int("min", 1, "Omit communities with fewer than this many packages")
+)
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+	g, c, err := tools.OpenSnapshot(ctx, *storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	// Build an undirected adjacency list over node numbers.
+	var names []string
+	index := make(map[string]int)
+	node := func(pkg string) int {
+		id, ok := index[pkg]
+		if !ok {
+			id = len(names)
+			index[pkg] = id
+			names = append(names, pkg)
+		}
+		return id
+	}
+	var adj [][]int
+	link := func(a, b int) {
+		for len(adj) <= a || len(adj) <= b {
+			adj = append(adj, nil)
+		}
+		adj[a] = append(adj[a], b)
+		adj[b] = append(adj[b], a)
+	}
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		src := node(row.ImportPath)
+		for _, dep := range row.Directs {
+			if *useStdlib || !deps.IsStdlib(dep) {
+				link(src, node(dep))
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Scan failed: %v", err)
+	}
+	for len(adj) < len(names) {
+		adj = append(adj, nil)
+	}
+
+	labels := propagate(adj, *maxRounds, rand.New(rand.NewSource(*randSeed)))
+
+	// Number the communities in decreasing order of size.
+	members := make(map[int][]string)
+	for id, label := range labels {
+		members[label] = append(members[label], names[id])
+	}
+	var order []int
+	for label, pkgs := range members {
+		if len(pkgs) >= *minSize {
+			order = append(order, label)
+			sort.Strings(pkgs)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := members[order[i]], members[order[j]]
+		return len(a) > len(b) || (len(a) == len(b) && a[0] < b[0])
+	})
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	if *doSummary {
+		fmt.Fprint(tw, "COMMUNITY\tSIZE\tEXAMPLE\n")
+	}
+	for i, label := range order {
+		pkgs := members[label]
+		if *doSummary {
+			fmt.Fprintf(tw, "%d\t%d\t%s\n", i, len(pkgs), pkgs[0])
+			continue
+		}
+		for _, pkg := range pkgs {
+			fmt.Fprintf(tw, "%s\t%d\n", pkg, i)
+		}
+	}
+	tw.Flush()
+}
+
+// propagate assigns a community label to each node of the undirected graph
+// whose adjacency lists are given by adj. Each node starts with a label of its
+// own; in each round, the nodes are visited in random order and each adopts
+// the label most common among its neighbours. Propagation stops when a round
+// makes no changes, or after maxRounds rounds.
+func propagate(adj [][]int, maxRounds int, rng *rand.Rand) []int {
+	labels := make([]int, len(adj))
+	order := make([]int, len(adj))
+	for i := range labels {
+		labels[i] = i
+		order[i] = i
+	}
+	count := make(map[int]int)
+	for round := 0; round < maxRounds; round++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		changed := false
+		for _, id := range order {
+			if len(adj[id]) == 0 {
+				continue
+			}
+			for k := range count {
+				delete(count, k)
+			}
+			for _, nb := range adj[id] {
+				count[labels[nb]]++
+			}
+			// Prefer the current label among equally common ones, so that
+			// the assignment settles; otherwise take the smallest.
+			best, max := labels[id], count[labels[id]]
+			for label, n := range count {
+				if n > max || (n == max && best != labels[id] && label < best) {
+					best, max = label, n
+				}
+			}
+			if best != labels[id] {
+				labels[id] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return labels
+}
@@ -0,0 +1,266 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program crawldeps fetches and scans the repositories that define packages
+// listed as dependencies in a graph but not yet present in it, and adds their
+// packages to the graph.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/creachadair/repodeps/auth"
+	"github.com/creachadair/repodeps/crawl"
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/local"
+	"github.com/creachadair/repodeps/throttle"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath   = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	doList      = flag.Bool("list", false, "List the crawl queue without fetching anything")
+	numRounds   = flag.Int("rounds", 1, "Number of crawl rounds (0 to continue until no progress)")
+	maxRepos    = flag.Int("limit", 0, "Maximum repositories to fetch per round (0 for no limit)")
+	doReset     = flag.Bool("reset", false, "Discard the saved crawl queue before starting")
+	journalPath = flag.String("journal", "", "Record changes to the graph in this journal file")
+
+	hostInterval = flag.Duration("host-interval", 0, "Minimum interval between remote operations on each host")
+	hostActive   = flag.Int("host-limit", 4, "Maximum concurrent remote operations on each host (0 for no limit)")
+	numRetries   = flag.Int("retries", 0, "Number of times to retry a failed remote operation")
+	retryBackoff = flag.Duration("backoff", throttle.DefaultBackoff, "Delay before the first retry of a remote operation")
+	authConfig   = flag.String("auth", os.Getenv("REPODEPS_AUTH"), "Read credentials for remote hosts from this JSON file")
+	cacheDir     = flag.String("clone-cache", "", "Keep clones of fetched repositories in this directory")
+	cacheShare   = flag.Bool("clone-share", false, "Share objects among clones in the -clone-cache directory")
+
+	remote *throttle.Limiter
+	creds  *auth.Config
+	clones *local.CloneCache
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options]
+
+Find the packages that are imported by packages in the graph but are not
+themselves in the graph, determine the repositories that define them, and clone
+and scan those repositories, adding their packages to the graph.
+
+The crawl queue is saved in the graph, so that an interrupted crawl resumes
+where it left off. Repositories are fetched in decreasing order of priority,
+which is proportional to the number of importers of their missing packages,
+grows the longer a repository has been waiting, and falls with each previous
+attempt to fetch it. Use -reset to discard the saved queue.
+
+Each round may itself add new missing dependencies; use -rounds to repeat the
+process. A repository is fetched at most once per run.
+
+Clones and go-import metadata lookups are paced for each host, as they are by
+repodeps: see the -host-interval, -host-limit, -retries, and -backoff flags.
+Private repositories are cloned with the credentials given by -auth, in the
+same format as for repodeps. If -clone-cache is set, clones are kept there, so
+that a repository fetched again by a later crawl downloads only what changed.
+With -clone-share, the cached clones share one object store, so that a crawl
+reaching many forks of a project stores their common history only once.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+	jc, err := tools.OpenJournal(g, *journalPath)
+	if err != nil {
+		log.Fatalf("Opening journal: %v", err)
+	}
+	defer jc.Close()
+
+	remote = &throttle.Limiter{
+		Interval:  *hostInterval,
+		MaxActive: *hostActive,
+		Retries:   *numRetries,
+		Backoff:   *retryBackoff,
+	}
+	crawl.HTTPClient = &http.Client{Transport: remote.Transport(nil)}
+	creds, err = auth.FromEnv(*authConfig)
+	if err != nil {
+		log.Fatalf("Loading credentials: %v", err)
+	}
+	if *cacheDir != "" {
+		clones, err = local.NewCloneCache(*cacheDir)
+		if err != nil {
+			log.Fatalf("Opening clone cache: %v", err)
+		}
+		clones.Shared = *cacheShare
+	}
+
+	ctx := context.Background()
+	if *doReset {
+		var roots []string
+		if err := g.ScanPending(ctx, func(p *graph.Pending) error {
+			roots = append(roots, p.Root)
+			return nil
+		}); err != nil {
+			log.Fatalf("Reading crawl queue: %v", err)
+		}
+		for _, root := range roots {
+			if err := g.RemovePending(ctx, root); err != nil {
+				log.Fatalf("Resetting crawl queue: %v", err)
+			}
+		}
+	}
+	if *doList {
+		failed, err := crawl.Refresh(ctx, g)
+		if err != nil {
+			log.Fatalf("Updating crawl queue: %v", err)
+		}
+		now := time.Now()
+		queue, err := crawl.Queue(ctx, g, now)
+		if err != nil {
+			log.Fatalf("Reading crawl queue: %v", err)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprint(tw, "ROOT\tPRIORITY\tIMPORTERS\tPACKAGES\tATTEMPTS\tURL\n")
+		for _, p := range queue {
+			url := p.Url
+			if p.Error != "" {
+				msg := strings.SplitN(p.Error, "\n", 2)[0]
+				url += " (error: " + msg + ")"
+			}
+			fmt.Fprintf(tw, "%s\t%.1f\t%d\t%d\t%d\t%s\n",
+				p.Root, crawl.Priority(p, now), p.Importers, len(p.Packages), p.Attempts, url)
+		}
+		for _, t := range failed {
+			fmt.Fprintf(tw, "%s\t-\t%d\t%d\t-\terror: %v\n", t.Root, t.Importers, len(t.Packages), t.Err)
+		}
+		tw.Flush()
+		return
+	}
+
+	tried := make(map[string]bool)
+	for round := 1; *numRounds <= 0 || round <= *numRounds; round++ {
+		if _, err := crawl.Refresh(ctx, g); err != nil {
+			log.Fatalf("Updating crawl queue: %v", err)
+		}
+		queue, err := crawl.Queue(ctx, g, time.Now())
+		if err != nil {
+			log.Fatalf("Reading crawl queue: %v", err)
+		}
+		var fetched int
+		for _, p := range queue {
+			if tried[p.Root] {
+				continue
+			} else if *maxRepos > 0 && fetched >= *maxRepos {
+				break
+			}
+			tried[p.Root] = true
+			fetched++
+			log.Printf("Round %d: fetching %q for %d packages...", round, p.Url, len(p.Packages))
+			err := fetch(ctx, g, p.Url)
+			if err != nil {
+				log.Printf("Skipped %q:\n  %v", p.Url, err)
+			}
+			if err := crawl.Attempted(ctx, g, p.Root, err); err != nil {
+				log.Fatalf("Updating crawl queue: %v", err)
+			}
+		}
+		if fetched == 0 {
+			log.Printf("Crawl queue exhausted after %d rounds", round-1)
+			break
+		}
+	}
+}
+
+// fetch clones the repository at url, scans it, and adds its packages and
+// modules to g.
+func fetch(ctx context.Context, g *graph.Graph, url string) error {
+	dir, ref, err := clone(ctx, url)
+	if err != nil {
+		return err
+	}
+	if clones == nil {
+		defer os.RemoveAll(filepath.Dir(dir))
+	}
+
+	// Scanning a ref lays out the tree by the remote URL, so that packages
+	// outside any module get their import paths from the repository URL.
+	repos, err := local.Load(ctx, dir, &deps.Options{Ref: ref})
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		repo.From = url
+		repo.Schema = deps.SchemaVersion
+		deps.ClassifyImports(repo)
+		for _, pkg := range repo.Packages {
+			if err := g.Add(ctx, repo, pkg); err != nil {
+				return fmt.Errorf("adding package %q: %v", pkg.ImportPath, err)
+			}
+		}
+		for _, mod := range repo.Modules {
+			if err := g.AddModule(ctx, repo, mod); err != nil {
+				return fmt.Errorf("adding module %q: %v", mod.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// clone fetches the repository at url, into the clone cache if there is one or
+// otherwise into a new temporary directory, and returns the directory of the
+// clone and the ref to scan.
+func clone(ctx context.Context, url string) (dir, ref string, err error) {
+	cred := creds.Lookup(url)
+	if clones != nil {
+		err = remote.Do(ctx, throttle.Host(url), func() error {
+			var ferr error
+			dir, ref, ferr = clones.Fetch(ctx, url, "", cred)
+			return ferr
+		})
+		return dir, ref, err
+	}
+	tmp, err := ioutil.TempDir("", "crawldeps")
+	if err != nil {
+		return "", "", err
+	}
+	dir = filepath.Join(tmp, "repo")
+	if err := remote.Do(ctx, throttle.Host(url), func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		return local.CloneAuth(ctx, url, dir, "", cred)
+	}); err != nil {
+		os.RemoveAll(tmp)
+		return "", "", err
+	}
+	return dir, "HEAD", nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// writeEdgeList writes the edges of sg to w as lines of the form "src dst",
+// where src and dst are the indexes of the nodes, or "src dst weight" if the
+// -weighted flag is set. The node index, mapping each
+// index to its import path as lines of the form "index<TAB>path", is written
+// to the file named by the -nodes flag.
+func writeEdgeList(w io.Writer, sg *subgraph) error {
+	if *nodesPath == "" {
+		return errors.New("the edgelist format requires a -nodes file")
+	}
+	f, err := os.Create(*nodesPath)
+	if err != nil {
+		return err
+	}
+	nw := bufio.NewWriter(f)
+	for i, n := range sg.Nodes {
+		fmt.Fprintf(nw, "%d\t%s\n", i, n.ImportPath)
+	}
+	if err := nw.Flush(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+
+	ew := bufio.NewWriter(w)
+	for i, e := range sg.Edges {
+		if *weighted {
+			fmt.Fprintf(ew, "%d %d %d\n", e[0], e[1], sg.Weights[i])
+		} else {
+			fmt.Fprintf(ew, "%d %d\n", e[0], e[1])
+		}
+	}
+	return ew.Flush()
+}
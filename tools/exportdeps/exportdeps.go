@@ -0,0 +1,241 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program exportdeps exports a subgraph of a dependency graph for use by other
+// tools. The subgraph includes the packages named on the command line and
+// their dependencies, up to a given depth, or the whole graph if no packages
+// are named.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	format    = flag.String("format", "html", `Output format ("html", "edgelist", or "parquet")`)
+	maxDepth  = flag.Int("depth", 2, "Maximum number of steps from the roots to include (0 for no limit)")
+	outPath   = flag.String("o", "", "Write output to this file (default stdout)")
+	nodesPath = flag.String("nodes", "", "Write the node index for -format=edgelist or parquet to this file")
+	direction = flag.String("direction", "imports", `Follow edges to "imports", "importers", or "both"`)
+	langs     = flag.String("lang", "", "Include only packages of these comma-separated languages")
+	labels    = flag.String("label", "", "Start only from packages with these comma-separated key=value labels")
+	weighted  = flag.Bool("weighted", false, "Include edge weights in -format=edgelist")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] [import-path...]
+
+Export the subgraph of the dependency graph reachable from the specified
+packages, following direct dependencies up to -depth steps away. With
+-direction=importers, the packages that reach the specified packages within
+-depth steps are exported instead, and with -direction=both, the union of the
+two. The subgraph includes every edge among its packages. If no packages are
+specified, the whole graph is exported.
+
+With -lang, packages of other languages (such as "go", "npm", "python",
+"cargo", or "jvm") and the edges that lead to them are omitted. With -label,
+only packages having the given labels are used as roots when none are
+specified, so that the export covers what one team or tier depends on.
+
+Formats:
+  html       a self-contained HTML page with an interactive force-directed layout
+  edgelist   one "src dst" line per edge, with nodes numbered from 0, as used
+             by graph embedding tools such as node2vec; the mapping from node
+             numbers to import paths is written to the -nodes file; with
+             -weighted, each line is "src dst weight", where the weight is the
+             number of references the source makes to the target, if known
+  parquet    a Parquet table of edges, with the source and target as node
+             numbers and import paths and the edge weight, and a table of
+             nodes with their import paths, repositories, and other fields
+             written to the -nodes file; for use with DuckDB, Spark, Athena,
+             and other tools that read Parquet directly
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+// A subgraph is a collection of packages and the edges among them.
+type subgraph struct {
+	Nodes   []*node
+	Edges   [][2]int // pairs of indexes into Nodes
+	Weights []int64  // parallel to Edges; reference counts, or 0 if unknown
+}
+
+// addEdge adds an edge from node i to node j, the k-th direct dependency of
+// row, to sg.
+func (sg *subgraph) addEdge(i, j, k int, row *graph.Row) {
+	var w int64
+	if len(row.Refs) == len(row.Directs) {
+		w = row.Refs[k]
+	}
+	sg.Edges = append(sg.Edges, [2]int{i, j})
+	sg.Weights = append(sg.Weights, w)
+}
+
+// A node is a single package in a subgraph.
+type node struct {
+	ImportPath string
+	Repository string
+	Depth      int        // the distance from the nearest root
+	Missing    bool       // no row for this package was found in the graph
+	Row        *graph.Row // nil if Missing
+}
+
+// exporters maps format names to functions that write a subgraph.
+var exporters = map[string]func(io.Writer, *subgraph) error{
+	"html":     writeHTML,
+	"edgelist": writeEdgeList,
+	"parquet":  writeParquet,
+}
+
+func main() {
+	flag.Parse()
+	export, ok := exporters[*format]
+	if !ok {
+		log.Fatalf("Unknown format %q", *format)
+	}
+	switch *direction {
+	case "imports", "importers", "both":
+	default:
+		log.Fatalf("Invalid -direction: %q", *direction)
+	}
+	ctx := context.Background()
+	g, c, err := tools.OpenSnapshot(ctx, *storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	keep := tools.ParseLanguages(*langs)
+	want, err := tools.ParseLabels(*labels)
+	if err != nil {
+		log.Fatalf("Invalid -label: %v", err)
+	}
+	roots := flag.Args()
+	if len(roots) == 0 {
+		if err := g.Scan(ctx, "", func(row *graph.Row) error {
+			if keep.Row(row) && want.Row(row) {
+				roots = append(roots, row.ImportPath)
+			}
+			return nil
+		}); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+	}
+	sg, err := loadSubgraph(ctx, g, roots, *maxDepth, *direction, keep)
+	if err != nil {
+		log.Fatalf("Loading subgraph: %v", err)
+	}
+	out := os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatalf("Creating output: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := export(out, sg); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+}
+
+// loadSubgraph returns the subgraph of g reachable from roots by following at
+// most depth edges in the given direction: "imports" follows edges from each
+// package to its dependencies, "importers" follows them backward, and "both"
+// does both. If depth ≤ 0, there is no limit. Packages whose languages are not
+// in keep are not included.
+func loadSubgraph(ctx context.Context, g *graph.Graph, roots []string, depth int, dir string, keep tools.LanguageSet) (*subgraph, error) {
+	var rdeps map[string][]string // :: import path → importers
+	if dir != "imports" {
+		rdeps = make(map[string][]string)
+		if err := g.Scan(ctx, "", func(row *graph.Row) error {
+			if keep.Row(row) {
+				for _, dep := range row.Directs {
+					rdeps[dep] = append(rdeps[dep], row.ImportPath)
+				}
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	sg := new(subgraph)
+	index := make(map[string]int) // :: import path → index in sg.Nodes
+	add := func(ipath string, depth int) {
+		if _, ok := index[ipath]; !ok {
+			index[ipath] = len(sg.Nodes)
+			sg.Nodes = append(sg.Nodes, &node{ImportPath: ipath, Depth: depth})
+		}
+	}
+	for _, root := range roots {
+		add(root, 0)
+	}
+	var rows []*graph.Row // parallel to sg.Nodes; nil for missing packages
+	for i := 0; i < len(sg.Nodes); i++ {
+		cur := sg.Nodes[i]
+		row, err := g.Row(ctx, cur.ImportPath)
+		if err == graph.ErrNotFound {
+			cur.Missing = true
+			row = nil
+		} else if err != nil {
+			return nil, err
+		} else {
+			cur.Repository = row.Repository
+			cur.Row = row
+		}
+		rows = append(rows, row)
+		if depth > 0 && cur.Depth >= depth {
+			continue // do not expand beyond the limit
+		}
+		if row != nil && dir != "importers" {
+			for _, dep := range row.Directs {
+				if keep.Path(dep) {
+					add(dep, cur.Depth+1)
+				}
+			}
+		}
+		for _, pkg := range rdeps[cur.ImportPath] {
+			add(pkg, cur.Depth+1)
+		}
+	}
+
+	// Include all the edges among the nodes of the subgraph, including those
+	// among the unexpanded nodes at the depth limit.
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		for k, dep := range row.Directs {
+			if j, ok := index[dep]; ok {
+				sg.addEdge(i, j, k, row)
+			}
+		}
+	}
+	return sg, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+)
+
+// writeHTML writes sg to w as a self-contained HTML page that renders the
+// subgraph with an interactive force-directed layout. The page has no
+// external dependencies, so it can be shared as a single file.
+func writeHTML(w io.Writer, sg *subgraph) error {
+	type jnode struct {
+		ID      string `json:"id"`
+		Repo    string `json:"repo,omitempty"`
+		Depth   int    `json:"depth"`
+		Missing bool   `json:"missing,omitempty"`
+	}
+	data := struct {
+		Nodes []jnode  `json:"nodes"`
+		Links [][2]int `json:"links"`
+	}{Links: sg.Edges}
+	for _, n := range sg.Nodes {
+		data.Nodes = append(data.Nodes, jnode{
+			ID:      n.ImportPath,
+			Repo:    n.Repository,
+			Depth:   n.Depth,
+			Missing: n.Missing,
+		})
+	}
+	if data.Links == nil {
+		data.Links = [][2]int{}
+	}
+	bits, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var title string
+	if len(sg.Nodes) != 0 {
+		title = sg.Nodes[0].ImportPath
+	}
+	return htmlPage.Execute(w, struct {
+		Title string
+		Graph template.JS
+	}{Title: title, Graph: template.JS(bits)})
+}
+
+// htmlPage renders a subgraph using a simple force simulation: all pairs of
+// nodes repel, links attract, and every node is pulled gently toward the
+// center. The simulation cools over time, and warms again while the user
+// drags nodes around.
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Dependencies of {{.Title}}</title>
+<style>
+  html, body { margin: 0; height: 100%; font-family: sans-serif; }
+  svg { width: 100%; height: 100%; cursor: move; }
+  line { stroke: #999; stroke-opacity: 0.6; }
+  circle { stroke: #fff; stroke-width: 1.5px; cursor: pointer; }
+  text { font-size: 10px; pointer-events: none; }
+  #info { position: absolute; top: 8px; left: 8px; background: #fff8;
+          padding: 4px 8px; font-size: 12px; }
+</style>
+</head>
+<body>
+<div id="info">Dependencies of <b>{{.Title}}</b>. Drag to move nodes, scroll to zoom.</div>
+<svg id="view"><defs><marker id="arrow" viewBox="0 -4 8 8" refX="14" markerWidth="6"
+  markerHeight="6" orient="auto"><path d="M0,-4L8,0L0,4" fill="#999"/></marker></defs>
+<g id="world"></g></svg>
+<script>
+"use strict";
+const graph = {{.Graph}};
+const svgNS = "http://www.w3.org/2000/svg";
+const svg = document.getElementById("view"), world = document.getElementById("world");
+const colors = ["#d62728", "#1f77b4", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b"];
+const W = () => svg.clientWidth, H = () => svg.clientHeight;
+let scale = 1, tx = 0, ty = 0;
+
+function el(name, attrs, parent) {
+  const e = document.createElementNS(svgNS, name);
+  for (const k in attrs) e.setAttribute(k, attrs[k]);
+  parent.appendChild(e);
+  return e;
+}
+
+const nodes = graph.nodes.map((n, i) => Object.assign(n, {
+  x: W()/2 + 100*Math.cos(i), y: H()/2 + 100*Math.sin(i), vx: 0, vy: 0,
+}));
+const links = graph.links.map(([s, t]) => ({
+  s: nodes[s], t: nodes[t],
+  e: el("line", {"marker-end": "url(#arrow)"}, world),
+}));
+for (const n of nodes) {
+  n.c = el("circle", {r: n.depth === 0 ? 8 : 5,
+    fill: n.missing ? "#ccc" : colors[n.depth % colors.length]}, world);
+  el("title", {}, n.c).textContent = n.id + (n.repo ? "\n" + n.repo : "");
+  n.t = el("text", {dx: 8, dy: 3}, world);
+  n.t.textContent = n.id;
+}
+
+let alpha = 1;
+function tick() {
+  for (let i = 0; i < nodes.length; i++) {
+    for (let j = i + 1; j < nodes.length; j++) {
+      const a = nodes[i], b = nodes[j];
+      let dx = b.x - a.x, dy = b.y - a.y, d2 = dx*dx + dy*dy || 0.01;
+      const f = 400 * alpha / d2;
+      a.vx -= dx*f; a.vy -= dy*f; b.vx += dx*f; b.vy += dy*f;
+    }
+  }
+  for (const l of links) {
+    const dx = l.t.x - l.s.x, dy = l.t.y - l.s.y;
+    const d = Math.sqrt(dx*dx + dy*dy) || 0.01, f = (d - 60) / d * 0.05 * alpha;
+    l.s.vx += dx*f; l.s.vy += dy*f; l.t.vx -= dx*f; l.t.vy -= dy*f;
+  }
+  for (const n of nodes) {
+    n.vx += (W()/2 - n.x) * 0.005 * alpha;
+    n.vy += (H()/2 - n.y) * 0.005 * alpha;
+    if (n !== dragging) { n.x += n.vx; n.y += n.vy; }
+    n.vx *= 0.6; n.vy *= 0.6;
+  }
+  alpha = Math.max(alpha * 0.995, 0.02);
+  draw();
+  requestAnimationFrame(tick);
+}
+
+function draw() {
+  world.setAttribute("transform", "translate(" + tx + "," + ty + ") scale(" + scale + ")");
+  for (const l of links) {
+    l.e.setAttribute("x1", l.s.x); l.e.setAttribute("y1", l.s.y);
+    l.e.setAttribute("x2", l.t.x); l.e.setAttribute("y2", l.t.y);
+  }
+  for (const n of nodes) {
+    n.c.setAttribute("cx", n.x); n.c.setAttribute("cy", n.y);
+    n.t.setAttribute("x", n.x); n.t.setAttribute("y", n.y);
+  }
+}
+
+let dragging = null, panning = null;
+const toWorld = (e) => [(e.clientX - tx) / scale, (e.clientY - ty) / scale];
+svg.addEventListener("mousedown", (e) => {
+  dragging = nodes.find((n) => n.c === e.target) || null;
+  if (!dragging) panning = [e.clientX - tx, e.clientY - ty];
+  alpha = Math.max(alpha, 0.3);
+});
+window.addEventListener("mousemove", (e) => {
+  if (dragging) [dragging.x, dragging.y] = toWorld(e);
+  else if (panning) { tx = e.clientX - panning[0]; ty = e.clientY - panning[1]; }
+});
+window.addEventListener("mouseup", () => { dragging = panning = null; });
+svg.addEventListener("wheel", (e) => {
+  e.preventDefault();
+  const k = Math.exp(-e.deltaY * 0.001), [wx, wy] = toWorld(e);
+  scale *= k;
+  tx = e.clientX - wx * scale; ty = e.clientY - wy * scale;
+}, {passive: false});
+requestAnimationFrame(tick);
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,400 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/creachadair/repodeps/graph"
+)
+
+// writeParquet writes the edges of sg to w as a Parquet table, and the nodes
+// of sg as another to the file named by the -nodes flag. The tables can be
+// joined on the node index:
+//
+//	nodes: id, import_path, name, repository, module, language, depth,
+//	       missing, num_imports, uses_unsafe, uses_reflect, uses_syscall
+//	edges: src, dst, importer, imported, weight
+//
+// The weight of an edge is the number of references the source makes to the
+// target, or 0 if that is not known.
+func writeParquet(w io.Writer, sg *subgraph) error {
+	if *nodesPath == "" {
+		return errors.New("the parquet format requires a -nodes file")
+	}
+	nodes := pqTable{
+		int64Column("id"),
+		stringColumn("import_path"),
+		stringColumn("name"),
+		stringColumn("repository"),
+		stringColumn("module"),
+		stringColumn("language"),
+		int64Column("depth"),
+		boolColumn("missing"),
+		int64Column("num_imports"),
+		boolColumn("uses_unsafe"),
+		boolColumn("uses_reflect"),
+		boolColumn("uses_syscall"),
+	}
+	for i, node := range sg.Nodes {
+		row := node.Row
+		if row == nil {
+			row = new(graph.Row)
+		}
+		nodes.addRow(i, node.ImportPath, row.Name, node.Repository, row.Module,
+			row.Language, node.Depth, node.Missing, len(row.Directs),
+			row.UsesUnsafe, row.UsesReflect, row.UsesSyscall)
+	}
+	f, err := os.Create(*nodesPath)
+	if err != nil {
+		return err
+	}
+	nw := bufio.NewWriter(f)
+	if err := nodes.write(nw); err != nil {
+		f.Close()
+		return err
+	} else if err := nw.Flush(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+
+	edges := pqTable{
+		int64Column("src"),
+		int64Column("dst"),
+		stringColumn("importer"),
+		stringColumn("imported"),
+		int64Column("weight"),
+	}
+	for i, e := range sg.Edges {
+		edges.addRow(e[0], e[1], sg.Nodes[e[0]].ImportPath, sg.Nodes[e[1]].ImportPath, sg.Weights[i])
+	}
+	ew := bufio.NewWriter(w)
+	if err := edges.write(ew); err != nil {
+		return err
+	}
+	return ew.Flush()
+}
+
+// The writer below produces the subset of the Parquet format needed for flat
+// tables: every column is required and PLAIN-encoded without compression,
+// and each column chunk of a row group is a single data page. The metadata
+// structures are written with the Thrift compact protocol, as the format
+// specifies; see https://github.com/apache/parquet-format.
+
+// Physical types, encodings, and other enumerations from parquet.thrift.
+const (
+	pqBoolean   = 0
+	pqInt64     = 2
+	pqByteArray = 6
+
+	pqRequired   = 0 // FieldRepetitionType
+	pqUTF8       = 0 // ConvertedType
+	pqPlain      = 0 // Encoding
+	pqDataPage   = 0 // PageType
+	pqRLE        = 3 // Encoding, for the (absent) levels
+	pqNoCompress = 0 // CompressionCodec
+)
+
+// rowGroupSize is the maximum number of rows in a row group.
+const rowGroupSize = 1 << 17
+
+// A pqTable is a table of values to be written as a Parquet file.
+type pqTable []*pqColumn
+
+// addRow adds a row to t, with one value for each column in order. Each value
+// must be an int or int64 for an integer column, a string for a string
+// column, or a bool for a Boolean column.
+func (t pqTable) addRow(vals ...interface{}) {
+	for i, v := range vals {
+		c := t[i]
+		switch v := v.(type) {
+		case int:
+			c.ints = append(c.ints, int64(v))
+		case int64:
+			c.ints = append(c.ints, v)
+		case string:
+			c.strs = append(c.strs, v)
+		case bool:
+			c.bools = append(c.bools, v)
+		default:
+			panic(fmt.Sprintf("invalid value for column %q: %T", c.name, v))
+		}
+	}
+}
+
+// A pqColumn is a column of a pqTable. Only the value slice for the type of
+// the column is populated.
+type pqColumn struct {
+	name  string
+	typ   int32
+	ints  []int64
+	strs  []string
+	bools []bool
+}
+
+func int64Column(name string) *pqColumn  { return &pqColumn{name: name, typ: pqInt64} }
+func stringColumn(name string) *pqColumn { return &pqColumn{name: name, typ: pqByteArray} }
+func boolColumn(name string) *pqColumn   { return &pqColumn{name: name, typ: pqBoolean} }
+
+func (c *pqColumn) len() int { return len(c.ints) + len(c.strs) + len(c.bools) }
+
+// encode returns the PLAIN encoding of rows [lo, hi) of c.
+func (c *pqColumn) encode(lo, hi int) []byte {
+	var buf bytes.Buffer
+	switch c.typ {
+	case pqInt64:
+		var b [8]byte
+		for _, v := range c.ints[lo:hi] {
+			binary.LittleEndian.PutUint64(b[:], uint64(v))
+			buf.Write(b[:])
+		}
+	case pqByteArray:
+		var b [4]byte
+		for _, v := range c.strs[lo:hi] {
+			binary.LittleEndian.PutUint32(b[:], uint32(len(v)))
+			buf.Write(b[:])
+			buf.WriteString(v)
+		}
+	case pqBoolean:
+		bits := make([]byte, (hi-lo+7)/8)
+		for i, v := range c.bools[lo:hi] {
+			if v {
+				bits[i/8] |= 1 << uint(i%8)
+			}
+		}
+		buf.Write(bits)
+	}
+	return buf.Bytes()
+}
+
+// A pqChunk records where a column chunk was written.
+type pqChunk struct {
+	offset int64 // of the page header
+	size   int64 // of the page header and data
+	values int64
+}
+
+// write writes t to w as a Parquet file.
+func (t pqTable) write(w io.Writer) error {
+	cols, nrows := t, t[0].len()
+	cw := &countingWriter{w: w}
+	cw.Write([]byte("PAR1"))
+
+	var groups [][]pqChunk
+	for lo := 0; lo < nrows; lo += rowGroupSize {
+		hi := lo + rowGroupSize
+		if hi > nrows {
+			hi = nrows
+		}
+		var chunks []pqChunk
+		for _, c := range cols {
+			data := c.encode(lo, hi)
+			var hdr thriftWriter
+			hdr.i32(1, pqDataPage)
+			hdr.i32(2, int32(len(data)))
+			hdr.i32(3, int32(len(data)))
+			hdr.beginStruct(5) // DataPageHeader
+			hdr.i32(1, int32(hi-lo))
+			hdr.i32(2, pqPlain)
+			hdr.i32(3, pqRLE)
+			hdr.i32(4, pqRLE)
+			hdr.endStruct()
+			hdr.stop()
+
+			chunk := pqChunk{offset: cw.n, values: int64(hi - lo)}
+			cw.Write(hdr.buf.Bytes())
+			cw.Write(data)
+			chunk.size = cw.n - chunk.offset
+			chunks = append(chunks, chunk)
+		}
+		groups = append(groups, chunks)
+	}
+
+	var meta thriftWriter
+	meta.i32(1, 1) // version
+	meta.beginList(2, thriftStruct, len(cols)+1)
+	meta.str(4, "schema") // the root of the schema tree
+	meta.i32(5, int32(len(cols)))
+	meta.stop()
+	for _, c := range cols {
+		meta.i32(1, c.typ)
+		meta.i32(3, pqRequired)
+		meta.str(4, c.name)
+		if c.typ == pqByteArray {
+			meta.i32(6, pqUTF8)
+		}
+		meta.stop()
+	}
+	meta.endList()
+	meta.i64(3, int64(nrows))
+	meta.beginList(4, thriftStruct, len(groups))
+	for g, chunks := range groups {
+		var total int64
+		meta.beginList(1, thriftStruct, len(chunks))
+		for i, chunk := range chunks {
+			c := cols[i]
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3) // ColumnMetaData
+			meta.i32(1, c.typ)
+			meta.beginList(2, thriftI32, 1)
+			meta.elemI32(pqPlain)
+			meta.endList()
+			meta.beginList(3, thriftBinary, 1)
+			meta.elemStr(c.name)
+			meta.endList()
+			meta.i32(4, pqNoCompress)
+			meta.i64(5, chunk.values)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.endStruct()
+			meta.stop()
+			total += chunk.size
+		}
+		meta.endList()
+		meta.i64(2, total)
+		rows := int64(rowGroupSize)
+		if g == len(groups)-1 {
+			rows = int64(nrows - g*rowGroupSize)
+		}
+		meta.i64(3, rows)
+		meta.stop()
+	}
+	meta.endList()
+	meta.str(6, "repodeps exportdeps")
+	meta.stop()
+
+	cw.Write(meta.buf.Bytes())
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(meta.buf.Len()))
+	cw.Write(n[:])
+	cw.Write([]byte("PAR1"))
+	return cw.err
+}
+
+// countingWriter counts the bytes written to w, and records the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(data []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(data)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// Thrift compact protocol type codes.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// A thriftWriter encodes structures in the Thrift compact protocol. Fields
+// are written in order of increasing ID within each structure, and each
+// structure is terminated by stop. The elements of a list of structures are
+// written as their fields followed by stop.
+type thriftWriter struct {
+	buf   bytes.Buffer
+	last  int16   // the ID of the previous field of the current structure
+	outer []int16 // the saved field IDs of enclosing structures
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if d := id - t.last; d > 0 && d <= 15 {
+		t.buf.WriteByte(byte(d)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutVarint(b[:], v)]) // zigzag, as compact requires
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (t *thriftWriter) i32(id int16, v int32)  { t.field(id, thriftI32); t.varint(int64(v)) }
+func (t *thriftWriter) i64(id int16, v int64)  { t.field(id, thriftI64); t.varint(v) }
+func (t *thriftWriter) str(id int16, s string) { t.field(id, thriftBinary); t.elemStr(s) }
+
+func (t *thriftWriter) elemI32(v int32) { t.varint(int64(v)) }
+
+func (t *thriftWriter) elemStr(s string) {
+	t.uvarint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+// beginStruct begins a structure-valued field. Its fields are written next,
+// followed by endStruct.
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.outer = append(t.outer, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.last = t.outer[len(t.outer)-1]
+	t.outer = t.outer[:len(t.outer)-1]
+}
+
+// beginList begins a list-valued field of n elements of type typ. The
+// elements are written next, followed by endList.
+func (t *thriftWriter) beginList(id int16, typ byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | typ)
+	} else {
+		t.buf.WriteByte(0xf0 | typ)
+		t.uvarint(uint64(n))
+	}
+	t.outer = append(t.outer, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endList() {
+	t.last = t.outer[len(t.outer)-1]
+	t.outer = t.outer[:len(t.outer)-1]
+}
+
+// stop ends the current structure. Within a list of structures, it also
+// resets the field IDs for the next element.
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+	t.last = 0
+}
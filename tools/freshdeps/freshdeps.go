@@ -0,0 +1,167 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program freshdeps compares the module requirements recorded in a graph
+// database with the latest versions published to a module proxy, and reports
+// how far behind the requiring modules are for each dependency.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/modproxy"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath  = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	proxyURL   = flag.String("proxy", modproxy.DefaultURL, "Base URL of the module proxy")
+	cacheDir   = flag.String("cache", "", "Cache proxy results in this directory")
+	doIndirect = flag.Bool("indirect", false, "Include requirements marked as indirect")
+	doVerbose  = flag.Bool("v", false, "List the requiring modules of each dependency")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] [module-prefix...]
+
+Compare the requirements of the modules in the graph whose paths have one of
+the given prefixes (default all) with the versions of the required modules
+published to the module proxy. For each required module, report its latest
+version, the number of modules that require it, how many of those are behind
+the latest version, and the most release versions any of them is behind.
+With -v, each requiring module is listed with its pinned version.
+
+Dependencies are listed in decreasing order of the number of modules behind.
+Modules the proxy does not know, such as private modules, are reported with
+latest version "?".
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+// A pin records that a module requires a dependency at a version.
+type pin struct {
+	Module  string // the requiring module, as path@version
+	Version string // the required version
+	Behind  int    // release versions newer than Version
+}
+
+// A dependency summarizes the pins of one required module.
+type dependency struct {
+	Path   string
+	Latest string
+	Pins   []pin
+
+	numBehind int // pins behind Latest
+	maxBehind int // largest Behind of any pin
+}
+
+func main() {
+	flag.Parse()
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	pfxs := flag.Args()
+	if len(pfxs) == 0 {
+		pfxs = append(pfxs, "") // check all
+	}
+	byPath := make(map[string]*dependency)
+	for _, pfx := range pfxs {
+		if err := g.ScanModules(ctx, pfx, func(mod *graph.Module) error {
+			self := mod.Path
+			if mod.Version != "" {
+				self += "@" + mod.Version
+			}
+			for _, req := range mod.Requires {
+				if req.Indirect && !*doIndirect {
+					continue
+				}
+				dep, ok := byPath[req.Path]
+				if !ok {
+					dep = &dependency{Path: req.Path}
+					byPath[req.Path] = dep
+				}
+				dep.Pins = append(dep.Pins, pin{Module: self, Version: req.Version})
+			}
+			return nil
+		}); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+	}
+
+	cli := &modproxy.Client{URL: *proxyURL, CacheDir: *cacheDir}
+	var all []*dependency
+	for _, dep := range byPath {
+		vs, err := cli.Versions(ctx, dep.Path)
+		if err == modproxy.ErrNotFound {
+			dep.Latest = "?"
+		} else if err != nil {
+			log.Printf("Checking %q: %v", dep.Path, err)
+			dep.Latest = "?"
+		} else {
+			dep.Latest = modproxy.Latest(vs)
+			for i, p := range dep.Pins {
+				n := modproxy.Behind(vs, p.Version)
+				dep.Pins[i].Behind = n
+				if n > 0 {
+					dep.numBehind++
+				}
+				if n > dep.maxBehind {
+					dep.maxBehind = n
+				}
+			}
+		}
+		sort.Slice(dep.Pins, func(i, j int) bool {
+			pi, pj := dep.Pins[i], dep.Pins[j]
+			return pi.Behind > pj.Behind || (pi.Behind == pj.Behind && pi.Module < pj.Module)
+		})
+		all = append(all, dep)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		di, dj := all[i], all[j]
+		if di.numBehind != dj.numBehind {
+			return di.numBehind > dj.numBehind
+		} else if di.maxBehind != dj.maxBehind {
+			return di.maxBehind > dj.maxBehind
+		}
+		return di.Path < dj.Path
+	})
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "DEPENDENCY\tLATEST\tREQUIRED BY\tBEHIND\tMAX BEHIND\n")
+	for _, dep := range all {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n", dep.Path, dep.Latest, len(dep.Pins), dep.numBehind, dep.maxBehind)
+		if *doVerbose {
+			for _, p := range dep.Pins {
+				fmt.Fprintf(tw, "  %s\t%s\t\t%d\t\n", p.Module, p.Version, p.Behind)
+			}
+		}
+	}
+	tw.Flush()
+}
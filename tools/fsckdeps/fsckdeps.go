@@ -0,0 +1,160 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program fsckdeps checks a graph database for integrity problems, and
+// optionally repairs them.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath    = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	doRepair     = flag.Bool("repair", false, "Repair or remove damaged rows")
+	doQuarantine = flag.Bool("quarantine", false, "Quarantine damaged rows rather than removing them")
+	doPrune      = flag.Bool("prune", false, "Remove dangling edges from rows")
+	useStdlib    = flag.Bool("stdlib", false, "Report dangling edges to standard library packages")
+	doVerbose    = flag.Bool("v", false, "List each row with dangling edges")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options]
+
+Check the rows of a graph database for integrity problems:
+
+  unreadable       the row could not be loaded or decoded
+  invalid key      the row is stored under a key that is not an import path
+  key mismatch     the row is stored under a key other than its import path
+  dangling edges   the row lists dependencies that have no rows
+
+By default problems are only reported. With -repair, a mismatched row is moved
+to its import path (unless a row is already there), and other damaged rows are
+removed. With -quarantine, damaged rows are instead set aside in a separate
+table where they are not visible to queries. With -prune, dangling edges are
+removed from the rows that contain them.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	counts := make(map[graph.ProblemKind]int)
+	handled := make(map[string]bool) // rows moved or removed
+	if err := g.Check(ctx, func(p *graph.Problem) error {
+		if p.Kind == graph.Dangling && !*useStdlib {
+			var keep []string
+			for _, dep := range p.Missing {
+				if !deps.IsStdlib(dep) {
+					keep = append(keep, dep)
+				}
+			}
+			if p.Missing = keep; len(keep) == 0 {
+				return nil
+			}
+		}
+		counts[p.Kind]++
+		if p.Kind != graph.Dangling || *doVerbose {
+			fmt.Println(p)
+		}
+		if handled[p.Key] {
+			return nil
+		}
+		switch {
+		case p.Kind == graph.Dangling:
+			if *doPrune && p.Row.ImportPath == p.Key {
+				return prune(ctx, g, p.Row, p.Missing)
+			}
+		case *doQuarantine:
+			qkey, err := g.Quarantine(ctx, p.Key)
+			if err != nil {
+				return err
+			}
+			log.Printf("Quarantined %q as %q", p.Key, qkey)
+			handled[p.Key] = true
+		case *doRepair:
+			handled[p.Key] = true
+			if p.Kind == graph.KeyMismatch {
+				if _, err := g.Row(ctx, p.Row.ImportPath); err == graph.ErrNotFound {
+					if err := g.Put(ctx, p.Row); err != nil {
+						return err
+					}
+					log.Printf("Moved %q to %q", p.Key, p.Row.ImportPath)
+				} else if err != nil {
+					return err
+				}
+			}
+			if err := g.Remove(ctx, p.Key); err != nil {
+				return err
+			}
+			log.Printf("Removed %q", p.Key)
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Check failed: %v", err)
+	}
+	for _, kind := range []graph.ProblemKind{graph.Unreadable, graph.InvalidKey, graph.KeyMismatch, graph.Dangling} {
+		fmt.Printf("%-16s %d\n", kind.String()+":", counts[kind])
+	}
+}
+
+// prune removes the specified missing dependencies from row and stores it.
+// The entries of the fields parallel to Directs are removed along with them.
+func prune(ctx context.Context, g *graph.Graph, row *graph.Row, missing []string) error {
+	drop := make(map[string]bool)
+	for _, dep := range missing {
+		drop[dep] = true
+	}
+	var directs []string
+	var classes []graph.ImportClass
+	var refs, symbols []int64
+	for i, dep := range row.Directs {
+		if drop[dep] {
+			continue
+		}
+		directs = append(directs, dep)
+		if i < len(row.Classes) {
+			classes = append(classes, row.Classes[i])
+		}
+		if i < len(row.Refs) {
+			refs = append(refs, row.Refs[i])
+		}
+		if i < len(row.Symbols) {
+			symbols = append(symbols, row.Symbols[i])
+		}
+	}
+	row.Directs, row.Classes = directs, classes
+	row.Refs, row.Symbols = refs, symbols
+	return g.Put(ctx, row)
+}
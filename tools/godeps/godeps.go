@@ -0,0 +1,182 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program godeps reports which packages in a graph would fail to build under
+// a policy that supports only Go versions up to a given release, because the
+// module containing them, or the module of one of their transitive
+// dependencies, declares a newer minimum Go version in its go.mod file.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/modfile"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	maxGo     = flag.String("go", "", "Newest Go version supported by the policy (required)")
+)
+
+// A verdict records why a package does or does not build under the policy.
+type verdict struct {
+	needs string // the Go version required, if newer than the policy
+	via   string // the package whose module imposes the requirement
+}
+
+// merge updates v to reflect the requirement of o, if it is newer.
+func (v *verdict) merge(o *verdict) {
+	if o.needs != "" && (v.needs == "" || modfile.CompareGo(o.needs, v.needs) > 0) {
+		v.needs, v.via = o.needs, o.via
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *maxGo == "" {
+		log.Fatal("You must provide a -go version")
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	goVersions := make(map[string]string) // :: module label → go version
+	moduleGo := func(label string) (string, error) {
+		if v, ok := goVersions[label]; ok {
+			return v, nil
+		}
+		path, version := graph.SplitModule(label)
+		mod, err := g.Module(ctx, path, version)
+		if err == graph.ErrNotFound {
+			mod, err = new(graph.Module), nil
+		} else if err != nil {
+			return "", err
+		}
+		goVersions[label] = mod.GoVersion
+		return mod.GoVersion, nil
+	}
+
+	// Compute a verdict for each package reachable from the selected rows,
+	// memoizing as we go. The packages of a dependency cycle reach one
+	// another, so they share a verdict; cycles are found with Tarjan's
+	// algorithm, and the verdicts of their packages are memoized only once
+	// the whole cycle has been visited.
+	verdicts := make(map[string]*verdict) // :: package → final verdict
+	partial := make(map[string]*verdict)  // :: package in progress → verdict so far
+	order := make(map[string]int)         // :: package in progress → visit order
+	var stack []string                    // packages in progress
+	var visited int                       // packages visited so far
+	var visit func(string) (int, error)
+	visit = func(pkg string) (int, error) {
+		n := visited
+		visited++
+		low := n
+		order[pkg] = n
+		stack = append(stack, pkg)
+		v := new(verdict)
+		partial[pkg] = v
+
+		row, err := g.Row(ctx, pkg)
+		if err == nil {
+			if row.Module != "" {
+				need, err := moduleGo(row.Module)
+				if err != nil {
+					return 0, err
+				} else if need != "" && modfile.CompareGo(need, *maxGo) > 0 {
+					v.needs, v.via = need, pkg
+				}
+			}
+			for _, dep := range row.Directs {
+				if dv, ok := verdicts[dep]; ok {
+					v.merge(dv)
+					continue
+				} else if i, ok := order[dep]; ok {
+					// A cycle; dep is merged when the cycle is complete.
+					if i < low {
+						low = i
+					}
+					continue
+				}
+				dlow, err := visit(dep)
+				if err != nil {
+					return 0, err
+				} else if dv, ok := verdicts[dep]; ok {
+					v.merge(dv)
+				} else if dlow < low {
+					low = dlow
+				}
+			}
+		} else if err != graph.ErrNotFound {
+			return 0, err
+		} // unknown packages are assumed to build
+
+		if low == n {
+			// The packages on the stack from pkg up form a cycle, or pkg alone
+			// if it is not in one. All of them are now visited.
+			i := len(stack) - 1
+			for stack[i] != pkg {
+				i--
+			}
+			for _, p := range stack[i:] {
+				v.merge(partial[p])
+			}
+			for _, p := range stack[i:] {
+				verdicts[p] = v
+				delete(partial, p)
+				delete(order, p)
+			}
+			stack = stack[:i]
+		}
+		return low, nil
+	}
+	check := func(pkg string) (*verdict, error) {
+		if _, ok := verdicts[pkg]; !ok {
+			if _, err := visit(pkg); err != nil {
+				return nil, err
+			}
+		}
+		return verdicts[pkg], nil
+	}
+
+	pfxs := flag.Args()
+	if len(pfxs) == 0 {
+		pfxs = append(pfxs, "") // check all
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "PACKAGE\tNEEDS\tVIA\n")
+	for _, pfx := range pfxs {
+		if err := g.Scan(ctx, pfx, func(row *graph.Row) error {
+			v, err := check(row.ImportPath)
+			if err != nil {
+				return err
+			} else if v.needs != "" {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", row.ImportPath, v.needs, v.via)
+			}
+			return nil
+		}); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program journaldeps reads the journal of changes to a dependency graph, as
+// written by the -journal flag of writedeps and crawldeps. It prints the
+// entries, optionally following the journal as it grows, or replays them into
+// a graph to reconstruct it.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required with -replay)")
+	doReplay  = flag.Bool("replay", false, "Apply the entries to the graph instead of printing them")
+	doFollow  = flag.Bool("follow", false, "Wait for new entries at the end of the journal")
+	afterSeq  = flag.Int64("after", 0, "Skip entries with sequence numbers up to this one")
+	pollEvery = flag.Duration("poll", time.Second, "How often to check for new entries with -follow")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] <journal>
+
+Read the entries of a graph journal, and print them as JSON objects in order
+of sequence number. With -follow, wait for entries to be added at the end of
+the journal and print them too, like "tail -f".
+
+With -replay, apply the entries to the graph given by -store instead. To
+reconstruct a damaged graph, replay its journal into a new, empty store. Use
+-after to resume from a sequence number already applied.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+// An entry is a journal entry as it is printed, with its encoded messages
+// decoded.
+type entry struct {
+	Seq     int64         `json:"seq"`
+	Time    time.Time     `json:"time"`
+	Op      string        `json:"op"`
+	Key     string        `json:"key,omitempty"`
+	Repo    *deps.Repo    `json:"repo,omitempty"`
+	Package *deps.Package `json:"package,omitempty"`
+	Module  *deps.Module  `json:"module,omitempty"`
+	Row     *graph.Row    `json:"row,omitempty"`
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("Opening journal: %v", err)
+	}
+	defer f.Close()
+
+	ctx := context.Background()
+	apply := printEntry(json.NewEncoder(os.Stdout))
+	if *doReplay {
+		g, c, err := tools.OpenGraph(*storePath)
+		if err != nil {
+			log.Fatalf("Opening graph: %v", err)
+		}
+		defer c.Close()
+		apply = func(e *graph.JournalEntry) error {
+			if err := g.Replay(ctx, e); err != nil && err != graph.ErrNotFound {
+				return err
+			}
+			return nil
+		}
+	}
+
+	last := *afterSeq
+	var pos int64 // the offset of the end of the last complete entry
+	for {
+		n, err := graph.ReadJournal(f, func(e *graph.JournalEntry) error {
+			if e.Seq <= last {
+				return nil
+			}
+			last = e.Seq
+			return apply(e)
+		})
+		if err != nil {
+			log.Fatalf("Reading journal: %v", err)
+		} else if !*doFollow {
+			break
+		}
+
+		// An entry may have been partly written when we reached the end, so
+		// resume reading after the last complete entry.
+		pos += n
+		time.Sleep(*pollEvery)
+		if _, err := f.Seek(pos, io.SeekStart); err != nil {
+			log.Fatalf("Seeking journal: %v", err)
+		}
+	}
+}
+
+// printEntry returns a function that writes journal entries to enc.
+func printEntry(enc *json.Encoder) func(*graph.JournalEntry) error {
+	return func(e *graph.JournalEntry) error {
+		out := &entry{
+			Seq:  e.Seq,
+			Time: time.Unix(e.Time, 0).UTC(),
+			Op:   e.Op.String(),
+			Key:  e.Key,
+			Row:  e.Row,
+		}
+		if e.Repo != nil {
+			out.Repo = new(deps.Repo)
+			if err := proto.Unmarshal(e.Repo, out.Repo); err != nil {
+				return fmt.Errorf("entry %d: invalid repository: %v", e.Seq, err)
+			}
+		}
+		if e.Package != nil {
+			out.Package = new(deps.Package)
+			if err := proto.Unmarshal(e.Package, out.Package); err != nil {
+				return fmt.Errorf("entry %d: invalid package: %v", e.Seq, err)
+			}
+		}
+		if e.Module != nil {
+			out.Module = new(deps.Module)
+			if err := proto.Unmarshal(e.Module, out.Module); err != nil {
+				return fmt.Errorf("entry %d: invalid module: %v", e.Seq, err)
+			}
+		}
+		return enc.Encode(out)
+	}
+}
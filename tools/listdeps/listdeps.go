@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Program listdeps lists the keys of a graph.
+// Program listdeps lists the rows of a graph.
 package main
 
 import (
@@ -26,7 +26,12 @@ import (
 	"github.com/creachadair/repodeps/tools"
 )
 
-var storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+var (
+	storePath   = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	listModules = flag.Bool("modules", false, "List module versions rather than packages")
+	langs       = flag.String("lang", "", "List only packages of these comma-separated languages")
+	labels      = flag.String("label", "", "List only packages with these comma-separated key=value labels")
+)
 
 func main() {
 	flag.Parse()
@@ -42,10 +47,26 @@ func main() {
 	}
 	ctx := context.Background()
 	enc := json.NewEncoder(os.Stdout)
+	keep := tools.ParseLanguages(*langs)
+	want, err := tools.ParseLabels(*labels)
+	if err != nil {
+		log.Fatalf("Invalid -label: %v", err)
+	}
 	for _, pfx := range pfxs {
-		if err := g.Scan(ctx, pfx, func(row *graph.Row) error {
-			return enc.Encode(row)
-		}); err != nil {
+		var err error
+		if *listModules {
+			err = g.ScanModules(ctx, pfx, func(mod *graph.Module) error {
+				return enc.Encode(mod)
+			})
+		} else {
+			err = g.Scan(ctx, pfx, func(row *graph.Row) error {
+				if !keep.Row(row) || !want.Row(row) {
+					return nil
+				}
+				return enc.Encode(row)
+			})
+		}
+		if err != nil {
 			log.Fatalf("Scan failed: %v", err)
 		}
 	}
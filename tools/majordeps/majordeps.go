@@ -0,0 +1,70 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program majordeps scans the module graph for module versions and
+// requirements whose major version does not agree with the major version
+// suffix of the module path, e.g., a v2 module without a /v2 suffix.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/modfile"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+
+func main() {
+	flag.Parse()
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "MODULE\tREQUIRES\tPROBLEM\n")
+	pfxs := flag.Args()
+	if len(pfxs) == 0 {
+		pfxs = append(pfxs, "") // check all
+	}
+	for _, pfx := range pfxs {
+		if err := g.ScanModules(ctx, pfx, func(mod *graph.Module) error {
+			self := mod.Path
+			if mod.Version != "" {
+				self += "@" + mod.Version
+			}
+			if err := modfile.CheckPathMajor(mod.Path, mod.Version); err != nil {
+				fmt.Fprintf(tw, "%s\t-\t%v\n", self, err)
+			}
+			for _, req := range mod.Requires {
+				if err := modfile.CheckPathMajor(req.Path, req.Version); err != nil {
+					fmt.Fprintf(tw, "%s\t%s@%s\t%v\n", self, req.Path, req.Version, err)
+				}
+			}
+			return nil
+		}); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,156 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program olddeps scans a graph database for packages that import deprecated
+// or frozen packages, and reports how many packages import each one. An entry
+// of the list ending in "/..." covers a whole tree of packages, and is
+// reported once for each package importing any of them.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	listPath  = flag.String("list", "", "Read deprecated packages from this file instead of the built-in list")
+	doVerbose = flag.Bool("v", false, "List the importers of each deprecated package")
+)
+
+// deprecated maps the import paths of deprecated or frozen packages to a note
+// about what should be used instead. A path ending in "/..." matches the
+// package with the path before it and every package beneath that.
+var deprecated = map[string]string{
+	"golang.org/x/net/context":              "use context",
+	"golang.org/x/net/context/ctxhttp":      "use net/http with Request.WithContext",
+	"io/ioutil":                             "use io and os (deprecated in Go 1.16)",
+	"syscall":                               "frozen; use golang.org/x/sys",
+	"log/syslog":                            "frozen",
+	"net/rpc":                               "frozen",
+	"net/rpc/jsonrpc":                       "frozen",
+	"net/smtp":                              "frozen",
+	"crypto/dsa":                            "deprecated; use a modern signature scheme",
+	"crypto/rc4":                            "cryptographically broken",
+	"golang.org/x/crypto/md4":               "deprecated",
+	"golang.org/x/crypto/ripemd160":         "deprecated",
+	"golang.org/x/crypto/openpgp/...":       "deprecated and unmaintained",
+	"golang.org/x/crypto/ssh/terminal":      "use golang.org/x/term",
+	"github.com/golang/protobuf/proto":      "use google.golang.org/protobuf/proto",
+	"github.com/golang/protobuf/ptypes/...": "use google.golang.org/protobuf/types/known",
+}
+
+func main() {
+	flag.Parse()
+	if *listPath != "" {
+		list, err := loadList(*listPath)
+		if err != nil {
+			log.Fatalf("Loading list: %v", err)
+		}
+		deprecated = list
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	importers := make(map[string][]string) // :: list entry → importers
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		seen := make(map[string]bool)
+		for _, ip := range row.Directs {
+			if key, ok := match(ip); ok && !seen[key] {
+				seen[key] = true
+				importers[key] = append(importers[key], row.ImportPath)
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Scan failed: %v", err)
+	}
+
+	keys := make([]string, 0, len(importers))
+	for key := range importers {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := len(importers[keys[i]]), len(importers[keys[j]])
+		return ni > nj || (ni == nj && keys[i] < keys[j])
+	})
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "PACKAGE\tIMPORTERS\tNOTE\n")
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", key, len(importers[key]), deprecated[key])
+		if *doVerbose {
+			sort.Strings(importers[key])
+			for _, ip := range importers[key] {
+				fmt.Fprintf(tw, "  %s\t\t\n", ip)
+			}
+		}
+	}
+	tw.Flush()
+}
+
+// match returns the entry of the deprecated list that matches the import path
+// ip, if there is one. An exact entry is preferred to a pattern, and a longer
+// pattern to a shorter one.
+func match(ip string) (string, bool) {
+	if _, ok := deprecated[ip]; ok {
+		return ip, true
+	}
+	for p := ip; p != ""; {
+		if _, ok := deprecated[p+"/..."]; ok {
+			return p + "/...", true
+		}
+		i := strings.LastIndex(p, "/")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return "", false
+}
+
+// loadList reads a list of deprecated packages from the file at path. Each
+// non-blank line not beginning with "#" gives an import path or a pattern
+// ending in "/...", optionally followed by whitespace and a note.
+func loadList(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	list := make(map[string]string)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Fields(line)
+		list[parts[0]] = strings.Join(parts[1:], " ")
+	}
+	return list, s.Err()
+}
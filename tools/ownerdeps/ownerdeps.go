@@ -0,0 +1,117 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program ownerdeps reports the owners of the packages that depend on the
+// packages named on the command line.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath    = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	doTransitive = flag.Bool("transitive", false, "Include indirect as well as direct importers")
+	doVerbose    = flag.Bool("v", false, "List the importing packages of each owner")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatalf("Usage: %s <import-path>...", os.Args[0])
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	// Build the reverse dependency graph and record the owners of each
+	// package, so that transitive importers can be found in a single scan.
+	ctx := context.Background()
+	rdeps := make(map[string][]string) // :: import path → importers
+	owners := make(map[string][]string)
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		for _, dep := range row.Directs {
+			rdeps[dep] = append(rdeps[dep], row.ImportPath)
+		}
+		owners[row.ImportPath] = row.Owners
+		return nil
+	}); err != nil {
+		log.Fatalf("Scan failed: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	queue := flag.Args()
+	for _, pkg := range queue {
+		seen[pkg] = true
+	}
+	byOwner := make(map[string][]string) // :: owner → importers
+	var unowned []string
+	for len(queue) != 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, imp := range rdeps[next] {
+			if seen[imp] {
+				continue
+			}
+			seen[imp] = true
+			if *doTransitive {
+				queue = append(queue, imp)
+			}
+			if len(owners[imp]) == 0 {
+				unowned = append(unowned, imp)
+			}
+			for _, owner := range owners[imp] {
+				byOwner[owner] = append(byOwner[owner], imp)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(byOwner))
+	for key := range byOwner {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := len(byOwner[keys[i]]), len(byOwner[keys[j]])
+		return ni > nj || (ni == nj && keys[i] < keys[j])
+	})
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(tw, "OWNER\tPACKAGES\n")
+	report := func(owner string, pkgs []string) {
+		fmt.Fprintf(tw, "%s\t%d\n", owner, len(pkgs))
+		if *doVerbose {
+			sort.Strings(pkgs)
+			for _, pkg := range pkgs {
+				fmt.Fprintf(tw, "  %s\n", pkg)
+			}
+		}
+	}
+	for _, key := range keys {
+		report(key, byOwner[key])
+	}
+	if len(unowned) != 0 {
+		report("(unowned)", unowned)
+	}
+	tw.Flush()
+}
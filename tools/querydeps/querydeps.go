@@ -0,0 +1,157 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program querydeps selects packages from a dependency graph with a query
+// expression, either once from the command line or repeatedly over HTTP.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/query"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	httpAddr  = flag.String("http", "", "Serve queries over HTTP at this address")
+	pathsOnly = flag.Bool("paths", false, "Print only the import paths of matching packages")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] <query>...
+       %[1]s [options] -http <addr>
+
+Select the packages of the dependency graph that match each query, and print
+them as JSON rows, each with the depth at which it was selected, in order of
+import path. With -paths, only the import paths are printed.
+
+A query compares fields of each package with values, follows dependencies
+with imports(...) and importers(...), and combines these with "and", "or",
+and "not". For example:
+
+  %[1]s 'importers(path ~ "github.com/org/*") and depth <= 2'
+  %[1]s 'label.team = infra and not language = go'
+
+Fields: path, name, repo, module, digest, language, owner, label.KEY, unsafe,
+reflect, syscall, imports (the number of direct dependencies), and depth.
+Operators: = != ~ !~ (glob, where * matches any sequence) < <= > >=.
+
+With -http, queries are served at /query?q=<query>, with the same output and
+the paths parameter equivalent to -paths. A query that does not parse is
+reported with status 400.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+// A result is a matching package as it is printed.
+type result struct {
+	*graph.Row
+	Depth int `json:"depth,omitempty"`
+}
+
+func main() {
+	flag.Parse()
+	if *httpAddr == "" && flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	if *httpAddr != "" {
+		http.HandleFunc("/query", func(w http.ResponseWriter, req *http.Request) {
+			q, err := query.Parse(req.FormValue("q"))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid query: %v", err), http.StatusBadRequest)
+				return
+			}
+			ms, err := q.Eval(req.Context(), g)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			paths := *pathsOnly || isTrue(req.FormValue("paths"))
+			if paths {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			} else {
+				w.Header().Set("Content-Type", "application/json")
+			}
+			if err := writeMatches(w, ms, paths); err != nil {
+				log.Printf("Writing response: %v", err)
+			}
+		})
+		log.Printf("Serving queries at %s", *httpAddr)
+		log.Fatal(http.ListenAndServe(*httpAddr, nil))
+	}
+
+	ctx := context.Background()
+	for _, arg := range flag.Args() {
+		q, err := query.Parse(arg)
+		if err != nil {
+			log.Fatalf("Invalid query %q: %v", arg, err)
+		}
+		ms, err := q.Eval(ctx, g)
+		if err != nil {
+			log.Fatalf("Query failed: %v", err)
+		}
+		if err := writeMatches(os.Stdout, ms, *pathsOnly); err != nil {
+			log.Fatalf("Writing output: %v", err)
+		}
+	}
+}
+
+// writeMatches writes ms to w, as JSON results or, if paths is true, as import
+// paths one per line.
+func writeMatches(w io.Writer, ms []*query.Match, paths bool) error {
+	enc := json.NewEncoder(w)
+	for _, m := range ms {
+		var err error
+		if paths {
+			_, err = fmt.Fprintln(w, m.Row.ImportPath)
+		} else {
+			err = enc.Encode(result{Row: m.Row, Depth: m.Depth})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTrue reports whether an HTTP parameter value requests an option.
+func isTrue(s string) bool {
+	switch strings.ToLower(s) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
@@ -12,7 +12,10 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Program readdeps reads the specified rows out of a graph.
+// Program readdeps reads the specified rows out of a graph. With -providers,
+// it reads the repositories that have provided each package instead, with
+// -edges it reads when each dependency of each package was first and last
+// seen, and with -deleted it reads the tombstones of deleted packages.
 package main
 
 import (
@@ -21,11 +24,24 @@ import (
 	"flag"
 	"log"
 	"os"
+	"time"
 
+	"github.com/creachadair/repodeps/graph"
 	"github.com/creachadair/repodeps/tools"
 )
 
-var storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	providers = flag.Bool("providers", false, "Read the repositories providing each package")
+	edges     = flag.Bool("edges", false, "Read the history of the dependencies of each package")
+	deleted   = flag.Bool("deleted", false, "Read the tombstones of deleted packages")
+)
+
+// An edge is a dependency history entry as it is printed.
+type edge struct {
+	From string `json:"from"`
+	*graph.Edge
+}
 
 func main() {
 	flag.Parse()
@@ -38,7 +54,42 @@ func main() {
 	ctx := context.Background()
 	enc := json.NewEncoder(os.Stdout)
 	for _, ipath := range flag.Args() {
-		row, err := g.Row(ctx, ipath)
+		if *providers {
+			ps, err := g.Providers(ctx, ipath)
+			if err != nil {
+				log.Printf("Reading providers of %q: %v", ipath, err)
+				continue
+			}
+			for _, p := range ps {
+				if err := enc.Encode(p); err != nil {
+					log.Fatalf("Writing output: %v", err)
+				}
+			}
+			continue
+		} else if *edges {
+			h, err := g.EdgeHistory(ctx, ipath)
+			if err != nil {
+				log.Printf("Reading edge history of %q: %v", ipath, err)
+				continue
+			}
+			for _, e := range h.Edges {
+				if err := enc.Encode(edge{From: ipath, Edge: e}); err != nil {
+					log.Fatalf("Writing output: %v", err)
+				}
+			}
+			continue
+		}
+		read := g.Row
+		if *deleted {
+			read = g.Tombstone
+		}
+		row, err := read(ctx, ipath)
+		if err == graph.ErrNotFound && !*deleted {
+			if t, err := g.Tombstone(ctx, ipath); err == nil {
+				log.Printf("Reading %q: deleted at %s", ipath, time.Unix(t.Deleted, 0).Format(time.RFC3339))
+				continue
+			}
+		}
 		if err != nil {
 			log.Printf("Reading %q: %v", ipath, err)
 			continue
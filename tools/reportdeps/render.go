@@ -0,0 +1,117 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	htemplate "html/template"
+	"io"
+	"text/template"
+)
+
+// renderers map the names accepted by -format to functions that write a
+// report in that format.
+var renderers = map[string]func(io.Writer, *report) error{
+	"markdown": func(w io.Writer, r *report) error { return markdownPage.Execute(w, r) },
+	"html":     func(w io.Writer, r *report) error { return htmlPage.Execute(w, r) },
+}
+
+var markdownPage = template.Must(template.New("report").Parse(`# Dependency report
+
+Generated {{.Generated}}: {{.Packages}} packages in {{.Repositories}} repositories, {{.Edges}} import edges.
+
+## Hubs
+
+| Package | Importers |
+|---|---:|
+{{range .Hubs}}| ` + "`{{.Name}}`" + ` | {{.N}} |
+{{end}}
+## Most forked packages
+
+{{if .Forked}}| Package | Repositories |
+|---|---:|
+{{range .Forked}}| ` + "`{{.Name}}`" + ` | {{.N}} |
+{{end}}{{else}}No package is provided by more than one repository.
+{{end}}
+## Licenses
+
+| License | Repositories |
+|---|---:|
+{{range .Licenses}}| {{.Name}} | {{.N}} |
+{{end}}
+## Cycles
+
+{{if .Cycles}}{{.Cycles}} import cycles involve {{.CyclePackages}} packages; the largest has {{.LargestCycle}}.
+{{else}}There are no import cycles.
+{{end}}
+## Stalest popular packages
+
+{{if .Stale}}Packages with at least {{.MinImports}} importers, by the date of the last commit to their repositories.
+
+| Package | Importers | Last commit |
+|---|---:|---|
+{{range .Stale}}| ` + "`{{.ImportPath}}`" + ` | {{.Importers}} | {{.LastCommit}} |
+{{end}}{{else}}No package with at least {{.MinImports}} importers has a recorded commit time.
+{{end}}`))
+
+var htmlPage = htemplate.Must(htemplate.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Dependency report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.2em 0.8em; border-bottom: 1px solid #ddd; text-align: left; }
+td.n { text-align: right; }
+code { font-size: 90%; }
+</style>
+</head>
+<body>
+<h1>Dependency report</h1>
+<p>Generated {{.Generated}}: {{.Packages}} packages in {{.Repositories}} repositories, {{.Edges}} import edges.</p>
+
+<h2>Hubs</h2>
+<table>
+<tr><th>Package</th><th>Importers</th></tr>
+{{range .Hubs}}<tr><td><code>{{.Name}}</code></td><td class="n">{{.N}}</td></tr>
+{{end}}</table>
+
+<h2>Most forked packages</h2>
+{{if .Forked}}<table>
+<tr><th>Package</th><th>Repositories</th></tr>
+{{range .Forked}}<tr><td><code>{{.Name}}</code></td><td class="n">{{.N}}</td></tr>
+{{end}}</table>
+{{else}}<p>No package is provided by more than one repository.</p>
+{{end}}
+<h2>Licenses</h2>
+<table>
+<tr><th>License</th><th>Repositories</th></tr>
+{{range .Licenses}}<tr><td>{{.Name}}</td><td class="n">{{.N}}</td></tr>
+{{end}}</table>
+
+<h2>Cycles</h2>
+{{if .Cycles}}<p>{{.Cycles}} import cycles involve {{.CyclePackages}} packages; the largest has {{.LargestCycle}}.</p>
+{{else}}<p>There are no import cycles.</p>
+{{end}}
+<h2>Stalest popular packages</h2>
+{{if .Stale}}<p>Packages with at least {{.MinImports}} importers, by the date of the last commit to their repositories.</p>
+<table>
+<tr><th>Package</th><th>Importers</th><th>Last commit</th></tr>
+{{range .Stale}}<tr><td><code>{{.ImportPath}}</code></td><td class="n">{{.Importers}}</td><td>{{.LastCommit}}</td></tr>
+{{end}}</table>
+{{else}}<p>No package with at least {{.MinImports}} importers has a recorded commit time.</p>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,300 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program reportdeps writes a summary of a dependency graph as a single
+// Markdown or HTML document.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath  = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	topN       = flag.Int("n", 10, "Number of entries to list in each section")
+	format     = flag.String("format", "markdown", `Output format ("markdown" or "html")`)
+	minImports = flag.Int("popular", 5, "Minimum importers for a package to be listed as stale")
+	langs      = flag.String("lang", "", "Report only packages of these comma-separated languages")
+	labels     = flag.String("label", "", "Report only packages with these comma-separated key=value labels")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options]
+
+Summarize the dependency graph as a single document, written to stdout in the
+format selected by -format. The report lists:
+
+ - the hubs, the packages with the most importers;
+ - the most forked packages, those provided by the most repositories;
+ - the number of repositories under each license;
+ - the number and sizes of import cycles among the packages;
+ - the stalest popular packages, those with at least -popular importers whose
+   repositories have gone longest without a commit.
+
+License and commit times are recorded by repodeps, the latter only with the
+-activity flag; packages without them are reported as unknown or omitted.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+// A count is a named quantity listed in the report.
+type count struct {
+	Name string
+	N    int
+}
+
+// A stale is a popular package whose repository has not changed recently.
+type stale struct {
+	ImportPath string
+	Importers  int
+	LastCommit string // YYYY-MM-DD
+}
+
+// A report is the data rendered by the output templates.
+type report struct {
+	Generated    string
+	Packages     int
+	Repositories int
+	Edges        int
+
+	Hubs     []count
+	Forked   []count
+	Licenses []count
+
+	Cycles        int // non-trivial strongly-connected components
+	CyclePackages int // packages that belong to some cycle
+	LargestCycle  int // packages in the largest cycle
+
+	Stale      []stale
+	MinImports int
+}
+
+func main() {
+	flag.Parse()
+	render, ok := renderers[*format]
+	if !ok {
+		log.Fatalf("Invalid -format: %q", *format)
+	}
+	ctx := context.Background()
+	g, c, err := tools.OpenSnapshot(ctx, *storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	keep := tools.ParseLanguages(*langs)
+	want, err := tools.ParseLabels(*labels)
+	if err != nil {
+		log.Fatalf("Invalid -label: %v", err)
+	}
+	var (
+		directs  = make(map[string][]string)
+		inDeg    = make(map[string]int)
+		forks    = make(map[string]int)
+		commits  = make(map[string]int64)  // :: import path → last commit
+		licenses = make(map[string]string) // :: repository → license
+	)
+	rep := &report{
+		Generated:  time.Now().UTC().Format("2006-01-02"),
+		MinImports: *minImports,
+	}
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		if !keep.Row(row) || !want.Row(row) {
+			return nil
+		}
+		rep.Packages++
+		rep.Edges += len(row.Directs)
+		directs[row.ImportPath] = row.Directs
+		for _, dep := range row.Directs {
+			inDeg[dep]++
+		}
+		if row.LastCommit != 0 {
+			commits[row.ImportPath] = row.LastCommit
+		}
+		if row.Repository != "" {
+			licenses[row.Repository] = row.License
+		}
+		ps, err := g.Providers(ctx, row.ImportPath)
+		if err == nil && len(ps) > 1 {
+			forks[row.ImportPath] = len(ps)
+		} else if err != nil && err != graph.ErrNotFound {
+			return err
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Scan failed: %v", err)
+	}
+	rep.Repositories = len(licenses)
+
+	rep.Hubs = topCounts(inDeg, *topN)
+	rep.Forked = topCounts(forks, *topN)
+
+	byLicense := make(map[string]int)
+	for _, lic := range licenses {
+		if lic == "" {
+			lic = "unknown"
+		}
+		byLicense[lic]++
+	}
+	rep.Licenses = topCounts(byLicense, len(byLicense))
+
+	for _, comp := range cycles(directs) {
+		rep.Cycles++
+		rep.CyclePackages += len(comp)
+		if len(comp) > rep.LargestCycle {
+			rep.LargestCycle = len(comp)
+		}
+	}
+
+	var pop []string
+	for ipath := range commits {
+		if inDeg[ipath] >= *minImports {
+			pop = append(pop, ipath)
+		}
+	}
+	sort.Slice(pop, func(i, j int) bool {
+		ci, cj := commits[pop[i]], commits[pop[j]]
+		return ci < cj || (ci == cj && pop[i] < pop[j])
+	})
+	if len(pop) > *topN {
+		pop = pop[:*topN]
+	}
+	for _, ipath := range pop {
+		rep.Stale = append(rep.Stale, stale{
+			ImportPath: ipath,
+			Importers:  inDeg[ipath],
+			LastCommit: time.Unix(commits[ipath], 0).UTC().Format("2006-01-02"),
+		})
+	}
+
+	if err := render(os.Stdout, rep); err != nil {
+		log.Fatalf("Writing report: %v", err)
+	}
+}
+
+// topCounts returns the n entries of m with the largest values, in decreasing
+// order of value and then of name.
+func topCounts(m map[string]int, n int) []count {
+	var out []count
+	for name, v := range m {
+		out = append(out, count{Name: name, N: v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].N > out[j].N || (out[i].N == out[j].N && out[i].Name < out[j].Name)
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// cycles returns the strongly-connected components of the graph with the
+// given edges that contain a cycle: those with more than one node, or a
+// single node that imports itself. Edges to nodes not in the graph are
+// ignored. It uses Tarjan's algorithm, with an explicit stack so that long
+// import chains do not exhaust the goroutine stack.
+func cycles(edges map[string][]string) [][]string {
+	var (
+		index   = make(map[string]int)
+		low     = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		out     [][]string
+	)
+	type frame struct {
+		node string
+		next int // index of the next edge to visit
+	}
+	nodes := make([]string, 0, len(edges))
+	for node := range edges {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, root := range nodes {
+		if _, ok := index[root]; ok {
+			continue
+		}
+		work := []*frame{{node: root}}
+		index[root], low[root] = len(index), len(index)
+		stack = append(stack, root)
+		onStack[root] = true
+		for len(work) != 0 {
+			f := work[len(work)-1]
+			if deps := edges[f.node]; f.next < len(deps) {
+				dep := deps[f.next]
+				f.next++
+				if _, ok := edges[dep]; !ok {
+					continue
+				} else if _, seen := index[dep]; !seen {
+					index[dep], low[dep] = len(index), len(index)
+					stack = append(stack, dep)
+					onStack[dep] = true
+					work = append(work, &frame{node: dep})
+				} else if onStack[dep] && index[dep] < low[f.node] {
+					low[f.node] = index[dep]
+				}
+				continue
+			}
+
+			// All the edges of f.node have been visited.
+			work = work[:len(work)-1]
+			if len(work) != 0 {
+				if p := work[len(work)-1].node; low[f.node] < low[p] {
+					low[p] = low[f.node]
+				}
+			}
+			if low[f.node] != index[f.node] {
+				continue
+			}
+			var comp []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				comp = append(comp, top)
+				if top == f.node {
+					break
+				}
+			}
+			if len(comp) > 1 || importsSelf(edges, f.node) {
+				out = append(out, comp)
+			}
+		}
+	}
+	return out
+}
+
+// importsSelf reports whether node has an edge to itself.
+func importsSelf(edges map[string][]string, node string) bool {
+	for _, dep := range edges[node] {
+		if dep == node {
+			return true
+		}
+	}
+	return false
+}
@@ -12,7 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Program revdeps lists the reverse dependencies of a package.
+// Program revdeps lists the reverse dependencies of a package, and builds the
+// reverse dependency index that speeds up finding them.
 package main
 
 import (
@@ -21,14 +22,51 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
+	"github.com/creachadair/repodeps/graph"
 	"github.com/creachadair/repodeps/tools"
 )
 
-var storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	doReindex = flag.Bool("reindex", false, "Rebuild the reverse dependency index before listing")
+	afterPath = flag.String("after", "", "List only importers whose import paths sort after this one")
+	pageSize  = flag.Int("limit", 0, "List at most this many importers of each package (0 means no limit)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] <import-path>...
+       %[1]s -reindex
+
+Print the import paths of the packages that directly depend on each package,
+in order.
+
+To list the importers of a widely-used package in pages, use -limit to set the
+size of a page. If more importers remain, the last import path printed is
+logged; pass it as -after to list the next page.
+
+Reverse dependencies are found with an index that is updated as packages are
+written to the graph. Until the index has been built with -reindex, every
+package in the graph is scanned instead. Use -reindex once to enable the
+index on an existing graph or after importing data written without it, and
+again to remove entries left by packages that were since changed or removed.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
 
 func main() {
 	flag.Parse()
+	if flag.NArg() == 0 && !*doReindex {
+		flag.Usage()
+		os.Exit(2)
+	} else if *pageSize < 0 {
+		log.Fatalf("Invalid -limit: %d", *pageSize)
+	}
 	g, c, err := tools.OpenGraph(*storePath)
 	if err != nil {
 		log.Fatalf("Opening graph: %v", err)
@@ -36,9 +74,23 @@ func main() {
 	defer c.Close()
 
 	ctx := context.Background()
+	if *doReindex {
+		if err := g.ReindexImporters(ctx); err != nil {
+			log.Fatalf("Reindexing failed: %v", err)
+		}
+	}
 	for _, pkg := range flag.Args() {
-		if err := g.Importers(ctx, pkg, func(ipath string) {
+		var n int
+		var last string
+		if err := g.ImportersAfter(ctx, pkg, *afterPath, func(ipath string) error {
+			if *pageSize > 0 && n == *pageSize {
+				log.Printf("More importers of %q remain; continue with -after %s", pkg, last)
+				return graph.ErrStopScan
+			}
 			fmt.Println(ipath)
+			n++
+			last = ipath
+			return nil
 		}); err != nil {
 			log.Fatalf("Importers failed: %v", err)
 		}
@@ -0,0 +1,185 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program searchdeps finds packages in a dependency graph by import path
+// prefix, import path substring, or package name, either once from the
+// command line or repeatedly over HTTP.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	kindName  = flag.String("kind", "prefix", `How to match ("prefix", "substring", or "name")`)
+	httpAddr  = flag.String("http", "", "Serve searches over HTTP at this address")
+	pathsOnly = flag.Bool("paths", false, "Print only the import paths of matching packages")
+	doReindex = flag.Bool("reindex", false, "Rebuild the search index before searching")
+	afterPath = flag.String("after", "", "Print only packages whose import paths sort after this one")
+	pageSize  = flag.Int("limit", 0, "Print at most this many packages for each query (0 means no limit)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] <query>...
+       %[1]s [options] -http <addr>
+       %[1]s -reindex
+
+Find the packages of the dependency graph whose import paths begin with or
+contain each query, or whose package names equal it, according to -kind, and
+print them as JSON rows in order of import path. With -paths, only the import
+paths are printed.
+
+To print a large result in pages, use -limit to set the size of a page. If
+more packages remain, the last import path printed is logged; pass it as
+-after to print the next page.
+
+Substring and name searches use an index that is updated as packages are
+written to the graph. Use -reindex to build the index for packages written
+before it existed, and to remove entries left by packages that were since
+removed or renamed.
+
+With -http, searches are served at /search?q=<query>, with the parameters
+kind, paths, after, and limit equivalent to the flags of the same names.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *httpAddr == "" && flag.NArg() == 0 && !*doReindex {
+		flag.Usage()
+		os.Exit(2)
+	}
+	kind, err := graph.ParseSearchKind(*kindName)
+	if err != nil {
+		log.Fatalf("Invalid -kind: %v", err)
+	} else if *pageSize < 0 {
+		log.Fatalf("Invalid -limit: %d", *pageSize)
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	if *doReindex {
+		if err := g.Reindex(ctx); err != nil {
+			log.Fatalf("Reindexing failed: %v", err)
+		}
+	}
+
+	if *httpAddr != "" {
+		http.HandleFunc("/search", func(w http.ResponseWriter, req *http.Request) {
+			kind := kind
+			if s := req.FormValue("kind"); s != "" {
+				k, err := graph.ParseSearchKind(s)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				kind = k
+			}
+			pg := &page{after: *afterPath, limit: *pageSize}
+			if s := req.FormValue("after"); s != "" {
+				pg.after = s
+			}
+			if s := req.FormValue("limit"); s != "" {
+				n, err := strconv.Atoi(s)
+				if err != nil || n < 0 {
+					http.Error(w, "invalid limit", http.StatusBadRequest)
+					return
+				}
+				pg.limit = n
+			}
+			paths := *pathsOnly || isTrue(req.FormValue("paths"))
+			if paths {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			} else {
+				w.Header().Set("Content-Type", "application/json")
+			}
+			if _, err := search(req.Context(), g, kind, req.FormValue("q"), pg, w, paths); err != nil {
+				log.Printf("Search failed: %v", err)
+			}
+		})
+		log.Printf("Serving searches at %s", *httpAddr)
+		log.Fatal(http.ListenAndServe(*httpAddr, nil))
+	}
+
+	for _, arg := range flag.Args() {
+		next, err := search(ctx, g, kind, arg, &page{after: *afterPath, limit: *pageSize}, os.Stdout, *pathsOnly)
+		if err != nil {
+			log.Fatalf("Search failed: %v", err)
+		} else if next != "" {
+			log.Printf("More packages matching %q remain; continue with -after %s", arg, next)
+		}
+	}
+}
+
+// A page selects a page of search results: at most limit results, or all if
+// limit == 0, of those whose import paths sort after after.
+type page struct {
+	after string
+	limit int
+}
+
+// search writes the page of packages of g matching query to w, as JSON rows
+// or, if paths is true, as import paths one per line. If more packages
+// remain, it returns the import path of the last one written.
+func search(ctx context.Context, g *graph.Graph, kind graph.SearchKind, query string, p *page, w io.Writer, paths bool) (string, error) {
+	enc := json.NewEncoder(w)
+	var n int
+	var last, next string
+	err := g.SearchAfter(ctx, kind, query, p.after, func(row *graph.Row) error {
+		if p.limit > 0 && n == p.limit {
+			next = last
+			return graph.ErrStopScan
+		}
+		n++
+		last = row.ImportPath
+		if paths {
+			_, err := fmt.Fprintln(w, row.ImportPath)
+			return err
+		}
+		return enc.Encode(row)
+	})
+	return next, err
+}
+
+// isTrue reports whether an HTTP parameter value requests an option.
+func isTrue(s string) bool {
+	switch strings.ToLower(s) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program servedeps serves the operations of a dependency graph over HTTP as
+// a JSON-RPC 2.0 service.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/creachadair/repodeps/service"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	httpAddr  = flag.String("http", "localhost:8080", "Serve requests at this address")
+	tokenPath = flag.String("tokens", "", "Read access tokens from this file")
+	isPublic  = flag.Bool("public", false, "With -tokens, allow queries without a token")
+	certPath  = flag.String("tls-cert", "", "Serve HTTPS with this PEM certificate (requires -tls-key)")
+	keyPath   = flag.String("tls-key", "", "PEM private key for -tls-cert")
+	clientCA  = flag.String("tls-client-ca", "", "Require client certificates signed by a CA in this PEM file")
+	callRate  = flag.Float64("rate", 0, "Limit each caller to this many calls per second (0 means no limit)")
+	callBurst = flag.Int("burst", 0, "With -rate, the number of calls a caller may make at once (default -rate)")
+	maxResult = flag.Int("max-results", 0, "Limit the number of results of a call (0 means no limit)")
+	cacheSize = flag.Int("cache-size", 1000, "Cache this many Closure and Importers results (0 disables caching)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options]
+
+Serve the operations of the dependency graph as JSON-RPC 2.0 methods at
+/rpc. Each POST request carries one request object, or a batch of them as a
+JSON array; the requests of a batch are handled concurrently, and their
+responses are returned as an array. Requests without an id are notifications
+and get no response. The methods are Add, Remove, Row, Imports, Importers,
+Closure, Scan, Search, and Version; see the service package for their
+parameters.
+
+Methods other than Add and Remove may also be called with GET requests, as
+/rpc?method=<name>&params=<json>. The graph version, which increases with each
+change to the graph, is sent as the ETag of each such response; a request
+whose If-None-Match header holds the current version gets status 304.
+
+With -tokens, callers must present an access token as "Authorization: Bearer
+<token>", and the token determines which methods they may call. The file has
+one token per line, preceded by its role:
+
+  read  <token>    query the graph
+  write <token>    query and modify the graph (Add and Remove)
+
+Lines beginning with "#" are ignored. With -public, callers without a token
+may query the graph. Without -tokens, any caller may call any method.
+
+With -tls-cert and -tls-key, requests are served over HTTPS. Adding
+-tls-client-ca requires mutual TLS: callers must present a certificate signed
+by one of the CAs in that file. Tokens, if any, are checked as well.
+
+With -rate, each caller may make that many calls per second on average, and
+calls in excess fail with code -32004 (each request of a batch is a call).
+Callers are told apart by token, or without -tokens or a token by network
+address. With -max-results, a Closure call whose result would be larger fails
+with code -32003, without finishing its traversal, and the results of
+Importers, Scan, and Search are returned in pages of at most that size, each
+with a cursor for the next.
+
+The results of recent Closure and Importers calls are cached, up to
+-cache-size of them, so that repeated queries about popular packages are
+answered without reading the graph. The cache is emptied whenever the graph
+version changes.
+
+For example:
+
+  curl -d '{"jsonrpc":"2.0","id":1,"method":"Imports",
+            "params":{"package":"github.com/creachadair/repodeps/deps"}}' \
+       http://localhost:8080/rpc
+
+  curl -G --data-urlencode method=Version http://localhost:8080/rpc
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *callRate < 0 {
+		log.Fatalf("Invalid -rate: %v", *callRate)
+	} else if *callBurst < 0 {
+		log.Fatalf("Invalid -burst: %d", *callBurst)
+	} else if *maxResult < 0 {
+		log.Fatalf("Invalid -max-results: %d", *maxResult)
+	} else if *cacheSize < 0 {
+		log.Fatalf("Invalid -cache-size: %d", *cacheSize)
+	}
+	opts := &service.Options{
+		Rate:       *callRate,
+		Burst:      *callBurst,
+		MaxResults: *maxResult,
+		CacheSize:  *cacheSize,
+	}
+	if *tokenPath != "" {
+		f, err := os.Open(*tokenPath)
+		if err != nil {
+			log.Fatalf("Reading tokens: %v", err)
+		}
+		opts.Tokens, err = service.ParseTokens(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("Invalid -tokens: %v", err)
+		} else if len(opts.Tokens) == 0 {
+			log.Fatalf("Invalid -tokens: no tokens in %q", *tokenPath)
+		}
+		if *isPublic {
+			opts.Anonymous = service.RoleRead
+		}
+	} else if *isPublic {
+		log.Fatal("The -public flag requires -tokens")
+	}
+
+	srv := &http.Server{Addr: *httpAddr}
+	if (*certPath == "") != (*keyPath == "") {
+		log.Fatal("The -tls-cert and -tls-key flags must be given together")
+	} else if *certPath != "" {
+		cfg, err := service.ServerTLSConfig(*certPath, *keyPath, *clientCA)
+		if err != nil {
+			log.Fatalf("Loading TLS certificate: %v", err)
+		}
+		srv.TLSConfig = cfg
+	} else if *clientCA != "" {
+		log.Fatal("The -tls-client-ca flag requires -tls-cert and -tls-key")
+	}
+
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	http.Handle("/rpc", service.New(g, opts).Handler())
+	if srv.TLSConfig != nil {
+		log.Printf("Serving requests at %s with TLS", *httpAddr)
+		log.Fatal(srv.ListenAndServeTLS("", ""))
+	}
+	log.Printf("Serving requests at %s", *httpAddr)
+	log.Fatal(srv.ListenAndServe())
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program simdeps reports the packages most similar to a given package, as
+// measured by the Jaccard similarity of their sets of imports or importers.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	compareBy = flag.String("by", "imports", `Compare packages by their "imports" or "importers"`)
+	maxResult = flag.Int("n", 10, "Report at most this many similar packages")
+	useStdlib = flag.Bool("stdlib", false, "Include standard library packages in the comparison")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatalf("Usage: %s <import-path>...", os.Args[0])
+	} else if *compareBy != "imports" && *compareBy != "importers" {
+		log.Fatalf("Invalid -by: %q", *compareBy)
+	}
+	ctx := context.Background()
+	g, c, err := tools.OpenSnapshot(ctx, *storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	// Collect the set of neighbours for each package.
+	sets := make(map[string]map[string]bool)
+	add := func(pkg, elt string) {
+		if sets[pkg] == nil {
+			sets[pkg] = make(map[string]bool)
+		}
+		sets[pkg][elt] = true
+	}
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		for _, dep := range row.Directs {
+			if !*useStdlib && deps.IsStdlib(dep) {
+				continue
+			}
+			if *compareBy == "imports" {
+				add(row.ImportPath, dep)
+			} else {
+				add(dep, row.ImportPath)
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Scan failed: %v", err)
+	}
+
+	type match struct {
+		pkg    string
+		shared int
+		score  float64
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, pkg := range flag.Args() {
+		want := sets[pkg]
+		if len(want) == 0 {
+			log.Printf("No %s found for %q", *compareBy, pkg)
+			continue
+		}
+		var ms []match
+		for other, set := range sets {
+			if other == pkg {
+				continue
+			}
+			n := intersect(want, set)
+			if n == 0 {
+				continue
+			}
+			ms = append(ms, match{
+				pkg:    other,
+				shared: n,
+				score:  float64(n) / float64(len(want)+len(set)-n),
+			})
+		}
+		sort.Slice(ms, func(i, j int) bool {
+			if ms[i].score == ms[j].score {
+				return ms[i].pkg < ms[j].pkg
+			}
+			return ms[i].score > ms[j].score
+		})
+		if len(ms) > *maxResult {
+			ms = ms[:*maxResult]
+		}
+		fmt.Fprintf(tw, "%s (%d %s)\tSIMILARITY\tSHARED\n", pkg, len(want), *compareBy)
+		for _, m := range ms {
+			fmt.Fprintf(tw, "  %s\t%.3f\t%d\n", m.pkg, m.score, m.shared)
+		}
+	}
+	tw.Flush()
+}
+
+// intersect returns the number of elements common to a and b.
+func intersect(a, b map[string]bool) int {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var n int
+	for elt := range a {
+		if b[elt] {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,354 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program sqldeps answers SQL queries about a dependency graph, by copying its
+// packages and edges into the tables of a SQLite database.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path")
+	dbPath    = flag.String("db", "", "Keep the tables in this SQLite database file (default in memory)")
+	doReload  = flag.Bool("reload", false, "Rebuild the -db file from the graph even if it exists")
+	doJSON    = flag.Bool("json", false, "Print each result row as a JSON object")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] <query>...
+
+Run each SQL query against tables holding the packages of the dependency graph
+and the edges among them, and print the results as a table with a header line,
+or with -json as one JSON object per row.
+
+The tables are loaded from the graph into an in-memory SQLite database when
+the program starts. With -db they are kept in the named file instead, and a
+later run with the same -db queries that file without reading the graph, so
+that a large graph is loaded once for many queries; use -reload to refresh
+it. With -db and no queries, the file is loaded and nothing else is done. The
+queries cannot modify the database.
+
+Tables:
+
+  packages(import_path, name, repository, module, language, digest,
+           license, last_commit, uses_unsafe, uses_reflect, uses_syscall,
+           num_imports)
+  edges(importer, imported, class, std, blank, dot, refs, symbols)
+  labels(import_path, key, value)
+  owners(import_path, owner)
+
+Each edge is a direct dependency of the importer. The class is one of STDLIB,
+SAME_MODULE, SAME_REPOSITORY, EXTERNAL, or UNCLASSIFIED; std, blank, and dot
+are 1 for standard library, blank (_), and dot (.) imports; and refs and
+symbols are the reference and distinct symbol counts, or NULL if unknown. The
+last_commit time is in seconds since the Unix epoch, or NULL if unknown. For
+example:
+
+  %[1]s "SELECT imported, count(*) AS n FROM edges
+          WHERE class = 'EXTERNAL' GROUP BY imported ORDER BY n DESC LIMIT 10"
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 && *dbPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	db, err := openDB(ctx)
+	if err != nil {
+		log.Fatalf("Loading tables: %v", err)
+	}
+	defer db.Close()
+
+	for _, arg := range flag.Args() {
+		if err := runQuery(ctx, db, arg, os.Stdout); err != nil {
+			log.Fatalf("Query failed: %v", err)
+		}
+	}
+}
+
+// openDB opens the database selected by the -db flag, loading the tables from
+// the graph if they were not already loaded, and makes it read-only.
+func openDB(ctx context.Context) (*sql.DB, error) {
+	name, load := ":memory:", true
+	if *dbPath != "" {
+		name = *dbPath
+		if _, err := os.Stat(*dbPath); err == nil {
+			if !*doReload {
+				load = false
+			} else if err := os.Remove(*dbPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	db, err := sql.Open("sqlite3", name)
+	if err != nil {
+		return nil, err
+	}
+	// Each connection to ":memory:" has its own database.
+	db.SetMaxOpenConns(1)
+	if load {
+		g, c, err := tools.OpenSnapshot(ctx, *storePath)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		err = loadTables(ctx, db, g)
+		c.Close()
+		if err != nil {
+			db.Close()
+			if *dbPath != "" {
+				os.Remove(*dbPath)
+			}
+			return nil, err
+		}
+	}
+	if _, err := db.ExecContext(ctx, `PRAGMA query_only = ON`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+const schema = `
+CREATE TABLE packages (
+  import_path  TEXT PRIMARY KEY,
+  name         TEXT,
+  repository   TEXT,
+  module       TEXT,
+  language     TEXT,
+  digest       TEXT,
+  license      TEXT,
+  last_commit  INTEGER,
+  uses_unsafe  INTEGER,
+  uses_reflect INTEGER,
+  uses_syscall INTEGER,
+  num_imports  INTEGER
+);
+CREATE TABLE edges (
+  importer TEXT,
+  imported TEXT,
+  class    TEXT,
+  std      INTEGER,
+  blank    INTEGER,
+  dot      INTEGER,
+  refs     INTEGER,
+  symbols  INTEGER
+);
+CREATE TABLE labels (import_path TEXT, key TEXT, value TEXT);
+CREATE TABLE owners (import_path TEXT, owner TEXT);
+`
+
+// indexes are created after the tables are loaded, which is faster than
+// maintaining them during the load.
+const indexes = `
+CREATE INDEX edges_importer ON edges (importer);
+CREATE INDEX edges_imported ON edges (imported);
+CREATE INDEX labels_key ON labels (key, value);
+CREATE INDEX owners_owner ON owners (owner);
+`
+
+// loadTables creates the tables in db and fills them from the rows of g.
+func loadTables(ctx context.Context, db *sql.DB, g *graph.Graph) error {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stmts [4]*sql.Stmt
+	for i, s := range []string{
+		`INSERT INTO packages VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO edges VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO labels VALUES (?, ?, ?)`,
+		`INSERT INTO owners VALUES (?, ?)`,
+	} {
+		stmt, err := tx.PrepareContext(ctx, s)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		stmts[i] = stmt
+	}
+	addPackage, addEdge, addLabel, addOwner := stmts[0], stmts[1], stmts[2], stmts[3]
+
+	var numRows int
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		numRows++
+		var lastCommit interface{}
+		if row.LastCommit != 0 {
+			lastCommit = row.LastCommit
+		}
+		if _, err := addPackage.ExecContext(ctx, row.ImportPath, row.Name, row.Repository,
+			row.Module, language(row), row.Digest, row.License, lastCommit,
+			row.UsesUnsafe, row.UsesReflect, row.UsesSyscall, len(row.Directs)); err != nil {
+			return fmt.Errorf("adding %q: %v", row.ImportPath, err)
+		}
+
+		std := stringSet(row.StdDirects)
+		blank := stringSet(row.BlankDirects)
+		dot := stringSet(row.DotDirects)
+		for i, dep := range row.Directs {
+			class := graph.ImportClass_UNCLASSIFIED
+			if i < len(row.Classes) {
+				class = row.Classes[i]
+			}
+			var refs, syms interface{}
+			if len(row.Refs) == len(row.Directs) {
+				refs = row.Refs[i]
+			}
+			if len(row.Symbols) == len(row.Directs) {
+				syms = row.Symbols[i]
+			}
+			if _, err := addEdge.ExecContext(ctx, row.ImportPath, dep, class.String(),
+				std[dep], blank[dep], dot[dep], refs, syms); err != nil {
+				return fmt.Errorf("adding edge %q → %q: %v", row.ImportPath, dep, err)
+			}
+			delete(std, dep)
+		}
+
+		// Standard library imports not recorded among the directs.
+		for _, dep := range row.StdDirects {
+			if !std[dep] {
+				continue
+			}
+			if _, err := addEdge.ExecContext(ctx, row.ImportPath, dep, graph.ImportClass_STDLIB.String(),
+				true, blank[dep], dot[dep], nil, nil); err != nil {
+				return fmt.Errorf("adding edge %q → %q: %v", row.ImportPath, dep, err)
+			}
+			delete(std, dep)
+		}
+		for key, val := range row.Labels {
+			if _, err := addLabel.ExecContext(ctx, row.ImportPath, key, val); err != nil {
+				return err
+			}
+		}
+		for _, owner := range row.Owners {
+			if _, err := addOwner.ExecContext(ctx, row.ImportPath, owner); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, indexes); err != nil {
+		return err
+	}
+	log.Printf("Loaded %d packages", numRows)
+	return nil
+}
+
+// language returns the language of row, with Go packages reported as "go".
+func language(row *graph.Row) string {
+	if row.Language == "" {
+		return "go"
+	}
+	return row.Language
+}
+
+func stringSet(ss []string) map[string]bool {
+	m := make(map[string]bool)
+	for _, s := range ss {
+		m[s] = true
+	}
+	return m
+}
+
+// runQuery runs the SQL query q against db and writes the results to w.
+func runQuery(ctx context.Context, db *sql.DB, q string, w io.Writer) error {
+	rows, err := db.QueryContext(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	vals := make([]interface{}, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+
+	enc := json.NewEncoder(w)
+	tw := tabwriter.NewWriter(w, 4, 8, 1, ' ', 0)
+	if !*doJSON {
+		fmt.Fprintln(tw, strings.Join(cols, "\t"))
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		for i, v := range vals {
+			if b, ok := v.([]byte); ok {
+				vals[i] = string(b)
+			}
+		}
+		if *doJSON {
+			obj := make(map[string]interface{})
+			for i, col := range cols {
+				obj[col] = vals[i]
+			}
+			if err := enc.Encode(obj); err != nil {
+				return err
+			}
+			continue
+		}
+		fields := make([]string, len(vals))
+		for i, v := range vals {
+			if v == nil {
+				fields[i] = "NULL"
+			} else {
+				fields[i] = fmt.Sprint(v)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(fields, "\t"))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
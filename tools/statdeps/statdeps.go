@@ -0,0 +1,217 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program statdeps reports summary statistics about a dependency graph.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	topN      = flag.Int("n", 10, "Number of hubs and components to list")
+	langs     = flag.String("lang", "", "Count only packages of these comma-separated languages")
+	labels    = flag.String("label", "", "Count only packages with these comma-separated key=value labels")
+)
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+	g, c, err := tools.OpenSnapshot(ctx, *storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	keep := tools.ParseLanguages(*langs)
+	want, err := tools.ParseLabels(*labels)
+	if err != nil {
+		log.Fatalf("Invalid -label: %v", err)
+	}
+	var (
+		numRows  int
+		numEdges int
+		numExt   int // edges classified as external
+		outDeg   []int
+		inDeg    = make(map[string]int)
+		repoPkgs = make(map[string]int)
+		uf       = newUnionFind()
+	)
+	if err := g.Scan(ctx, "", func(row *graph.Row) error {
+		if !keep.Row(row) || !want.Row(row) {
+			return nil
+		}
+		numRows++
+		numEdges += len(row.Directs)
+		outDeg = append(outDeg, len(row.Directs))
+		repoPkgs[row.Repository]++
+		uf.find(row.ImportPath)
+		for _, dep := range row.Directs {
+			inDeg[dep]++
+			uf.union(row.ImportPath, dep)
+		}
+		for _, c := range row.Classes {
+			if c == graph.ImportClass_EXTERNAL {
+				numExt++
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Scan failed: %v", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "Packages:\t%d\n", numRows)
+	fmt.Fprintf(tw, "Nodes (including unscanned dependencies):\t%d\n", len(uf.parent))
+	fmt.Fprintf(tw, "Edges:\t%d\n", numEdges)
+	if numExt > 0 {
+		fmt.Fprintf(tw, "External edges:\t%d (%.1f per package)\n", numExt, float64(numExt)/float64(numRows))
+	}
+	fmt.Fprintf(tw, "Repositories:\t%d\n", len(repoPkgs))
+	fmt.Fprintln(tw)
+
+	var inList []int
+	for _, n := range inDeg {
+		inList = append(inList, n)
+	}
+	for len(inList) < len(uf.parent) {
+		inList = append(inList, 0) // nodes with no importers
+	}
+	var perRepo []int
+	for _, n := range repoPkgs {
+		perRepo = append(perRepo, n)
+	}
+	printDist(tw, "Out-degree (imports per package)", outDeg)
+	printDist(tw, "In-degree (importers per node)", inList)
+	printDist(tw, "Packages per repository", perRepo)
+
+	// List the packages with the most importers.
+	hubs := make([]string, 0, len(inDeg))
+	for pkg := range inDeg {
+		hubs = append(hubs, pkg)
+	}
+	sort.Slice(hubs, func(i, j int) bool {
+		ni, nj := inDeg[hubs[i]], inDeg[hubs[j]]
+		return ni > nj || (ni == nj && hubs[i] < hubs[j])
+	})
+	if len(hubs) > *topN {
+		hubs = hubs[:*topN]
+	}
+	fmt.Fprintln(tw, "Top hubs (by importers):")
+	for _, pkg := range hubs {
+		fmt.Fprintf(tw, "  %s\t%d\n", pkg, inDeg[pkg])
+	}
+	fmt.Fprintln(tw)
+
+	// Report the sizes of the weakly-connected components.
+	sizes := make(map[string]int)
+	for node := range uf.parent {
+		sizes[uf.find(node)]++
+	}
+	var comps []int
+	for _, n := range sizes {
+		comps = append(comps, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(comps)))
+	fmt.Fprintf(tw, "Connected components:\t%d\n", len(comps))
+	if len(comps) > *topN {
+		comps = comps[:*topN]
+	}
+	for i, n := range comps {
+		fmt.Fprintf(tw, "  #%d\t%d nodes\n", i+1, n)
+	}
+}
+
+// printDist writes a summary and a logarithmic histogram of the values in vs.
+func printDist(tw *tabwriter.Writer, title string, vs []int) {
+	fmt.Fprintf(tw, "%s:\n", title)
+	if len(vs) == 0 {
+		fmt.Fprintln(tw, "  (none)")
+		return
+	}
+	sort.Ints(vs)
+	var sum int
+	for _, v := range vs {
+		sum += v
+	}
+	fmt.Fprintf(tw, "  mean %.2f\tmedian %d\tmax %d\n", float64(sum)/float64(len(vs)), vs[len(vs)/2], vs[len(vs)-1])
+
+	// Buckets are 0, 1, 2–3, 4–7, 8–15, ...
+	var buckets []int
+	for _, v := range vs {
+		b := 0
+		for n := v; n > 0; n >>= 1 {
+			b++
+		}
+		for len(buckets) <= b {
+			buckets = append(buckets, 0)
+		}
+		buckets[b]++
+	}
+	for b, n := range buckets {
+		if n == 0 {
+			continue
+		}
+		lo, hi := 0, 0
+		if b > 0 {
+			lo, hi = 1<<uint(b-1), 1<<uint(b)-1
+		}
+		label := fmt.Sprint(lo)
+		if hi > lo {
+			label = fmt.Sprintf("%d–%d", lo, hi)
+		}
+		fmt.Fprintf(tw, "  %s\t%d\n", label, n)
+	}
+	fmt.Fprintln(tw)
+}
+
+// A unionFind tracks a partition of strings into disjoint sets.
+type unionFind struct {
+	parent map[string]string
+}
+
+func newUnionFind() *unionFind { return &unionFind{parent: make(map[string]string)} }
+
+// find returns the representative of the set containing s, adding s as a
+// singleton set if it is not already present.
+func (u *unionFind) find(s string) string {
+	p, ok := u.parent[s]
+	if !ok {
+		u.parent[s] = s
+		return s
+	}
+	if p != s {
+		p = u.find(p)
+		u.parent[s] = p
+	}
+	return p
+}
+
+// union merges the sets containing a and b.
+func (u *unionFind) union(a, b string) {
+	if ra, rb := u.find(a), u.find(b); ra != rb {
+		u.parent[ra] = rb
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program thindeps lists import edges through which only a few exported names
+// of the imported package are used. Such dependencies are candidates for
+// removal, for example by copying the few pieces that are needed.
+//
+// This requires a graph whose packages were scanned with "repodeps -refs".
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath  = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	maxSymbols = flag.Int("max", 1, "Report edges using at most this many distinct names")
+	doStdlib   = flag.Bool("stdlib", false, "Include imports of standard library packages")
+	doUnused   = flag.Bool("unused", false, "Include edges using no names at all (blank or dot imports)")
+)
+
+func main() {
+	flag.Parse()
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tIMPORT\tNAMES\tREFS")
+	var counted, skipped int
+	ctx := context.Background()
+	for _, prefix := range prefixes() {
+		if err := g.Scan(ctx, prefix, func(row *graph.Row) error {
+			if len(row.Symbols) != len(row.Directs) || len(row.Refs) != len(row.Directs) {
+				skipped++
+				return nil // usage was not counted for this package
+			}
+			counted++
+			for i, dep := range row.Directs {
+				n := row.Symbols[i]
+				if n > int64(*maxSymbols) || (n == 0 && !*doUnused) {
+					continue
+				} else if !*doStdlib && deps.IsStdlib(dep) {
+					continue
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", row.ImportPath, dep, n, row.Refs[i])
+			}
+			return nil
+		}); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+	}
+	tw.Flush()
+	if skipped != 0 {
+		log.Printf("Skipped %d of %d packages without usage counts", skipped, counted+skipped)
+	}
+}
+
+// prefixes returns the import path prefixes to scan, from the command line.
+func prefixes() []string {
+	if flag.NArg() == 0 {
+		return []string{""}
+	}
+	var out []string
+	for _, arg := range flag.Args() {
+		out = append(out, strings.TrimSuffix(arg, "..."))
+	}
+	return out
+}
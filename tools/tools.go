@@ -16,11 +16,14 @@
 package tools
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/creachadair/badgerstore"
+	"github.com/creachadair/repodeps/deps"
 	"github.com/creachadair/repodeps/graph"
 	"github.com/creachadair/repodeps/storage"
 )
@@ -37,3 +40,115 @@ func OpenGraph(path string) (*graph.Graph, io.Closer, error) {
 	}
 	return graph.New(storage.NewBlob(s)), s, nil
 }
+
+// OpenSnapshot opens the graph indicated by the -store flag as OpenGraph does,
+// and returns a read-only snapshot of it, so that a long analysis sees a
+// consistent view of the graph even if it is written meanwhile. The caller
+// must ensure the closer is closed; it releases the snapshot and closes the
+// storage.
+func OpenSnapshot(ctx context.Context, path string) (*graph.Graph, io.Closer, error) {
+	g, c, err := OpenGraph(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	snap, sc, err := g.Snapshot(ctx)
+	if err != nil {
+		c.Close()
+		return nil, nil, fmt.Errorf("taking snapshot: %v", err)
+	}
+	return snap, closerFunc(func() error {
+		serr := sc.Close()
+		if err := c.Close(); err != nil {
+			return err
+		}
+		return serr
+	}), nil
+}
+
+// OpenJournal opens the journal indicated by the -journal flag and attaches it
+// to g, so that each mutation of g is recorded in the journal. If path is
+// empty, no journal is opened. The caller must ensure the closer is closed.
+func OpenJournal(g *graph.Graph, path string) (io.Closer, error) {
+	if path == "" {
+		return closerFunc(func() error { return nil }), nil
+	}
+	j, err := graph.OpenJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	g.SetJournal(j)
+	return j, nil
+}
+
+type closerFunc func() error
+
+func (c closerFunc) Close() error { return c() }
+
+// A LanguageSet is a set of language names, as selected by a -lang flag. An
+// empty set selects all languages.
+type LanguageSet map[string]bool
+
+// ParseLanguages parses a comma-separated list of language names, such as
+// "go,npm".
+func ParseLanguages(s string) LanguageSet {
+	set := make(LanguageSet)
+	for _, lang := range strings.Split(s, ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			set[lang] = true
+		}
+	}
+	return set
+}
+
+// Row reports whether the package described by row is selected.
+func (s LanguageSet) Row(row *graph.Row) bool {
+	if len(s) == 0 {
+		return true
+	} else if row.Language == "" {
+		return s["go"]
+	}
+	return s[row.Language]
+}
+
+// Path reports whether the package with the given import path is selected,
+// which is useful for packages that may not have rows in the graph.
+func (s LanguageSet) Path(ipath string) bool {
+	return len(s) == 0 || s[deps.PathLanguage(ipath)]
+}
+
+// A LabelSet is a set of label constraints, as selected by a -label flag. A
+// constraint with an empty value requires only that the label be present. An
+// empty set selects all packages.
+type LabelSet map[string]string
+
+// ParseLabels parses a comma-separated list of label constraints, each of the
+// form key=value or key, such as "team=infra,tier".
+func ParseLabels(s string) (LabelSet, error) {
+	set := make(LabelSet)
+	for _, elt := range strings.Split(s, ",") {
+		if elt = strings.TrimSpace(elt); elt == "" {
+			continue
+		}
+		key, val := elt, ""
+		if i := strings.Index(elt, "="); i >= 0 {
+			key, val = strings.TrimSpace(elt[:i]), strings.TrimSpace(elt[i+1:])
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid label constraint %q", elt)
+		}
+		set[key] = val
+	}
+	return set, nil
+}
+
+// Row reports whether the package described by row satisfies all the
+// constraints in s.
+func (s LabelSet) Row(row *graph.Row) bool {
+	for key, want := range s {
+		got, ok := row.Labels[key]
+		if !ok || (want != "" && got != want) {
+			return false
+		}
+	}
+	return true
+}
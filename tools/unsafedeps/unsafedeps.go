@@ -0,0 +1,88 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program unsafedeps lists the packages in the transitive closure of the
+// specified packages that import unsafe, reflect, or syscall.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	doUnsafe  = flag.Bool("unsafe", true, "Report packages that import unsafe")
+	doReflect = flag.Bool("reflect", false, "Report packages that import reflect")
+	doSyscall = flag.Bool("syscall", false, "Report packages that import syscall")
+	doMissing = flag.Bool("missing", false, "List packages in the closure that are not in the graph")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatalf("Usage: %s <import-path>...", filepath.Base(os.Args[0]))
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	stats, err := g.OpenClosure(ctx, flag.Args(), func(row *graph.Row) error {
+		var uses []string
+		if *doUnsafe && row.UsesUnsafe {
+			uses = append(uses, "unsafe")
+		}
+		if *doReflect && row.UsesReflect {
+			uses = append(uses, "reflect")
+		}
+		if *doSyscall && row.UsesSyscall {
+			uses = append(uses, "syscall")
+		}
+		if len(uses) != 0 {
+			fmt.Printf("%s\t%s\n", row.ImportPath, strings.Join(uses, ","))
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Closure failed: %v", err)
+	}
+	if stats.Complete() {
+		return
+	} else if !*doMissing {
+		log.Printf("Closure is incomplete: %d packages found, %d missing (see -missing)",
+			stats.Visited, len(stats.Missing))
+		return
+	}
+	var missing []string
+	for pkg := range stats.Missing {
+		missing = append(missing, pkg)
+	}
+	sort.Strings(missing)
+	fmt.Printf("# %d packages found, %d missing\n", stats.Visited, len(missing))
+	for _, pkg := range missing {
+		fmt.Printf("%s\tmissing\t%d importers\n", pkg, stats.Missing[pkg])
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 Michael J. Fromberger. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Program upgradedeps estimates how many modules in a graph database would
+// need changes to adopt a new version of a module.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/creachadair/repodeps/graph"
+	"github.com/creachadair/repodeps/semver"
+	"github.com/creachadair/repodeps/tools"
+)
+
+var (
+	storePath = flag.String("store", os.Getenv("REPODEPS_DB"), "Storage path (required)")
+	doVerbose = flag.Bool("v", false, "List each affected module")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %[1]s [options] <module>@<version>
+
+Estimate the impact of upgrading the given module to the given version on the
+modules scanned from working trees in the module graph. A module is affected
+if minimal version selection currently selects an older version of the
+module for its build, including an older major version with a different
+module path.
+
+Each affected module needs a change to its go.mod file. It also needs changes
+to its source if the upgrade changes the major version and it imports the
+module's packages, and the upgrade may raise the selected versions of other
+modules. The summary counts the affected modules and repositories in each of
+these categories; with -v, each affected module is listed.
+
+Options:
+`, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path, version := graph.SplitModule(flag.Arg(0))
+	if !semver.IsValid(version) {
+		log.Fatalf("Invalid version %q", version)
+	}
+	g, c, err := tools.OpenGraph(*storePath)
+	if err != nil {
+		log.Fatalf("Opening graph: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	if *doVerbose {
+		fmt.Fprint(tw, "MODULE\tCURRENT\tDIRECT\tIMPORTERS\tRAISES\n")
+	}
+	var (
+		numAffected int
+		numDirect   int
+		numSource   int // affected modules that must change their imports
+		numRaised   int
+		repos       = make(map[string]bool)
+	)
+	if err := g.UpgradeImpact(ctx, path, version, func(imp *graph.Impact) error {
+		numAffected++
+		if imp.Direct {
+			numDirect++
+		}
+		if imp.Importers != 0 && strings.Contains(imp.Current, "@") {
+			numSource++
+		}
+		if len(imp.Raised) != 0 {
+			numRaised++
+		}
+		if imp.Repository != "" {
+			repos[imp.Repository] = true
+		}
+		if *doVerbose {
+			var raised []string
+			for _, req := range imp.Raised {
+				raised = append(raised, req.Path+"@"+req.Version)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%v\t%d\t%s\n", imp.Module, imp.Current, imp.Direct,
+				imp.Importers, strings.Join(raised, " "))
+		}
+		return nil
+	}); err != nil {
+		log.Fatalf("Estimating impact failed: %v", err)
+	}
+	if *doVerbose {
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "Upgrade:\t%s@%s\n", path, version)
+	fmt.Fprintf(tw, "Affected repositories:\t%d\n", len(repos))
+	fmt.Fprintf(tw, "Affected modules:\t%d\n", numAffected)
+	fmt.Fprintf(tw, "  requiring it directly:\t%d\n", numDirect)
+	fmt.Fprintf(tw, "  requiring it indirectly:\t%d\n", numAffected-numDirect)
+	fmt.Fprintf(tw, "  needing import changes:\t%d\n", numSource)
+	fmt.Fprintf(tw, "  with other versions raised:\t%d\n", numRaised)
+	tw.Flush()
+}
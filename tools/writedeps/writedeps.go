@@ -13,7 +13,8 @@
 // limitations under the License.
 
 // Program writedeps copies a stream of JSON-encoded *deps.Repo messages into a
-// graph in adjacency list format.
+// graph in adjacency list format. With -prune, packages that a repository no
+// longer defines are deleted from the graph, leaving tombstones.
 package main
 
 import (
@@ -24,13 +25,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/creachadair/fileinput"
 	"github.com/creachadair/repodeps/deps"
+	"github.com/creachadair/repodeps/graph"
 	"github.com/creachadair/repodeps/tools"
 )
 
-var storePath = flag.String("store", "", "Storage path (required)")
+var (
+	storePath   = flag.String("store", "", "Storage path (required)")
+	doPrune     = flag.Bool("prune", false, "Delete packages a rescanned repository no longer defines")
+	journalPath = flag.String("journal", "", "Record changes to the graph in this journal file")
+)
 
 func main() {
 	flag.Parse()
@@ -39,6 +46,11 @@ func main() {
 	if err != nil {
 		log.Fatalf("Opening graph: %v", err)
 	}
+	jc, err := tools.OpenJournal(g, *journalPath)
+	if err != nil {
+		log.Fatalf("Opening journal: %v", err)
+	}
+	defer jc.Close()
 
 	ctx := context.Background()
 	rc := fileinput.CatOrFile(ctx, flag.Args(), os.Stdin)
@@ -50,12 +62,25 @@ func main() {
 			log.Fatalf("Decoding failed: %v", err)
 		}
 		for _, repo := range msg {
+			if err := deps.CheckSchema(repo); err != nil {
+				log.Fatalf("Invalid input: %v", err)
+			}
 			for _, pkg := range repo.Packages {
 				if err := g.Add(ctx, repo, pkg); err != nil {
 					log.Fatalf("Adding package %q: %v", pkg.ImportPath, err)
 				}
 				fmt.Println(pkg.ImportPath)
 			}
+			if *doPrune {
+				if err := prune(ctx, g, repo); err != nil {
+					log.Fatalf("Pruning packages: %v", err)
+				}
+			}
+			for _, mod := range repo.Modules {
+				if err := g.AddModule(ctx, repo, mod); err != nil {
+					log.Fatalf("Adding module %q: %v", mod.Path, err)
+				}
+			}
 		}
 	}
 
@@ -63,3 +88,44 @@ func main() {
 		log.Fatalf("Closing storage: %v", err)
 	}
 }
+
+// prune deletes the packages of g recorded from repo that repo no longer
+// defines. Only the packages under the longest directory prefix shared by the
+// packages repo defines are considered, so a repository that now defines no
+// packages is not pruned.
+func prune(ctx context.Context, g *graph.Graph, repo *deps.Repo) error {
+	if len(repo.Remotes) == 0 || len(repo.Packages) == 0 {
+		return nil
+	}
+	url := repo.Remotes[0].Url
+	keep := make(map[string]bool)
+	prefix := repo.Packages[0].ImportPath
+	for _, pkg := range repo.Packages {
+		keep[pkg.ImportPath] = true
+		for !strings.HasPrefix(pkg.ImportPath, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if !keep[prefix] {
+		if i := strings.LastIndex(prefix, "/"); i >= 0 {
+			prefix = prefix[:i]
+		}
+	}
+
+	var gone []string
+	if err := g.Scan(ctx, prefix, func(row *graph.Row) error {
+		if row.Repository == url && !keep[row.ImportPath] {
+			gone = append(gone, row.ImportPath)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, ipath := range gone {
+		if err := g.Delete(ctx, ipath); err != nil {
+			return fmt.Errorf("deleting %q: %v", ipath, err)
+		}
+		log.Printf("Deleted %q", ipath)
+	}
+	return nil
+}